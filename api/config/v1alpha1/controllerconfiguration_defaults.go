@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	DefaultDaemonImageRepository = "cloudflare/cloudflared"
+	DefaultDaemonVersion         = "latest"
+	DefaultReleasesURL           = "https://api.github.com"
+	DefaultCatchAllService       = "http_status:404"
+
+	DefaultCloudflareRequestsPerSecond = 4
+	DefaultCloudflareRequestBurst      = 10
+	DefaultCloudflareMaxRetries        = 3
+	DefaultZoneCacheTTL                = 10 * time.Minute
+)
+
+// Default fills omitted fields with defaults of the operator.
+func (c *ControllerConfiguration) Default() {
+	c.APIVersion = GroupVersion.String()
+	c.Kind = "ControllerConfiguration"
+
+	c.Cloudflare.Default()
+	c.Daemon.Default()
+}
+
+func (c *CloudflareAPIConfiguration) Default() {
+	if c.RequestsPerSecond == 0 {
+		c.RequestsPerSecond = DefaultCloudflareRequestsPerSecond
+	}
+	if c.RequestBurst == 0 {
+		c.RequestBurst = DefaultCloudflareRequestBurst
+	}
+	if c.MaxRetries == nil {
+		c.MaxRetries = ptr.To(DefaultCloudflareMaxRetries)
+	}
+	if c.ZoneCacheTTL == nil {
+		c.ZoneCacheTTL = &metav1.Duration{Duration: DefaultZoneCacheTTL}
+	}
+}
+
+func (c *DaemonConfiguration) Default() {
+	if c.Image.Repository == "" {
+		c.Image.Repository = DefaultDaemonImageRepository
+	}
+	if c.DefaultVersion == "" {
+		c.DefaultVersion = DefaultDaemonVersion
+	}
+	c.VersionResolver.Default(c.Image)
+	if c.SecurityContext == nil {
+		c.SecurityContext = &corev1.SecurityContext{
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			ReadOnlyRootFilesystem:   ptr.To(true),
+			AllowPrivilegeEscalation: ptr.To(false),
+		}
+	}
+	if c.PodSecurityContext == nil {
+		c.PodSecurityContext = &corev1.PodSecurityContext{
+			RunAsUser:    ptr.To(int64(65532)),
+			RunAsNonRoot: ptr.To(true),
+		}
+	}
+	if c.LivenessProbe == nil {
+		c.LivenessProbe = &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/ready",
+					Port: intstr.FromInt32(2000),
+				},
+			},
+			InitialDelaySeconds: 10,
+			PeriodSeconds:       10,
+			FailureThreshold:    1,
+		}
+	}
+	if c.CatchAllService == "" {
+		c.CatchAllService = DefaultCatchAllService
+	}
+}
+
+func (c *DaemonVersionResolverConfiguration) Default(image DaemonImageConfiguration) {
+	if c.Type == "" {
+		c.Type = DaemonVersionResolverGitHub
+	}
+	if c.ReleasesURL == "" {
+		c.ReleasesURL = DefaultReleasesURL
+	}
+	if c.Repository == "" {
+		c.Repository = image.MirroredRepository()
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControllerConfiguration is the configuration file of cloudflared-operator manager, given by --config flag.
+// It carries cluster-wide defaults and restrictions that apply to every resource the operator manages.
+// Every field is optional, and omitted fields fall back to the values of Default.
+// +kubebuilder:object:root=true
+type ControllerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// WatchNamespaces restricts namespaces that the operator watches. Empty watches every namespace.
+	// +optional
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// AllowedAccountIDs restricts Cloudflare accounts that resources may refer to.
+	// Resources of other accounts are not reconciled and get AccountNotAllowed reason.
+	// Empty allows every account.
+	// +optional
+	AllowedAccountIDs []string `json:"allowedAccountIDs,omitempty"`
+
+	// Cloudflare configures clients of Cloudflare API.
+	// +optional
+	Cloudflare CloudflareAPIConfiguration `json:"cloudflare,omitempty"`
+
+	// Daemon holds defaults of cloudflared workloads deployed for Tunnels.
+	// +optional
+	Daemon DaemonConfiguration `json:"daemon,omitempty"`
+}
+
+type CloudflareAPIConfiguration struct {
+	// BaseURL overrides the endpoint of Cloudflare API, e.g. https://api.cloudflare.com/client/v4.
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+
+	// RequestsPerSecond is the sustained request rate to Cloudflare API per account.
+	// +optional
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+
+	// RequestBurst is the burst size of requests to Cloudflare API per account.
+	// +optional
+	RequestBurst int `json:"requestBurst,omitempty"`
+
+	// MaxRetries is how many times a request is retried on 429 and 5xx responses.
+	// +optional
+	MaxRetries *int `json:"maxRetries,omitempty"`
+
+	// ZoneCacheTTL is how long the zone list of an account is cached.
+	// +optional
+	ZoneCacheTTL *metav1.Duration `json:"zoneCacheTTL,omitempty"`
+}
+
+type DaemonConfiguration struct {
+	// Image configures where cloudflared images are pulled from.
+	// +optional
+	Image DaemonImageConfiguration `json:"image,omitempty"`
+
+	// DefaultVersion is the cloudflared version of Tunnels without spec.daemonDeployment.daemonVersion.
+	// "latest" follows the latest release.
+	// +optional
+	DefaultVersion string `json:"defaultVersion,omitempty"`
+
+	// VersionResolver selects where daemon versions are resolved and verified.
+	// +optional
+	VersionResolver DaemonVersionResolverConfiguration `json:"versionResolver,omitempty"`
+
+	// Resources is used for cloudflared containers of Tunnels without spec.daemonDeployment.resources.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Tolerations is used for cloudflared pods of Tunnels without spec.daemonDeployment.tolerations.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// CommonLabels are added to every cloudflared workload and pod. Labels of Tunnel take precedence.
+	// Labels that the operator selects pods with can not be overridden.
+	// +optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// SecurityContext of cloudflared containers.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// PodSecurityContext of cloudflared pods.
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// LivenessProbe of cloudflared containers. cloudflared serves /ready on its metrics port 2000.
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+
+	// CatchAllService is the service of the last ingress rule, which serves requests that match no TunnelIngress.
+	// +optional
+	CatchAllService string `json:"catchAllService,omitempty"`
+}
+
+type DaemonImageConfiguration struct {
+	// Repository of cloudflared image, without tag.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// RegistryMirror replaces the registry of Repository, e.g. "registry.example.com/dockerhub".
+	// Air-gapped clusters pull cloudflared from "<RegistryMirror>/<path of Repository>".
+	// +optional
+	RegistryMirror string `json:"registryMirror,omitempty"`
+
+	// PullSecrets are added to every cloudflared pod, e.g. credentials of RegistryMirror.
+	// +optional
+	PullSecrets []corev1.LocalObjectReference `json:"pullSecrets,omitempty"`
+}
+
+// DaemonVersionResolverType is a source of cloudflared versions.
+type DaemonVersionResolverType string
+
+const (
+	// DaemonVersionResolverGitHub looks up GitHub releases of cloudflared.
+	DaemonVersionResolverGitHub DaemonVersionResolverType = "GitHub"
+	// DaemonVersionResolverOCI lists tags of the cloudflared image repository on its registry.
+	DaemonVersionResolverOCI DaemonVersionResolverType = "OCI"
+	// DaemonVersionResolverStatic only accepts versions of an allow-list, without any network access.
+	DaemonVersionResolverStatic DaemonVersionResolverType = "Static"
+)
+
+type DaemonVersionResolverConfiguration struct {
+	// Type is one of GitHub, OCI or Static. Air-gapped clusters use OCI with a registry mirror, or Static.
+	// +optional
+	Type DaemonVersionResolverType `json:"type,omitempty"`
+
+	// ReleasesURL is the GitHub API endpoint that cloudflared releases are looked up from. Used by GitHub.
+	// +optional
+	ReleasesURL string `json:"releasesURL,omitempty"`
+
+	// Repository whose tags are listed by OCI. Defaults to the daemon image repository with registry mirror applied.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// PlainHTTP talks to the registry of Repository without TLS. Used by OCI.
+	// +optional
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
+
+	// Versions allowed by Static. The newest of them is "latest".
+	// +optional
+	Versions []string `json:"versions,omitempty"`
+}
+
+// MirroredRepository returns Repository with its registry replaced by RegistryMirror, if any,
+// so that "cloudflare/cloudflared" becomes "<RegistryMirror>/cloudflare/cloudflared".
+func (c DaemonImageConfiguration) MirroredRepository() string {
+	repository := c.Repository
+	if c.RegistryMirror == "" {
+		return repository
+	}
+	if registry, path, found := strings.Cut(repository, "/"); found &&
+		(strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		repository = path
+	}
+	return strings.TrimSuffix(c.RegistryMirror, "/") + "/" + repository
+}
+
+func init() {
+	SchemeBuilder.Register(&ControllerConfiguration{})
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessServiceTokenSpec defines the desired state of AccessServiceToken
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.tunnelRef.namespace)",message="tunnelRef.namespace is not supported"
+type AccessServiceTokenSpec struct {
+	// The service token name. It wil show up in Cloudflare Zero Trust dashboard.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// TunnelRef is the Tunnel whose AccountID and APITokenSecretRef are used to manage the service token.
+	TunnelRef TunnelRef `json:"tunnelRef"`
+
+	// Duration is the lifetime of the service token. Defaults to 8760h (1 year).
+	//
+	// +optional
+	//+kubebuilder:default:="8760h"
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before expiry the service token is refreshed. Defaults to 720h (30 days).
+	//
+	// +optional
+	//+kubebuilder:default:="720h"
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// SecretName is for generated client ID and secret. Defaults to cloudflare-access-service-token-<NAME>
+	//
+	// +kubebuilder:validation:Pattern:="[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*"
+	// +optional
+	SecretName *string `json:"secretName,omitempty"`
+}
+
+func (s *AccessServiceTokenSpec) TokenSecretName() string {
+	if s.SecretName != nil {
+		return *s.SecretName
+	}
+	return "cloudflare-access-service-token-" + s.Name
+}
+
+// AccessServiceTokenConditionType ...
+// +kubebuilder:validation:Enum=Token;Secret
+type AccessServiceTokenConditionType string
+
+const (
+	AccessServiceTokenConditionTypeToken  AccessServiceTokenConditionType = "Token"
+	AccessServiceTokenConditionTypeSecret AccessServiceTokenConditionType = "Secret"
+)
+
+// AccessServiceTokenConditionReason ...
+// +kubebuilder:validation:Enum=Creating;NoToken;AccountNotAllowed;FailedToConnectCloudflare;FailedToCreateToken;FailedToRenewToken;FailedToRotateToken;FailedToDeleteToken;TokenRequired;FailedToCreateSecret;FailedToUpdateSecret;FailedToGetExistingSecret
+type AccessServiceTokenConditionReason string
+
+const (
+	ServiceTokenReasonCreating            AccessServiceTokenConditionReason = "Creating"
+	ServiceTokenReasonNoToken             AccessServiceTokenConditionReason = "NoToken"
+	ServiceTokenReasonAccountNotAllowed   AccessServiceTokenConditionReason = "AccountNotAllowed"
+	ServiceTokenReasonFailedToConnectCF   AccessServiceTokenConditionReason = "FailedToConnectCloudflare"
+	ServiceTokenReasonFailedToCreateToken AccessServiceTokenConditionReason = "FailedToCreateToken"
+	ServiceTokenReasonFailedToRenewToken  AccessServiceTokenConditionReason = "FailedToRenewToken"
+	ServiceTokenReasonFailedToRotateToken AccessServiceTokenConditionReason = "FailedToRotateToken"
+	ServiceTokenReasonFailedToDeleteToken AccessServiceTokenConditionReason = "FailedToDeleteToken"
+
+	ServiceTokenSecretReasonTokenRequired             AccessServiceTokenConditionReason = "TokenRequired"
+	ServiceTokenSecretReasonFailedToCreateSecret      AccessServiceTokenConditionReason = "FailedToCreateSecret"
+	ServiceTokenSecretReasonFailedToUpdateSecret      AccessServiceTokenConditionReason = "FailedToUpdateSecret"
+	ServiceTokenSecretReasonFailedToGetExistingSecret AccessServiceTokenConditionReason = "FailedToGetExistingSecret"
+)
+
+type AccessServiceTokenStatusCondition struct {
+	// Type of condition for a component.
+	// Valid value: "Token", "Secret"
+	Type AccessServiceTokenConditionType `json:"type"`
+
+	// Status of the condition for a component.
+	// Valid values for "Token", "Secret": "True", "False", or "Unknown".
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Message about the condition for a component.
+	// For example, information about a health check.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Error is Condition error code for a component.
+	// For example, a health check error code.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// +optional
+	Reason AccessServiceTokenConditionReason `json:"reason,omitempty"`
+}
+
+func (c AccessServiceTokenStatusCondition) GetConditionType() AccessServiceTokenConditionType {
+	return c.Type
+}
+
+func (c AccessServiceTokenStatusCondition) Equals(o AccessServiceTokenStatusCondition) bool {
+	return c.Type == o.Type && c.Status == o.Status && c.Message == o.Message &&
+		c.Error == o.Error && c.Reason == o.Reason
+}
+
+// AccessServiceTokenStatus defines the observed state of AccessServiceToken
+type AccessServiceTokenStatus struct {
+	Conditions []AccessServiceTokenStatusCondition `json:"conditions,omitempty"`
+
+	// TokenID is the Cloudflare ID of the service token.
+	// Access policies refer the service token by this ID in their `service_token` rules.
+	//
+	// +optional
+	TokenID string `json:"tokenID,omitempty"`
+
+	// AccountID is the Cloudflare account that owns the service token.
+	//
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
+
+	// ClientID is the public half of the service token. It is also stored in the generated Secret.
+	//
+	// +optional
+	ClientID string `json:"clientID,omitempty"`
+
+	// ExpiresAt is when the service token expires unless it is renewed.
+	//
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// PendingSince is set before a service token is created in AccountID, and cleared once its TokenID is saved.
+	// If TokenID failed to be saved, a service token of the same name created since then is adopted
+	// instead of creating another one.
+	//
+	// +optional
+	PendingSince *metav1.Time `json:"pendingSince,omitempty"`
+}
+
+func (s *AccessServiceTokenStatus) GetCondition(
+	condType AccessServiceTokenConditionType,
+) AccessServiceTokenStatusCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == condType {
+			return s.Conditions[i]
+		}
+	}
+	return AccessServiceTokenStatusCondition{}
+}
+
+func (s *AccessServiceTokenStatus) SetCondition(condition AccessServiceTokenStatusCondition) {
+	idx := slices.IndexFunc(s.Conditions, func(c AccessServiceTokenStatusCondition) bool {
+		return c.Type == condition.Type
+	})
+	if idx == -1 {
+		s.Conditions = append(s.Conditions, condition)
+	} else {
+		s.Conditions[idx] = condition
+	}
+}
+
+// AccessServiceToken is the Schema for the accessservicetokens API
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Token Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Token ID",type=string,JSONPath=`.status.tokenID`
+// +kubebuilder:printcolumn:name="Expires At",type=date,JSONPath=`.status.expiresAt`
+type AccessServiceToken struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccessServiceTokenSpec   `json:"spec,omitempty"`
+	Status AccessServiceTokenStatus `json:"status,omitempty"`
+}
+
+// AccessServiceTokenList contains a list of AccessServiceToken
+//
+// +kubebuilder:object:root=true
+type AccessServiceTokenList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessServiceToken `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AccessServiceToken{}, &AccessServiceTokenList{})
+}
@@ -1,9 +1,16 @@
 package v1
 
 import (
+	"regexp"
+
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // OriginTLSSettings holds the TLS specific settings.
@@ -131,14 +138,181 @@ const (
 	DeploymentKindDeployment DeploymentKind = "Deployment"
 )
 
+// DaemonImage is the container image of cloudflared.
+type DaemonImage struct {
+	// Repository of the image without tag, e.g. registry.example.com/cloudflare/cloudflared.
+	// Defaults to the repository of the operator's configuration, with its registry mirror applied.
+	//
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Tag of the image. It is used as is, so DaemonVersion is neither resolved nor verified.
+	//
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest pins the image, e.g. sha256:<hex>. Without Tag, a DaemonVersion other than latest is used as tag
+	// without being verified, so that pinned images never need the version resolver.
+	//
+	// +optional
+	//+kubebuilder:validation:Pattern:=`^sha256:[a-f0-9]{64}$`
+	Digest string `json:"digest,omitempty"`
+
+	// Image pull policy. One of Always, Never, IfNotPresent.
+	// Defaults to Always if :latest tag is specified, or IfNotPresent otherwise.
+	//
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+
+	// ImagePullSecrets are added to pull secrets of the operator's configuration.
+	//
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// DaemonUpgradePolicy controls automatic upgrades of cloudflared following the latest release.
+type DaemonUpgradePolicy struct {
+	// Constraint restricts versions to upgrade to, e.g. "~2024.2" for patch releases of 2024.2 only,
+	// or ">=2024.4.0, <2025" for releases of 2024 since 2024.4.0.
+	//
+	// +optional
+	Constraint string `json:"constraint,omitempty"`
+
+	// MaintenanceWindows are when upgrades may start. Empty allows any time.
+	//
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// MinReleaseAge is how long a release has to be available before it is adopted.
+	// The age counts from when the operator first found the release.
+	//
+	// +optional
+	MinReleaseAge *metav1.Duration `json:"minReleaseAge,omitempty"`
+
+	// WaitFor selects Tunnels that have to run a version with healthy connectors before this Tunnel adopts it,
+	// e.g. canary Tunnels labeled for an earlier stage of the rollout.
+	//
+	// +optional
+	WaitFor *metav1.LabelSelector `json:"waitFor,omitempty"`
+
+	// HealthTimeout is how long an upgraded daemon has to become ready.
+	// Otherwise it is rolled back, and the version is not adopted again. Defaults to 10m.
+	//
+	// +optional
+	HealthTimeout *metav1.Duration `json:"healthTimeout,omitempty"`
+
+	// CheckInterval is how often new releases are looked up. Defaults to 1h.
+	//
+	// +optional
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+}
+
+// MaintenanceWindow is a recurring period of time.
+type MaintenanceWindow struct {
+	// Days of week that the window starts on. Empty means every day.
+	//
+	// +optional
+	//+kubebuilder:validation:items:Enum:=Sun;Mon;Tue;Wed;Thu;Fri;Sat
+	Days []string `json:"days,omitempty"`
+
+	// Start is the time of day that the window starts at, in HH:MM.
+	//
+	//+kubebuilder:validation:Pattern:=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// Duration of the window.
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone of Start, in IANA time zone database name. Defaults to UTC.
+	//
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// DaemonDisruptionBudget declares a PodDisruptionBudget of cloudflared pods, so that voluntary disruptions such as
+// node drains can not take all connectors of the tunnel down at once.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.minAvailable) != has(self.maxUnavailable)",message="exactly one of minAvailable or maxUnavailable must be set"
+type DaemonDisruptionBudget struct {
+	// MinAvailable is the number or percentage of pods that must be available after an eviction.
+	//
+	// +optional
+	//+kubebuilder:validation:XIntOrString
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that can be unavailable after an eviction.
+	//
+	// +optional
+	//+kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// UnhealthyPodEvictionPolicy defines the criteria for when unhealthy pods should be considered for eviction.
+	// Refer the field of the same name in PodDisruptionBudgetSpec.
+	//
+	// +optional
+	UnhealthyPodEvictionPolicy *policyv1.UnhealthyPodEvictionPolicyType `json:"unhealthyPodEvictionPolicy,omitempty"`
+}
+
+// DaemonAutoscaling declares a HorizontalPodAutoscaler of cloudflared. (only applies when kind == Deployment)
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not be greater than maxReplicas"
+type DaemonAutoscaling struct {
+	// MinReplicas is the lower limit for the number of replicas. Defaults to 1.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum:=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of replicas.
+	//
+	//+kubebuilder:validation:Minimum:=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization over all pods,
+	// in percentage of the requested CPU.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum:=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetConcurrentRequests is the target average of concurrent requests per pod, read from the
+	// cloudflared_tunnel_concurrent_requests_per_tunnel metric of cloudflared.
+	// It requires a custom metrics API serving the metric, e.g. prometheus-adapter.
+	//
+	// +optional
+	TargetConcurrentRequests *resource.Quantity `json:"targetConcurrentRequests,omitempty"`
+
+	// Metrics are added to the metrics above, e.g. to scale on other metrics of cloudflared.
+	// Without any metric, it scales on 80% of CPU utilization.
+	//
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+
+	// Behavior configures the scaling behavior in both up and down directions.
+	//
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
 type Deployment struct {
-	// DaemonVersion specify Cloudfalred version to deploy. Defaults to latest.
+	// DaemonVersion specify Cloudfalred version to deploy. "latest" follows the latest release.
+	// Defaults to the default version of the operator's configuration, which is latest unless configured.
 	// Refer https://github.com/cloudflare/cloudflared/releases to available versions.
 	//
 	// +optional
-	//+kubebuilder:default:=latest
 	DaemonVersion string `json:"daemonVersion,omitempty"`
 
+	// Image overrides the cloudflared image. Defaults to the image of the operator's configuration.
+	//
+	// +optional
+	Image *DaemonImage `json:"image,omitempty"`
+
+	// UpgradePolicy controls how a DaemonVersion of latest moves to new releases.
+	// Without it, new releases are adopted as soon as they are found.
+	//
+	// +optional
+	UpgradePolicy *DaemonUpgradePolicy `json:"upgradePolicy,omitempty"`
+
 	//+kubebuilder:default:=Deployment
 	Kind DeploymentKind `json:"kind"`
 
@@ -147,6 +321,17 @@ type Deployment struct {
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// Autoscaling creates a HorizontalPodAutoscaler of the Deployment, which takes over Replicas.
+	// (only applies when kind == Deployment)
+	//
+	// +optional
+	Autoscaling *DaemonAutoscaling `json:"autoscaling,omitempty"`
+
+	// DisruptionBudget creates a PodDisruptionBudget of cloudflared pods.
+	//
+	// +optional
+	DisruptionBudget *DaemonDisruptionBudget `json:"disruptionBudget,omitempty"`
+
 	// The deployment strategy to use to replace existing pods with new ones. (only applies when kind == Deployment)
 	// +optional
 	// +patchStrategy=retainKeys
@@ -221,20 +406,56 @@ type Deployment struct {
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// PreStopDelay delays stopping cloudflared after its pod starts terminating,
+	// so that the pod leaves endpoints before connections are drained for GracePeriod of TunnelRunParameters.
+	// It lasts at least as long as the readiness probe of cloudflared takes to fail.
+	// It uses the sleep action of preStop hook, as cloudflared images have no shell to run sleep in.
+	// So it requires Kubernetes 1.30 or later, which enables the sleep action by default,
+	// and is ignored on API servers of older versions.
+	//
+	// +optional
+	PreStopDelay *metav1.Duration `json:"preStopDelay,omitempty"`
+
 	// Compute Resources required by this container.
 	// Cannot be updated.
 	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// PodTemplate is a strategic merge patch of PodTemplateSpec, merged over the pod template that the operator
+	// generates. Use it for fields not covered above, e.g. topologySpreadConstraints, priorityClassName,
+	// serviceAccountName, extra env, volumes or sidecars. Containers are merged by name, and the daemon container is
+	// named cloudflared.
+	// The credential and config volumes, the image, command, args and volume mounts of cloudflared container,
+	// and labels and annotations that the operator sets on pods can not be changed.
+	//
+	// +optional
+	//+kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *runtime.RawExtension `json:"podTemplate,omitempty"`
 }
 
 type TunnelConfigIngress struct {
-	Hostname      *string              `json:"hostname,omitempty"`
-	Path          *string              `json:"path,omitempty"`
+	Hostname *string `json:"hostname,omitempty"`
+	Path     *string `json:"path,omitempty"`
+
+	// Service is the origin in the form of cloudflared ingress rule,
+	// e.g. http://web.default.svc:8080, ssh://bastion:22, unix:/run/app.sock or http_status:404.
+	// The scheme-less host:port form that Services were exposed with by earlier releases is deprecated,
+	// and read as http://host:port.
+	//
+	// +optional
+	//+kubebuilder:validation:Pattern:=`^((https?|tcp|ssh|rdp|smb|wss?)://[^/]+|unix(\+tls)?:/.+|http_status:[1-5][0-9]{2}|hello_world|bastion|socks5|[^:/_]+:[0-9]+)$`
 	Service       string               `json:"service,omitempty"`
 	OriginRequest *OriginRequestConfig `json:"originRequest,omitempty"`
 }
 
+var legacyServicePattern = regexp.MustCompile(`^[^:/_]+:[0-9]+$`)
+
+// IsLegacyService reports whether service is in the deprecated host:port form.
+func IsLegacyService(service string) bool {
+	return legacyServicePattern.MatchString(service)
+}
+
 type OriginRequestConfig struct {
 	*OriginTLSSettings        `json:",inline"`
 	*OriginHTTPSettings       `json:",inline"`
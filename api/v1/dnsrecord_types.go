@@ -0,0 +1,251 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SRVRecordData is the content of SRV record.
+type SRVRecordData struct {
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	Weight int32 `json:"weight"`
+
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	//+kubebuilder:validation:MinLength=1
+	Target string `json:"target"`
+}
+
+// CAARecordData is the content of CAA record.
+type CAARecordData struct {
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=255
+	Flags int32 `json:"flags,omitempty"`
+
+	//+kubebuilder:validation:Enum:=issue;issuewild;iodef
+	Tag string `json:"tag"`
+
+	//+kubebuilder:validation:MinLength=1
+	Value string `json:"value"`
+}
+
+// DNSRecordSpec defines the desired state of DNSRecord
+//
+// +kubebuilder:validation:XValidation:rule="self.type in ['SRV', 'CAA'] || has(self.content)",message="content is required for the record type"
+// +kubebuilder:validation:XValidation:rule="self.type != 'SRV' || has(self.srv)",message="srv is required for SRV record"
+// +kubebuilder:validation:XValidation:rule="self.type != 'CAA' || has(self.caa)",message="caa is required for CAA record"
+// +kubebuilder:validation:XValidation:rule="!(self.type in ['MX', 'SRV']) || has(self.priority)",message="priority is required for MX and SRV record"
+// +kubebuilder:validation:XValidation:rule="!has(self.proxied) || !self.proxied || self.type in ['A', 'AAAA', 'CNAME']",message="only A, AAAA and CNAME record can be proxied"
+type DNSRecordSpec struct {
+	// Name is the fully qualified domain name of the record (e.g. www.example.com). Its zone must be in the account.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	//+kubebuilder:validation:Enum:=A;AAAA;CNAME;TXT;MX;SRV;CAA
+	Type string `json:"type"`
+
+	// Content of the record. IP address for A and AAAA, hostname for CNAME and MX, and text for TXT.
+	//
+	// +optional
+	Content *string `json:"content,omitempty"`
+
+	// Priority of MX and SRV record.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	Priority *int32 `json:"priority,omitempty"`
+
+	// SRV is the content of SRV record.
+	//
+	// +optional
+	SRV *SRVRecordData `json:"srv,omitempty"`
+
+	// CAA is the content of CAA record.
+	//
+	// +optional
+	CAA *CAARecordData `json:"caa,omitempty"`
+
+	// Proxied makes the traffic go through Cloudflare.
+	//
+	// +optional
+	Proxied *bool `json:"proxied,omitempty"`
+
+	// TTL of the record in seconds. 1 means automatic. Proxied records always use automatic.
+	//
+	// +optional
+	//+kubebuilder:default:=1
+	//+kubebuilder:validation:XValidation:rule="self == 1 || (self >= 30 && self <= 86400)",message="ttl must be 1 (automatic) or between 30 and 86400"
+	TTL *int32 `json:"ttl,omitempty"`
+
+	// Comment is an optional note of the record. It will show up in Cloudflare dashboard.
+	//
+	// +optional
+	Comment *string `json:"comment,omitempty"`
+
+	// Tags of the record in the format of name:value.
+	//
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// Cloudflared's account id that the zone of the record belongs to.
+	// Refer https://developers.cloudflare.com/fundamentals/setup/find-account-and-zone-ids/ to find the value.
+	//
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:MinLength=1
+	AccountID string `json:"accountID"`
+
+	// Reference to secret resource that contains Cloudflare API token.
+	APITokenSecretRef SecretKeyRef `json:"apiTokenSecretRef"`
+}
+
+// DNSRecordConditionType ...
+// +kubebuilder:validation:Enum=Record
+type DNSRecordConditionType string
+
+const (
+	DNSRecordConditionTypeRecord DNSRecordConditionType = "Record"
+)
+
+// DNSRecordConditionReason ...
+// +kubebuilder:validation:Enum=Creating;NoToken;AccountNotAllowed;FailedToConnectCloudflare;FailedToGetRecord;FailedToCreateRecord;FailedToUpdateRecord;FailedToDeleteRecord;PolicyViolation
+type DNSRecordConditionReason string
+
+const (
+	RecordReasonCreating             DNSRecordConditionReason = "Creating"
+	RecordReasonNoToken              DNSRecordConditionReason = "NoToken"
+	RecordReasonAccountNotAllowed    DNSRecordConditionReason = "AccountNotAllowed"
+	RecordReasonFailedToConnectCF    DNSRecordConditionReason = "FailedToConnectCloudflare"
+	RecordReasonFailedToGetRecord    DNSRecordConditionReason = "FailedToGetRecord"
+	RecordReasonFailedToCreateRecord DNSRecordConditionReason = "FailedToCreateRecord"
+	RecordReasonFailedToUpdateRecord DNSRecordConditionReason = "FailedToUpdateRecord"
+	RecordReasonFailedToDeleteRecord DNSRecordConditionReason = "FailedToDeleteRecord"
+	RecordReasonPolicyViolation      DNSRecordConditionReason = "PolicyViolation"
+)
+
+type DNSRecordStatusCondition struct {
+	// Type of condition for a component.
+	// Valid value: "Record"
+	Type DNSRecordConditionType `json:"type"`
+
+	// Status of the condition for a component.
+	// Valid values for "Record": "True", "False", or "Unknown".
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Message about the condition for a component.
+	// For example, information about a health check.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Error is Condition error code for a component.
+	// For example, a health check error code.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// +optional
+	Reason DNSRecordConditionReason `json:"reason,omitempty"`
+}
+
+func (c DNSRecordStatusCondition) GetConditionType() DNSRecordConditionType {
+	return c.Type
+}
+
+func (c DNSRecordStatusCondition) Equals(o DNSRecordStatusCondition) bool {
+	return c.Type == o.Type && c.Status == o.Status && c.Message == o.Message &&
+		c.Error == o.Error && c.Reason == o.Reason
+}
+
+// DNSRecordStatus defines the observed state of DNSRecord
+type DNSRecordStatus struct {
+	Conditions []DNSRecordStatusCondition `json:"conditions,omitempty"`
+
+	// RecordID is the Cloudflare ID of the record.
+	//
+	// +optional
+	RecordID string `json:"recordID,omitempty"`
+
+	// Name is the name that the record is currently registered with.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// AccountID is the account that the zone of the record belongs to.
+	//
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
+}
+
+func (s *DNSRecordStatus) GetCondition(condType DNSRecordConditionType) DNSRecordStatusCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == condType {
+			return s.Conditions[i]
+		}
+	}
+	return DNSRecordStatusCondition{}
+}
+
+func (s *DNSRecordStatus) SetCondition(condition DNSRecordStatusCondition) {
+	idx := slices.IndexFunc(s.Conditions, func(c DNSRecordStatusCondition) bool {
+		return c.Type == condition.Type
+	})
+	if idx == -1 {
+		s.Conditions = append(s.Conditions, condition)
+	} else {
+		s.Conditions[idx] = condition
+	}
+}
+
+// DNSRecord is the Schema for the dnsrecords API
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Content",type=string,JSONPath=`.spec.content`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Record")].status`
+type DNSRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSRecordSpec   `json:"spec,omitempty"`
+	Status DNSRecordStatus `json:"status,omitempty"`
+}
+
+// DNSRecordList contains a list of DNSRecord
+//
+// +kubebuilder:object:root=true
+type DNSRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DNSRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DNSRecord{}, &DNSRecordList{})
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LoadBalancerPoolRef refers a pool either by LoadBalancerPool resource in the same namespace,
+// or by Cloudflare ID for pools that are managed elsewhere (e.g. by the operator of another cluster).
+//
+// +kubebuilder:validation:XValidation:rule="has(self.name) != has(self.id)",message="exactly one of name or id must be set"
+type LoadBalancerPoolRef struct {
+	// Name of LoadBalancerPool resource in the same namespace.
+	//
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// ID of the pool on Cloudflare.
+	//
+	// +optional
+	ID *string `json:"id,omitempty"`
+}
+
+// LoadBalancerDefaultPool is a pool in the failover order of the load balancer.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.name) != has(self.id)",message="exactly one of name or id must be set"
+type LoadBalancerDefaultPool struct {
+	// Name of LoadBalancerPool resource in the same namespace.
+	//
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// ID of the pool on Cloudflare.
+	//
+	// +optional
+	ID *string `json:"id,omitempty"`
+
+	// Weight of the pool in percent. Used by random, least_outstanding_requests and least_connections steering.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=100
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+func (p LoadBalancerDefaultPool) Ref() LoadBalancerPoolRef {
+	return LoadBalancerPoolRef{Name: p.Name, ID: p.ID}
+}
+
+// LoadBalancerSpec defines the desired state of LoadBalancer
+type LoadBalancerSpec struct {
+	// Hostname is the DNS name of the load balancer. Its zone must be in the account.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Hostname string `json:"hostname"`
+
+	// Cloudflared's account id that the zone of the hostname belongs to.
+	// Refer https://developers.cloudflare.com/fundamentals/setup/find-account-and-zone-ids/ to find the value.
+	//
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:MinLength=1
+	AccountID string `json:"accountID"`
+
+	// Reference to secret resource that contains Cloudflare API token.
+	APITokenSecretRef SecretKeyRef `json:"apiTokenSecretRef"`
+
+	// +optional
+	Description *string `json:"description,omitempty"`
+
+	// +optional
+	//+kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Proxied makes the traffic go through Cloudflare. Tunnel origins are only reachable when proxied.
+	//
+	// +optional
+	//+kubebuilder:default:=true
+	Proxied *bool `json:"proxied,omitempty"`
+
+	// TTL of the DNS record in seconds. Only applies to non-proxied load balancers.
+	//
+	// +optional
+	TTL *int32 `json:"ttl,omitempty"`
+
+	// DefaultPools is the failover order of the pools.
+	//
+	//+kubebuilder:validation:MinItems=1
+	DefaultPools []LoadBalancerDefaultPool `json:"defaultPools"`
+
+	// FallbackPool is used when all other pools are unhealthy. Defaults to the last one of defaultPools.
+	//
+	// +optional
+	FallbackPool *LoadBalancerPoolRef `json:"fallbackPool,omitempty"`
+
+	// SteeringPolicy decides how traffic is distributed across the pools.
+	// Refer https://developers.cloudflare.com/load-balancing/understand-basics/traffic-steering/steering-policies/ for details.
+	//
+	// +optional
+	//+kubebuilder:validation:Enum:=off;geo;random;dynamic_latency;proximity;least_outstanding_requests;least_connections
+	SteeringPolicy *string `json:"steeringPolicy,omitempty"`
+
+	// RegionPools maps region codes (e.g. WNAM, EEU) to the pools for geo steering.
+	//
+	// +optional
+	RegionPools map[string][]LoadBalancerPoolRef `json:"regionPools,omitempty"`
+
+	// CountryPools maps country codes (e.g. US, KR) to the pools for geo steering.
+	//
+	// +optional
+	CountryPools map[string][]LoadBalancerPoolRef `json:"countryPools,omitempty"`
+
+	// PopPools maps Cloudflare data center codes (e.g. ICN, LAX) to the pools for geo steering.
+	//
+	// +optional
+	PopPools map[string][]LoadBalancerPoolRef `json:"popPools,omitempty"`
+
+	// SessionAffinity sends the requests of the same client to the same origin.
+	//
+	// +optional
+	//+kubebuilder:validation:Enum:=none;cookie;ip_cookie;header
+	SessionAffinity *string `json:"sessionAffinity,omitempty"`
+
+	// SessionAffinityTTL is the time in seconds that the session affinity is kept.
+	//
+	// +optional
+	SessionAffinityTTL *int32 `json:"sessionAffinityTTL,omitempty"`
+}
+
+// LoadBalancerConditionType ...
+// +kubebuilder:validation:Enum=LoadBalancer
+type LoadBalancerConditionType string
+
+const (
+	LoadBalancerConditionTypeLoadBalancer LoadBalancerConditionType = "LoadBalancer"
+)
+
+// LoadBalancerConditionReason ...
+// +kubebuilder:validation:Enum=Creating;NoToken;AccountNotAllowed;FailedToConnectCloudflare;PoolNotReady;FailedToGetLoadBalancer;FailedToCreateLoadBalancer;FailedToUpdateLoadBalancer;FailedToDeleteLoadBalancer;PolicyViolation
+type LoadBalancerConditionReason string
+
+const (
+	LoadBalancerReasonCreating          LoadBalancerConditionReason = "Creating"
+	LoadBalancerReasonNoToken           LoadBalancerConditionReason = "NoToken"
+	LoadBalancerReasonAccountNotAllowed LoadBalancerConditionReason = "AccountNotAllowed"
+	LoadBalancerReasonFailedToConnectCF LoadBalancerConditionReason = "FailedToConnectCloudflare"
+	LoadBalancerReasonPoolNotReady      LoadBalancerConditionReason = "PoolNotReady"
+	LoadBalancerReasonFailedToGet       LoadBalancerConditionReason = "FailedToGetLoadBalancer"
+	LoadBalancerReasonFailedToCreate    LoadBalancerConditionReason = "FailedToCreateLoadBalancer"
+	LoadBalancerReasonFailedToUpdate    LoadBalancerConditionReason = "FailedToUpdateLoadBalancer"
+	LoadBalancerReasonFailedToDelete    LoadBalancerConditionReason = "FailedToDeleteLoadBalancer"
+	LoadBalancerReasonPolicyViolation   LoadBalancerConditionReason = "PolicyViolation"
+)
+
+type LoadBalancerStatusCondition struct {
+	// Type of condition for a component.
+	// Valid value: "LoadBalancer"
+	Type LoadBalancerConditionType `json:"type"`
+
+	// Status of the condition for a component.
+	// Valid values for "LoadBalancer": "True", "False", or "Unknown".
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Message about the condition for a component.
+	// For example, information about a health check.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Error is Condition error code for a component.
+	// For example, a health check error code.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// +optional
+	Reason LoadBalancerConditionReason `json:"reason,omitempty"`
+}
+
+func (c LoadBalancerStatusCondition) GetConditionType() LoadBalancerConditionType {
+	return c.Type
+}
+
+func (c LoadBalancerStatusCondition) Equals(o LoadBalancerStatusCondition) bool {
+	return c.Type == o.Type && c.Status == o.Status && c.Message == o.Message &&
+		c.Error == o.Error && c.Reason == o.Reason
+}
+
+// LoadBalancerStatus defines the observed state of LoadBalancer
+type LoadBalancerStatus struct {
+	Conditions []LoadBalancerStatusCondition `json:"conditions,omitempty"`
+
+	// LoadBalancerID is the Cloudflare ID of the load balancer.
+	//
+	// +optional
+	LoadBalancerID string `json:"loadBalancerID,omitempty"`
+
+	// Hostname is the hostname that the load balancer is currently registered with.
+	//
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// AccountID is the account that the zone of the load balancer belongs to.
+	//
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
+}
+
+func (s *LoadBalancerStatus) GetCondition(condType LoadBalancerConditionType) LoadBalancerStatusCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == condType {
+			return s.Conditions[i]
+		}
+	}
+	return LoadBalancerStatusCondition{}
+}
+
+func (s *LoadBalancerStatus) SetCondition(condition LoadBalancerStatusCondition) {
+	idx := slices.IndexFunc(s.Conditions, func(c LoadBalancerStatusCondition) bool {
+		return c.Type == condition.Type
+	})
+	if idx == -1 {
+		s.Conditions = append(s.Conditions, condition)
+	} else {
+		s.Conditions[idx] = condition
+	}
+}
+
+// LoadBalancer is the Schema for the loadbalancers API
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Hostname",type=string,JSONPath=`.spec.hostname`
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.loadBalancerID`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="LoadBalancer")].status`
+type LoadBalancer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LoadBalancerSpec   `json:"spec,omitempty"`
+	Status LoadBalancerStatus `json:"status,omitempty"`
+}
+
+// LoadBalancerList contains a list of LoadBalancer
+//
+// +kubebuilder:object:root=true
+type LoadBalancerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LoadBalancer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LoadBalancer{}, &LoadBalancerList{})
+}
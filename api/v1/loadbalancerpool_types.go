@@ -0,0 +1,325 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LoadBalancerMonitorSpec is a health check that probes the origins of the pool.
+// Refer https://developers.cloudflare.com/load-balancing/monitors/ for details.
+type LoadBalancerMonitorSpec struct {
+	// Type is the protocol to use for the health check.
+	//
+	// +optional
+	//+kubebuilder:default:=http
+	//+kubebuilder:validation:Enum:=http;https;tcp;udp_icmp;icmp_ping;smtp
+	Type *string `json:"type,omitempty"`
+
+	// +optional
+	Description *string `json:"description,omitempty"`
+
+	// Method is the HTTP method to use for the health check.
+	//
+	// +optional
+	Method *string `json:"method,omitempty"`
+
+	// Path is the endpoint path to probe.
+	//
+	// +optional
+	Path *string `json:"path,omitempty"`
+
+	// Header is the HTTP request headers to send in the health check.
+	//
+	// +optional
+	Header map[string][]string `json:"header,omitempty"`
+
+	// Port is the port number to connect to for the health check.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// Interval is the interval between each health check in seconds.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=5
+	Interval *int32 `json:"interval,omitempty"`
+
+	// Timeout is the timeout in seconds before marking the health check as failed.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	Timeout *int32 `json:"timeout,omitempty"`
+
+	// Retries is the number of retries to attempt in case of a timeout before marking the origin as unhealthy.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	Retries *int32 `json:"retries,omitempty"`
+
+	// ConsecutiveUp is the number of consecutive successes required to mark the origin as healthy.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	ConsecutiveUp *int32 `json:"consecutiveUp,omitempty"`
+
+	// ConsecutiveDown is the number of consecutive failures required to mark the origin as unhealthy.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=0
+	ConsecutiveDown *int32 `json:"consecutiveDown,omitempty"`
+
+	// ExpectedBody is a case-insensitive sub-string to look for in the response body.
+	//
+	// +optional
+	ExpectedBody *string `json:"expectedBody,omitempty"`
+
+	// ExpectedCodes is the expected HTTP response code or code range of the health check (e.g. 2xx).
+	//
+	// +optional
+	ExpectedCodes *string `json:"expectedCodes,omitempty"`
+
+	// +optional
+	FollowRedirects bool `json:"followRedirects,omitempty"`
+
+	// AllowInsecure does not validate the certificate when the health check uses HTTPS.
+	//
+	// +optional
+	AllowInsecure bool `json:"allowInsecure,omitempty"`
+
+	// ProbeZone is the zone that the health check requests are sent from when the origin is a tunnel.
+	//
+	// +optional
+	ProbeZone *string `json:"probeZone,omitempty"`
+}
+
+// LoadBalancerPoolSpec defines the desired state of LoadBalancerPool
+type LoadBalancerPoolSpec struct {
+	// The pool name. It will show up in Cloudflare dashboard.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Cloudflared's account id to create pool. It must be the same account as the selected tunnels.
+	// Refer https://developers.cloudflare.com/fundamentals/setup/find-account-and-zone-ids/ to find the value.
+	//
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:MinLength=1
+	AccountID string `json:"accountID"`
+
+	// Reference to secret resource that contains Cloudflare API token.
+	APITokenSecretRef SecretKeyRef `json:"apiTokenSecretRef"`
+
+	// TunnelSelector selects Tunnels in the same namespace that become origins of the pool.
+	// Each tunnel is added as <tunnelID>.cfargotunnel.com once it is created on Cloudflare.
+	TunnelSelector metav1.LabelSelector `json:"tunnelSelector"`
+
+	// OriginHost overrides the Host header sent to the tunnels.
+	// It must match a hostname of the tunnels' ingress rules. Defaults to the hostname of the request.
+	//
+	// +optional
+	OriginHost *string `json:"originHost,omitempty"`
+
+	// OriginSteeringPolicy selects an origin of the pool for each request.
+	//
+	// +optional
+	//+kubebuilder:validation:Enum:=random;hash;least_outstanding_requests;least_connections
+	OriginSteeringPolicy *string `json:"originSteeringPolicy,omitempty"`
+
+	// +optional
+	Description *string `json:"description,omitempty"`
+
+	// +optional
+	//+kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MinimumOrigins is the minimum number of healthy origins for the pool to be marked as healthy.
+	//
+	// +optional
+	//+kubebuilder:default:=1
+	//+kubebuilder:validation:Minimum=1
+	MinimumOrigins *int32 `json:"minimumOrigins,omitempty"`
+
+	// NotificationEmail is the email address to send health status notifications to.
+	//
+	// +optional
+	NotificationEmail *string `json:"notificationEmail,omitempty"`
+
+	// CheckRegions is the regions that health checks run from (e.g. WNAM, WEU). Empty means all regions.
+	//
+	// +optional
+	CheckRegions []string `json:"checkRegions,omitempty"`
+
+	// Monitor is the health check of the pool. No health check is done if not specified.
+	//
+	// +optional
+	Monitor *LoadBalancerMonitorSpec `json:"monitor,omitempty"`
+}
+
+// LoadBalancerPoolConditionType ...
+// +kubebuilder:validation:Enum=Monitor;Pool
+type LoadBalancerPoolConditionType string
+
+const (
+	LoadBalancerPoolConditionTypeMonitor LoadBalancerPoolConditionType = "Monitor"
+	LoadBalancerPoolConditionTypePool    LoadBalancerPoolConditionType = "Pool"
+)
+
+// LoadBalancerPoolConditionReason ...
+// +kubebuilder:validation:Enum=Creating;NoToken;AccountNotAllowed;FailedToConnectCloudflare;FailedToGetMonitor;FailedToCreateMonitor;FailedToUpdateMonitor;FailedToDeleteMonitor;InvalidTunnelSelector;FailedToListTunnels;NoOrigin;FailedToGetPool;FailedToCreatePool;FailedToUpdatePool;FailedToDeletePool
+type LoadBalancerPoolConditionReason string
+
+const (
+	LoadBalancerPoolReasonCreating          LoadBalancerPoolConditionReason = "Creating"
+	LoadBalancerPoolReasonNoToken           LoadBalancerPoolConditionReason = "NoToken"
+	LoadBalancerPoolReasonAccountNotAllowed LoadBalancerPoolConditionReason = "AccountNotAllowed"
+	LoadBalancerPoolReasonFailedToConnectCF LoadBalancerPoolConditionReason = "FailedToConnectCloudflare"
+
+	MonitorReasonFailedToGetMonitor    LoadBalancerPoolConditionReason = "FailedToGetMonitor"
+	MonitorReasonFailedToCreateMonitor LoadBalancerPoolConditionReason = "FailedToCreateMonitor"
+	MonitorReasonFailedToUpdateMonitor LoadBalancerPoolConditionReason = "FailedToUpdateMonitor"
+	MonitorReasonFailedToDeleteMonitor LoadBalancerPoolConditionReason = "FailedToDeleteMonitor"
+
+	PoolReasonInvalidTunnelSelector LoadBalancerPoolConditionReason = "InvalidTunnelSelector"
+	PoolReasonFailedToListTunnels   LoadBalancerPoolConditionReason = "FailedToListTunnels"
+	PoolReasonNoOrigin              LoadBalancerPoolConditionReason = "NoOrigin"
+	PoolReasonFailedToGetPool       LoadBalancerPoolConditionReason = "FailedToGetPool"
+	PoolReasonFailedToCreatePool    LoadBalancerPoolConditionReason = "FailedToCreatePool"
+	PoolReasonFailedToUpdatePool    LoadBalancerPoolConditionReason = "FailedToUpdatePool"
+	PoolReasonFailedToDeletePool    LoadBalancerPoolConditionReason = "FailedToDeletePool"
+)
+
+type LoadBalancerPoolStatusCondition struct {
+	// Type of condition for a component.
+	// Valid value: "Monitor", "Pool"
+	Type LoadBalancerPoolConditionType `json:"type"`
+
+	// Status of the condition for a component.
+	// Valid values for "Monitor", "Pool": "True", "False", or "Unknown".
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Message about the condition for a component.
+	// For example, information about a health check.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Error is Condition error code for a component.
+	// For example, a health check error code.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// +optional
+	Reason LoadBalancerPoolConditionReason `json:"reason,omitempty"`
+}
+
+func (c LoadBalancerPoolStatusCondition) GetConditionType() LoadBalancerPoolConditionType {
+	return c.Type
+}
+
+func (c LoadBalancerPoolStatusCondition) Equals(o LoadBalancerPoolStatusCondition) bool {
+	return c.Type == o.Type && c.Status == o.Status && c.Message == o.Message &&
+		c.Error == o.Error && c.Reason == o.Reason
+}
+
+type LoadBalancerPoolOrigin struct {
+	// TunnelName is the Tunnel resource that the origin comes from.
+	TunnelName string `json:"tunnelName"`
+
+	// Address is <tunnelID>.cfargotunnel.com of the tunnel.
+	Address string `json:"address"`
+}
+
+// LoadBalancerPoolStatus defines the observed state of LoadBalancerPool
+type LoadBalancerPoolStatus struct {
+	Conditions []LoadBalancerPoolStatusCondition `json:"conditions,omitempty"`
+
+	// PoolID is the Cloudflare ID of the pool. LoadBalancer refers the pool by it.
+	//
+	// +optional
+	PoolID string `json:"poolID,omitempty"`
+
+	// MonitorID is the Cloudflare ID of the health monitor that the pool uses.
+	//
+	// +optional
+	MonitorID string `json:"monitorID,omitempty"`
+
+	// AccountID is the account that the pool was created in.
+	//
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
+
+	// Origins are the tunnels currently registered to the pool.
+	//
+	// +optional
+	Origins []LoadBalancerPoolOrigin `json:"origins,omitempty"`
+}
+
+func (s *LoadBalancerPoolStatus) GetCondition(condType LoadBalancerPoolConditionType) LoadBalancerPoolStatusCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == condType {
+			return s.Conditions[i]
+		}
+	}
+	return LoadBalancerPoolStatusCondition{}
+}
+
+func (s *LoadBalancerPoolStatus) SetCondition(condition LoadBalancerPoolStatusCondition) {
+	idx := slices.IndexFunc(s.Conditions, func(c LoadBalancerPoolStatusCondition) bool {
+		return c.Type == condition.Type
+	})
+	if idx == -1 {
+		s.Conditions = append(s.Conditions, condition)
+	} else {
+		s.Conditions[idx] = condition
+	}
+}
+
+// LoadBalancerPool is the Schema for the loadbalancerpools API
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Pool ID",type=string,JSONPath=`.status.poolID`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Pool")].status`
+type LoadBalancerPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LoadBalancerPoolSpec   `json:"spec,omitempty"`
+	Status LoadBalancerPoolStatus `json:"status,omitempty"`
+}
+
+// LoadBalancerPoolList contains a list of LoadBalancerPool
+//
+// +kubebuilder:object:root=true
+type LoadBalancerPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LoadBalancerPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LoadBalancerPool{}, &LoadBalancerPoolList{})
+}
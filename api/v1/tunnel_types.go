@@ -39,76 +39,166 @@ type SecretKeyRef struct {
 	Namespace *string `json:"namespace,omitempty"`
 }
 
+// EdgeIPVersion is the IP version used to connect to the Cloudflare edge.
+// +kubebuilder:validation:Enum=auto;"4";"6"
+type EdgeIPVersion string
+
+const (
+	EdgeIPVersionAuto EdgeIPVersion = "auto"
+	EdgeIPVersion4    EdgeIPVersion = "4"
+	EdgeIPVersion6    EdgeIPVersion = "6"
+)
+
+// TunnelProtocol is the transport protocol between cloudflared and the Cloudflare edge.
+// +kubebuilder:validation:Enum=auto;http2;quic
+type TunnelProtocol string
+
+const (
+	TunnelProtocolAuto  TunnelProtocol = "auto"
+	TunnelProtocolHTTP2 TunnelProtocol = "http2"
+	TunnelProtocolQUIC  TunnelProtocol = "quic"
+)
+
+// EdgeRegion is the region of the Cloudflare edge that cloudflared connects to.
+// +kubebuilder:validation:Enum=us
+type EdgeRegion string
+
+const (
+	EdgeRegionUS EdgeRegion = "us"
+)
+
+// LogLevel is the level of logging of cloudflared.
+// +kubebuilder:validation:Enum=debug;info;warn;error;fatal
+type LogLevel string
+
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelWarn  LogLevel = "warn"
+	LogLevelError LogLevel = "error"
+	LogLevelFatal LogLevel = "fatal"
+)
+
 // TunnelRunParameters represents the configurable options for Cloudflare Tunnel.
+// They are written in config.yaml, except ones that config.yaml can not express, which are passed as container args.
+// The operator manages --config, --credentials-file, --metrics and --no-autoupdate by itself.
+// Options that the cloudflared of DaemonVersion does not support are rejected.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.post__dash__quantum) || !self.post__dash__quantum || !has(self.protocol) || self.protocol != 'http2'",message="post-quantum requires quic protocol"
 type TunnelRunParameters struct {
-	// EdgeBindAddress defines the address that `cloudflared` will bind to on the Cloudflare edge.
+	// EdgeBindAddress is the local IP address that connections to the Cloudflare edge are bound to.
+	// Requires cloudflared 2023.2.2 or later.
 	//
 	// +optional
-	//EdgeBindAddress *string `json:"edgeBindAddress,omitempty"`
+	EdgeBindAddress *string `json:"edge-bind-address,omitempty"`
 
-	// EdgeIPVersion sets the IP version for edge connections.
+	// EdgeIPVersion sets the IP version for edge connections. Defaults to `4`.
+	// Requires cloudflared 2022.12.0 or later.
 	//
 	// +optional
-	//EdgeIPVersion *string `json:"edgeIPVersion,omitempty"`
+	EdgeIPVersion *EdgeIPVersion `json:"edge-ip-version,omitempty"`
 
-	// GracePeriod specifies the time to wait for connections to close gracefully before exiting.
+	// GracePeriod specifies the time to wait for connections to close gracefully before exiting. Defaults to 30s.
+	// It is passed as --grace-period container arg, and terminationGracePeriodSeconds of pods is derived from it.
 	//
 	// +optional
 	GracePeriod *metav1.Duration `json:"grace-period,omitempty"`
 
+	// HAConnections is the number of connections to the Cloudflare edge. Defaults to 4.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	HAConnections *int32 `json:"ha-connections,omitempty"`
+
+	// Label is the name of connectors shown in Cloudflare dashboard, instead of the hostname of pods.
+	// Requires cloudflared 2023.4.1 or later.
+	//
+	// +optional
+	Label *string `json:"label,omitempty"`
+
 	// Logfile sets the path to the log file.
 	//
 	// +optional
 	Logfile *string `json:"logfile,omitempty"`
 
-	// Loglevel defines the level of logging (e.g., debug, info).
+	// LogDirectory sets the directory of log files.
+	//
+	// +optional
+	LogDirectory *string `json:"log-directory,omitempty"`
+
+	// Loglevel defines the level of logging. Defaults to `info`.
 	//
 	// +optional
-	//+kubebuilder:validation:Enum:=debug;info;warn;error;fatal
-	Loglevel *string `json:"loglevel,omitempty"`
+	Loglevel *LogLevel `json:"loglevel,omitempty"`
 
-	// Metrics sets the address for exposing the metrics reporting endpoint.
+	// TransportLoglevel defines the level of logging about connections to the Cloudflare edge. Defaults to `info`.
+	//
 	// +optional
-	// Metrics *string `json:"metrics,omitempty"`
+	TransportLoglevel *LogLevel `json:"transport-loglevel,omitempty"`
 
-	// NoAutoupdate indicates whether autoupdates are disabled.
+	// ManagementDiagnostics exposes metrics and pprof of cloudflared through the management API of Cloudflare.
+	// Requires cloudflared 2023.10.0 or later.
 	//
 	// +optional
-	//NoAutoupdate *bool `json:"noAutoupdate,omitempty"`
+	ManagementDiagnostics *bool `json:"management-diagnostics,omitempty"`
 
-	// Origincert specifies the path to the certificate file for authentication with Cloudflare.
+	// MaxFetchSize is the maximum number of results that cloudflared fetches from Cloudflare API at once.
 	//
 	// +optional
-	//Origincert *string `json:"origincert,omitempty"`
+	//+kubebuilder:validation:Minimum=1
+	MaxFetchSize *int32 `json:"max-fetch-size,omitempty"`
 
 	// Pidfile sets the path to the PID file.
 	// +optional
 	Pidfile *string `json:"pidfile,omitempty"`
 
-	// Protocol specifies the protocol for the tunnel.
+	// PostQuantum establishes connections to the Cloudflare edge with post-quantum key agreement.
+	// Requires quic protocol and cloudflared 2022.9.1 or later.
 	//
 	// +optional
-	Protocol *string `json:"protocol,omitempty"`
+	PostQuantum *bool `json:"post-quantum,omitempty"`
 
-	// Region sets the preferred region for the edge connection.
+	// Protocol specifies the protocol for the tunnel. Defaults to `auto`.
 	//
 	// +optional
-	Region *string `json:"region,omitempty"`
+	Protocol *TunnelProtocol `json:"protocol,omitempty"`
 
-	// Retries specifies the number of retries for the tunnel connection.
+	// Region sets the region of the Cloudflare edge. The global region is used if omitted.
+	// Requires cloudflared 2023.4.1 or later.
 	//
 	// +optional
+	Region *EdgeRegion `json:"region,omitempty"`
+
+	// Retries specifies the maximum number of retries for connection errors. Defaults to 5.
+	//
+	// +optional
+	//+kubebuilder:validation:Minimum=0
 	Retries *int `json:"retries,omitempty"`
 
-	// Tag contains tags for the tunnel in the format of key-value pairs.
+	// CompressionQuality is the level of compression of cross-stream data, from 0 (off) to 3 (high).
 	//
 	// +optional
-	Tag map[string]string `json:"tag,omitempty"`
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=3
+	CompressionQuality *int32 `json:"compression-quality,omitempty"`
+
+	// ICMPv4Src is the source address of ICMPv4 proxied by cloudflared.
+	// Requires cloudflared 2023.2.1 or later.
+	//
+	// +optional
+	ICMPv4Src *string `json:"icmpv4-src,omitempty"`
+
+	// ICMPv6Src is the source address of ICMPv6 proxied by cloudflared.
+	// Requires cloudflared 2023.2.1 or later.
+	//
+	// +optional
+	ICMPv6Src *string `json:"icmpv6-src,omitempty"`
 
-	// Token specifies the token for authentication with Cloudflare.
+	// Tag contains tags for the tunnel in the format of key-value pairs.
+	// They are passed as --tag container args, since config.yaml takes them as a list.
 	//
 	// +optional
-	//Token *string `json:"token,omitempty"`
+	Tag map[string]string `json:"tag,omitempty"`
 }
 
 // TunnelSpec defines the desired state of Tunnel
@@ -150,8 +240,35 @@ type TunnelSpec struct {
 
 	// +optional
 	TunnelRunParameters *TunnelRunParameters `json:"tunnelRunParameters,omitempty"`
+
+	// ConfigReloadStrategy decides how cloudflared picks up config changes, e.g. added or removed TunnelIngress.
+	// Restart rolls out pods with the default strategy of the workload.
+	// SurgeRollout starts new pods before stopping old ones, and lets old ones drain for GracePeriod.
+	// RemoteManaged pushes ingress rules to Cloudflare, and cloudflared applies them without restarting.
+	// A tunnel once remotely managed stays so on Cloudflare, so its config keeps being pushed after switching back.
+	// The operator records it with the cloudflared-operator.bhyoo.com/remotely-managed annotation of the Tunnel.
+	//
+	// +optional
+	//+kubebuilder:default:=Restart
+	ConfigReloadStrategy ConfigReloadStrategy `json:"configReloadStrategy,omitempty"`
+
+	// VirtualNetworkRef is the default virtual network for private network routes of this tunnel.
+	// TunnelNetworkRoute that specifies its own virtual network overrides it.
+	//
+	// +optional
+	VirtualNetworkRef *VirtualNetworkRef `json:"virtualNetworkRef,omitempty"`
 }
 
+// ConfigReloadStrategy ...
+// +kubebuilder:validation:Enum=Restart;SurgeRollout;RemoteManaged
+type ConfigReloadStrategy string
+
+const (
+	ConfigReloadStrategyRestart       ConfigReloadStrategy = "Restart"
+	ConfigReloadStrategySurgeRollout  ConfigReloadStrategy = "SurgeRollout"
+	ConfigReloadStrategyRemoteManaged ConfigReloadStrategy = "RemoteManaged"
+)
+
 func (s *TunnelSpec) CredentialSecretName() string {
 	if s.SecretName != nil {
 		return *s.SecretName
@@ -167,28 +284,34 @@ func (s *TunnelSpec) ConfigName() string {
 }
 
 // TunnelConditionType ...
-// +kubebuilder:validation:Enum=Daemon;Credential;Config
+// +kubebuilder:validation:Enum=Daemon;Credential;Config;Upgrade
 type TunnelConditionType string
 
 const (
 	TunnelConditionTypeDaemon     TunnelConditionType = "Daemon"
 	TunnelConditionTypeCredential TunnelConditionType = "Credential"
 	TunnelConditionTypeConfig     TunnelConditionType = "Config"
+	TunnelConditionTypeUpgrade    TunnelConditionType = "Upgrade"
 )
 
 // TunnelConditionReason ...
-// +kubebuilder:validation:Enum=CredentialRequired;ConfigRequired;FailedToDeleteOrphans;FailedToDeploy;DeletingOrphans;Creating;NoToken;FailedToConnectCloudflare;FailedToCreateTunnelOnCloudflare;FailedToCreateSecret;InvalidCredential;FailedToValidate;FailedToGetExistingCredential;FailedToBuildConfigFromSpec;FailedToGetExistingConfig;FailedToCreateConfigMap;FailedToUpdateConfigMap;InvalidConfig
+// +kubebuilder:validation:Enum=CredentialRequired;ConfigRequired;FailedToDeleteOrphans;FailedToDeploy;DeletingOrphans;InvalidPodTemplate;FailedToDeployDisruptionBudget;FailedToDeployAutoscaler;UnsupportedRunParameters;Creating;NoToken;AccountNotAllowed;FailedToConnectCloudflare;FailedToCreateTunnelOnCloudflare;FailedToCreateSecret;InvalidCredential;FailedToValidate;FailedToGetExistingCredential;FailedToBuildConfigFromSpec;FailedToGetExistingConfig;FailedToCreateConfigMap;FailedToUpdateConfigMap;InvalidConfig;FailedToUpdateRemoteConfig;FailedToResolveVersion;WaitingForReleaseAge;WaitingForMaintenanceWindow;WaitingForStage;RollingOut;RolledBack
 type TunnelConditionReason string
 
 const (
-	DaemonReasonCredentialRequired    TunnelConditionReason = "CredentialRequired"
-	DaemonReasonConfigRequired        TunnelConditionReason = "ConfigRequired"
-	DaemonReasonFailedToDeleteOrphans TunnelConditionReason = "FailedToDeleteOrphans"
-	DaemonReasonFailedToDeploy        TunnelConditionReason = "FailedToDeploy"
-	DaemonReasonDeletingOrphans       TunnelConditionReason = "DeletingOrphans"
+	DaemonReasonCredentialRequired             TunnelConditionReason = "CredentialRequired"
+	DaemonReasonConfigRequired                 TunnelConditionReason = "ConfigRequired"
+	DaemonReasonFailedToDeleteOrphans          TunnelConditionReason = "FailedToDeleteOrphans"
+	DaemonReasonFailedToDeploy                 TunnelConditionReason = "FailedToDeploy"
+	DaemonReasonDeletingOrphans                TunnelConditionReason = "DeletingOrphans"
+	DaemonReasonInvalidPodTemplate             TunnelConditionReason = "InvalidPodTemplate"
+	DaemonReasonFailedToDeployDisruptionBudget TunnelConditionReason = "FailedToDeployDisruptionBudget"
+	DaemonReasonFailedToDeployAutoscaler       TunnelConditionReason = "FailedToDeployAutoscaler"
+	DaemonReasonUnsupportedRunParameters       TunnelConditionReason = "UnsupportedRunParameters"
 
 	CredentialReasonCreating                      TunnelConditionReason = "Creating"
 	CredentialReasonNoToken                       TunnelConditionReason = "NoToken"
+	CredentialReasonAccountNotAllowed             TunnelConditionReason = "AccountNotAllowed"
 	CredentialReasonFailedToConnectCF             TunnelConditionReason = "FailedToConnectCloudflare"
 	CredentialReasonFailedToCreateTunnelOnCF      TunnelConditionReason = "FailedToCreateTunnelOnCloudflare"
 	CredentialReasonFailedToCreateSecret          TunnelConditionReason = "FailedToCreateSecret"
@@ -201,11 +324,19 @@ const (
 	ConfigReasonFailedToCreateConfigMap     TunnelConditionReason = "FailedToCreateConfigMap"
 	ConfigReasonFailedToUpdateConfigMap     TunnelConditionReason = "FailedToUpdateConfigMap"
 	ConfigReasonInvalidConfig               TunnelConditionReason = "InvalidConfig"
+	ConfigReasonFailedToUpdateRemoteConfig  TunnelConditionReason = "FailedToUpdateRemoteConfig"
+
+	UpgradeReasonFailedToResolveVersion      TunnelConditionReason = "FailedToResolveVersion"
+	UpgradeReasonWaitingForReleaseAge        TunnelConditionReason = "WaitingForReleaseAge"
+	UpgradeReasonWaitingForMaintenanceWindow TunnelConditionReason = "WaitingForMaintenanceWindow"
+	UpgradeReasonWaitingForStage             TunnelConditionReason = "WaitingForStage"
+	UpgradeReasonRollingOut                  TunnelConditionReason = "RollingOut"
+	UpgradeReasonRolledBack                  TunnelConditionReason = "RolledBack"
 )
 
 type TunnelStatusCondition struct {
 	// Type of condition for a component.
-	// Valid value: "Daemon", "Credential", "Config"
+	// Valid value: "Daemon", "Credential", "Config", "Upgrade"
 	Type TunnelConditionType `json:"type"`
 
 	// Status of the condition for a component.
@@ -247,6 +378,60 @@ type TunnelStatus struct {
 
 	// +optional
 	DaemonVersion string `json:"daemonVersion,omitempty"`
+
+	// RemoteConfigHash is the hash of the config last pushed to Cloudflare. It is set once the tunnel is remotely
+	// managed.
+	//
+	// +optional
+	RemoteConfigHash string `json:"remoteConfigHash,omitempty"`
+
+	// AvailableVersion is the newest release allowed by the upgrade policy, found by the last check.
+	// It differs from DaemonVersion while an upgrade waits or rolls out.
+	//
+	// +optional
+	AvailableVersion string `json:"availableVersion,omitempty"`
+
+	// Upgrade tracks automatic upgrades of a DaemonVersion of latest.
+	//
+	// +optional
+	Upgrade DaemonUpgradeStatus `json:"upgrade,omitempty"`
+
+	// DaemonImage is the image reference that cloudflared is deployed with.
+	//
+	// +optional
+	DaemonImage string `json:"daemonImage,omitempty"`
+
+	// DaemonImageDigest is the digest that the image is pinned to, if any.
+	//
+	// +optional
+	DaemonImageDigest string `json:"daemonImageDigest,omitempty"`
+}
+
+type DaemonUpgradeStatus struct {
+	// AvailableSince is when the operator first found AvailableVersion.
+	//
+	// +optional
+	AvailableSince *metav1.Time `json:"availableSince,omitempty"`
+
+	// LastCheckTime is when releases were looked up last time.
+	//
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// StartedAt is when the ongoing upgrade started. It is cleared once the daemon becomes ready.
+	//
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// PreviousVersion is what the ongoing upgrade rolls back to.
+	//
+	// +optional
+	PreviousVersion string `json:"previousVersion,omitempty"`
+
+	// FailedVersion was rolled back, and is not adopted again.
+	//
+	// +optional
+	FailedVersion string `json:"failedVersion,omitempty"`
 }
 
 func (s *TunnelStatus) GetCondition(condType TunnelConditionType) TunnelStatusCondition {
@@ -277,6 +462,7 @@ func (s *TunnelStatus) SetCondition(condition TunnelStatusCondition) {
 // +kubebuilder:printcolumn:name="Tunnel Name",type=string,JSONPath=`.spec.name`
 // +kubebuilder:printcolumn:name="Tunnel ID",type=string,JSONPath=`.status.tunnelID`
 // +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.daemonVersion`
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.availableVersion`
 type Tunnel struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -21,6 +21,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // TunnelKind ...
@@ -35,44 +36,139 @@ type TunnelRef struct {
 	// Name is Tunnel name that bind to the TunnelIngress.
 	Name string `json:"name"`
 
+	// Namespace of Tunnel. Defaults to the namespace of the referrer.
+	// Tunnels of other namespaces need a TunnelReferenceGrant in the namespace of Tunnel.
+	// Only TunnelIngress supports it.
+	//
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
 	// Kind is the type of the resource. Defaults to `Tunnel`.
 	// +optional
 	// +kubebuilder:default:=Tunnel
 	Kind TunnelKind `json:"kind,omitempty"`
 }
 
+// OriginScheme is the protocol that cloudflared proxies to the origin with.
+// +kubebuilder:validation:Enum=http;https;tcp;ssh;rdp;smb;unix;unix+tls;http_status
+type OriginScheme string
+
+const (
+	OriginSchemeHTTP       OriginScheme = "http"
+	OriginSchemeHTTPS      OriginScheme = "https"
+	OriginSchemeTCP        OriginScheme = "tcp"
+	OriginSchemeSSH        OriginScheme = "ssh"
+	OriginSchemeRDP        OriginScheme = "rdp"
+	OriginSchemeSMB        OriginScheme = "smb"
+	OriginSchemeUnix       OriginScheme = "unix"
+	OriginSchemeUnixTLS    OriginScheme = "unix+tls"
+	OriginSchemeHTTPStatus OriginScheme = "http_status"
+)
+
+// IngressOrigin is the origin that a TunnelIngress proxies to.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.backendRef) != has(self.address)",message="exactly one of backendRef or address must be set"
+// +kubebuilder:validation:XValidation:rule="!(self.scheme in ['unix', 'unix+tls', 'http_status']) || has(self.address)",message="unix, unix+tls and http_status schemes require address"
+// +kubebuilder:validation:XValidation:rule="self.scheme != 'http_status' || (has(self.address) && self.address.matches('^[1-5][0-9]{2}$'))",message="address of http_status must be a status code"
+// +kubebuilder:validation:XValidation:rule="!(self.scheme in ['unix', 'unix+tls']) || (has(self.address) && self.address.startsWith('/'))",message="address of unix sockets must be an absolute path"
+// +kubebuilder:validation:XValidation:rule="self.scheme in ['unix', 'unix+tls', 'http_status'] || !has(self.address) || !(self.address.contains('/') || self.address.contains('@'))",message="address must be host[:port]"
+type IngressOrigin struct {
+	// Scheme is the protocol of the origin.
+	Scheme OriginScheme `json:"scheme"`
+
+	// BackendRef refers a port of Service in the namespace of TunnelIngress.
+	//
+	// +optional
+	BackendRef *BackendRef `json:"backendRef,omitempty"`
+
+	// Address is host[:port] of the origin, an absolute socket path for unix and unix+tls,
+	// or a status code for http_status.
+	//
+	// +optional
+	Address string `json:"address,omitempty"`
+}
+
+// BackendRef refers a port of Service.
+type BackendRef struct {
+	// Name of Service.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of Service. Defaults to the namespace of the referrer.
+	// Services of other namespaces need a TunnelReferenceGrant in the namespace of Service.
+	//
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// Port of Service, either the port number or the name of port.
+	//
+	//+kubebuilder:validation:XIntOrString
+	Port intstr.IntOrString `json:"port"`
+}
+
 // TunnelIngressSpec defines the desired state of TunnelIngress
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.zoneID) && has(self.zoneName))",message="zoneID and zoneName are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="has(self.service) != has(self.origin)",message="exactly one of service or origin must be set"
 type TunnelIngressSpec struct {
 	TunnelConfigIngress `json:",inline"`
 
+	// Origin is a typed alternative to Service, which is resolved into the service of cloudflared ingress rule.
+	//
+	// +optional
+	Origin *IngressOrigin `json:"origin,omitempty"`
+
 	TunnelRef TunnelRef `json:"tunnelRef"`
 
 	// +optional
 	OverwriteExistingDNS bool `json:"overwriteExistingDNS,omitempty"`
+
+	// ZoneID is the Cloudflare zone that the DNS record of hostname is created in.
+	// The most specific zone of the account that contains hostname is used if neither zoneID nor zoneName is specified.
+	//
+	// +optional
+	ZoneID *string `json:"zoneID,omitempty"`
+
+	// ZoneName is the name of Cloudflare zone that the DNS record of hostname is created in (e.g. dev.example.com).
+	//
+	// +optional
+	ZoneName *string `json:"zoneName,omitempty"`
 }
 
 // TunnelIngressConditionType ...
-// +kubebuilder:validation:Enum=DNSRecord
+// +kubebuilder:validation:Enum=DNSRecord;BackendReady
 type TunnelIngressConditionType string
 
 const (
 	TunnelIngressConditionTypeDNSRecord TunnelIngressConditionType = "DNSRecord"
+	// TunnelIngressConditionTypeBackendReady tells whether the Service of backendRef has ready endpoints.
+	TunnelIngressConditionTypeBackendReady TunnelIngressConditionType = "BackendReady"
 )
 
 // TunnelIngressConditionReason ...
-// +kubebuilder:validation:Enum=Creating;NoToken;FailedToConnectCloudflare;FailedToCreateRecord
+// +kubebuilder:validation:Enum=Creating;NoToken;AccountNotAllowed;FailedToConnectCloudflare;FailedToCreateRecord;FailedToDeleteRecord;RefNotPermitted;PolicyViolation;ServiceNotFound;PortNotFound;NoReadyEndpoints
 type TunnelIngressConditionReason string
 
 const (
 	DNSRecordReasonCreating             TunnelIngressConditionReason = "Creating"
 	DNSRecordReasonNoToken              TunnelIngressConditionReason = "NoToken"
+	DNSRecordReasonAccountNotAllowed    TunnelIngressConditionReason = "AccountNotAllowed"
 	DNSRecordReasonFailedToConnectCF    TunnelIngressConditionReason = "FailedToConnectCloudflare"
 	DNSRecordReasonFailedToCreateRecord TunnelIngressConditionReason = "FailedToCreateRecord"
+	DNSRecordReasonFailedToDeleteRecord TunnelIngressConditionReason = "FailedToDeleteRecord"
+	DNSRecordReasonRefNotPermitted      TunnelIngressConditionReason = "RefNotPermitted"
+	DNSRecordReasonPolicyViolation      TunnelIngressConditionReason = "PolicyViolation"
+
+	BackendReasonServiceNotFound  TunnelIngressConditionReason = "ServiceNotFound"
+	BackendReasonPortNotFound     TunnelIngressConditionReason = "PortNotFound"
+	BackendReasonNoReadyEndpoints TunnelIngressConditionReason = "NoReadyEndpoints"
+	BackendReasonRefNotPermitted  TunnelIngressConditionReason = "RefNotPermitted"
 )
 
 type TunnelIngressStatusCondition struct {
 	// Type of condition for a component.
-	// Valid value: "Daemon", "Credential", "Config"
+	// Valid value: "DNSRecord", "BackendReady"
 	Type TunnelIngressConditionType `json:"type"`
 
 	// Status of the condition for a component.
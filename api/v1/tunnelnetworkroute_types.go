@@ -0,0 +1,192 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TunnelNetworkRouteSpec defines the desired state of TunnelNetworkRoute
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.virtualNetworkID) && has(self.virtualNetworkRef))",message="virtualNetworkID and virtualNetworkRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.tunnelRef.namespace)",message="tunnelRef.namespace is not supported"
+type TunnelNetworkRouteSpec struct {
+	// TunnelRef is the Tunnel that private network traffic is routed to.
+	TunnelRef TunnelRef `json:"tunnelRef"`
+
+	// Network is the private network in CIDR notation (e.g. 10.96.0.0/12) reachable through the tunnel.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Network string `json:"network"`
+
+	// Comment is an optional description of the route. It will show up in Cloudflare Zero Trust dashboard.
+	//
+	// +optional
+	Comment *string `json:"comment,omitempty"`
+
+	// VirtualNetworkID is the Cloudflare ID of the virtual network that the route belongs to.
+	// Uses the tunnel's virtual network, or the account's default one if neither is specified.
+	//
+	// +optional
+	VirtualNetworkID *string `json:"virtualNetworkID,omitempty"`
+
+	// VirtualNetworkRef refers a VirtualNetwork resource that the route belongs to.
+	//
+	// +optional
+	VirtualNetworkRef *VirtualNetworkRef `json:"virtualNetworkRef,omitempty"`
+}
+
+// TunnelNetworkRouteConditionType ...
+// +kubebuilder:validation:Enum=Route
+type TunnelNetworkRouteConditionType string
+
+const (
+	TunnelNetworkRouteConditionTypeRoute TunnelNetworkRouteConditionType = "Route"
+)
+
+// TunnelNetworkRouteConditionReason ...
+// +kubebuilder:validation:Enum=Creating;NoToken;AccountNotAllowed;FailedToConnectCloudflare;TunnelNotReady;VirtualNetworkNotReady;InvalidNetwork;Conflict;FailedToGetRoute;FailedToCreateRoute;FailedToUpdateRoute;FailedToDeleteRoute
+type TunnelNetworkRouteConditionReason string
+
+const (
+	RouteReasonCreating               TunnelNetworkRouteConditionReason = "Creating"
+	RouteReasonNoToken                TunnelNetworkRouteConditionReason = "NoToken"
+	RouteReasonAccountNotAllowed      TunnelNetworkRouteConditionReason = "AccountNotAllowed"
+	RouteReasonFailedToConnectCF      TunnelNetworkRouteConditionReason = "FailedToConnectCloudflare"
+	RouteReasonTunnelNotReady         TunnelNetworkRouteConditionReason = "TunnelNotReady"
+	RouteReasonVirtualNetworkNotReady TunnelNetworkRouteConditionReason = "VirtualNetworkNotReady"
+	RouteReasonInvalidNetwork         TunnelNetworkRouteConditionReason = "InvalidNetwork"
+	RouteReasonConflict               TunnelNetworkRouteConditionReason = "Conflict"
+	RouteReasonFailedToGetRoute       TunnelNetworkRouteConditionReason = "FailedToGetRoute"
+	RouteReasonFailedToCreateRoute    TunnelNetworkRouteConditionReason = "FailedToCreateRoute"
+	RouteReasonFailedToUpdateRoute    TunnelNetworkRouteConditionReason = "FailedToUpdateRoute"
+	RouteReasonFailedToDeleteRoute    TunnelNetworkRouteConditionReason = "FailedToDeleteRoute"
+)
+
+type TunnelNetworkRouteStatusCondition struct {
+	// Type of condition for a component.
+	// Valid value: "Route"
+	Type TunnelNetworkRouteConditionType `json:"type"`
+
+	// Status of the condition for a component.
+	// Valid values for "Route": "True", "False", or "Unknown".
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Message about the condition for a component.
+	// For example, information about a health check.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Error is Condition error code for a component.
+	// For example, a health check error code.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// +optional
+	Reason TunnelNetworkRouteConditionReason `json:"reason,omitempty"`
+}
+
+func (c TunnelNetworkRouteStatusCondition) GetConditionType() TunnelNetworkRouteConditionType {
+	return c.Type
+}
+
+func (c TunnelNetworkRouteStatusCondition) Equals(o TunnelNetworkRouteStatusCondition) bool {
+	return c.Type == o.Type && c.Status == o.Status && c.Message == o.Message &&
+		c.Error == o.Error && c.Reason == o.Reason
+}
+
+// TunnelNetworkRouteStatus defines the observed state of TunnelNetworkRoute
+type TunnelNetworkRouteStatus struct {
+	Conditions []TunnelNetworkRouteStatusCondition `json:"conditions,omitempty"`
+
+	// Network is the route currently registered on Cloudflare.
+	//
+	// +optional
+	Network string `json:"network,omitempty"`
+
+	// VirtualNetworkID is the virtual network of the registered route.
+	//
+	// +optional
+	VirtualNetworkID string `json:"virtualNetworkID,omitempty"`
+
+	// TunnelID is the tunnel that the registered route points to.
+	//
+	// +optional
+	TunnelID string `json:"tunnelID,omitempty"`
+
+	// ConflictingTunnelID is set when the network is already routed to another tunnel.
+	//
+	// +optional
+	ConflictingTunnelID string `json:"conflictingTunnelID,omitempty"`
+}
+
+func (s *TunnelNetworkRouteStatus) GetCondition(
+	condType TunnelNetworkRouteConditionType,
+) TunnelNetworkRouteStatusCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == condType {
+			return s.Conditions[i]
+		}
+	}
+	return TunnelNetworkRouteStatusCondition{}
+}
+
+func (s *TunnelNetworkRouteStatus) SetCondition(condition TunnelNetworkRouteStatusCondition) {
+	idx := slices.IndexFunc(s.Conditions, func(c TunnelNetworkRouteStatusCondition) bool {
+		return c.Type == condition.Type
+	})
+	if idx == -1 {
+		s.Conditions = append(s.Conditions, condition)
+	} else {
+		s.Conditions[idx] = condition
+	}
+}
+
+// TunnelNetworkRoute is the Schema for the tunnelnetworkroutes API
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Network",type=string,JSONPath=`.spec.network`
+// +kubebuilder:printcolumn:name="Tunnel",type=string,JSONPath=`.spec.tunnelRef.name`
+// +kubebuilder:printcolumn:name="Tunnel ID",type=string,JSONPath=`.status.tunnelID`
+// +kubebuilder:printcolumn:name="Routed",type=string,JSONPath=`.status.conditions[?(@.type=="Route")].status`
+type TunnelNetworkRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TunnelNetworkRouteSpec   `json:"spec,omitempty"`
+	Status TunnelNetworkRouteStatus `json:"status,omitempty"`
+}
+
+// TunnelNetworkRouteList contains a list of TunnelNetworkRoute
+//
+// +kubebuilder:object:root=true
+type TunnelNetworkRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TunnelNetworkRoute `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TunnelNetworkRoute{}, &TunnelNetworkRouteList{})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HostnamePattern is a hostname, or a wildcard like `*.example.com` that matches every subdomain of example.com.
+//
+// +kubebuilder:validation:Pattern:=`^(\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+type HostnamePattern string
+
+// PolicyTunnelRef selects tunnels that TunnelIngresses may bind to.
+type PolicyTunnelRef struct {
+	// Kind of tunnel. Defaults to `Tunnel`.
+	//
+	// +optional
+	// +kubebuilder:default:=Tunnel
+	Kind TunnelKind `json:"kind,omitempty"`
+
+	// Namespace of Tunnel. Defaults to the namespace of TunnelIngress.
+	//
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// Name of Tunnel. `*` matches every name.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// TunnelPolicySpec defines the desired state of TunnelPolicy
+type TunnelPolicySpec struct {
+	// NamespaceSelector selects namespaces that the policy applies to. Every namespace is selected if omitted.
+	//
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// AllowedHostnames are hostnames that TunnelIngresses, DNSRecords and LoadBalancers may claim.
+	// Every hostname is allowed if omitted.
+	//
+	// +optional
+	AllowedHostnames []HostnamePattern `json:"allowedHostnames,omitempty"`
+
+	// AllowedTunnels are tunnels that TunnelIngresses may bind to. Every tunnel is allowed if omitted.
+	//
+	// +optional
+	AllowedTunnels []PolicyTunnelRef `json:"allowedTunnels,omitempty"`
+
+	// AllowedAccountIDs are Cloudflare accounts that DNSRecords, LoadBalancers and Tunnels of TunnelIngresses may belong to.
+	// Every account is allowed if omitted.
+	//
+	// +optional
+	AllowedAccountIDs []string `json:"allowedAccountIDs,omitempty"`
+
+	// AllowOverwriteExistingDNS tells whether TunnelIngresses may set overwriteExistingDNS,
+	// and whether DNSRecords and LoadBalancers may take over existing ones on Cloudflare that differ from their spec.
+	//
+	// +optional
+	AllowOverwriteExistingDNS bool `json:"allowOverwriteExistingDNS,omitempty"`
+
+	// AllowedOriginSchemes are schemes of origin that TunnelIngresses may proxy to. Every scheme is allowed if omitted.
+	// Services of TunnelIngress with other schemes, such as hello_world, are denied if set.
+	//
+	// +optional
+	AllowedOriginSchemes []OriginScheme `json:"allowedOriginSchemes,omitempty"`
+}
+
+// TunnelPolicy restricts TunnelIngresses, DNSRecords and LoadBalancers of the selected namespaces.
+// They must satisfy every TunnelPolicy that selects their namespace,
+// and those of namespaces that no policy selects are not restricted.
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+type TunnelPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TunnelPolicySpec `json:"spec,omitempty"`
+}
+
+// TunnelPolicyList contains a list of TunnelPolicy
+//
+// +kubebuilder:object:root=true
+type TunnelPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TunnelPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TunnelPolicy{}, &TunnelPolicyList{})
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReferenceGrantFrom selects referrers that are granted.
+type ReferenceGrantFrom struct {
+	// Kind of referrer.
+	Kind ReferenceGrantFromKind `json:"kind"`
+
+	// Namespace of referrer.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// ReferenceGrantFromKind ...
+// +kubebuilder:validation:Enum=TunnelIngress
+type ReferenceGrantFromKind string
+
+const (
+	ReferenceGrantFromKindTunnelIngress ReferenceGrantFromKind = "TunnelIngress"
+)
+
+// ReferenceGrantTo selects objects in the namespace of TunnelReferenceGrant that may be referred.
+type ReferenceGrantTo struct {
+	// Kind of referent.
+	Kind ReferenceGrantToKind `json:"kind"`
+
+	// Name of referent. Empty grants every object of the kind.
+	//
+	// +optional
+	Name *string `json:"name,omitempty"`
+}
+
+// ReferenceGrantToKind ...
+// +kubebuilder:validation:Enum=Tunnel;Service
+type ReferenceGrantToKind string
+
+const (
+	ReferenceGrantToKindTunnel  ReferenceGrantToKind = "Tunnel"
+	ReferenceGrantToKindService ReferenceGrantToKind = "Service"
+)
+
+// TunnelReferenceGrantSpec defines the desired state of TunnelReferenceGrant
+type TunnelReferenceGrantSpec struct {
+	// From are referrers in other namespaces that may refer objects of To.
+	//
+	//+kubebuilder:validation:MinItems=1
+	From []ReferenceGrantFrom `json:"from"`
+
+	// To are objects in the namespace of TunnelReferenceGrant that may be referred.
+	//
+	//+kubebuilder:validation:MinItems=1
+	To []ReferenceGrantTo `json:"to"`
+}
+
+// TunnelReferenceGrant permits objects in other namespaces to refer objects in its namespace,
+// like ReferenceGrant of Gateway API. References to other namespaces without a grant are not followed.
+//
+// +kubebuilder:object:root=true
+type TunnelReferenceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TunnelReferenceGrantSpec `json:"spec,omitempty"`
+}
+
+// TunnelReferenceGrantList contains a list of TunnelReferenceGrant
+//
+// +kubebuilder:object:root=true
+type TunnelReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TunnelReferenceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TunnelReferenceGrant{}, &TunnelReferenceGrantList{})
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VirtualNetworkSpec defines the desired state of VirtualNetwork
+type VirtualNetworkSpec struct {
+	// The virtual network name. It will show up in Cloudflare Zero Trust dashboard.
+	// An existing virtual network with the same name is adopted instead of creating a new one.
+	//
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Cloudflared's account id to create virtual network.
+	// Refer https://developers.cloudflare.com/fundamentals/setup/find-account-and-zone-ids/ to find the value.
+	//
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:MinLength=1
+	AccountID string `json:"accountID"`
+
+	// Reference to secret resource that contains Cloudflare API token.
+	APITokenSecretRef SecretKeyRef `json:"apiTokenSecretRef"`
+
+	// Comment is an optional description of the virtual network.
+	//
+	// +optional
+	Comment *string `json:"comment,omitempty"`
+
+	// IsDefault makes this virtual network the account's default one.
+	// Routes without a virtual network are placed in the default virtual network.
+	// Setting it to false does not unset the default on Cloudflare, since an account always has one.
+	//
+	// +optional
+	IsDefault bool `json:"isDefault,omitempty"`
+
+	// DeletionProtection keeps the virtual network on Cloudflare while this resource is being deleted.
+	// The resource stays in terminating state until the protection is disabled.
+	//
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+}
+
+// VirtualNetworkRef refers a VirtualNetwork in the same namespace.
+type VirtualNetworkRef struct {
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// VirtualNetworkConditionType ...
+// +kubebuilder:validation:Enum=VirtualNetwork
+type VirtualNetworkConditionType string
+
+const (
+	VirtualNetworkConditionTypeVirtualNetwork VirtualNetworkConditionType = "VirtualNetwork"
+)
+
+// VirtualNetworkConditionReason ...
+// +kubebuilder:validation:Enum=Creating;NoToken;AccountNotAllowed;FailedToConnectCloudflare;FailedToGetVirtualNetwork;FailedToCreateVirtualNetwork;FailedToUpdateVirtualNetwork;FailedToDeleteVirtualNetwork;DeletionProtected
+type VirtualNetworkConditionReason string
+
+const (
+	VirtualNetworkReasonCreating          VirtualNetworkConditionReason = "Creating"
+	VirtualNetworkReasonNoToken           VirtualNetworkConditionReason = "NoToken"
+	VirtualNetworkReasonAccountNotAllowed VirtualNetworkConditionReason = "AccountNotAllowed"
+	VirtualNetworkReasonFailedToConnectCF VirtualNetworkConditionReason = "FailedToConnectCloudflare"
+	VirtualNetworkReasonFailedToGet       VirtualNetworkConditionReason = "FailedToGetVirtualNetwork"
+	VirtualNetworkReasonFailedToCreate    VirtualNetworkConditionReason = "FailedToCreateVirtualNetwork"
+	VirtualNetworkReasonFailedToUpdate    VirtualNetworkConditionReason = "FailedToUpdateVirtualNetwork"
+	VirtualNetworkReasonFailedToDelete    VirtualNetworkConditionReason = "FailedToDeleteVirtualNetwork"
+	VirtualNetworkReasonDeletionProtected VirtualNetworkConditionReason = "DeletionProtected"
+)
+
+type VirtualNetworkStatusCondition struct {
+	// Type of condition for a component.
+	// Valid value: "VirtualNetwork"
+	Type VirtualNetworkConditionType `json:"type"`
+
+	// Status of the condition for a component.
+	// Valid values for "VirtualNetwork": "True", "False", or "Unknown".
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Message about the condition for a component.
+	// For example, information about a health check.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Error is Condition error code for a component.
+	// For example, a health check error code.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// +optional
+	Reason VirtualNetworkConditionReason `json:"reason,omitempty"`
+}
+
+func (c VirtualNetworkStatusCondition) GetConditionType() VirtualNetworkConditionType {
+	return c.Type
+}
+
+func (c VirtualNetworkStatusCondition) Equals(o VirtualNetworkStatusCondition) bool {
+	return c.Type == o.Type && c.Status == o.Status && c.Message == o.Message &&
+		c.Error == o.Error && c.Reason == o.Reason
+}
+
+// VirtualNetworkStatus defines the observed state of VirtualNetwork
+type VirtualNetworkStatus struct {
+	Conditions []VirtualNetworkStatusCondition `json:"conditions,omitempty"`
+
+	// VirtualNetworkID is the Cloudflare ID of the virtual network.
+	//
+	// +optional
+	VirtualNetworkID string `json:"virtualNetworkID,omitempty"`
+
+	// AccountID is the account that the virtual network was created in.
+	//
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
+
+	// IsDefault reports whether the virtual network is the account's default one on Cloudflare.
+	//
+	// +optional
+	IsDefault bool `json:"isDefault,omitempty"`
+}
+
+func (s *VirtualNetworkStatus) GetCondition(condType VirtualNetworkConditionType) VirtualNetworkStatusCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == condType {
+			return s.Conditions[i]
+		}
+	}
+	return VirtualNetworkStatusCondition{}
+}
+
+func (s *VirtualNetworkStatus) SetCondition(condition VirtualNetworkStatusCondition) {
+	idx := slices.IndexFunc(s.Conditions, func(c VirtualNetworkStatusCondition) bool {
+		return c.Type == condition.Type
+	})
+	if idx == -1 {
+		s.Conditions = append(s.Conditions, condition)
+	} else {
+		s.Conditions[idx] = condition
+	}
+}
+
+// VirtualNetwork is the Schema for the virtualnetworks API
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.virtualNetworkID`
+// +kubebuilder:printcolumn:name="Default",type=boolean,JSONPath=`.status.isDefault`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="VirtualNetwork")].status`
+type VirtualNetwork struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualNetworkSpec   `json:"spec,omitempty"`
+	Status VirtualNetworkStatus `json:"status,omitempty"`
+}
+
+// VirtualNetworkList contains a list of VirtualNetwork
+//
+// +kubebuilder:object:root=true
+type VirtualNetworkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualNetwork `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VirtualNetwork{}, &VirtualNetworkList{})
+}
@@ -21,429 +21,1886 @@ limitations under the License.
 package v1
 
 import (
+	"k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Deployment) DeepCopyInto(out *Deployment) {
+func (in *AccessServiceToken) DeepCopyInto(out *AccessServiceToken) {
 	*out = *in
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessServiceToken.
+func (in *AccessServiceToken) DeepCopy() *AccessServiceToken {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessServiceToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AccessServiceToken) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessServiceTokenList) DeepCopyInto(out *AccessServiceTokenList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AccessServiceToken, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessServiceTokenList.
+func (in *AccessServiceTokenList) DeepCopy() *AccessServiceTokenList {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessServiceTokenList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AccessServiceTokenList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessServiceTokenSpec) DeepCopyInto(out *AccessServiceTokenSpec) {
+	*out = *in
+	in.TunnelRef.DeepCopyInto(&out.TunnelRef)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
 		**out = **in
 	}
-	in.DeploymentStrategy.DeepCopyInto(&out.DeploymentStrategy)
-	in.DaemonSetUpdateStrategy.DeepCopyInto(&out.DaemonSetUpdateStrategy)
-	if in.RevisionHistoryLimit != nil {
-		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
-		*out = new(int32)
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
 		**out = **in
 	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.SecretName != nil {
+		in, out := &in.SecretName, &out.SecretName
+		*out = new(string)
+		**out = **in
 	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessServiceTokenSpec.
+func (in *AccessServiceTokenSpec) DeepCopy() *AccessServiceTokenSpec {
+	if in == nil {
+		return nil
 	}
-	if in.PodLabels != nil {
-		in, out := &in.PodLabels, &out.PodLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	out := new(AccessServiceTokenSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessServiceTokenStatus) DeepCopyInto(out *AccessServiceTokenStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]AccessServiceTokenStatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.PodAnnotations != nil {
-		in, out := &in.PodAnnotations, &out.PodAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.PendingSince != nil {
+		in, out := &in.PendingSince, &out.PendingSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessServiceTokenStatus.
+func (in *AccessServiceTokenStatus) DeepCopy() *AccessServiceTokenStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessServiceTokenStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessServiceTokenStatusCondition) DeepCopyInto(out *AccessServiceTokenStatusCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessServiceTokenStatusCondition.
+func (in *AccessServiceTokenStatusCondition) DeepCopy() *AccessServiceTokenStatusCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessServiceTokenStatusCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendRef) DeepCopyInto(out *BackendRef) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+	out.Port = in.Port
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendRef.
+func (in *BackendRef) DeepCopy() *BackendRef {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CAARecordData) DeepCopyInto(out *CAARecordData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CAARecordData.
+func (in *CAARecordData) DeepCopy() *CAARecordData {
+	if in == nil {
+		return nil
+	}
+	out := new(CAARecordData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSRecord) DeepCopyInto(out *DNSRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecord.
+func (in *DNSRecord) DeepCopy() *DNSRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSRecordList) DeepCopyInto(out *DNSRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DNSRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordList.
+func (in *DNSRecordList) DeepCopy() *DNSRecordList {
+	if in == nil {
+		return nil
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
+	out := new(DNSRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSRecordSpec) DeepCopyInto(out *DNSRecordSpec) {
+	*out = *in
+	if in.Content != nil {
+		in, out := &in.Content, &out.Content
+		*out = new(string)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SRV != nil {
+		in, out := &in.SRV, &out.SRV
+		*out = new(SRVRecordData)
+		**out = **in
+	}
+	if in.CAA != nil {
+		in, out := &in.CAA, &out.CAA
+		*out = new(CAARecordData)
+		**out = **in
+	}
+	if in.Proxied != nil {
+		in, out := &in.Proxied, &out.Proxied
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.APITokenSecretRef.DeepCopyInto(&out.APITokenSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordSpec.
+func (in *DNSRecordSpec) DeepCopy() *DNSRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSRecordStatus) DeepCopyInto(out *DNSRecordStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]DNSRecordStatusCondition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deployment.
-func (in *Deployment) DeepCopy() *Deployment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordStatus.
+func (in *DNSRecordStatus) DeepCopy() *DNSRecordStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Deployment)
+	out := new(DNSRecordStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OriginAccessSettings) DeepCopyInto(out *OriginAccessSettings) {
+func (in *DNSRecordStatusCondition) DeepCopyInto(out *DNSRecordStatusCondition) {
 	*out = *in
-	if in.Access != nil {
-		in, out := &in.Access, &out.Access
-		*out = new(OriginAccessSettingsAccess)
-		(*in).DeepCopyInto(*out)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordStatusCondition.
+func (in *DNSRecordStatusCondition) DeepCopy() *DNSRecordStatusCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSRecordStatusCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonAutoscaling) DeepCopyInto(out *DaemonAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetConcurrentRequests != nil {
+		in, out := &in.TargetConcurrentRequests, &out.TargetConcurrentRequests
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonAutoscaling.
+func (in *DaemonAutoscaling) DeepCopy() *DaemonAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonDisruptionBudget) DeepCopyInto(out *DaemonDisruptionBudget) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.UnhealthyPodEvictionPolicy != nil {
+		in, out := &in.UnhealthyPodEvictionPolicy, &out.UnhealthyPodEvictionPolicy
+		*out = new(policyv1.UnhealthyPodEvictionPolicyType)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonDisruptionBudget.
+func (in *DaemonDisruptionBudget) DeepCopy() *DaemonDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonImage) DeepCopyInto(out *DaemonImage) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonImage.
+func (in *DaemonImage) DeepCopy() *DaemonImage {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonUpgradePolicy) DeepCopyInto(out *DaemonUpgradePolicy) {
+	*out = *in
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MinReleaseAge != nil {
+		in, out := &in.MinReleaseAge, &out.MinReleaseAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.WaitFor != nil {
+		in, out := &in.WaitFor, &out.WaitFor
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthTimeout != nil {
+		in, out := &in.HealthTimeout, &out.HealthTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CheckInterval != nil {
+		in, out := &in.CheckInterval, &out.CheckInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonUpgradePolicy.
+func (in *DaemonUpgradePolicy) DeepCopy() *DaemonUpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonUpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonUpgradeStatus) DeepCopyInto(out *DaemonUpgradeStatus) {
+	*out = *in
+	if in.AvailableSince != nil {
+		in, out := &in.AvailableSince, &out.AvailableSince
+		*out = (*in).DeepCopy()
+	}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonUpgradeStatus.
+func (in *DaemonUpgradeStatus) DeepCopy() *DaemonUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Deployment) DeepCopyInto(out *Deployment) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(DaemonImage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradePolicy != nil {
+		in, out := &in.UpgradePolicy, &out.UpgradePolicy
+		*out = new(DaemonUpgradePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(DaemonAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(DaemonDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	in.DeploymentStrategy.DeepCopyInto(&out.DeploymentStrategy)
+	in.DaemonSetUpdateStrategy.DeepCopyInto(&out.DaemonSetUpdateStrategy)
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreStopDelay != nil {
+		in, out := &in.PreStopDelay, &out.PreStopDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deployment.
+func (in *Deployment) DeepCopy() *Deployment {
+	if in == nil {
+		return nil
+	}
+	out := new(Deployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressOrigin) DeepCopyInto(out *IngressOrigin) {
+	*out = *in
+	if in.BackendRef != nil {
+		in, out := &in.BackendRef, &out.BackendRef
+		*out = new(BackendRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressOrigin.
+func (in *IngressOrigin) DeepCopy() *IngressOrigin {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressOrigin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancer) DeepCopyInto(out *LoadBalancer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancer.
+func (in *LoadBalancer) DeepCopy() *LoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerDefaultPool) DeepCopyInto(out *LoadBalancerDefaultPool) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.ID != nil {
+		in, out := &in.ID, &out.ID
+		*out = new(string)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerDefaultPool.
+func (in *LoadBalancerDefaultPool) DeepCopy() *LoadBalancerDefaultPool {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerDefaultPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerList) DeepCopyInto(out *LoadBalancerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LoadBalancer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerList.
+func (in *LoadBalancerList) DeepCopy() *LoadBalancerList {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerMonitorSpec) DeepCopyInto(out *LoadBalancerMonitorSpec) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(string)
+		**out = **in
+	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.Method != nil {
+		in, out := &in.Method, &out.Method
+		*out = new(string)
+		**out = **in
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConsecutiveUp != nil {
+		in, out := &in.ConsecutiveUp, &out.ConsecutiveUp
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConsecutiveDown != nil {
+		in, out := &in.ConsecutiveDown, &out.ConsecutiveDown
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExpectedBody != nil {
+		in, out := &in.ExpectedBody, &out.ExpectedBody
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExpectedCodes != nil {
+		in, out := &in.ExpectedCodes, &out.ExpectedCodes
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProbeZone != nil {
+		in, out := &in.ProbeZone, &out.ProbeZone
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerMonitorSpec.
+func (in *LoadBalancerMonitorSpec) DeepCopy() *LoadBalancerMonitorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerMonitorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPool) DeepCopyInto(out *LoadBalancerPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPool.
+func (in *LoadBalancerPool) DeepCopy() *LoadBalancerPool {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPoolList) DeepCopyInto(out *LoadBalancerPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LoadBalancerPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPoolList.
+func (in *LoadBalancerPoolList) DeepCopy() *LoadBalancerPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPoolOrigin) DeepCopyInto(out *LoadBalancerPoolOrigin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPoolOrigin.
+func (in *LoadBalancerPoolOrigin) DeepCopy() *LoadBalancerPoolOrigin {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPoolOrigin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPoolRef) DeepCopyInto(out *LoadBalancerPoolRef) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.ID != nil {
+		in, out := &in.ID, &out.ID
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPoolRef.
+func (in *LoadBalancerPoolRef) DeepCopy() *LoadBalancerPoolRef {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPoolRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPoolSpec) DeepCopyInto(out *LoadBalancerPoolSpec) {
+	*out = *in
+	in.APITokenSecretRef.DeepCopyInto(&out.APITokenSecretRef)
+	in.TunnelSelector.DeepCopyInto(&out.TunnelSelector)
+	if in.OriginHost != nil {
+		in, out := &in.OriginHost, &out.OriginHost
+		*out = new(string)
+		**out = **in
+	}
+	if in.OriginSteeringPolicy != nil {
+		in, out := &in.OriginSteeringPolicy, &out.OriginSteeringPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinimumOrigins != nil {
+		in, out := &in.MinimumOrigins, &out.MinimumOrigins
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NotificationEmail != nil {
+		in, out := &in.NotificationEmail, &out.NotificationEmail
+		*out = new(string)
+		**out = **in
+	}
+	if in.CheckRegions != nil {
+		in, out := &in.CheckRegions, &out.CheckRegions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Monitor != nil {
+		in, out := &in.Monitor, &out.Monitor
+		*out = new(LoadBalancerMonitorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPoolSpec.
+func (in *LoadBalancerPoolSpec) DeepCopy() *LoadBalancerPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPoolStatus) DeepCopyInto(out *LoadBalancerPoolStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]LoadBalancerPoolStatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Origins != nil {
+		in, out := &in.Origins, &out.Origins
+		*out = make([]LoadBalancerPoolOrigin, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPoolStatus.
+func (in *LoadBalancerPoolStatus) DeepCopy() *LoadBalancerPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPoolStatusCondition) DeepCopyInto(out *LoadBalancerPoolStatusCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPoolStatusCondition.
+func (in *LoadBalancerPoolStatusCondition) DeepCopy() *LoadBalancerPoolStatusCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPoolStatusCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerSpec) DeepCopyInto(out *LoadBalancerSpec) {
+	*out = *in
+	in.APITokenSecretRef.DeepCopyInto(&out.APITokenSecretRef)
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Proxied != nil {
+		in, out := &in.Proxied, &out.Proxied
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DefaultPools != nil {
+		in, out := &in.DefaultPools, &out.DefaultPools
+		*out = make([]LoadBalancerDefaultPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FallbackPool != nil {
+		in, out := &in.FallbackPool, &out.FallbackPool
+		*out = new(LoadBalancerPoolRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SteeringPolicy != nil {
+		in, out := &in.SteeringPolicy, &out.SteeringPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.RegionPools != nil {
+		in, out := &in.RegionPools, &out.RegionPools
+		*out = make(map[string][]LoadBalancerPoolRef, len(*in))
+		for key, val := range *in {
+			var outVal []LoadBalancerPoolRef
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]LoadBalancerPoolRef, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.CountryPools != nil {
+		in, out := &in.CountryPools, &out.CountryPools
+		*out = make(map[string][]LoadBalancerPoolRef, len(*in))
+		for key, val := range *in {
+			var outVal []LoadBalancerPoolRef
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]LoadBalancerPoolRef, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.PopPools != nil {
+		in, out := &in.PopPools, &out.PopPools
+		*out = make(map[string][]LoadBalancerPoolRef, len(*in))
+		for key, val := range *in {
+			var outVal []LoadBalancerPoolRef
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]LoadBalancerPoolRef, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(string)
+		**out = **in
+	}
+	if in.SessionAffinityTTL != nil {
+		in, out := &in.SessionAffinityTTL, &out.SessionAffinityTTL
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerSpec.
+func (in *LoadBalancerSpec) DeepCopy() *LoadBalancerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerStatus) DeepCopyInto(out *LoadBalancerStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]LoadBalancerStatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStatus.
+func (in *LoadBalancerStatus) DeepCopy() *LoadBalancerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerStatusCondition) DeepCopyInto(out *LoadBalancerStatusCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStatusCondition.
+func (in *LoadBalancerStatusCondition) DeepCopy() *LoadBalancerStatusCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerStatusCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OriginAccessSettings) DeepCopyInto(out *OriginAccessSettings) {
+	*out = *in
+	if in.Access != nil {
+		in, out := &in.Access, &out.Access
+		*out = new(OriginAccessSettingsAccess)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginAccessSettings.
+func (in *OriginAccessSettings) DeepCopy() *OriginAccessSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OriginAccessSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OriginAccessSettingsAccess) DeepCopyInto(out *OriginAccessSettingsAccess) {
+	*out = *in
+	if in.Required != nil {
+		in, out := &in.Required, &out.Required
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TeamName != nil {
+		in, out := &in.TeamName, &out.TeamName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AudTag != nil {
+		in, out := &in.AudTag, &out.AudTag
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginAccessSettingsAccess.
+func (in *OriginAccessSettingsAccess) DeepCopy() *OriginAccessSettingsAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(OriginAccessSettingsAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OriginConfiguration) DeepCopyInto(out *OriginConfiguration) {
+	*out = *in
+	if in.TLSSettings != nil {
+		in, out := &in.TLSSettings, &out.TLSSettings
+		*out = new(OriginTLSSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPSettings != nil {
+		in, out := &in.HTTPSettings, &out.HTTPSettings
+		*out = new(OriginHTTPSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionSettings != nil {
+		in, out := &in.ConnectionSettings, &out.ConnectionSettings
+		*out = new(OriginConnectionSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessSettings != nil {
+		in, out := &in.AccessSettings, &out.AccessSettings
+		*out = new(OriginAccessSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginConfiguration.
+func (in *OriginConfiguration) DeepCopy() *OriginConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(OriginConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OriginConnectionSettings) DeepCopyInto(out *OriginConnectionSettings) {
+	*out = *in
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.NoHappyEyeballs != nil {
+		in, out := &in.NoHappyEyeballs, &out.NoHappyEyeballs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProxyType != nil {
+		in, out := &in.ProxyType, &out.ProxyType
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProxyAddress != nil {
+		in, out := &in.ProxyAddress, &out.ProxyAddress
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProxyPort != nil {
+		in, out := &in.ProxyPort, &out.ProxyPort
+		*out = new(int)
+		**out = **in
+	}
+	if in.KeepAliveTimeout != nil {
+		in, out := &in.KeepAliveTimeout, &out.KeepAliveTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.KeepAliveConnections != nil {
+		in, out := &in.KeepAliveConnections, &out.KeepAliveConnections
+		*out = new(int)
+		**out = **in
+	}
+	if in.TCPKeepAlive != nil {
+		in, out := &in.TCPKeepAlive, &out.TCPKeepAlive
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginConnectionSettings.
+func (in *OriginConnectionSettings) DeepCopy() *OriginConnectionSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OriginConnectionSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OriginHTTPSettings) DeepCopyInto(out *OriginHTTPSettings) {
+	*out = *in
+	if in.HTTPHostHeader != nil {
+		in, out := &in.HTTPHostHeader, &out.HTTPHostHeader
+		*out = new(string)
+		**out = **in
+	}
+	if in.DisableChunkedEncoding != nil {
+		in, out := &in.DisableChunkedEncoding, &out.DisableChunkedEncoding
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginHTTPSettings.
+func (in *OriginHTTPSettings) DeepCopy() *OriginHTTPSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OriginHTTPSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OriginRequestConfig) DeepCopyInto(out *OriginRequestConfig) {
+	*out = *in
+	if in.OriginTLSSettings != nil {
+		in, out := &in.OriginTLSSettings, &out.OriginTLSSettings
+		*out = new(OriginTLSSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OriginHTTPSettings != nil {
+		in, out := &in.OriginHTTPSettings, &out.OriginHTTPSettings
+		*out = new(OriginHTTPSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OriginConnectionSettings != nil {
+		in, out := &in.OriginConnectionSettings, &out.OriginConnectionSettings
+		*out = new(OriginConnectionSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OriginAccessSettings != nil {
+		in, out := &in.OriginAccessSettings, &out.OriginAccessSettings
+		*out = new(OriginAccessSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginRequestConfig.
+func (in *OriginRequestConfig) DeepCopy() *OriginRequestConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OriginRequestConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OriginTLSSettings) DeepCopyInto(out *OriginTLSSettings) {
+	*out = *in
+	if in.OriginServerName != nil {
+		in, out := &in.OriginServerName, &out.OriginServerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.CAPool != nil {
+		in, out := &in.CAPool, &out.CAPool
+		*out = new(string)
+		**out = **in
+	}
+	if in.NoTLSVerify != nil {
+		in, out := &in.NoTLSVerify, &out.NoTLSVerify
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TLSTimeout != nil {
+		in, out := &in.TLSTimeout, &out.TLSTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.HTTP2Origin != nil {
+		in, out := &in.HTTP2Origin, &out.HTTP2Origin
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginTLSSettings.
+func (in *OriginTLSSettings) DeepCopy() *OriginTLSSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OriginTLSSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyTunnelRef) DeepCopyInto(out *PolicyTunnelRef) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyTunnelRef.
+func (in *PolicyTunnelRef) DeepCopy() *PolicyTunnelRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyTunnelRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantFrom) DeepCopyInto(out *ReferenceGrantFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantFrom.
+func (in *ReferenceGrantFrom) DeepCopy() *ReferenceGrantFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrantFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferenceGrantTo) DeepCopyInto(out *ReferenceGrantTo) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceGrantTo.
+func (in *ReferenceGrantTo) DeepCopy() *ReferenceGrantTo {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferenceGrantTo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRVRecordData) DeepCopyInto(out *SRVRecordData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRVRecordData.
+func (in *SRVRecordData) DeepCopy() *SRVRecordData {
+	if in == nil {
+		return nil
+	}
+	out := new(SRVRecordData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+	*out = *in
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Tunnel) DeepCopyInto(out *Tunnel) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Tunnel.
+func (in *Tunnel) DeepCopy() *Tunnel {
+	if in == nil {
+		return nil
+	}
+	out := new(Tunnel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Tunnel) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelConfigIngress) DeepCopyInto(out *TunnelConfigIngress) {
+	*out = *in
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(string)
+		**out = **in
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.OriginRequest != nil {
+		in, out := &in.OriginRequest, &out.OriginRequest
+		*out = new(OriginRequestConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelConfigIngress.
+func (in *TunnelConfigIngress) DeepCopy() *TunnelConfigIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelConfigIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelIngress) DeepCopyInto(out *TunnelIngress) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngress.
+func (in *TunnelIngress) DeepCopy() *TunnelIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TunnelIngress) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelIngressList) DeepCopyInto(out *TunnelIngressList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TunnelIngress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginAccessSettings.
-func (in *OriginAccessSettings) DeepCopy() *OriginAccessSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngressList.
+func (in *TunnelIngressList) DeepCopy() *TunnelIngressList {
 	if in == nil {
 		return nil
 	}
-	out := new(OriginAccessSettings)
+	out := new(TunnelIngressList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TunnelIngressList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OriginAccessSettingsAccess) DeepCopyInto(out *OriginAccessSettingsAccess) {
+func (in *TunnelIngressSpec) DeepCopyInto(out *TunnelIngressSpec) {
 	*out = *in
-	if in.Required != nil {
-		in, out := &in.Required, &out.Required
-		*out = new(bool)
-		**out = **in
+	in.TunnelConfigIngress.DeepCopyInto(&out.TunnelConfigIngress)
+	if in.Origin != nil {
+		in, out := &in.Origin, &out.Origin
+		*out = new(IngressOrigin)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.TeamName != nil {
-		in, out := &in.TeamName, &out.TeamName
+	in.TunnelRef.DeepCopyInto(&out.TunnelRef)
+	if in.ZoneID != nil {
+		in, out := &in.ZoneID, &out.ZoneID
 		*out = new(string)
 		**out = **in
 	}
-	if in.AudTag != nil {
-		in, out := &in.AudTag, &out.AudTag
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.ZoneName != nil {
+		in, out := &in.ZoneName, &out.ZoneName
+		*out = new(string)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginAccessSettingsAccess.
-func (in *OriginAccessSettingsAccess) DeepCopy() *OriginAccessSettingsAccess {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngressSpec.
+func (in *TunnelIngressSpec) DeepCopy() *TunnelIngressSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(OriginAccessSettingsAccess)
+	out := new(TunnelIngressSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OriginConfiguration) DeepCopyInto(out *OriginConfiguration) {
+func (in *TunnelIngressStatus) DeepCopyInto(out *TunnelIngressStatus) {
 	*out = *in
-	if in.TLSSettings != nil {
-		in, out := &in.TLSSettings, &out.TLSSettings
-		*out = new(OriginTLSSettings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.HTTPSettings != nil {
-		in, out := &in.HTTPSettings, &out.HTTPSettings
-		*out = new(OriginHTTPSettings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ConnectionSettings != nil {
-		in, out := &in.ConnectionSettings, &out.ConnectionSettings
-		*out = new(OriginConnectionSettings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.AccessSettings != nil {
-		in, out := &in.AccessSettings, &out.AccessSettings
-		*out = new(OriginAccessSettings)
-		(*in).DeepCopyInto(*out)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]TunnelIngressStatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginConfiguration.
-func (in *OriginConfiguration) DeepCopy() *OriginConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngressStatus.
+func (in *TunnelIngressStatus) DeepCopy() *TunnelIngressStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OriginConfiguration)
+	out := new(TunnelIngressStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OriginConnectionSettings) DeepCopyInto(out *OriginConnectionSettings) {
+func (in *TunnelIngressStatusCondition) DeepCopyInto(out *TunnelIngressStatusCondition) {
 	*out = *in
-	if in.ConnectTimeout != nil {
-		in, out := &in.ConnectTimeout, &out.ConnectTimeout
-		*out = new(string)
-		**out = **in
-	}
-	if in.NoHappyEyeballs != nil {
-		in, out := &in.NoHappyEyeballs, &out.NoHappyEyeballs
-		*out = new(bool)
-		**out = **in
-	}
-	if in.ProxyType != nil {
-		in, out := &in.ProxyType, &out.ProxyType
-		*out = new(string)
-		**out = **in
-	}
-	if in.ProxyAddress != nil {
-		in, out := &in.ProxyAddress, &out.ProxyAddress
-		*out = new(string)
-		**out = **in
-	}
-	if in.ProxyPort != nil {
-		in, out := &in.ProxyPort, &out.ProxyPort
-		*out = new(int)
-		**out = **in
-	}
-	if in.KeepAliveTimeout != nil {
-		in, out := &in.KeepAliveTimeout, &out.KeepAliveTimeout
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-	if in.KeepAliveConnections != nil {
-		in, out := &in.KeepAliveConnections, &out.KeepAliveConnections
-		*out = new(int)
-		**out = **in
-	}
-	if in.TCPKeepAlive != nil {
-		in, out := &in.TCPKeepAlive, &out.TCPKeepAlive
-		*out = new(metav1.Duration)
-		**out = **in
-	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginConnectionSettings.
-func (in *OriginConnectionSettings) DeepCopy() *OriginConnectionSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngressStatusCondition.
+func (in *TunnelIngressStatusCondition) DeepCopy() *TunnelIngressStatusCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(OriginConnectionSettings)
+	out := new(TunnelIngressStatusCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OriginHTTPSettings) DeepCopyInto(out *OriginHTTPSettings) {
+func (in *TunnelList) DeepCopyInto(out *TunnelList) {
 	*out = *in
-	if in.HTTPHostHeader != nil {
-		in, out := &in.HTTPHostHeader, &out.HTTPHostHeader
-		*out = new(string)
-		**out = **in
-	}
-	if in.DisableChunkedEncoding != nil {
-		in, out := &in.DisableChunkedEncoding, &out.DisableChunkedEncoding
-		*out = new(bool)
-		**out = **in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Tunnel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginHTTPSettings.
-func (in *OriginHTTPSettings) DeepCopy() *OriginHTTPSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelList.
+func (in *TunnelList) DeepCopy() *TunnelList {
 	if in == nil {
 		return nil
 	}
-	out := new(OriginHTTPSettings)
+	out := new(TunnelList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TunnelList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OriginRequestConfig) DeepCopyInto(out *OriginRequestConfig) {
+func (in *TunnelNetworkRoute) DeepCopyInto(out *TunnelNetworkRoute) {
 	*out = *in
-	if in.OriginTLSSettings != nil {
-		in, out := &in.OriginTLSSettings, &out.OriginTLSSettings
-		*out = new(OriginTLSSettings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.OriginHTTPSettings != nil {
-		in, out := &in.OriginHTTPSettings, &out.OriginHTTPSettings
-		*out = new(OriginHTTPSettings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.OriginConnectionSettings != nil {
-		in, out := &in.OriginConnectionSettings, &out.OriginConnectionSettings
-		*out = new(OriginConnectionSettings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.OriginAccessSettings != nil {
-		in, out := &in.OriginAccessSettings, &out.OriginAccessSettings
-		*out = new(OriginAccessSettings)
-		(*in).DeepCopyInto(*out)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginRequestConfig.
-func (in *OriginRequestConfig) DeepCopy() *OriginRequestConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelNetworkRoute.
+func (in *TunnelNetworkRoute) DeepCopy() *TunnelNetworkRoute {
 	if in == nil {
 		return nil
 	}
-	out := new(OriginRequestConfig)
+	out := new(TunnelNetworkRoute)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TunnelNetworkRoute) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OriginTLSSettings) DeepCopyInto(out *OriginTLSSettings) {
+func (in *TunnelNetworkRouteList) DeepCopyInto(out *TunnelNetworkRouteList) {
 	*out = *in
-	if in.OriginServerName != nil {
-		in, out := &in.OriginServerName, &out.OriginServerName
-		*out = new(string)
-		**out = **in
-	}
-	if in.CAPool != nil {
-		in, out := &in.CAPool, &out.CAPool
-		*out = new(string)
-		**out = **in
-	}
-	if in.NoTLSVerify != nil {
-		in, out := &in.NoTLSVerify, &out.NoTLSVerify
-		*out = new(bool)
-		**out = **in
-	}
-	if in.TLSTimeout != nil {
-		in, out := &in.TLSTimeout, &out.TLSTimeout
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-	if in.HTTP2Origin != nil {
-		in, out := &in.HTTP2Origin, &out.HTTP2Origin
-		*out = new(bool)
-		**out = **in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TunnelNetworkRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OriginTLSSettings.
-func (in *OriginTLSSettings) DeepCopy() *OriginTLSSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelNetworkRouteList.
+func (in *TunnelNetworkRouteList) DeepCopy() *TunnelNetworkRouteList {
 	if in == nil {
 		return nil
 	}
-	out := new(OriginTLSSettings)
+	out := new(TunnelNetworkRouteList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TunnelNetworkRouteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+func (in *TunnelNetworkRouteSpec) DeepCopyInto(out *TunnelNetworkRouteSpec) {
 	*out = *in
-	if in.Key != nil {
-		in, out := &in.Key, &out.Key
+	in.TunnelRef.DeepCopyInto(&out.TunnelRef)
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
 		*out = new(string)
 		**out = **in
 	}
-	if in.Namespace != nil {
-		in, out := &in.Namespace, &out.Namespace
+	if in.VirtualNetworkID != nil {
+		in, out := &in.VirtualNetworkID, &out.VirtualNetworkID
 		*out = new(string)
 		**out = **in
 	}
+	if in.VirtualNetworkRef != nil {
+		in, out := &in.VirtualNetworkRef, &out.VirtualNetworkRef
+		*out = new(VirtualNetworkRef)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
-func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelNetworkRouteSpec.
+func (in *TunnelNetworkRouteSpec) DeepCopy() *TunnelNetworkRouteSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretKeyRef)
+	out := new(TunnelNetworkRouteSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Tunnel) DeepCopyInto(out *Tunnel) {
+func (in *TunnelNetworkRouteStatus) DeepCopyInto(out *TunnelNetworkRouteStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]TunnelNetworkRouteStatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Tunnel.
-func (in *Tunnel) DeepCopy() *Tunnel {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelNetworkRouteStatus.
+func (in *TunnelNetworkRouteStatus) DeepCopy() *TunnelNetworkRouteStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Tunnel)
+	out := new(TunnelNetworkRouteStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Tunnel) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TunnelConfigIngress) DeepCopyInto(out *TunnelConfigIngress) {
+func (in *TunnelNetworkRouteStatusCondition) DeepCopyInto(out *TunnelNetworkRouteStatusCondition) {
 	*out = *in
-	if in.Hostname != nil {
-		in, out := &in.Hostname, &out.Hostname
-		*out = new(string)
-		**out = **in
-	}
-	if in.Path != nil {
-		in, out := &in.Path, &out.Path
-		*out = new(string)
-		**out = **in
-	}
-	if in.OriginRequest != nil {
-		in, out := &in.OriginRequest, &out.OriginRequest
-		*out = new(OriginRequestConfig)
-		(*in).DeepCopyInto(*out)
-	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelConfigIngress.
-func (in *TunnelConfigIngress) DeepCopy() *TunnelConfigIngress {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelNetworkRouteStatusCondition.
+func (in *TunnelNetworkRouteStatusCondition) DeepCopy() *TunnelNetworkRouteStatusCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(TunnelConfigIngress)
+	out := new(TunnelNetworkRouteStatusCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TunnelIngress) DeepCopyInto(out *TunnelIngress) {
+func (in *TunnelPolicy) DeepCopyInto(out *TunnelPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngress.
-func (in *TunnelIngress) DeepCopy() *TunnelIngress {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelPolicy.
+func (in *TunnelPolicy) DeepCopy() *TunnelPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(TunnelIngress)
+	out := new(TunnelPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TunnelIngress) DeepCopyObject() runtime.Object {
+func (in *TunnelPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -451,31 +1908,31 @@ func (in *TunnelIngress) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TunnelIngressList) DeepCopyInto(out *TunnelIngressList) {
+func (in *TunnelPolicyList) DeepCopyInto(out *TunnelPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]TunnelIngress, len(*in))
+		*out = make([]TunnelPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngressList.
-func (in *TunnelIngressList) DeepCopy() *TunnelIngressList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelPolicyList.
+func (in *TunnelPolicyList) DeepCopy() *TunnelPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(TunnelIngressList)
+	out := new(TunnelPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TunnelIngressList) DeepCopyObject() runtime.Object {
+func (in *TunnelPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -483,86 +1940,119 @@ func (in *TunnelIngressList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TunnelIngressSpec) DeepCopyInto(out *TunnelIngressSpec) {
+func (in *TunnelPolicySpec) DeepCopyInto(out *TunnelPolicySpec) {
 	*out = *in
-	in.TunnelConfigIngress.DeepCopyInto(&out.TunnelConfigIngress)
-	out.TunnelRef = in.TunnelRef
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedHostnames != nil {
+		in, out := &in.AllowedHostnames, &out.AllowedHostnames
+		*out = make([]HostnamePattern, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedTunnels != nil {
+		in, out := &in.AllowedTunnels, &out.AllowedTunnels
+		*out = make([]PolicyTunnelRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedAccountIDs != nil {
+		in, out := &in.AllowedAccountIDs, &out.AllowedAccountIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedOriginSchemes != nil {
+		in, out := &in.AllowedOriginSchemes, &out.AllowedOriginSchemes
+		*out = make([]OriginScheme, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngressSpec.
-func (in *TunnelIngressSpec) DeepCopy() *TunnelIngressSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelPolicySpec.
+func (in *TunnelPolicySpec) DeepCopy() *TunnelPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TunnelIngressSpec)
+	out := new(TunnelPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TunnelIngressStatus) DeepCopyInto(out *TunnelIngressStatus) {
+func (in *TunnelRef) DeepCopyInto(out *TunnelRef) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]TunnelIngressStatusCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngressStatus.
-func (in *TunnelIngressStatus) DeepCopy() *TunnelIngressStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelRef.
+func (in *TunnelRef) DeepCopy() *TunnelRef {
 	if in == nil {
 		return nil
 	}
-	out := new(TunnelIngressStatus)
+	out := new(TunnelRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TunnelIngressStatusCondition) DeepCopyInto(out *TunnelIngressStatusCondition) {
+func (in *TunnelReferenceGrant) DeepCopyInto(out *TunnelReferenceGrant) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelIngressStatusCondition.
-func (in *TunnelIngressStatusCondition) DeepCopy() *TunnelIngressStatusCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelReferenceGrant.
+func (in *TunnelReferenceGrant) DeepCopy() *TunnelReferenceGrant {
 	if in == nil {
 		return nil
 	}
-	out := new(TunnelIngressStatusCondition)
+	out := new(TunnelReferenceGrant)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TunnelReferenceGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TunnelList) DeepCopyInto(out *TunnelList) {
+func (in *TunnelReferenceGrantList) DeepCopyInto(out *TunnelReferenceGrantList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Tunnel, len(*in))
+		*out = make([]TunnelReferenceGrant, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelList.
-func (in *TunnelList) DeepCopy() *TunnelList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelReferenceGrantList.
+func (in *TunnelReferenceGrantList) DeepCopy() *TunnelReferenceGrantList {
 	if in == nil {
 		return nil
 	}
-	out := new(TunnelList)
+	out := new(TunnelReferenceGrantList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TunnelList) DeepCopyObject() runtime.Object {
+func (in *TunnelReferenceGrantList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -570,16 +2060,28 @@ func (in *TunnelList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TunnelRef) DeepCopyInto(out *TunnelRef) {
+func (in *TunnelReferenceGrantSpec) DeepCopyInto(out *TunnelReferenceGrantSpec) {
 	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]ReferenceGrantFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]ReferenceGrantTo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelRef.
-func (in *TunnelRef) DeepCopy() *TunnelRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelReferenceGrantSpec.
+func (in *TunnelReferenceGrantSpec) DeepCopy() *TunnelReferenceGrantSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TunnelRef)
+	out := new(TunnelReferenceGrantSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -587,19 +2089,59 @@ func (in *TunnelRef) DeepCopy() *TunnelRef {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TunnelRunParameters) DeepCopyInto(out *TunnelRunParameters) {
 	*out = *in
+	if in.EdgeBindAddress != nil {
+		in, out := &in.EdgeBindAddress, &out.EdgeBindAddress
+		*out = new(string)
+		**out = **in
+	}
+	if in.EdgeIPVersion != nil {
+		in, out := &in.EdgeIPVersion, &out.EdgeIPVersion
+		*out = new(EdgeIPVersion)
+		**out = **in
+	}
 	if in.GracePeriod != nil {
 		in, out := &in.GracePeriod, &out.GracePeriod
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.HAConnections != nil {
+		in, out := &in.HAConnections, &out.HAConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Label != nil {
+		in, out := &in.Label, &out.Label
+		*out = new(string)
+		**out = **in
+	}
 	if in.Logfile != nil {
 		in, out := &in.Logfile, &out.Logfile
 		*out = new(string)
 		**out = **in
 	}
+	if in.LogDirectory != nil {
+		in, out := &in.LogDirectory, &out.LogDirectory
+		*out = new(string)
+		**out = **in
+	}
 	if in.Loglevel != nil {
 		in, out := &in.Loglevel, &out.Loglevel
-		*out = new(string)
+		*out = new(LogLevel)
+		**out = **in
+	}
+	if in.TransportLoglevel != nil {
+		in, out := &in.TransportLoglevel, &out.TransportLoglevel
+		*out = new(LogLevel)
+		**out = **in
+	}
+	if in.ManagementDiagnostics != nil {
+		in, out := &in.ManagementDiagnostics, &out.ManagementDiagnostics
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxFetchSize != nil {
+		in, out := &in.MaxFetchSize, &out.MaxFetchSize
+		*out = new(int32)
 		**out = **in
 	}
 	if in.Pidfile != nil {
@@ -607,14 +2149,19 @@ func (in *TunnelRunParameters) DeepCopyInto(out *TunnelRunParameters) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PostQuantum != nil {
+		in, out := &in.PostQuantum, &out.PostQuantum
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Protocol != nil {
 		in, out := &in.Protocol, &out.Protocol
-		*out = new(string)
+		*out = new(TunnelProtocol)
 		**out = **in
 	}
 	if in.Region != nil {
 		in, out := &in.Region, &out.Region
-		*out = new(string)
+		*out = new(EdgeRegion)
 		**out = **in
 	}
 	if in.Retries != nil {
@@ -622,6 +2169,21 @@ func (in *TunnelRunParameters) DeepCopyInto(out *TunnelRunParameters) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.CompressionQuality != nil {
+		in, out := &in.CompressionQuality, &out.CompressionQuality
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ICMPv4Src != nil {
+		in, out := &in.ICMPv4Src, &out.ICMPv4Src
+		*out = new(string)
+		**out = **in
+	}
+	if in.ICMPv6Src != nil {
+		in, out := &in.ICMPv6Src, &out.ICMPv6Src
+		*out = new(string)
+		**out = **in
+	}
 	if in.Tag != nil {
 		in, out := &in.Tag, &out.Tag
 		*out = make(map[string]string, len(*in))
@@ -666,6 +2228,11 @@ func (in *TunnelSpec) DeepCopyInto(out *TunnelSpec) {
 		*out = new(TunnelRunParameters)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.VirtualNetworkRef != nil {
+		in, out := &in.VirtualNetworkRef, &out.VirtualNetworkRef
+		*out = new(VirtualNetworkRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelSpec.
@@ -688,6 +2255,7 @@ func (in *TunnelStatus) DeepCopyInto(out *TunnelStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.Upgrade.DeepCopyInto(&out.Upgrade)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelStatus.
@@ -715,3 +2283,136 @@ func (in *TunnelStatusCondition) DeepCopy() *TunnelStatusCondition {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNetwork) DeepCopyInto(out *VirtualNetwork) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNetwork.
+func (in *VirtualNetwork) DeepCopy() *VirtualNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualNetwork) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNetworkList) DeepCopyInto(out *VirtualNetworkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualNetwork, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNetworkList.
+func (in *VirtualNetworkList) DeepCopy() *VirtualNetworkList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNetworkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualNetworkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNetworkRef) DeepCopyInto(out *VirtualNetworkRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNetworkRef.
+func (in *VirtualNetworkRef) DeepCopy() *VirtualNetworkRef {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNetworkRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNetworkSpec) DeepCopyInto(out *VirtualNetworkSpec) {
+	*out = *in
+	in.APITokenSecretRef.DeepCopyInto(&out.APITokenSecretRef)
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNetworkSpec.
+func (in *VirtualNetworkSpec) DeepCopy() *VirtualNetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNetworkStatus) DeepCopyInto(out *VirtualNetworkStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]VirtualNetworkStatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNetworkStatus.
+func (in *VirtualNetworkStatus) DeepCopy() *VirtualNetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNetworkStatusCondition) DeepCopyInto(out *VirtualNetworkStatusCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNetworkStatusCondition.
+func (in *VirtualNetworkStatusCondition) DeepCopy() *VirtualNetworkStatusCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNetworkStatusCondition)
+	in.DeepCopyInto(out)
+	return out
+}
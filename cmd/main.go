@@ -20,6 +20,9 @@ import (
 	"flag"
 	"os"
 
+	// maintenance windows of daemon upgrades are in IANA time zones, which distroless images do not ship
+	_ "time/tzdata"
+
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	"k8s.io/apimachinery/pkg/runtime"
@@ -28,12 +31,16 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	cloudflaredoperatorv1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/config"
 	"github.com/isac322/cloudflared-operator/internal/controller"
+	webhookcloudflaredoperatorv1 "github.com/isac322/cloudflared-operator/internal/webhook/v1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -53,11 +60,14 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var configFile string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&configFile, "config", "",
+		"The path to the ControllerConfiguration file. Defaults are used for every field if omitted.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -66,9 +76,24 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	controllerConfig, err := config.Load(configFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load controller configuration")
+		os.Exit(1)
+	}
+
+	var cacheOpts cache.Options
+	if len(controllerConfig.WatchNamespaces) > 0 {
+		cacheOpts.DefaultNamespaces = make(map[string]cache.Config, len(controllerConfig.WatchNamespaces))
+		for _, ns := range controllerConfig.WatchNamespaces {
+			cacheOpts.DefaultNamespaces[ns] = cache.Config{}
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
+		Cache:                  cacheOpts,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "18f1a764.bhyoo.com",
@@ -89,10 +114,27 @@ func main() {
 		os.Exit(1)
 	}
 
+	cfClients := cloudflare.NewClientPool(config.ClientOptions(controllerConfig), clock.RealClock{})
+
+	preStopSleepSupported, err := controller.SupportsPreStopSleep(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to discover version of Kubernetes")
+		os.Exit(1)
+	}
+	if !preStopSleepSupported {
+		setupLog.Info("preStopDelay of tunnels is ignored, since it needs Kubernetes 1.30 or later")
+	}
+
 	if err = (&controller.TunnelReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Clock:  clock.RealClock{},
+
+		CloudflareClients: cfClients,
+		DaemonConfig:      controllerConfig.Daemon,
+		DaemonVersions:    config.DaemonVersionResolver(controllerConfig, clock.RealClock{}),
+
+		PreStopSleepUnsupported: !preStopSleepSupported,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Tunnel")
 		os.Exit(1)
@@ -101,20 +143,101 @@ func main() {
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Clock:  clock.RealClock{},
+
+		CloudflareClients: cfClients,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TunnelIngress")
 		os.Exit(1)
 	}
-	//+kubebuilder:scaffold:builder
+	if err = (&controller.AccessServiceTokenReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Clock:    clock.RealClock{},
+		Recorder: mgr.GetEventRecorderFor("accessservicetoken-controller"),
 
-	if err = (&controller.ServiceReconciler{
+		CloudflareClients: cfClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AccessServiceToken")
+		os.Exit(1)
+	}
+	if err = (&controller.TunnelNetworkRouteReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Clock:  clock.RealClock{},
+
+		CloudflareClients: cfClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TunnelNetworkRoute")
+		os.Exit(1)
+	}
+	if err = (&controller.VirtualNetworkReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Clock:  clock.RealClock{},
+
+		CloudflareClients: cfClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VirtualNetwork")
+		os.Exit(1)
+	}
+	if err = (&controller.LoadBalancerPoolReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Clock:  clock.RealClock{},
+
+		CloudflareClients: cfClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LoadBalancerPool")
+		os.Exit(1)
+	}
+	if err = (&controller.LoadBalancerReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Clock:  clock.RealClock{},
+
+		CloudflareClients: cfClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LoadBalancer")
+		os.Exit(1)
+	}
+	if err = (&controller.DNSRecordReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Clock:  clock.RealClock{},
+
+		CloudflareClients: cfClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DNSRecord")
+		os.Exit(1)
+	}
+	//+kubebuilder:scaffold:builder
+
+	if err = (&controller.ServiceReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("service-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Service")
 		os.Exit(1)
 	}
 
+	// webhooks need a serving certificate, e.g. of cert-manager, so they are enabled only on request
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = webhookcloudflaredoperatorv1.SetupTunnelIngressWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "TunnelIngress")
+			os.Exit(1)
+		}
+		if err = webhookcloudflaredoperatorv1.SetupDNSRecordWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DNSRecord")
+			os.Exit(1)
+		}
+		if err = webhookcloudflaredoperatorv1.SetupLoadBalancerWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "LoadBalancer")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,126 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"k8s.io/utils/ptr"
+)
+
+type AccessServiceToken struct {
+	ID       string
+	ClientID string
+	// ClientSecret is only filled on creation and rotation.
+	ClientSecret string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+func (c client) CreateAccessServiceToken(
+	ctx context.Context,
+	accountID, name string,
+	duration time.Duration,
+) (AccessServiceToken, error) {
+	params := cloudflare.CreateAccessServiceTokenParams{Name: name}
+	if duration > 0 {
+		params.Duration = duration.String()
+	}
+
+	res, err := c.API.CreateAccessServiceToken(
+		ctx,
+		accountContainer(accountID),
+		params,
+	)
+	if err != nil {
+		return AccessServiceToken{}, err
+	}
+
+	return AccessServiceToken{
+		ID:           res.ID,
+		ClientID:     res.ClientID,
+		ClientSecret: res.ClientSecret,
+		CreatedAt:    ptr.Deref(res.CreatedAt, time.Time{}),
+		ExpiresAt:    ptr.Deref(res.ExpiresAt, time.Time{}),
+	}, nil
+}
+
+// FindAccessServiceTokens returns service tokens of given name, which is not unique in an account.
+func (c client) FindAccessServiceTokens(ctx context.Context, accountID, name string) ([]AccessServiceToken, error) {
+	res, _, err := c.API.ListAccessServiceTokens(
+		ctx,
+		accountContainer(accountID),
+		cloudflare.ListAccessServiceTokensParams{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []AccessServiceToken
+	for _, token := range res {
+		if token.Name != name {
+			continue
+		}
+		tokens = append(tokens, AccessServiceToken{
+			ID:        token.ID,
+			ClientID:  token.ClientID,
+			CreatedAt: ptr.Deref(token.CreatedAt, time.Time{}),
+			ExpiresAt: ptr.Deref(token.ExpiresAt, time.Time{}),
+		})
+	}
+	return tokens, nil
+}
+
+func (c client) RefreshAccessServiceToken(ctx context.Context, accountID, tokenID string) (AccessServiceToken, error) {
+	res, err := c.API.RefreshAccessServiceToken(
+		ctx,
+		accountContainer(accountID),
+		tokenID,
+	)
+	if err != nil {
+		return AccessServiceToken{}, err
+	}
+
+	return AccessServiceToken{
+		ID:        res.ID,
+		ClientID:  res.ClientID,
+		ExpiresAt: ptr.Deref(res.ExpiresAt, time.Time{}),
+	}, nil
+}
+
+func (c client) RotateAccessServiceToken(ctx context.Context, accountID, tokenID string) (AccessServiceToken, error) {
+	res, err := c.API.RotateAccessServiceToken(
+		ctx,
+		accountContainer(accountID),
+		tokenID,
+	)
+	if err != nil {
+		return AccessServiceToken{}, err
+	}
+
+	return AccessServiceToken{
+		ID:           res.ID,
+		ClientID:     res.ClientID,
+		ClientSecret: res.ClientSecret,
+		ExpiresAt:    ptr.Deref(res.ExpiresAt, time.Time{}),
+	}, nil
+}
+
+func (c client) DeleteAccessServiceToken(ctx context.Context, accountID, tokenID string) error {
+	_, err := c.API.DeleteAccessServiceToken(
+		ctx,
+		accountContainer(accountID),
+		tokenID,
+	)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// IsNotFound reports whether err is a Cloudflare API "not found" response.
+func IsNotFound(err error) bool {
+	var notFound *cloudflare.NotFoundError
+	return errors.As(err, &notFound)
+}
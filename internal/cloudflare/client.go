@@ -4,16 +4,14 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"errors"
-	"fmt"
 	"net/http"
-	"sync"
+	"time"
 
 	"github.com/cloudflare/cloudflare-go"
 	"github.com/goccy/go-json"
 	"golang.org/x/net/idna"
-	"golang.org/x/net/publicsuffix"
 	"golang.org/x/sync/errgroup"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/ptr"
 )
 
@@ -30,22 +28,75 @@ type TunnelCredential struct {
 type Client interface {
 	ValidateTunnelCredential(ctx context.Context, credential TunnelCredential) (bool, error)
 	GetOrCreateTunnel(ctx context.Context, accountID, name string) (TunnelCredential, error)
-	CreateRoute(ctx context.Context, accountID, tunnelID, domain string, overwrite bool) error
+	CreateRoute(ctx context.Context, accountID, tunnelID, domain string, zone ZoneRef, overwrite bool) error
 	DeleteTunnel(ctx context.Context, accountID, tunnelID string) error
-	DeleteDNSRecord(ctx context.Context, accountID, domain string) error
+	UpdateTunnelConfiguration(
+		ctx context.Context,
+		accountID, tunnelID string,
+		config cloudflare.TunnelConfiguration,
+	) error
+	DeleteDNSRecord(ctx context.Context, accountID, domain string, zone ZoneRef) error
+
+	CreateAccessServiceToken(
+		ctx context.Context,
+		accountID, name string,
+		duration time.Duration,
+	) (AccessServiceToken, error)
+	FindAccessServiceTokens(ctx context.Context, accountID, name string) ([]AccessServiceToken, error)
+	RefreshAccessServiceToken(ctx context.Context, accountID, tokenID string) (AccessServiceToken, error)
+	RotateAccessServiceToken(ctx context.Context, accountID, tokenID string) (AccessServiceToken, error)
+	DeleteAccessServiceToken(ctx context.Context, accountID, tokenID string) error
+
+	GetNetworkRoute(ctx context.Context, accountID, network, vnetID string) (*NetworkRoute, error)
+	CreateNetworkRoute(ctx context.Context, accountID string, route NetworkRoute) error
+	UpdateNetworkRoute(ctx context.Context, accountID string, route NetworkRoute) error
+	DeleteNetworkRoute(ctx context.Context, accountID, network, vnetID string) error
+
+	GetVirtualNetwork(ctx context.Context, accountID, vnetID string) (*VirtualNetwork, error)
+	GetVirtualNetworkByName(ctx context.Context, accountID, name string) (*VirtualNetwork, error)
+	CreateVirtualNetwork(ctx context.Context, accountID string, vnet VirtualNetwork) (string, error)
+	UpdateVirtualNetwork(ctx context.Context, accountID string, vnet VirtualNetwork) error
+	DeleteVirtualNetwork(ctx context.Context, accountID, vnetID string) error
+
+	GetLoadBalancerMonitor(ctx context.Context, accountID, monitorID string) (*LoadBalancerMonitor, error)
+	CreateLoadBalancerMonitor(ctx context.Context, accountID string, monitor LoadBalancerMonitor) (string, error)
+	UpdateLoadBalancerMonitor(ctx context.Context, accountID string, monitor LoadBalancerMonitor) error
+	DeleteLoadBalancerMonitor(ctx context.Context, accountID, monitorID string) error
+
+	GetLoadBalancerPool(ctx context.Context, accountID, poolID string) (*LoadBalancerPool, error)
+	CreateLoadBalancerPool(ctx context.Context, accountID string, pool LoadBalancerPool) (string, error)
+	UpdateLoadBalancerPool(ctx context.Context, accountID string, pool LoadBalancerPool) error
+	DeleteLoadBalancerPool(ctx context.Context, accountID, poolID string) error
+
+	GetLoadBalancer(ctx context.Context, accountID, hostname string) (*LoadBalancer, error)
+	CreateLoadBalancer(ctx context.Context, accountID string, lb LoadBalancer) (string, error)
+	UpdateLoadBalancer(ctx context.Context, accountID string, lb LoadBalancer) error
+	DeleteLoadBalancer(ctx context.Context, accountID, hostname, lbID string) error
+
+	GetDNSRecord(ctx context.Context, accountID, domain, recordID string) (*DNSRecord, error)
+	FindDNSRecord(ctx context.Context, accountID string, record DNSRecord) (*DNSRecord, error)
+	CreateDNSRecord(ctx context.Context, accountID string, record DNSRecord) (string, error)
+	UpdateDNSRecord(ctx context.Context, accountID string, record DNSRecord) error
+	DeleteDNSRecordByID(ctx context.Context, accountID, domain, recordID string) error
 }
 
 type client struct {
 	*cloudflare.API
-	zoneCache *sync.Map
+	zoneCache *zoneCache
+	limiters  *accountLimiters
 }
 
+// NewClient creates a standalone client. Use ClientPool to share the client and its caches across reconciles.
 func NewClient(token string) (Client, error) {
-	cli, err := cloudflare.NewWithAPIToken(token)
-	if err != nil {
-		return nil, err
-	}
-	return client{cli, &sync.Map{}}, nil
+	opts := DefaultClientOptions()
+	limiters := newAccountLimiters(opts.RequestsPerSecond, opts.RequestBurst)
+	return newClient(
+		token,
+		opts.BaseURL,
+		newHTTPClient(limiters, opts.MaxRetries, clock.RealClock{}),
+		limiters,
+		newZoneCache(opts.ZoneCacheTTL, clock.RealClock{}),
+	)
 }
 
 func (c client) CreateTunnel(ctx context.Context, accountID, name string) (TunnelCredential, error) {
@@ -137,51 +188,14 @@ func (c client) GetOrCreateTunnel(ctx context.Context, accountID, name string) (
 	return c.getTunnelCredential(ctx, accountID, tunnels[0].ID)
 }
 
-func (c client) getZoneIDFromName(ctx context.Context, accountID, zoneName string) (zoneID string, err error) {
-	zoneName = normalizeZoneName(zoneName)
-
-	cacheKey := accountID + "-" + zoneName
-	if zoneID, ok := c.zoneCache.Load(cacheKey); ok {
-		return zoneID.(string), nil
-	}
-
-	res, err := c.API.ListZonesContext(ctx, cloudflare.WithZoneFilters(zoneName, accountID, ""))
-	if err != nil {
-		return "", fmt.Errorf("ListZonesContext command failed: %w", err)
-	}
-
-	switch len(res.Result) {
-	case 0:
-		return "", errors.New("zone could not be found")
-	case 1:
-		zoneID = res.Result[0].ID
-	default:
-		return "", errors.New("ambiguous zone name; an account ID might help")
-	}
-
-	c.zoneCache.Store(cacheKey, zoneID)
-	return zoneID, nil
-}
-
-func normalizeZoneName(name string) string {
-	if n, err := idna.ToUnicode(name); err == nil {
-		return n
-	}
-	return name
-}
-
 func (c client) CreateRoute(
 	ctx context.Context,
 	accountID, tunnelID, domain string,
+	zone ZoneRef,
 	overwrite bool,
 ) error {
 	domain = normalizeZoneName(domain)
-	zoneName, err := publicsuffix.EffectiveTLDPlusOne(domain)
-	if err != nil {
-		return err
-	}
-
-	zoneID, err := c.getZoneIDFromName(ctx, accountID, zoneName)
+	zoneID, err := c.resolveZoneID(ctx, accountID, domain, zone)
 	if err != nil {
 		return err
 	}
@@ -201,7 +215,7 @@ func (c client) CreateRoute(
 		},
 		nil,
 	)
-	return err
+	return c.invalidateZonesIfGone(accountID, err)
 }
 
 func (c client) DeleteTunnel(ctx context.Context, accountID, tunnelID string) error {
@@ -215,13 +229,8 @@ func (c client) DeleteTunnel(ctx context.Context, accountID, tunnelID string) er
 	return err
 }
 
-func (c client) DeleteDNSRecord(ctx context.Context, accountID, domain string) error {
-	zoneName, err := publicsuffix.EffectiveTLDPlusOne(domain)
-	if err != nil {
-		return err
-	}
-
-	zoneID, err := c.getZoneIDFromName(ctx, accountID, zoneName)
+func (c client) DeleteDNSRecord(ctx context.Context, accountID, domain string, zone ZoneRef) error {
+	zoneID, err := c.resolveZoneID(ctx, accountID, domain, zone)
 	if err != nil {
 		return err
 	}
@@ -236,14 +245,15 @@ func (c client) DeleteDNSRecord(ctx context.Context, accountID, domain string) e
 		cloudflare.ListDNSRecordsParams{Name: punycodeDomain},
 	)
 	if err != nil {
-		return err
+		return c.invalidateZonesIfGone(accountID, err)
 	}
 
 	grp, ctx := errgroup.WithContext(ctx)
 	for _, record := range records {
 		recordID := record.ID
 		grp.Go(func() error {
-			err = c.API.DeleteDNSRecord(
+			// each goroutine returns its own error, errgroup keeps the first one
+			return c.API.DeleteDNSRecord(
 				ctx,
 				&cloudflare.ResourceContainer{
 					Identifier: zoneID,
@@ -251,9 +261,26 @@ func (c client) DeleteDNSRecord(ctx context.Context, accountID, domain string) e
 				},
 				recordID,
 			)
-			return err
 		})
 	}
 
 	return grp.Wait()
 }
+
+// accountContainer is for APIs that tell account level resources apart by ResourceContainer.Level.
+func accountContainer(accountID string) *cloudflare.ResourceContainer {
+	return &cloudflare.ResourceContainer{
+		Identifier: accountID,
+		Level:      cloudflare.AccountRouteLevel,
+		Type:       cloudflare.AccountType,
+	}
+}
+
+// zoneContainer is for APIs that tell zone level resources apart by ResourceContainer.Level.
+func zoneContainer(zoneID string) *cloudflare.ResourceContainer {
+	return &cloudflare.ResourceContainer{
+		Identifier: zoneID,
+		Level:      cloudflare.ZoneRouteLevel,
+		Type:       cloudflare.ZoneType,
+	}
+}
@@ -0,0 +1,10 @@
+package cloudflare
+
+// DaemonSupports tells whether cloudflared of version supports a feature introduced in the version since.
+// Versions out of the YYYY.M.P format of cloudflared, e.g. custom tags, are assumed to support every feature.
+func DaemonSupports(version, since string) bool {
+	if _, ok := parseDaemonVersion(version); !ok {
+		return true
+	}
+	return CompareDaemonVersions(version, since) >= 0
+}
@@ -6,47 +6,101 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/goccy/go-json"
+	"k8s.io/utils/clock"
 )
 
 const (
-	latestVersionURL = "https://api.github.com/repos/cloudflare/cloudflared/releases/latest"
-	cacheTTL         = 5 * time.Minute
+	cloudflaredReleasesPath = "/repos/cloudflare/cloudflared/releases"
+	cacheTTL                = 5 * time.Minute
 )
 
-var (
-	latestVersionMu       = &sync.RWMutex{}
-	latestVersionCachedAt time.Time
-	latestVersion         string
+// DaemonVersionResolver finds released versions of cloudflared.
+// Implementations are safe for concurrent use.
+type DaemonVersionResolver interface {
+	// DaemonVersions returns released versions in no particular order.
+	DaemonVersions(ctx context.Context) ([]string, error)
+	// VerifyDaemonVersion reports whether the version is released.
+	VerifyDaemonVersion(ctx context.Context, version string) (bool, error)
+}
 
-	versionsMap = &sync.Map{}
-)
+// LatestDaemonVersion returns the newest released version that satisfies the constraint, except excluded ones.
+func LatestDaemonVersion(
+	ctx context.Context,
+	resolver DaemonVersionResolver,
+	constraint DaemonVersionConstraint,
+	excluded ...string,
+) (string, error) {
+	versions, err := resolver.DaemonVersions(ctx)
+	if err != nil {
+		return "", err
+	}
+	versions = slices.DeleteFunc(slices.Clone(versions), func(v string) bool {
+		return slices.Contains(excluded, v) || !constraint.Check(v)
+	})
+	latest, ok := latestDaemonVersionOf(versions)
+	if !ok {
+		if constraint.String() != "" {
+			return "", fmt.Errorf("no daemon version satisfies %q", constraint)
+		}
+		return "", errors.New("no daemon version is released")
+	}
+	return latest, nil
+}
+
+// GitHubReleases resolves versions from GitHub releases of cloudflared. Drafts and pre-releases are ignored.
+type GitHubReleases struct {
+	baseURL    string
+	httpClient *http.Client
+	clock      clock.PassiveClock
 
-func GetLatestDaemonVersion(ctx context.Context) (version string, err error) {
-	latestVersionMu.RLock()
+	releasesMu       sync.Mutex
+	releasesCachedAt time.Time
+	releases         []string
 
-	if !latestVersionCachedAt.IsZero() && time.Since(latestVersionCachedAt) <= cacheTTL && latestVersion != "" {
-		defer latestVersionMu.RUnlock()
-		return latestVersion, nil
+	versionsMap sync.Map
+}
+
+// NewGitHubReleases creates a resolver querying GitHub API at baseURL, e.g. https://api.github.com.
+// It can point to a mirror or a fake server.
+func NewGitHubReleases(baseURL string, clk clock.PassiveClock) *GitHubReleases {
+	return &GitHubReleases{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		clock:      clk,
 	}
+}
 
-	latestVersionMu.RUnlock()
-	latestVersionMu.Lock()
-	defer latestVersionMu.Unlock()
+// DaemonVersions returns the most recent 100 releases, which is plenty for picking an upgrade target.
+func (g *GitHubReleases) DaemonVersions(ctx context.Context) (versions []string, err error) {
+	g.releasesMu.Lock()
+	defer g.releasesMu.Unlock()
+
+	if !g.releasesCachedAt.IsZero() && g.clock.Since(g.releasesCachedAt) <= cacheTTL {
+		return g.releases, nil
+	}
 
 	var req *http.Request
-	req, err = http.NewRequestWithContext(ctx, http.MethodGet, latestVersionURL, nil)
+	req, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		g.baseURL+cloudflaredReleasesPath+"?per_page=100",
+		nil,
+	)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	var res *http.Response
-	res, err = http.DefaultClient.Do(req)
+	res, err = g.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer func() {
 		closeErr := res.Body.Close()
@@ -58,39 +112,50 @@ func GetLatestDaemonVersion(ctx context.Context) (version string, err error) {
 			}
 		}
 	}()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unknown status code: %d", res.StatusCode)
+	}
 
 	rawBody, err := io.ReadAll(res.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	var tmp struct {
-		TagName string `json:"tag_name"`
+	var tmp []struct {
+		TagName    string `json:"tag_name"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
 	}
 	if err = json.UnmarshalNoEscape(rawBody, &tmp); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	latestVersion = tmp.TagName
-	latestVersionCachedAt = time.Now() // TODO: use clock
-	return latestVersion, nil
+	versions = make([]string, 0, len(tmp))
+	for _, release := range tmp {
+		if !release.Draft && !release.Prerelease {
+			versions = append(versions, release.TagName)
+		}
+	}
+	g.releases = versions
+	g.releasesCachedAt = g.clock.Now()
+	return versions, nil
 }
 
-func VerifyDaemonVersion(ctx context.Context, version string) (bool, error) {
-	if value, cached := versionsMap.Load(version); cached {
+func (g *GitHubReleases) VerifyDaemonVersion(ctx context.Context, version string) (bool, error) {
+	if value, cached := g.versionsMap.Load(version); cached {
 		return value.(bool), nil
 	}
 
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodGet,
-		"https://api.github.com/repos/cloudflare/cloudflared/releases/tags/"+version,
+		g.baseURL+cloudflaredReleasesPath+"/tags/"+version,
 		nil,
 	)
 	if err != nil {
 		return false, err
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
-	res, err := http.DefaultClient.Do(req)
+	res, err := g.httpClient.Do(req)
 	if err != nil {
 		return false, err
 	}
@@ -107,6 +172,72 @@ func VerifyDaemonVersion(ctx context.Context, version string) (bool, error) {
 	default:
 		return false, fmt.Errorf("unknown status code: %d", res.StatusCode)
 	}
-	versionsMap.Store(versionsMap, isValid)
+	g.versionsMap.Store(version, isValid)
 	return isValid, nil
 }
+
+// StaticDaemonVersions resolves versions from a fixed allow-list, without any network access.
+type StaticDaemonVersions struct {
+	versions []string
+}
+
+func NewStaticDaemonVersions(versions []string) *StaticDaemonVersions {
+	return &StaticDaemonVersions{versions: slices.Clone(versions)}
+}
+
+func (s *StaticDaemonVersions) DaemonVersions(context.Context) ([]string, error) {
+	return s.versions, nil
+}
+
+func (s *StaticDaemonVersions) VerifyDaemonVersion(_ context.Context, version string) (bool, error) {
+	return slices.Contains(s.versions, version), nil
+}
+
+// latestDaemonVersionOf returns the newest of versions in cloudflared's YYYY.M.P format.
+// Other tags such as "latest" or architecture suffixed ones are ignored.
+func latestDaemonVersionOf(versions []string) (string, bool) {
+	var latest string
+	var latestParts [3]int
+	for _, v := range versions {
+		parts, ok := parseDaemonVersion(v)
+		if !ok {
+			continue
+		}
+		if latest == "" || slices.Compare(parts[:], latestParts[:]) > 0 {
+			latest, latestParts = v, parts
+		}
+	}
+	return latest, latest != ""
+}
+
+// CompareDaemonVersions compares versions in YYYY.M.P format like strings.Compare.
+// Malformed versions are older than any well-formed one.
+func CompareDaemonVersions(a, b string) int {
+	aParts, aOK := parseDaemonVersion(a)
+	bParts, bOK := parseDaemonVersion(b)
+	switch {
+	case !aOK && !bOK:
+		return strings.Compare(a, b)
+	case !aOK:
+		return -1
+	case !bOK:
+		return 1
+	}
+	return slices.Compare(aParts[:], bParts[:])
+}
+
+func parseDaemonVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	fields := strings.Split(version, ".")
+	if len(fields) != len(parts) {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
@@ -0,0 +1,158 @@
+package cloudflare
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DaemonVersionConstraint restricts cloudflared versions, in the syntax of npm-style semver ranges:
+//   - comparisons: "=2024.2.1", "!=2024.2.1", ">2024.2", ">=2024.2.0", "<2025", "<=2024.12.0"
+//   - tilde allows patch releases of the given minor: "~2024.2" is ">=2024.2.0, <2024.3.0"
+//   - caret allows releases of the given major: "^2024.2.1" is ">=2024.2.1, <2025.0.0"
+//   - a bare partial version matches its prefix: "2024.2" is "~2024.2"
+//
+// Comparisons separated by comma or space must all hold, and groups separated by "||" are alternatives.
+// The zero value allows every version.
+type DaemonVersionConstraint struct {
+	raw    string
+	groups [][]versionComparison
+}
+
+type versionComparison struct {
+	op      string
+	version [3]int
+}
+
+func ParseDaemonVersionConstraint(raw string) (DaemonVersionConstraint, error) {
+	constraint := DaemonVersionConstraint{raw: strings.TrimSpace(raw)}
+	if constraint.raw == "" {
+		return constraint, nil
+	}
+
+	for _, group := range strings.Split(constraint.raw, "||") {
+		var comparisons []versionComparison
+		for _, term := range strings.FieldsFunc(group, func(r rune) bool { return r == ',' || r == ' ' }) {
+			parsed, err := parseVersionTerm(term)
+			if err != nil {
+				return DaemonVersionConstraint{}, fmt.Errorf("invalid daemon version constraint %q: %w", raw, err)
+			}
+			comparisons = append(comparisons, parsed...)
+		}
+		if len(comparisons) == 0 {
+			return DaemonVersionConstraint{}, fmt.Errorf("invalid daemon version constraint %q: empty group", raw)
+		}
+		constraint.groups = append(constraint.groups, comparisons)
+	}
+	return constraint, nil
+}
+
+func parseVersionTerm(term string) ([]versionComparison, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", "!=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	version, precision, err := parsePartialVersion(strings.TrimPrefix(term, op))
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "~", "":
+		if op == "" && precision == len(version) {
+			return []versionComparison{{op: "=", version: version}}, nil
+		}
+		var upper [3]int
+		if precision <= 1 {
+			upper = [3]int{version[0] + 1, 0, 0}
+		} else {
+			upper = [3]int{version[0], version[1] + 1, 0}
+		}
+		return []versionComparison{{op: ">=", version: version}, {op: "<", version: upper}}, nil
+	case "^":
+		return []versionComparison{
+			{op: ">=", version: version},
+			{op: "<", version: [3]int{version[0] + 1, 0, 0}},
+		}, nil
+	case "=":
+		if precision < len(version) {
+			return parseVersionTerm(strings.TrimPrefix(term, op))
+		}
+	case "<=":
+		if precision < len(version) {
+			// "<=2024.2" includes every patch of 2024.2
+			next := version
+			next[precision-1]++
+			return []versionComparison{{op: "<", version: next}}, nil
+		}
+	case ">":
+		if precision < len(version) {
+			next := version
+			next[precision-1]++
+			return []versionComparison{{op: ">=", version: next}}, nil
+		}
+	}
+	return []versionComparison{{op: op, version: version}}, nil
+}
+
+// parsePartialVersion parses "2024", "2024.2" or "2024.2.1". Omitted parts are zero.
+func parsePartialVersion(raw string) (version [3]int, precision int, err error) {
+	fields := strings.Split(raw, ".")
+	if raw == "" || len(fields) > len(version) {
+		return version, 0, fmt.Errorf("malformed version %q", raw)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return version, 0, fmt.Errorf("malformed version %q", raw)
+		}
+		version[i] = n
+	}
+	return version, len(fields), nil
+}
+
+// Check reports whether the version satisfies the constraint. Malformed versions never satisfy a non-empty one.
+func (c DaemonVersionConstraint) Check(version string) bool {
+	if len(c.groups) == 0 {
+		return true
+	}
+	parts, ok := parseDaemonVersion(version)
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(c.groups, func(group []versionComparison) bool {
+		for _, comparison := range group {
+			if !comparison.check(parts) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (c versionComparison) check(version [3]int) bool {
+	cmp := slices.Compare(version[:], c.version[:])
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+func (c DaemonVersionConstraint) String() string {
+	return c.raw
+}
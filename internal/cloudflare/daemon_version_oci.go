@@ -0,0 +1,233 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"k8s.io/utils/clock"
+)
+
+const dockerHubRegistry = "registry-1.docker.io"
+
+// OCIRegistryTags resolves versions from tags of a cloudflared image repository on an OCI registry,
+// so that clusters pulling from a mirror do not need access to GitHub.
+// Only anonymous pulls are supported, which covers public registries and most in-cluster mirrors.
+type OCIRegistryTags struct {
+	baseURL    string
+	name       string
+	httpClient *http.Client
+	clock      clock.PassiveClock
+
+	mu       sync.Mutex
+	token    string
+	tags     []string
+	cachedAt time.Time
+}
+
+// NewOCIRegistryTags creates a resolver listing tags of repository, e.g. "registry.example.com/cloudflare/cloudflared".
+// Repositories without registry host are looked up on Docker Hub. plainHTTP talks to the registry without TLS.
+func NewOCIRegistryTags(repository string, plainHTTP bool, clk clock.PassiveClock) *OCIRegistryTags {
+	registry, name := splitRepository(repository)
+	scheme := "https"
+	if plainHTTP {
+		scheme = "http"
+	}
+	return &OCIRegistryTags{
+		baseURL:    scheme + "://" + registry,
+		name:       name,
+		httpClient: http.DefaultClient,
+		clock:      clk,
+	}
+}
+
+func splitRepository(repository string) (registry, name string) {
+	registry, name, found := strings.Cut(repository, "/")
+	if !found || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		registry, name = dockerHubRegistry, repository
+	}
+	if registry == dockerHubRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	return registry, name
+}
+
+func (o *OCIRegistryTags) DaemonVersions(ctx context.Context) ([]string, error) {
+	return o.listTags(ctx)
+}
+
+func (o *OCIRegistryTags) VerifyDaemonVersion(ctx context.Context, version string) (bool, error) {
+	tags, err := o.listTags(ctx)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(tags, version), nil
+}
+
+func (o *OCIRegistryTags) listTags(ctx context.Context) ([]string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.cachedAt.IsZero() && o.clock.Since(o.cachedAt) <= cacheTTL {
+		return o.tags, nil
+	}
+
+	var tags []string
+	next := o.baseURL + "/v2/" + o.name + "/tags/list?n=1000"
+	for next != "" {
+		res, err := o.get(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&page)
+		err = errors.Join(err, res.Body.Close())
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+
+		next, err = nextPageURL(res, next)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	o.tags = tags
+	o.cachedAt = o.clock.Now()
+	return tags, nil
+}
+
+// get requests the registry, answering a bearer token challenge once if the registry asks for it.
+func (o *OCIRegistryTags) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	res, err := o.doGet(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		challenge := res.Header.Get("WWW-Authenticate")
+		if err = res.Body.Close(); err != nil {
+			return nil, err
+		}
+		if o.token, err = o.fetchToken(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if res, err = o.doGet(ctx, rawURL); err != nil {
+			return nil, err
+		}
+	}
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("unknown status code: %d", res.StatusCode)
+	}
+	return res, nil
+}
+
+func (o *OCIRegistryTags) doGet(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if o.token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.token)
+	}
+	return o.httpClient.Do(req)
+}
+
+func (o *OCIRegistryTags) fetchToken(ctx context.Context, challenge string) (token string, err error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported registry authentication: %q", challenge)
+	}
+	attrs := parseChallengeParams(params)
+	if attrs["realm"] == "" {
+		return "", fmt.Errorf("registry authentication without realm: %q", challenge)
+	}
+
+	tokenURL, err := url.Parse(attrs["realm"])
+	if err != nil {
+		return "", err
+	}
+	query := tokenURL.Query()
+	if service := attrs["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", "repository:"+o.name+":pull")
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		err = errors.Join(err, res.Body.Close())
+	}()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unknown status code of registry token: %d", res.StatusCode)
+	}
+
+	rawBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	var tmp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.Unmarshal(rawBody, &tmp); err != nil {
+		return "", err
+	}
+	if tmp.Token != "" {
+		return tmp.Token, nil
+	}
+	return tmp.AccessToken, nil
+}
+
+// parseChallengeParams parses `realm="...",service="..."` of WWW-Authenticate header.
+func parseChallengeParams(params string) map[string]string {
+	attrs := make(map[string]string)
+	for _, param := range strings.Split(params, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			continue
+		}
+		attrs[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return attrs
+}
+
+// nextPageURL follows `Link: </v2/...>; rel="next"` header of paginated tag lists.
+func nextPageURL(res *http.Response, current string) (string, error) {
+	link := res.Header.Get("Link")
+	if link == "" {
+		return "", nil
+	}
+	target, rel, _ := strings.Cut(link, ";")
+	if !strings.Contains(rel, `rel="next"`) {
+		return "", nil
+	}
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
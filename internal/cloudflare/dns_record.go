@@ -0,0 +1,274 @@
+package cloudflare
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/goccy/go-json"
+	"golang.org/x/net/idna"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	dnsRecordTypeSRV = "SRV"
+	dnsRecordTypeCAA = "CAA"
+)
+
+// DNSRecord is a DNS record of a zone. The zone is found from Name.
+type DNSRecord struct {
+	ID       string
+	Type     string
+	Name     string
+	Content  string
+	Proxied  bool
+	TTL      int
+	Priority *uint16
+	Comment  string
+	Tags     []string
+	SRV      *SRVRecordData
+	CAA      *CAARecordData
+}
+
+// SRVRecordData is the structured content of SRV record. Priority goes to DNSRecord.Priority.
+type SRVRecordData struct {
+	Weight uint16 `json:"weight"`
+	Port   uint16 `json:"port"`
+	Target string `json:"target"`
+}
+
+// CAARecordData is the structured content of CAA record.
+type CAARecordData struct {
+	Flags uint8  `json:"flags"`
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// GetDNSRecord finds a record by its ID. It returns nil if there is no such record.
+func (c client) GetDNSRecord(ctx context.Context, accountID, domain, recordID string) (*DNSRecord, error) {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.API.GetDNSRecord(ctx, &cloudflare.ResourceContainer{Identifier: zoneID, Type: cloudflare.ZoneType}, recordID)
+	if err = c.invalidateZonesIfGone(accountID, err); IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromCFRecord(res)
+}
+
+// FindDNSRecord finds a record that has the same name, type and content with given one.
+// It returns nil if there is no such record.
+func (c client) FindDNSRecord(ctx context.Context, accountID string, record DNSRecord) (*DNSRecord, error) {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, record.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	punycodeDomain, err := idna.ToASCII(record.Name)
+	if err != nil {
+		punycodeDomain = record.Name
+	}
+	records, _, err := c.API.ListDNSRecords(
+		ctx,
+		&cloudflare.ResourceContainer{Identifier: zoneID, Type: cloudflare.ZoneType},
+		cloudflare.ListDNSRecordsParams{Name: punycodeDomain, Type: record.Type},
+	)
+	if err != nil {
+		return nil, c.invalidateZonesIfGone(accountID, err)
+	}
+
+	for _, r := range records {
+		existing, err := fromCFRecord(r)
+		if err != nil {
+			return nil, err
+		}
+		if existing.hasSameContent(&record) {
+			return existing, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c client) CreateDNSRecord(ctx context.Context, accountID string, record DNSRecord) (string, error) {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, record.Name)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := c.API.CreateDNSRecord(
+		ctx,
+		&cloudflare.ResourceContainer{Identifier: zoneID, Type: cloudflare.ZoneType},
+		cloudflare.CreateDNSRecordParams{
+			Type:     record.Type,
+			Name:     record.Name,
+			Content:  record.Content,
+			Data:     record.data(),
+			Priority: record.Priority,
+			TTL:      record.TTL,
+			Proxied:  ptr.To(record.Proxied),
+			Comment:  record.Comment,
+			Tags:     record.Tags,
+		},
+	)
+	if err != nil {
+		return "", c.invalidateZonesIfGone(accountID, err)
+	}
+	return res.ID, nil
+}
+
+func (c client) UpdateDNSRecord(ctx context.Context, accountID string, record DNSRecord) error {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, record.Name)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.API.UpdateDNSRecord(
+		ctx,
+		&cloudflare.ResourceContainer{Identifier: zoneID, Type: cloudflare.ZoneType},
+		cloudflare.UpdateDNSRecordParams{
+			ID:       record.ID,
+			Type:     record.Type,
+			Name:     record.Name,
+			Content:  record.Content,
+			Data:     record.data(),
+			Priority: record.Priority,
+			TTL:      record.TTL,
+			Proxied:  ptr.To(record.Proxied),
+			Comment:  ptr.To(record.Comment),
+			Tags:     record.Tags,
+		},
+	)
+	return c.invalidateZonesIfGone(accountID, err)
+}
+
+func (c client) DeleteDNSRecordByID(ctx context.Context, accountID, domain, recordID string) error {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, domain)
+	if err != nil {
+		return err
+	}
+
+	err = c.API.DeleteDNSRecord(ctx, &cloudflare.ResourceContainer{Identifier: zoneID, Type: cloudflare.ZoneType}, recordID)
+	if err = c.invalidateZonesIfGone(accountID, err); IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// IsDNSRecordChanged reports whether existing record differs from desired one in any field that this operator manages.
+func IsDNSRecordChanged(existing, desired *DNSRecord) bool {
+	return !strings.EqualFold(normalizeRecordName(existing.Name), normalizeRecordName(desired.Name)) ||
+		existing.Type != desired.Type ||
+		!existing.hasSameContent(desired) ||
+		existing.Proxied != desired.Proxied ||
+		existing.TTL != desired.TTL ||
+		ptr.Deref(existing.Priority, 0) != ptr.Deref(desired.Priority, 0) ||
+		existing.Comment != desired.Comment ||
+		!slices.Equal(sortedTags(existing.Tags), sortedTags(desired.Tags))
+}
+
+func (r *DNSRecord) hasSameContent(o *DNSRecord) bool {
+	switch r.Type {
+	case dnsRecordTypeSRV:
+		return ptr.Deref(r.Priority, 0) == ptr.Deref(o.Priority, 0) && ptr.Equal(r.SRV, o.SRV)
+	case dnsRecordTypeCAA:
+		return ptr.Equal(r.CAA, o.CAA)
+	case "A", "AAAA":
+		// Cloudflare stores IP addresses in its canonical form
+		a, errA := netip.ParseAddr(r.Content)
+		b, errB := netip.ParseAddr(o.Content)
+		if errA != nil || errB != nil {
+			return r.Content == o.Content
+		}
+		return a == b
+	case "CNAME", "MX":
+		return strings.EqualFold(normalizeRecordName(r.Content), normalizeRecordName(o.Content))
+	default:
+		return r.Content == o.Content
+	}
+}
+
+func (r *DNSRecord) data() any {
+	switch r.Type {
+	case dnsRecordTypeSRV:
+		if r.SRV == nil {
+			return nil
+		}
+		return struct {
+			Priority uint16 `json:"priority"`
+			SRVRecordData
+		}{
+			Priority:      ptr.Deref(r.Priority, 0),
+			SRVRecordData: *r.SRV,
+		}
+	case dnsRecordTypeCAA:
+		if r.CAA == nil {
+			return nil
+		}
+		return r.CAA
+	}
+	return nil
+}
+
+func fromCFRecord(record cloudflare.DNSRecord) (*DNSRecord, error) {
+	res := DNSRecord{
+		ID:       record.ID,
+		Type:     record.Type,
+		Name:     record.Name,
+		Content:  record.Content,
+		Proxied:  ptr.Deref(record.Proxied, false),
+		TTL:      record.TTL,
+		Priority: record.Priority,
+		Comment:  record.Comment,
+		Tags:     record.Tags,
+	}
+
+	if record.Data == nil {
+		return &res, nil
+	}
+	// data comes as a generic map, so it is decoded again into the typed one
+	rawData, err := json.Marshal(record.Data)
+	if err != nil {
+		return nil, err
+	}
+	switch record.Type {
+	case dnsRecordTypeSRV:
+		var srv struct {
+			Priority *uint16 `json:"priority"`
+			SRVRecordData
+		}
+		if err = json.Unmarshal(rawData, &srv); err == nil {
+			res.SRV = &srv.SRVRecordData
+			if res.Priority == nil {
+				res.Priority = srv.Priority
+			}
+		}
+	case dnsRecordTypeCAA:
+		res.CAA = &CAARecordData{}
+		err = json.Unmarshal(rawData, res.CAA)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func normalizeRecordName(name string) string {
+	name = strings.TrimSuffix(name, ".")
+	if n, err := idna.ToASCII(name); err == nil {
+		return n
+	}
+	return name
+}
+
+func sortedTags(tags []string) []string {
+	tags = slices.Clone(tags)
+	slices.Sort(tags)
+	return tags
+}
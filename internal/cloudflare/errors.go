@@ -0,0 +1,77 @@
+package cloudflare
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// APIError is returned when Cloudflare API responds with 429 or 5xx, and the request is not retried any more.
+type APIError struct {
+	StatusCode int
+	Codes      []int
+	Messages   []string
+	// RetryAfter is the delay that Cloudflare asked for with Retry-After header. Zero if there was none.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("Cloudflare API responded %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Messages) != 0 {
+		msg += ": " + strings.Join(e.Messages, ", ")
+	}
+	if len(e.Codes) != 0 {
+		msg += fmt.Sprintf(" (codes: %v)", e.Codes)
+	}
+	return msg
+}
+
+func (e *APIError) ErrorCodes() []int {
+	return e.Codes
+}
+
+func (e *APIError) InternalErrorCodeIs(code int) bool {
+	for _, c := range e.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorCodes returns Cloudflare error codes carried by err, regardless of which client layer raised it.
+func ErrorCodes(err error) []int {
+	var cfErr interface{ ErrorCodes() []int }
+	if errors.As(err, &cfErr) {
+		return cfErr.ErrorCodes()
+	}
+	return nil
+}
+
+// IsRateLimited reports whether err is caused by Cloudflare API rate limit,
+// or Cloudflare asked to wait with Retry-After longer than the transport waits for.
+// The returned duration is what Cloudflare asked to wait for, or zero if unknown.
+func IsRateLimited(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter, apiErr.StatusCode == http.StatusTooManyRequests || apiErr.RetryAfter > 0
+	}
+	var rateLimitErr *cloudflare.RatelimitError
+	if errors.As(err, &rateLimitErr) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// IsPermanent reports whether err is a 4xx response of Cloudflare API other than rate limit,
+// which fails again however many times it is retried.
+func IsPermanent(err error) bool {
+	var authnErr *cloudflare.AuthenticationError
+	var authzErr *cloudflare.AuthorizationError
+	var reqErr *cloudflare.RequestError
+	return errors.As(err, &authnErr) || errors.As(err, &authzErr) || errors.As(err, &reqErr) || IsNotFound(err)
+}
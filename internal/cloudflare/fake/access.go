@@ -0,0 +1,169 @@
+package fake
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"k8s.io/utils/ptr"
+)
+
+const defaultServiceTokenDuration = 8760 * time.Hour
+
+type ServiceToken struct {
+	AccountID    string
+	ID           string
+	Name         string
+	ClientID     string
+	ClientSecret string
+	Duration     time.Duration
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// ServiceToken returns the Access service token of given ID.
+func (s *Server) ServiceToken(tokenID string) (ServiceToken, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.serviceTokens[tokenID]
+	if !ok {
+		return ServiceToken{}, false
+	}
+	return *token, true
+}
+
+// ServiceTokens returns Access service tokens of the account.
+func (s *Server) ServiceTokens(accountID string) []ServiceToken {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []ServiceToken
+	for _, token := range s.serviceTokens {
+		if token.AccountID == accountID {
+			res = append(res, *token)
+		}
+	}
+	return res
+}
+
+// AddServiceToken stores token as if it was created through the API, filling ID and client credentials if empty.
+func (s *Server) AddServiceToken(token ServiceToken) ServiceToken {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.ID == "" {
+		token.ID = newID()
+	}
+	if token.ClientID == "" {
+		token.ClientID = newHexID() + ".access"
+	}
+	if token.ClientSecret == "" {
+		token.ClientSecret = newHexID() + newHexID()
+	}
+	if token.Duration == 0 {
+		token.Duration = defaultServiceTokenDuration
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	if token.ExpiresAt.IsZero() {
+		token.ExpiresAt = token.CreatedAt.Add(token.Duration)
+	}
+	s.serviceTokens[token.ID] = &token
+	return token
+}
+
+func (s *Server) serveServiceTokens(w http.ResponseWriter, r *http.Request, accountID string, segments []string) {
+	if len(segments) == 0 {
+		if r.Method == http.MethodGet {
+			s.listServiceTokens(w, accountID)
+			return
+		}
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+		var params cloudflare.CreateAccessServiceTokenParams
+		if !decodeBody(w, r, &params) {
+			return
+		}
+		duration := defaultServiceTokenDuration
+		if d, err := time.ParseDuration(params.Duration); err == nil {
+			duration = d
+		}
+		now := time.Now()
+		token := &ServiceToken{
+			AccountID:    accountID,
+			ID:           newID(),
+			Name:         params.Name,
+			ClientID:     newHexID() + ".access",
+			ClientSecret: newHexID() + newHexID(),
+			Duration:     duration,
+			CreatedAt:    now,
+			ExpiresAt:    now.Add(duration),
+		}
+		s.serviceTokens[token.ID] = token
+		writeResult(w, http.StatusOK, token.withSecret())
+		return
+	}
+
+	token, ok := s.serviceTokens[segments[0]]
+	if !ok || token.AccountID != accountID {
+		writeError(w, http.StatusNotFound, CodeServiceTokenNotFound, "access.api.error.not_found")
+		return
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		delete(s.serviceTokens, token.ID)
+		writeResult(w, http.StatusOK, token.withoutSecret())
+
+	case len(segments) == 2 && segments[1] == "refresh" && r.Method == http.MethodPost:
+		token.ExpiresAt = time.Now().Add(token.Duration)
+		writeResult(w, http.StatusOK, token.withoutSecret())
+
+	case len(segments) == 2 && segments[1] == "rotate" && r.Method == http.MethodPost:
+		token.ClientSecret = newHexID() + newHexID()
+		writeResult(w, http.StatusOK, token.withSecret())
+
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) listServiceTokens(w http.ResponseWriter, accountID string) {
+	var res []cloudflare.AccessServiceToken
+	for _, token := range s.serviceTokens {
+		if token.AccountID == accountID {
+			res = append(res, cloudflare.AccessServiceToken{
+				ID:        token.ID,
+				Name:      token.Name,
+				ClientID:  token.ClientID,
+				CreatedAt: ptr.To(token.CreatedAt),
+				ExpiresAt: ptr.To(token.ExpiresAt),
+				Duration:  token.Duration.String(),
+			})
+		}
+	}
+	writeList(w, res)
+}
+
+func (t *ServiceToken) withSecret() cloudflare.AccessServiceTokenCreateResponse {
+	return cloudflare.AccessServiceTokenCreateResponse{
+		ID:           t.ID,
+		Name:         t.Name,
+		ClientID:     t.ClientID,
+		ClientSecret: t.ClientSecret,
+		CreatedAt:    ptr.To(t.CreatedAt),
+		ExpiresAt:    ptr.To(t.ExpiresAt),
+		Duration:     t.Duration.String(),
+	}
+}
+
+func (t *ServiceToken) withoutSecret() cloudflare.AccessServiceTokenRefreshResponse {
+	return cloudflare.AccessServiceTokenRefreshResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		ClientID:  t.ClientID,
+		ExpiresAt: ptr.To(t.ExpiresAt),
+		Duration:  t.Duration.String(),
+	}
+}
@@ -0,0 +1,148 @@
+package fake
+
+import (
+	"net/http"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"k8s.io/utils/ptr"
+)
+
+type NetworkRoute struct {
+	cloudflare.TunnelRoute
+	AccountID string
+}
+
+// NetworkRoutes returns private network routes of the account that are not deleted.
+func (s *Server) NetworkRoutes(accountID string) []NetworkRoute {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []NetworkRoute
+	for _, route := range s.networkRoutes {
+		if route.AccountID == accountID {
+			res = append(res, *route)
+		}
+	}
+	return res
+}
+
+func (s *Server) serveNetworkRoutes(w http.ResponseWriter, r *http.Request, accountID string, segments []string) {
+	if len(segments) == 0 {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		s.listNetworkRoutes(w, r, accountID)
+		return
+	}
+	if len(segments) != 2 || segments[0] != "network" {
+		writeError(w, http.StatusNotFound, CodeInvalidObjectIdentifier, "No route for that URI")
+		return
+	}
+
+	network := segments[1]
+	vnetID := r.URL.Query().Get("virtual_network_id")
+	idx := slices.IndexFunc(s.networkRoutes, func(route *NetworkRoute) bool {
+		return route.AccountID == accountID && route.Network == network &&
+			(vnetID == "" || route.VirtualNetworkID == vnetID)
+	})
+
+	switch r.Method {
+	case http.MethodPost:
+		var params cloudflare.TunnelRoutesCreateParams
+		if !decodeBody(w, r, &params) {
+			return
+		}
+		if slices.ContainsFunc(s.networkRoutes, func(route *NetworkRoute) bool {
+			return route.AccountID == accountID && route.Network == network &&
+				route.VirtualNetworkID == params.VirtualNetworkID
+		}) {
+			writeError(w, http.StatusConflict, CodeRouteAlreadyExists, "Route already exists")
+			return
+		}
+		route := &NetworkRoute{
+			TunnelRoute: cloudflare.TunnelRoute{
+				Network:          network,
+				TunnelID:         params.TunnelID,
+				TunnelName:       s.tunnelName(params.TunnelID),
+				Comment:          params.Comment,
+				CreatedAt:        ptr.To(time.Now()),
+				VirtualNetworkID: params.VirtualNetworkID,
+			},
+			AccountID: accountID,
+		}
+		s.networkRoutes = append(s.networkRoutes, route)
+		writeResult(w, http.StatusOK, route.TunnelRoute)
+
+	case http.MethodPatch:
+		if idx < 0 {
+			writeError(w, http.StatusNotFound, CodeRouteNotFound, "Route not found")
+			return
+		}
+		var params cloudflare.TunnelRoutesUpdateParams
+		if !decodeBody(w, r, &params) {
+			return
+		}
+		route := s.networkRoutes[idx]
+		route.TunnelID = params.TunnelID
+		route.TunnelName = s.tunnelName(params.TunnelID)
+		route.Comment = params.Comment
+		if params.VirtualNetworkID != "" {
+			route.VirtualNetworkID = params.VirtualNetworkID
+		}
+		writeResult(w, http.StatusOK, route.TunnelRoute)
+
+	case http.MethodDelete:
+		if idx < 0 {
+			writeError(w, http.StatusNotFound, CodeRouteNotFound, "Route not found")
+			return
+		}
+		route := s.networkRoutes[idx]
+		s.networkRoutes = slices.Delete(s.networkRoutes, idx, idx+1)
+		writeResult(w, http.StatusOK, route.TunnelRoute)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) listNetworkRoutes(w http.ResponseWriter, r *http.Request, accountID string) {
+	query := r.URL.Query()
+	subset, errSubset := netip.ParsePrefix(query.Get("network_subset"))
+	superset, errSuperset := netip.ParsePrefix(query.Get("network_superset"))
+
+	var res []cloudflare.TunnelRoute
+	for _, route := range s.networkRoutes {
+		if route.AccountID != accountID {
+			continue
+		}
+		if tunnelID := query.Get("tunnel_id"); tunnelID != "" && route.TunnelID != tunnelID {
+			continue
+		}
+		if vnetID := query.Get("virtual_network_id"); vnetID != "" && route.VirtualNetworkID != vnetID {
+			continue
+		}
+		network, err := netip.ParsePrefix(route.Network)
+		if err != nil {
+			continue
+		}
+		// network_subset matches routes inside of it, and network_superset matches routes containing it.
+		if errSubset == nil && !(subset.Bits() <= network.Bits() && subset.Contains(network.Addr())) {
+			continue
+		}
+		if errSuperset == nil && !(network.Bits() <= superset.Bits() && network.Contains(superset.Addr())) {
+			continue
+		}
+		res = append(res, route.TunnelRoute)
+	}
+	writeList(w, res)
+}
+
+func (s *Server) tunnelName(tunnelID string) string {
+	if t, ok := s.tunnels[tunnelID]; ok {
+		return t.Name
+	}
+	return ""
+}
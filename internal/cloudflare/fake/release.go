@@ -0,0 +1,50 @@
+package fake
+
+import (
+	"net/http"
+	"slices"
+)
+
+const defaultDaemonVersion = "2024.4.1"
+
+// releases are cloudflared releases served as GitHub API, so that daemon version lookups do not leave the process.
+type releases struct {
+	latest   string
+	versions []string
+}
+
+func defaultReleases() releases {
+	return releases{latest: defaultDaemonVersion, versions: []string{defaultDaemonVersion}}
+}
+
+// SetDaemonReleases replaces cloudflared releases. The latest one is added to versions.
+func (s *Server) SetDaemonReleases(latest string, versions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releases = releases{latest: latest, versions: append(versions, latest)}
+}
+
+// serveReleases answers /repos/cloudflare/cloudflared/releases[/{latest,tags/{version}}].
+func (s *Server) serveReleases(w http.ResponseWriter, r *http.Request, segments []string) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	segments = segments[3:]
+	switch {
+	case len(segments) == 1 && segments[0] == "releases":
+		list := make([]map[string]any, 0, len(s.releases.versions))
+		for _, version := range s.releases.versions {
+			list = append(list, map[string]any{"tag_name": version, "draft": false, "prerelease": false})
+		}
+		writeJSON(w, http.StatusOK, list)
+	case len(segments) == 2 && segments[0] == "releases" && segments[1] == "latest":
+		writeJSON(w, http.StatusOK, map[string]string{"tag_name": s.releases.latest})
+	case len(segments) == 3 && segments[0] == "releases" && segments[1] == "tags" &&
+		slices.Contains(s.releases.versions, segments[2]):
+		writeJSON(w, http.StatusOK, map[string]string{"tag_name": segments[2]})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}
+}
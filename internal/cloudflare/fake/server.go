@@ -0,0 +1,252 @@
+// Package fake provides an in-process Cloudflare API server for tests.
+//
+// Server keeps tunnels, zones, DNS records, Access service tokens and private network routes in memory and
+// answers the subset of Cloudflare API that the operator calls. Failures can be injected per request pattern to
+// exercise retries and error conditions.
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/goccy/go-json"
+)
+
+// Cloudflare API error codes that the fake responds with.
+const (
+	CodeInvalidObjectIdentifier = 7003
+	CodeTunnelNotFound          = 1003
+	CodeTunnelNameConflict      = 1013
+	CodeTunnelHasConnections    = 1022
+	CodeRecordNotFound          = 81044
+	CodeRecordAlreadyExists     = 81053
+	CodeServiceTokenNotFound    = 12130
+	CodeRouteNotFound           = 1054
+	CodeRouteAlreadyExists      = 1014
+)
+
+// Failure makes requests matching Method and Path fail with given status.
+type Failure struct {
+	// Method matches any method if empty.
+	Method string
+	// Path is a regular expression matched against the request path, without query.
+	Path       string
+	StatusCode int
+	Code       int
+	Message    string
+	// RetryAfter is sent as Retry-After header if positive.
+	RetryAfter time.Duration
+	// Times is how many requests fail. Zero fails every matching request until ClearFailures is called.
+	Times int
+
+	path *regexp.Regexp
+}
+
+// Request is a request that the server has received.
+type Request struct {
+	Method string
+	Path   string
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	zones         map[string]cloudflare.Zone
+	tunnels       map[string]*Tunnel
+	records       map[string]cloudflare.DNSRecord
+	serviceTokens map[string]*ServiceToken
+	networkRoutes []*NetworkRoute
+	releases      releases
+	failures      []*Failure
+	requests      []Request
+}
+
+// NewServer starts a server. Callers should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		zones:         make(map[string]cloudflare.Zone),
+		tunnels:       make(map[string]*Tunnel),
+		records:       make(map[string]cloudflare.DNSRecord),
+		serviceTokens: make(map[string]*ServiceToken),
+		releases:      defaultReleases(),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// InjectFailure registers a failure. Failures are matched in order of registration.
+func (s *Server) InjectFailure(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f.path = regexp.MustCompile(f.Path)
+	s.failures = append(s.failures, &f)
+}
+
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// Requests returns received requests whose method and path match. Empty method matches any.
+func (s *Server) Requests(method, pathPattern string) []Request {
+	re := regexp.MustCompile(pathPattern)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []Request
+	for _, req := range s.requests {
+		if (method == "" || req.Method == method) && re.MatchString(req.Path) {
+			res = append(res, req)
+		}
+	}
+	return res
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/client/v4")
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path})
+
+	if f := s.takeFailure(r.Method, path); f != nil {
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Seconds())))
+		}
+		writeError(w, f.StatusCode, f.Code, f.Message)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range segments {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			segments[i] = unescaped
+		}
+	}
+
+	switch {
+	case len(segments) >= 2 && segments[0] == "accounts":
+		s.serveAccount(w, r, segments[1], segments[2:])
+	case len(segments) >= 1 && segments[0] == "zones":
+		s.serveZones(w, r, segments[1:])
+	case len(segments) >= 3 && segments[0] == "repos":
+		s.serveReleases(w, r, segments)
+	default:
+		writeError(w, http.StatusNotFound, CodeInvalidObjectIdentifier, "No route for that URI")
+	}
+}
+
+func (s *Server) serveAccount(w http.ResponseWriter, r *http.Request, accountID string, segments []string) {
+	switch {
+	case len(segments) >= 1 && segments[0] == "cfd_tunnel":
+		s.serveTunnels(w, r, accountID, segments[1:])
+	case len(segments) >= 2 && segments[0] == "access" && segments[1] == "service_tokens":
+		s.serveServiceTokens(w, r, accountID, segments[2:])
+	case len(segments) >= 2 && segments[0] == "teamnet" && segments[1] == "routes":
+		s.serveNetworkRoutes(w, r, accountID, segments[2:])
+	default:
+		writeError(w, http.StatusNotFound, CodeInvalidObjectIdentifier, "No route for that URI")
+	}
+}
+
+func (s *Server) takeFailure(method, path string) *Failure {
+	for i, f := range s.failures {
+		if (f.Method != "" && f.Method != method) || !f.path.MatchString(path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i:i], s.failures[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+type response struct {
+	Success    bool                      `json:"success"`
+	Errors     []cloudflare.ResponseInfo `json:"errors"`
+	Messages   []cloudflare.ResponseInfo `json:"messages"`
+	Result     any                       `json:"result"`
+	ResultInfo *cloudflare.ResultInfo    `json:"result_info,omitempty"`
+}
+
+func writeResult(w http.ResponseWriter, status int, result any) {
+	writeJSON(w, status, response{
+		Success:  true,
+		Errors:   []cloudflare.ResponseInfo{},
+		Messages: []cloudflare.ResponseInfo{},
+		Result:   result,
+	})
+}
+
+// writeList responds with all items in a single page.
+func writeList[T any](w http.ResponseWriter, items []T) {
+	if items == nil {
+		items = []T{}
+	}
+	writeJSON(w, http.StatusOK, response{
+		Success:  true,
+		Errors:   []cloudflare.ResponseInfo{},
+		Messages: []cloudflare.ResponseInfo{},
+		Result:   items,
+		ResultInfo: &cloudflare.ResultInfo{
+			Page:       1,
+			PerPage:    max(len(items), 1),
+			TotalPages: 1,
+			Count:      len(items),
+			Total:      len(items),
+		},
+	})
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, response{
+		Errors:   []cloudflare.ResponseInfo{{Code: code, Message: message}},
+		Messages: []cloudflare.ResponseInfo{},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, dest any) bool {
+	if err := json.NewDecoder(r.Body).Decode(dest); err != nil {
+		writeError(w, http.StatusBadRequest, 1001, "Invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, 10000, "Method not allowed")
+}
+
+// newID returns a random ID formatted like UUID, which Cloudflare uses for tunnels and Zero Trust objects.
+func newID() string {
+	h := newHexID()
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// newHexID returns a random 32 hex digits ID, which Cloudflare uses for zones and DNS records.
+func newHexID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,189 @@
+package fake
+
+import (
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/goccy/go-json"
+	"k8s.io/utils/ptr"
+)
+
+type Tunnel struct {
+	cloudflare.Tunnel
+	AccountID string
+	// Configuration is the remotely managed configuration, and ConfigVersion counts its updates.
+	Configuration *cloudflare.TunnelConfiguration
+	ConfigVersion int
+}
+
+// Tunnel returns the tunnel of given ID, including deleted one.
+func (s *Server) Tunnel(tunnelID string) (Tunnel, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tunnels[tunnelID]
+	if !ok {
+		return Tunnel{}, false
+	}
+	return *t, true
+}
+
+// Tunnels returns tunnels of the account that are not deleted.
+func (s *Server) Tunnels(accountID string) []Tunnel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []Tunnel
+	for _, t := range s.tunnels {
+		if t.AccountID == accountID && t.DeletedAt == nil {
+			res = append(res, *t)
+		}
+	}
+	return res
+}
+
+// SetConnections replaces connections of the tunnel, as if cloudflared connected to the edge.
+func (s *Server) SetConnections(tunnelID string, conns ...cloudflare.TunnelConnection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.tunnels[tunnelID]; ok {
+		t.Connections = conns
+	}
+}
+
+func (s *Server) serveTunnels(w http.ResponseWriter, r *http.Request, accountID string, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			s.listTunnels(w, r, accountID)
+		case http.MethodPost:
+			s.createTunnel(w, r, accountID)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	t, ok := s.tunnels[segments[0]]
+	if !ok || t.AccountID != accountID {
+		writeError(w, http.StatusNotFound, CodeTunnelNotFound, "Tunnel not found")
+		return
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		writeResult(w, http.StatusOK, t.Tunnel)
+
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		if len(t.Connections) != 0 && r.URL.Query().Get("cascade") != "true" {
+			writeError(w, http.StatusBadRequest, CodeTunnelHasConnections, "Cannot delete tunnel because it has active connections")
+			return
+		}
+		if t.DeletedAt == nil {
+			t.DeletedAt = ptr.To(time.Now())
+			t.Connections = nil
+		}
+		writeResult(w, http.StatusOK, t.Tunnel)
+
+	case len(segments) == 2 && segments[1] == "token" && r.Method == http.MethodGet:
+		token, _ := json.Marshal(struct {
+			AccountTag   string `json:"a"`
+			TunnelID     string `json:"t"`
+			TunnelSecret string `json:"s"`
+		}{t.AccountID, t.ID, t.Secret})
+		writeResult(w, http.StatusOK, base64.StdEncoding.EncodeToString(token))
+
+	case len(segments) == 2 && segments[1] == "connections" && r.Method == http.MethodGet:
+		var res []cloudflare.Connection
+		for _, conn := range t.Connections {
+			res = append(res, cloudflare.Connection{
+				ID:          conn.ClientID,
+				Version:     conn.ClientVersion,
+				Connections: []cloudflare.TunnelConnection{conn},
+			})
+		}
+		writeList(w, res)
+
+	case len(segments) == 2 && segments[1] == "configurations" && r.Method == http.MethodGet:
+		res := cloudflare.TunnelConfigurationResult{TunnelID: t.ID, Version: t.ConfigVersion}
+		if t.Configuration != nil {
+			res.Config = *t.Configuration
+		}
+		writeResult(w, http.StatusOK, res)
+
+	case len(segments) == 2 && segments[1] == "configurations" && r.Method == http.MethodPut:
+		var params cloudflare.TunnelConfigurationParams
+		if !decodeBody(w, r, &params) {
+			return
+		}
+		t.Configuration = &params.Config
+		t.ConfigVersion++
+		t.RemoteConfig = true
+		writeResult(w, http.StatusOK, cloudflare.TunnelConfigurationResult{
+			TunnelID: t.ID,
+			Config:   params.Config,
+			Version:  t.ConfigVersion,
+		})
+
+	case len(segments) == 2 && segments[1] == "connections" && r.Method == http.MethodDelete:
+		t.Connections = nil
+		writeResult(w, http.StatusOK, nil)
+
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) listTunnels(w http.ResponseWriter, r *http.Request, accountID string) {
+	query := r.URL.Query()
+
+	var res []cloudflare.Tunnel
+	for _, t := range s.tunnels {
+		if t.AccountID != accountID {
+			continue
+		}
+		if name := query.Get("name"); name != "" && t.Name != name {
+			continue
+		}
+		if id := query.Get("uuid"); id != "" && t.ID != id {
+			continue
+		}
+		if deleted := query.Get("is_deleted"); deleted != "" && (deleted == "true") != (t.DeletedAt != nil) {
+			continue
+		}
+		res = append(res, t.Tunnel)
+	}
+	slices.SortFunc(res, func(a, b cloudflare.Tunnel) int {
+		return a.CreatedAt.Compare(*b.CreatedAt)
+	})
+	writeList(w, res)
+}
+
+func (s *Server) createTunnel(w http.ResponseWriter, r *http.Request, accountID string) {
+	var params cloudflare.TunnelCreateParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	for _, t := range s.tunnels {
+		if t.AccountID == accountID && t.Name == params.Name && t.DeletedAt == nil {
+			writeError(w, http.StatusConflict, CodeTunnelNameConflict, "You already have a tunnel with this name")
+			return
+		}
+	}
+
+	t := &Tunnel{
+		Tunnel: cloudflare.Tunnel{
+			ID:           newID(),
+			Name:         params.Name,
+			Secret:       params.Secret,
+			CreatedAt:    ptr.To(time.Now()),
+			TunnelType:   "cfd_tunnel",
+			Status:       "inactive",
+			RemoteConfig: params.ConfigSrc == "cloudflare",
+		},
+		AccountID: accountID,
+	}
+	s.tunnels[t.ID] = t
+	writeResult(w, http.StatusOK, t.Tunnel)
+}
@@ -0,0 +1,289 @@
+package fake
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"golang.org/x/net/idna"
+	"k8s.io/utils/ptr"
+)
+
+// AddZone registers a zone of the account.
+func (s *Server) AddZone(accountID, name string) cloudflare.Zone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zone := cloudflare.Zone{
+		ID:     newHexID(),
+		Name:   name,
+		Status: "active",
+		Type:   "full",
+	}
+	zone.Account.ID = accountID
+	s.zones[zone.ID] = zone
+	return zone
+}
+
+// RemoveZone deletes the zone and its records.
+func (s *Server) RemoveZone(zoneID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.zones, zoneID)
+	for id, record := range s.records {
+		if record.ZoneID == zoneID {
+			delete(s.records, id)
+		}
+	}
+}
+
+// AddDNSRecord stores the record as if it was created out of the operator. ID and ZoneName are filled.
+func (s *Server) AddDNSRecord(record cloudflare.DNSRecord) cloudflare.DNSRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.ID = newHexID()
+	record.ZoneName = s.zones[record.ZoneID].Name
+	record.CreatedOn = time.Now()
+	record.ModifiedOn = record.CreatedOn
+	s.records[record.ID] = record
+	return record
+}
+
+// DNSRecords returns records of given name in every zone.
+func (s *Server) DNSRecords(name string) []cloudflare.DNSRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.findRecords("", asciiName(name), "")
+}
+
+func (s *Server) serveZones(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		s.listZones(w, r)
+		return
+	}
+
+	zone, ok := s.zones[segments[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeInvalidObjectIdentifier,
+			"Could not route to /zones/"+segments[0]+", perhaps your object identifier is invalid?")
+		return
+	}
+
+	switch {
+	case len(segments) >= 2 && segments[1] == "dns_records":
+		s.serveDNSRecords(w, r, zone, segments[2:])
+	case len(segments) == 4 && segments[1] == "tunnels" && segments[3] == "routes" && r.Method == http.MethodPut:
+		s.routeTunnel(w, r, zone, segments[2])
+	default:
+		writeError(w, http.StatusNotFound, CodeInvalidObjectIdentifier, "No route for that URI")
+	}
+}
+
+func (s *Server) listZones(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var res []cloudflare.Zone
+	for _, zone := range s.zones {
+		if accountID := query.Get("account.id"); accountID != "" && zone.Account.ID != accountID {
+			continue
+		}
+		if name := query.Get("name"); name != "" && zone.Name != name {
+			continue
+		}
+		res = append(res, zone)
+	}
+	slices.SortFunc(res, func(a, b cloudflare.Zone) int { return strings.Compare(a.Name, b.Name) })
+	writeList(w, res)
+}
+
+func (s *Server) serveDNSRecords(w http.ResponseWriter, r *http.Request, zone cloudflare.Zone, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			query := r.URL.Query()
+			writeList(w, s.findRecords(zone.ID, query.Get("name"), query.Get("type")))
+		case http.MethodPost:
+			s.createRecord(w, r, zone)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	record, ok := s.records[segments[0]]
+	if !ok || record.ZoneID != zone.ID {
+		writeError(w, http.StatusNotFound, CodeRecordNotFound, "Record does not exist.")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeResult(w, http.StatusOK, record)
+
+	case http.MethodPatch:
+		var params cloudflare.UpdateDNSRecordParams
+		if !decodeBody(w, r, &params) {
+			return
+		}
+		if params.Type != "" {
+			record.Type = params.Type
+		}
+		if params.Name != "" {
+			record.Name = asciiName(params.Name)
+		}
+		if params.Content != "" {
+			record.Content = params.Content
+		}
+		if params.Data != nil {
+			record.Data = params.Data
+		}
+		if params.Priority != nil {
+			record.Priority = params.Priority
+		}
+		if params.TTL != 0 {
+			record.TTL = params.TTL
+		}
+		if params.Proxied != nil {
+			record.Proxied = params.Proxied
+		}
+		if params.Comment != nil {
+			record.Comment = *params.Comment
+		}
+		if params.Tags != nil {
+			record.Tags = params.Tags
+		}
+		record.ModifiedOn = time.Now()
+		s.records[record.ID] = record
+		writeResult(w, http.StatusOK, record)
+
+	case http.MethodDelete:
+		delete(s.records, record.ID)
+		writeResult(w, http.StatusOK, map[string]string{"id": record.ID})
+
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) createRecord(w http.ResponseWriter, r *http.Request, zone cloudflare.Zone) {
+	var params cloudflare.CreateDNSRecordParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	name := asciiName(params.Name)
+	if s.conflicts(zone.ID, name, params.Type) {
+		writeError(w, http.StatusBadRequest, CodeRecordAlreadyExists,
+			"An A, AAAA, or CNAME record with that host already exists.")
+		return
+	}
+
+	record := cloudflare.DNSRecord{
+		ID:         newHexID(),
+		ZoneID:     zone.ID,
+		ZoneName:   zone.Name,
+		Type:       params.Type,
+		Name:       name,
+		Content:    params.Content,
+		Data:       params.Data,
+		Priority:   params.Priority,
+		TTL:        max(params.TTL, 1),
+		Proxied:    ptr.To(ptr.Deref(params.Proxied, false)),
+		Comment:    params.Comment,
+		Tags:       params.Tags,
+		CreatedOn:  time.Now(),
+		ModifiedOn: time.Now(),
+	}
+	s.records[record.ID] = record
+	writeResult(w, http.StatusOK, record)
+}
+
+// routeTunnel creates a proxied CNAME record that points to the tunnel, like `cloudflared tunnel route dns`.
+func (s *Server) routeTunnel(w http.ResponseWriter, r *http.Request, zone cloudflare.Zone, tunnelID string) {
+	var params struct {
+		Type              string `json:"type"`
+		UserHostname      string `json:"user_hostname"`
+		OverwriteExisting bool   `json:"overwrite_existing"`
+	}
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	t, ok := s.tunnels[tunnelID]
+	if !ok || t.DeletedAt != nil || t.AccountID != zone.Account.ID {
+		writeError(w, http.StatusNotFound, CodeTunnelNotFound, "Tunnel not found")
+		return
+	}
+
+	name := asciiName(params.UserHostname)
+	target := tunnelID + ".cfargotunnel.com"
+	existing := s.findRecords(zone.ID, name, "")
+	for _, record := range existing {
+		if record.Type == "CNAME" && record.Content == target {
+			writeResult(w, http.StatusOK, map[string]string{"cname": "unchanged", "name": name})
+			return
+		}
+	}
+	if len(existing) != 0 && !params.OverwriteExisting {
+		writeError(w, http.StatusBadRequest, CodeRecordAlreadyExists,
+			"An A, AAAA, or CNAME record with that host already exists.")
+		return
+	}
+	for _, record := range existing {
+		delete(s.records, record.ID)
+	}
+
+	record := cloudflare.DNSRecord{
+		ID:         newHexID(),
+		ZoneID:     zone.ID,
+		ZoneName:   zone.Name,
+		Type:       "CNAME",
+		Name:       name,
+		Content:    target,
+		TTL:        1,
+		Proxied:    ptr.To(true),
+		CreatedOn:  time.Now(),
+		ModifiedOn: time.Now(),
+	}
+	s.records[record.ID] = record
+	writeResult(w, http.StatusOK, map[string]string{"cname": "new", "name": name})
+}
+
+func (s *Server) findRecords(zoneID, name, recordType string) []cloudflare.DNSRecord {
+	var res []cloudflare.DNSRecord
+	for _, record := range s.records {
+		if zoneID != "" && record.ZoneID != zoneID {
+			continue
+		}
+		if name != "" && !strings.EqualFold(record.Name, name) {
+			continue
+		}
+		if recordType != "" && record.Type != recordType {
+			continue
+		}
+		res = append(res, record)
+	}
+	slices.SortFunc(res, func(a, b cloudflare.DNSRecord) int { return a.CreatedOn.Compare(b.CreatedOn) })
+	return res
+}
+
+// conflicts reports whether a record of recordType cannot coexist with existing records of the name.
+func (s *Server) conflicts(zoneID, name, recordType string) bool {
+	for _, record := range s.findRecords(zoneID, name, "") {
+		if record.Type == "CNAME" || recordType == "CNAME" {
+			return true
+		}
+	}
+	return false
+}
+
+func asciiName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if ascii, err := idna.ToASCII(name); err == nil {
+		return ascii
+	}
+	return name
+}
@@ -0,0 +1,370 @@
+package cloudflare
+
+import (
+	"context"
+
+	"github.com/cloudflare/cloudflare-go"
+	"k8s.io/utils/ptr"
+)
+
+// LoadBalancerMonitor is a health check that load balancer pools use to probe their origins.
+type LoadBalancerMonitor struct {
+	ID              string
+	Type            string
+	Description     string
+	Method          string
+	Path            string
+	Header          map[string][]string
+	Port            uint16
+	Timeout         int
+	Retries         int
+	Interval        int
+	ConsecutiveUp   int
+	ConsecutiveDown int
+	ExpectedBody    string
+	ExpectedCodes   string
+	FollowRedirects bool
+	AllowInsecure   bool
+	ProbeZone       string
+}
+
+// LoadBalancerOrigin is an origin of a pool. Tunnel origins are addressed by <tunnelID>.cfargotunnel.com.
+type LoadBalancerOrigin struct {
+	Name    string
+	Address string
+	Enabled bool
+	Weight  float64
+	// Host overrides the Host header sent to the origin.
+	Host string
+}
+
+// LoadBalancerPool is a group of origins that a load balancer steers traffic to.
+type LoadBalancerPool struct {
+	ID                   string
+	Name                 string
+	Description          string
+	Enabled              bool
+	MinimumOrigins       int
+	MonitorID            string
+	Origins              []LoadBalancerOrigin
+	OriginSteeringPolicy string
+	NotificationEmail    string
+	CheckRegions         []string
+}
+
+// LoadBalancer distributes traffic of a hostname across pools.
+// Pools are referred by their IDs.
+type LoadBalancer struct {
+	ID                 string
+	Hostname           string
+	Description        string
+	Enabled            bool
+	Proxied            bool
+	TTL                int
+	SteeringPolicy     string
+	FallbackPool       string
+	DefaultPools       []string
+	PoolWeights        map[string]float64
+	RegionPools        map[string][]string
+	CountryPools       map[string][]string
+	PopPools           map[string][]string
+	SessionAffinity    string
+	SessionAffinityTTL int
+}
+
+func (c client) GetLoadBalancerMonitor(
+	ctx context.Context,
+	accountID, monitorID string,
+) (*LoadBalancerMonitor, error) {
+	res, err := c.API.GetLoadBalancerMonitor(ctx, accountContainer(accountID), monitorID)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header := res.Header
+	if len(header) == 0 {
+		header = nil
+	}
+
+	return &LoadBalancerMonitor{
+		ID:              res.ID,
+		Type:            res.Type,
+		Description:     res.Description,
+		Method:          res.Method,
+		Path:            res.Path,
+		Header:          header,
+		Port:            res.Port,
+		Timeout:         res.Timeout,
+		Retries:         res.Retries,
+		Interval:        res.Interval,
+		ConsecutiveUp:   res.ConsecutiveUp,
+		ConsecutiveDown: res.ConsecutiveDown,
+		ExpectedBody:    res.ExpectedBody,
+		ExpectedCodes:   res.ExpectedCodes,
+		FollowRedirects: res.FollowRedirects,
+		AllowInsecure:   res.AllowInsecure,
+		ProbeZone:       res.ProbeZone,
+	}, nil
+}
+
+func (c client) CreateLoadBalancerMonitor(
+	ctx context.Context,
+	accountID string,
+	monitor LoadBalancerMonitor,
+) (string, error) {
+	res, err := c.API.CreateLoadBalancerMonitor(
+		ctx,
+		accountContainer(accountID),
+		cloudflare.CreateLoadBalancerMonitorParams{LoadBalancerMonitor: toCFMonitor(monitor)},
+	)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+func (c client) UpdateLoadBalancerMonitor(ctx context.Context, accountID string, monitor LoadBalancerMonitor) error {
+	_, err := c.API.UpdateLoadBalancerMonitor(
+		ctx,
+		accountContainer(accountID),
+		cloudflare.UpdateLoadBalancerMonitorParams{LoadBalancerMonitor: toCFMonitor(monitor)},
+	)
+	return err
+}
+
+func (c client) DeleteLoadBalancerMonitor(ctx context.Context, accountID, monitorID string) error {
+	err := c.API.DeleteLoadBalancerMonitor(ctx, accountContainer(accountID), monitorID)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func toCFMonitor(monitor LoadBalancerMonitor) cloudflare.LoadBalancerMonitor {
+	return cloudflare.LoadBalancerMonitor{
+		ID:              monitor.ID,
+		Type:            monitor.Type,
+		Description:     monitor.Description,
+		Method:          monitor.Method,
+		Path:            monitor.Path,
+		Header:          monitor.Header,
+		Port:            monitor.Port,
+		Timeout:         monitor.Timeout,
+		Retries:         monitor.Retries,
+		Interval:        monitor.Interval,
+		ConsecutiveUp:   monitor.ConsecutiveUp,
+		ConsecutiveDown: monitor.ConsecutiveDown,
+		ExpectedBody:    monitor.ExpectedBody,
+		ExpectedCodes:   monitor.ExpectedCodes,
+		FollowRedirects: monitor.FollowRedirects,
+		AllowInsecure:   monitor.AllowInsecure,
+		ProbeZone:       monitor.ProbeZone,
+	}
+}
+
+func (c client) GetLoadBalancerPool(ctx context.Context, accountID, poolID string) (*LoadBalancerPool, error) {
+	res, err := c.API.GetLoadBalancerPool(ctx, accountContainer(accountID), poolID)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pool := LoadBalancerPool{
+		ID:                res.ID,
+		Name:              res.Name,
+		Description:       res.Description,
+		Enabled:           res.Enabled,
+		MinimumOrigins:    ptr.Deref(res.MinimumOrigins, 1),
+		MonitorID:         res.Monitor,
+		Origins:           make([]LoadBalancerOrigin, len(res.Origins)),
+		NotificationEmail: res.NotificationEmail,
+		CheckRegions:      res.CheckRegions,
+	}
+	if res.OriginSteering != nil {
+		pool.OriginSteeringPolicy = res.OriginSteering.Policy
+	}
+	for i, origin := range res.Origins {
+		pool.Origins[i] = LoadBalancerOrigin{
+			Name:    origin.Name,
+			Address: origin.Address,
+			Enabled: origin.Enabled,
+			Weight:  origin.Weight,
+		}
+		if hosts := origin.Header["Host"]; len(hosts) > 0 {
+			pool.Origins[i].Host = hosts[0]
+		}
+	}
+	return &pool, nil
+}
+
+func (c client) CreateLoadBalancerPool(ctx context.Context, accountID string, pool LoadBalancerPool) (string, error) {
+	res, err := c.API.CreateLoadBalancerPool(
+		ctx,
+		accountContainer(accountID),
+		cloudflare.CreateLoadBalancerPoolParams{LoadBalancerPool: toCFPool(pool)},
+	)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+func (c client) UpdateLoadBalancerPool(ctx context.Context, accountID string, pool LoadBalancerPool) error {
+	_, err := c.API.UpdateLoadBalancerPool(
+		ctx,
+		accountContainer(accountID),
+		cloudflare.UpdateLoadBalancerPoolParams{LoadBalancer: toCFPool(pool)},
+	)
+	return err
+}
+
+func (c client) DeleteLoadBalancerPool(ctx context.Context, accountID, poolID string) error {
+	err := c.API.DeleteLoadBalancerPool(ctx, accountContainer(accountID), poolID)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func toCFPool(pool LoadBalancerPool) cloudflare.LoadBalancerPool {
+	res := cloudflare.LoadBalancerPool{
+		ID:                pool.ID,
+		Name:              pool.Name,
+		Description:       pool.Description,
+		Enabled:           pool.Enabled,
+		MinimumOrigins:    ptr.To(pool.MinimumOrigins),
+		Monitor:           pool.MonitorID,
+		Origins:           make([]cloudflare.LoadBalancerOrigin, len(pool.Origins)),
+		NotificationEmail: pool.NotificationEmail,
+		CheckRegions:      pool.CheckRegions,
+	}
+	if pool.OriginSteeringPolicy != "" {
+		res.OriginSteering = &cloudflare.LoadBalancerOriginSteering{Policy: pool.OriginSteeringPolicy}
+	}
+	for i, origin := range pool.Origins {
+		res.Origins[i] = cloudflare.LoadBalancerOrigin{
+			Name:    origin.Name,
+			Address: origin.Address,
+			Enabled: origin.Enabled,
+			Weight:  origin.Weight,
+		}
+		if origin.Host != "" {
+			res.Origins[i].Header = map[string][]string{"Host": {origin.Host}}
+		}
+	}
+	return res
+}
+
+// GetLoadBalancer finds the load balancer of given hostname. It returns nil if there is no such load balancer.
+func (c client) GetLoadBalancer(ctx context.Context, accountID, hostname string) (*LoadBalancer, error) {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	lbs, err := c.API.ListLoadBalancers(ctx, zoneContainer(zoneID), cloudflare.ListLoadBalancerParams{})
+	if err != nil {
+		return nil, c.invalidateZonesIfGone(accountID, err)
+	}
+
+	hostname = normalizeZoneName(hostname)
+	for _, lb := range lbs {
+		if normalizeZoneName(lb.Name) != hostname {
+			continue
+		}
+
+		res := LoadBalancer{
+			ID:                 lb.ID,
+			Hostname:           lb.Name,
+			Description:        lb.Description,
+			Enabled:            ptr.Deref(lb.Enabled, true),
+			Proxied:            lb.Proxied,
+			TTL:                lb.TTL,
+			SteeringPolicy:     lb.SteeringPolicy,
+			FallbackPool:       lb.FallbackPool,
+			DefaultPools:       lb.DefaultPools,
+			RegionPools:        lb.RegionPools,
+			CountryPools:       lb.CountryPools,
+			PopPools:           lb.PopPools,
+			SessionAffinity:    lb.Persistence,
+			SessionAffinityTTL: lb.PersistenceTTL,
+		}
+		if lb.RandomSteering != nil {
+			res.PoolWeights = lb.RandomSteering.PoolWeights
+		}
+		return &res, nil
+	}
+	return nil, nil
+}
+
+func (c client) CreateLoadBalancer(ctx context.Context, accountID string, lb LoadBalancer) (string, error) {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, lb.Hostname)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := c.API.CreateLoadBalancer(
+		ctx,
+		zoneContainer(zoneID),
+		cloudflare.CreateLoadBalancerParams{LoadBalancer: toCFLoadBalancer(lb)},
+	)
+	if err != nil {
+		return "", c.invalidateZonesIfGone(accountID, err)
+	}
+	return res.ID, nil
+}
+
+func (c client) UpdateLoadBalancer(ctx context.Context, accountID string, lb LoadBalancer) error {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, lb.Hostname)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.API.UpdateLoadBalancer(
+		ctx,
+		zoneContainer(zoneID),
+		cloudflare.UpdateLoadBalancerParams{LoadBalancer: toCFLoadBalancer(lb)},
+	)
+	return c.invalidateZonesIfGone(accountID, err)
+}
+
+func (c client) DeleteLoadBalancer(ctx context.Context, accountID, hostname, lbID string) error {
+	zoneID, err := c.getZoneIDFromDomain(ctx, accountID, hostname)
+	if err != nil {
+		return err
+	}
+
+	err = c.API.DeleteLoadBalancer(ctx, zoneContainer(zoneID), lbID)
+	if err = c.invalidateZonesIfGone(accountID, err); IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func toCFLoadBalancer(lb LoadBalancer) cloudflare.LoadBalancer {
+	res := cloudflare.LoadBalancer{
+		ID:             lb.ID,
+		Name:           lb.Hostname,
+		Description:    lb.Description,
+		Enabled:        ptr.To(lb.Enabled),
+		Proxied:        lb.Proxied,
+		TTL:            lb.TTL,
+		SteeringPolicy: lb.SteeringPolicy,
+		FallbackPool:   lb.FallbackPool,
+		DefaultPools:   lb.DefaultPools,
+		RegionPools:    lb.RegionPools,
+		CountryPools:   lb.CountryPools,
+		PopPools:       lb.PopPools,
+		Persistence:    lb.SessionAffinity,
+		PersistenceTTL: lb.SessionAffinityTTL,
+	}
+	if len(lb.PoolWeights) > 0 {
+		res.RandomSteering = &cloudflare.RandomSteering{PoolWeights: lb.PoolWeights}
+	}
+	return res
+}
@@ -0,0 +1,49 @@
+package cloudflare
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "cloudflared_operator"
+
+var (
+	pooledClients = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "cloudflare",
+		Name:      "pooled_clients",
+		Help:      "Number of Cloudflare clients kept in the pool, one per API token.",
+	})
+
+	zoneCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "cloudflare",
+		Name:      "zone_cache_lookups_total",
+		Help:      "Number of zone list lookups by result (hit, miss, expired).",
+	}, []string{"result"})
+
+	zoneCacheInvalidations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "cloudflare",
+		Name:      "zone_cache_invalidations_total",
+		Help:      "Number of zone list invalidations by reason.",
+	}, []string{"reason"})
+
+	zoneListRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "cloudflare",
+		Name:      "zone_list_requests_total",
+		Help:      "Number of ListZones calls to Cloudflare API by result (success, error).",
+	}, []string{"result"})
+
+	apiRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "cloudflare",
+		Name:      "api_retries_total",
+		Help:      "Number of retried Cloudflare API requests by cause (HTTP status code or network).",
+	}, []string{"cause"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(pooledClients, zoneCacheLookups, zoneCacheInvalidations, zoneListRequests, apiRetries)
+}
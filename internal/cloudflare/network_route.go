@@ -0,0 +1,117 @@
+package cloudflare
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/cloudflare/cloudflare-go"
+	"k8s.io/utils/ptr"
+)
+
+// NetworkRoute is a private network route (teamnet route) that sends WARP traffic to a tunnel.
+type NetworkRoute struct {
+	Network          string
+	TunnelID         string
+	TunnelName       string
+	Comment          string
+	VirtualNetworkID string
+}
+
+// GetNetworkRoute finds the route that exactly matches given network.
+// An empty vnetID matches any virtual network. It returns nil if there is no such route.
+func (c client) GetNetworkRoute(ctx context.Context, accountID, network, vnetID string) (*NetworkRoute, error) {
+	routes, err := c.API.ListTunnelRoutes(
+		ctx,
+		&cloudflare.ResourceContainer{
+			Identifier: accountID,
+			Type:       cloudflare.AccountType,
+		},
+		cloudflare.TunnelRoutesListParams{
+			IsDeleted:        ptr.To(false),
+			NetworkSubset:    network,
+			NetworkSuperset:  network,
+			VirtualNetworkID: vnetID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, route := range routes {
+		if !isSameNetwork(route.Network, network) {
+			continue
+		}
+		if vnetID != "" && route.VirtualNetworkID != vnetID {
+			continue
+		}
+		return &NetworkRoute{
+			Network:          route.Network,
+			TunnelID:         route.TunnelID,
+			TunnelName:       route.TunnelName,
+			Comment:          route.Comment,
+			VirtualNetworkID: route.VirtualNetworkID,
+		}, nil
+	}
+	return nil, nil
+}
+
+func (c client) CreateNetworkRoute(ctx context.Context, accountID string, route NetworkRoute) error {
+	_, err := c.API.CreateTunnelRoute(
+		ctx,
+		&cloudflare.ResourceContainer{
+			Identifier: accountID,
+			Type:       cloudflare.AccountType,
+		},
+		cloudflare.TunnelRoutesCreateParams{
+			Network:          route.Network,
+			TunnelID:         route.TunnelID,
+			Comment:          route.Comment,
+			VirtualNetworkID: route.VirtualNetworkID,
+		},
+	)
+	return err
+}
+
+func (c client) UpdateNetworkRoute(ctx context.Context, accountID string, route NetworkRoute) error {
+	_, err := c.API.UpdateTunnelRoute(
+		ctx,
+		&cloudflare.ResourceContainer{
+			Identifier: accountID,
+			Type:       cloudflare.AccountType,
+		},
+		cloudflare.TunnelRoutesUpdateParams{
+			Network:          route.Network,
+			TunnelID:         route.TunnelID,
+			Comment:          route.Comment,
+			VirtualNetworkID: route.VirtualNetworkID,
+		},
+	)
+	return err
+}
+
+func (c client) DeleteNetworkRoute(ctx context.Context, accountID, network, vnetID string) error {
+	err := c.API.DeleteTunnelRoute(
+		ctx,
+		&cloudflare.ResourceContainer{
+			Identifier: accountID,
+			Type:       cloudflare.AccountType,
+		},
+		cloudflare.TunnelRoutesDeleteParams{
+			Network:          network,
+			VirtualNetworkID: vnetID,
+		},
+	)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func isSameNetwork(a, b string) bool {
+	pa, errA := netip.ParsePrefix(a)
+	pb, errB := netip.ParsePrefix(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return pa.Masked() == pb.Masked()
+}
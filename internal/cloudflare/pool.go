@@ -0,0 +1,152 @@
+package cloudflare
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"golang.org/x/time/rate"
+	"k8s.io/utils/clock"
+)
+
+const (
+	DefaultZoneCacheTTL = 10 * time.Minute
+	// DefaultRequestsPerSecond matches the global Cloudflare API limit, 1200 requests per 5 minutes.
+	DefaultRequestsPerSecond = 4
+	DefaultRequestBurst      = 10
+	DefaultMaxRetries        = 3
+
+	// idleClientTTL is how long a client is kept after its last use. Rotated tokens are dropped with it.
+	idleClientTTL = time.Hour
+)
+
+// ClientOptions configures clients created by ClientPool.
+type ClientOptions struct {
+	ZoneCacheTTL time.Duration
+	// RequestsPerSecond and RequestBurst configure the token bucket of each Cloudflare account.
+	RequestsPerSecond float64
+	RequestBurst      int
+	// MaxRetries is how many times a request is retried on 429 and 5xx responses.
+	MaxRetries int
+	// BaseURL overrides the endpoint of Cloudflare API, e.g. https://api.cloudflare.com/client/v4.
+	BaseURL string
+	// AllowedAccountIDs restricts accounts that clients of the pool may be used for. Empty allows every account.
+	AllowedAccountIDs []string
+}
+
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		ZoneCacheTTL:      DefaultZoneCacheTTL,
+		RequestsPerSecond: DefaultRequestsPerSecond,
+		RequestBurst:      DefaultRequestBurst,
+		MaxRetries:        DefaultMaxRetries,
+	}
+}
+
+// ClientPool shares Cloudflare clients across reconciles, so that caches such as the zone list survive.
+// Clients are keyed by the hash of API token, so the token itself is never kept as a map key.
+// Rate limits are shared by all clients of the pool, because Cloudflare counts requests per account, not per token.
+type ClientPool struct {
+	mu         sync.Mutex
+	clients    map[string]*pooledClient
+	opts       ClientOptions
+	httpClient *http.Client
+	limiters   *accountLimiters
+	clock      clock.Clock
+}
+
+type pooledClient struct {
+	client
+	lastUsed time.Time
+}
+
+func NewClientPool(opts ClientOptions, clk clock.Clock) *ClientPool {
+	limiters := newAccountLimiters(opts.RequestsPerSecond, opts.RequestBurst)
+	return &ClientPool{
+		clients:    make(map[string]*pooledClient),
+		opts:       opts,
+		httpClient: newHTTPClient(limiters, opts.MaxRetries, clk),
+		limiters:   limiters,
+		clock:      clk,
+	}
+}
+
+// Get returns the client of the token, creating one if there is none.
+func (p *ClientPool) Get(token string) (Client, error) {
+	key := hashToken(token)
+	now := p.clock.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.evictIdle(now)
+
+	if cached, ok := p.clients[key]; ok {
+		cached.lastUsed = now
+		return cached.client, nil
+	}
+
+	cli, err := newClient(token, p.opts.BaseURL, p.httpClient, p.limiters, newZoneCache(p.opts.ZoneCacheTTL, p.clock))
+	if err != nil {
+		return nil, err
+	}
+	p.clients[key] = &pooledClient{client: cli, lastUsed: now}
+	pooledClients.Set(float64(len(p.clients)))
+	return cli, nil
+}
+
+// IsAccountAllowed reports whether the account may be managed with clients of the pool.
+func (p *ClientPool) IsAccountAllowed(accountID string) bool {
+	return len(p.opts.AllowedAccountIDs) == 0 || slices.Contains(p.opts.AllowedAccountIDs, accountID)
+}
+
+func (p *ClientPool) evictIdle(now time.Time) {
+	for key, cached := range p.clients {
+		if now.Sub(cached.lastUsed) > idleClientTTL {
+			delete(p.clients, key)
+		}
+	}
+	pooledClients.Set(float64(len(p.clients)))
+}
+
+func newClient(
+	token, baseURL string,
+	httpClient *http.Client,
+	limiters *accountLimiters,
+	zones *zoneCache,
+) (client, error) {
+	opts := []cloudflare.Option{
+		cloudflare.HTTPClient(httpClient),
+		// rate limit and retries are handled by retryTransport, which keeps Cloudflare error codes of failed requests
+		cloudflare.UsingRateLimit(float64(rate.Inf)),
+		cloudflare.UsingRetryPolicy(0, 0, 0),
+	}
+	if baseURL != "" {
+		opts = append(opts, cloudflare.BaseURL(baseURL))
+	}
+	cli, err := cloudflare.NewWithAPIToken(token, opts...)
+	if err != nil {
+		return client{}, err
+	}
+	return client{API: cli, zoneCache: zones, limiters: limiters}, nil
+}
+
+func newHTTPClient(limiters *accountLimiters, maxRetries int, clk clock.Clock) *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{
+			next:       http.DefaultTransport,
+			limiters:   limiters,
+			maxRetries: maxRetries,
+			clock:      clk,
+		},
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
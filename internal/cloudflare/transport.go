@@ -0,0 +1,237 @@
+package cloudflare
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-json"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/clock"
+)
+
+const (
+	minRetryBackoff = time.Second
+	maxRetryBackoff = 30 * time.Second
+
+	// maxErrorBodySize bounds how much of an error response is read to extract Cloudflare error codes.
+	maxErrorBodySize = 64 << 10
+)
+
+// accountLimiters keeps a token bucket per Cloudflare account.
+// Requests against a zone are charged to the account that owns it, once the zone list of the account is known.
+type accountLimiters struct {
+	limit rate.Limit
+	burst int
+
+	mu           sync.Mutex
+	limiters     map[string]*rate.Limiter
+	zoneAccounts map[string]string
+}
+
+func newAccountLimiters(qps float64, burst int) *accountLimiters {
+	return &accountLimiters{
+		limit:        rate.Limit(qps),
+		burst:        burst,
+		limiters:     make(map[string]*rate.Limiter),
+		zoneAccounts: make(map[string]string),
+	}
+}
+
+func (l *accountLimiters) forRequest(u *url.URL) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	accountID := l.accountOf(u)
+	limiter, ok := l.limiters[accountID]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[accountID] = limiter
+	}
+	return limiter
+}
+
+// accountOf finds account ID from API path such as /client/v4/accounts/{id}/... or /client/v4/zones/{id}/....
+// Requests whose account is unknown share a bucket keyed by an empty string.
+func (l *accountLimiters) accountOf(u *url.URL) string {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		switch segments[i] {
+		case "accounts":
+			return segments[i+1]
+		case "zones":
+			return l.zoneAccounts[segments[i+1]]
+		}
+	}
+	// zone listing filters by account with query
+	return u.Query().Get("account.id")
+}
+
+func (l *accountLimiters) setZoneAccount(accountID string, zoneIDs []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, zoneID := range zoneIDs {
+		l.zoneAccounts[zoneID] = accountID
+	}
+}
+
+// retryTransport rate-limits requests per account and retries failed requests with jittered backoff.
+// 429 responses and connection errors before the request is sent are retried for every method,
+// but 5xx responses and other connection errors only for idempotent methods,
+// as Cloudflare may have committed e.g. a created tunnel before failing.
+// Once it stops retrying, it returns APIError so that callers can tell what Cloudflare responded.
+type retryTransport struct {
+	next       http.RoundTripper
+	limiters   *accountLimiters
+	maxRetries int
+	clock      clock.Clock
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	limiter := t.limiters.forRequest(req.URL)
+
+	for attempt := 0; ; attempt++ {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		attemptReq := req
+		if attempt > 0 {
+			var err error
+			if attemptReq, err = rewindRequest(req); err != nil {
+				return nil, err
+			}
+		}
+
+		var sent atomic.Bool
+		attemptReq = attemptReq.WithContext(httptrace.WithClientTrace(attemptReq.Context(), &httptrace.ClientTrace{
+			WroteHeaders: func() { sent.Store(true) },
+		}))
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err != nil {
+			if ctx.Err() != nil || attempt >= t.maxRetries || !canRetry(req) ||
+				(sent.Load() && !isIdempotent(req.Method)) {
+				return nil, err
+			}
+			apiRetries.WithLabelValues("network").Inc()
+			if err := t.sleep(req, retryBackoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError {
+			return resp, nil
+		}
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), t.clock.Now())
+		// Waiting longer than the backoff limit would stall the reconciler, so leave it to the caller.
+		if attempt >= t.maxRetries || !canRetry(req) || retryAfter > maxRetryBackoff ||
+			(resp.StatusCode != http.StatusTooManyRequests && !isIdempotent(req.Method)) {
+			return nil, newAPIError(resp, retryAfter)
+		}
+
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+		_ = resp.Body.Close()
+
+		apiRetries.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+		delay := retryAfter
+		if delay == 0 {
+			delay = retryBackoff(attempt)
+		}
+		if err := t.sleep(req, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *retryTransport) sleep(req *http.Request, d time.Duration) error {
+	timer := t.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// retryBackoff doubles from minRetryBackoff on each attempt, with up to 50% of jitter so that
+// reconcilers hitting the limit together do not retry in lockstep.
+func retryBackoff(attempt int) time.Duration {
+	backoff := maxRetryBackoff
+	if attempt < 5 {
+		backoff = min(minRetryBackoff<<attempt, maxRetryBackoff)
+	}
+	return wait.Jitter(backoff/2, 1)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// parseRetryAfter reads Retry-After header, which is either delay seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+func newAPIError(resp *http.Response, retryAfter time.Duration) *APIError {
+	defer resp.Body.Close()
+
+	apiErr := &APIError{StatusCode: resp.StatusCode, RetryAfter: retryAfter}
+
+	var body struct {
+		Errors []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil || json.Unmarshal(raw, &body) != nil {
+		return apiErr
+	}
+	for _, e := range body.Errors {
+		apiErr.Codes = append(apiErr.Codes, e.Code)
+		apiErr.Messages = append(apiErr.Messages, e.Message)
+	}
+	return apiErr
+}
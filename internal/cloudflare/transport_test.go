@@ -0,0 +1,333 @@
+package cloudflare
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/clock"
+)
+
+// recordingClock fires timers right away and records how long they were asked to wait.
+type recordingClock struct {
+	clock.RealClock
+
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (c *recordingClock) NewTimer(d time.Duration) clock.Timer {
+	c.mu.Lock()
+	c.delays = append(c.delays, d)
+	c.mu.Unlock()
+	return c.RealClock.NewTimer(0)
+}
+
+func (c *recordingClock) Delays() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.delays
+}
+
+type testResponse struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+// newTestTransport returns a transport against a server that responds with responses in order,
+// repeating the last one, and the number of requests the server received.
+func newTestTransport(t *testing.T, responses ...testResponse) (*retryTransport, string, *atomic.Int32) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		i := int(received.Add(1)) - 1
+		res := responses[min(i, len(responses)-1)]
+		if res.retryAfter != "" {
+			w.Header().Set("Retry-After", res.retryAfter)
+		}
+		w.WriteHeader(res.status)
+		_, _ = io.WriteString(w, res.body)
+	}))
+	t.Cleanup(server.Close)
+
+	return &retryTransport{
+		next:       http.DefaultTransport,
+		limiters:   newAccountLimiters(1000, 1000),
+		maxRetries: 2,
+		clock:      &recordingClock{},
+	}, server.URL, &received
+}
+
+func TestRetryTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method    string
+		responses []testResponse
+		requests  int32
+		status    int
+		// err is checked if status is zero
+		err func(g Gomega, err error)
+	}{
+		"GET is retried on 5xx": {
+			method:    http.MethodGet,
+			responses: []testResponse{{status: 503}, {status: 502}, {status: 200}},
+			requests:  3,
+			status:    200,
+		},
+		"DELETE is retried on 5xx": {
+			method:    http.MethodDelete,
+			responses: []testResponse{{status: 500}, {status: 200}},
+			requests:  2,
+			status:    200,
+		},
+		"POST is not retried on 5xx": {
+			method:    http.MethodPost,
+			responses: []testResponse{{status: 503}, {status: 200}},
+			requests:  1,
+			err: func(g Gomega, err error) {
+				var apiErr *APIError
+				g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+				g.Expect(apiErr.StatusCode).To(Equal(503))
+			},
+		},
+		"PATCH is not retried on 5xx": {
+			method:    http.MethodPatch,
+			responses: []testResponse{{status: 500}, {status: 200}},
+			requests:  1,
+			err:       func(g Gomega, err error) { g.Expect(err).To(HaveOccurred()) },
+		},
+		"POST is retried on 429": {
+			method:    http.MethodPost,
+			responses: []testResponse{{status: 429}, {status: 200}},
+			requests:  2,
+			status:    200,
+		},
+		"4xx is returned as is": {
+			method:    http.MethodGet,
+			responses: []testResponse{{status: 404}, {status: 200}},
+			requests:  1,
+			status:    404,
+		},
+		"retries are exhausted": {
+			method: http.MethodGet,
+			responses: []testResponse{{
+				status: 500,
+				body:   `{"success":false,"errors":[{"code":10001,"message":"internal"}]}`,
+			}},
+			requests: 3,
+			err: func(g Gomega, err error) {
+				var apiErr *APIError
+				g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+				g.Expect(apiErr.StatusCode).To(Equal(500))
+				g.Expect(apiErr.Codes).To(Equal([]int{10001}))
+				g.Expect(apiErr.Messages).To(Equal([]string{"internal"}))
+				_, limited := IsRateLimited(err)
+				g.Expect(limited).To(BeFalse())
+			},
+		},
+		"Retry-After longer than the backoff limit is left to the caller": {
+			method:    http.MethodGet,
+			responses: []testResponse{{status: 429, retryAfter: "120"}, {status: 200}},
+			requests:  1,
+			err: func(g Gomega, err error) {
+				retryAfter, limited := IsRateLimited(err)
+				g.Expect(limited).To(BeTrue())
+				g.Expect(retryAfter).To(Equal(2 * time.Minute))
+			},
+		},
+		"Retry-After of 5xx is rate limit as well": {
+			method:    http.MethodGet,
+			responses: []testResponse{{status: 503, retryAfter: "120"}, {status: 200}},
+			requests:  1,
+			err: func(g Gomega, err error) {
+				retryAfter, limited := IsRateLimited(err)
+				g.Expect(limited).To(BeTrue())
+				g.Expect(retryAfter).To(Equal(2 * time.Minute))
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			transport, serverURL, received := newTestTransport(t, tc.responses...)
+			req, err := http.NewRequest(tc.method, serverURL+"/client/v4/accounts/a/tunnels", strings.NewReader("{}"))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			resp, err := transport.RoundTrip(req)
+			g.Expect(received.Load()).To(Equal(tc.requests))
+			if tc.status == 0 {
+				tc.err(g, err)
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			g.Expect(resp.StatusCode).To(Equal(tc.status))
+		})
+	}
+}
+
+func TestRetryTransportWaitsForRetryAfter(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	transport, serverURL, _ := newTestTransport(t, testResponse{status: 429, retryAfter: "3"}, testResponse{status: 200})
+	req, err := http.NewRequest(http.MethodGet, serverURL+"/client/v4/zones", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	resp, err := transport.RoundTrip(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	g.Expect(resp.StatusCode).To(Equal(200))
+	g.Expect(transport.clock.(*recordingClock).Delays()).To(Equal([]time.Duration{3 * time.Second}))
+}
+
+// failingRoundTripper fails requests, after writing headers if sent is true.
+type failingRoundTripper struct {
+	sent     bool
+	attempts atomic.Int32
+}
+
+func (f *failingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.attempts.Add(1)
+	if trace := httptrace.ContextClientTrace(req.Context()); f.sent && trace != nil && trace.WroteHeaders != nil {
+		trace.WroteHeaders()
+	}
+	return nil, errors.New("connection reset")
+}
+
+func TestRetryTransportConnectionErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method   string
+		sent     bool
+		attempts int32
+	}{
+		"GET after sent":    {method: http.MethodGet, sent: true, attempts: 3},
+		"POST before sent":  {method: http.MethodPost, sent: false, attempts: 3},
+		"POST after sent":   {method: http.MethodPost, sent: true, attempts: 1},
+		"DELETE after sent": {method: http.MethodDelete, sent: true, attempts: 3},
+		"PATCH after sent":  {method: http.MethodPatch, sent: true, attempts: 1},
+		"PATCH before sent": {method: http.MethodPatch, sent: false, attempts: 3},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			next := &failingRoundTripper{sent: tc.sent}
+			transport := &retryTransport{
+				next:       next,
+				limiters:   newAccountLimiters(1000, 1000),
+				maxRetries: 2,
+				clock:      &recordingClock{},
+			}
+			req, err := http.NewRequest(tc.method, "http://cloudflare.invalid/client/v4/zones", strings.NewReader("{}"))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			_, err = transport.RoundTrip(req)
+			g.Expect(err).To(MatchError("connection reset"))
+			g.Expect(next.attempts.Load()).To(Equal(tc.attempts))
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	for attempt := 0; attempt < 10; attempt++ {
+		limit := min(minRetryBackoff<<attempt, maxRetryBackoff)
+		for i := 0; i < 100; i++ {
+			backoff := retryBackoff(attempt)
+			g.Expect(backoff).To(BeNumerically(">=", limit/2), "attempt %d", attempt)
+			g.Expect(backoff).To(BeNumerically("<=", limit), "attempt %d", attempt)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		value string
+		want  time.Duration
+	}{
+		"empty":             {value: "", want: 0},
+		"seconds":           {value: "5", want: 5 * time.Second},
+		"negative":          {value: "-5", want: 0},
+		"http date":         {value: now.Add(time.Minute).Format(http.TimeFormat), want: time.Minute},
+		"http date in past": {value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		"garbage":           {value: "soon", want: 0},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			NewWithT(t).Expect(parseRetryAfter(tc.value, now)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestAccountLimiters(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	limiters := newAccountLimiters(1, 1)
+	limiters.setZoneAccount("account-a", []string{"zone-a"})
+	limiter := func(rawURL string) any {
+		u, err := url.Parse(rawURL)
+		g.Expect(err).NotTo(HaveOccurred())
+		return limiters.forRequest(u)
+	}
+
+	accountA := limiter("https://api.cloudflare.com/client/v4/accounts/account-a/cfd_tunnel")
+	g.Expect(limiter("https://api.cloudflare.com/client/v4/zones/zone-a/dns_records")).To(BeIdenticalTo(accountA))
+	g.Expect(limiter("https://api.cloudflare.com/client/v4/zones?account.id=account-a")).To(BeIdenticalTo(accountA))
+	g.Expect(limiter("https://api.cloudflare.com/client/v4/accounts/account-b/cfd_tunnel")).
+		NotTo(BeIdenticalTo(accountA))
+	// requests of unknown account share a bucket
+	g.Expect(limiter("https://api.cloudflare.com/client/v4/zones/zone-b/dns_records")).
+		To(BeIdenticalTo(limiter("https://api.cloudflare.com/client/v4/user/tokens/verify")))
+}
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	err := fmt.Errorf("CreateTunnel: %w", &APIError{
+		StatusCode: 429,
+		Codes:      []int{971},
+		Messages:   []string{"Please wait and consider throttling your request speed"},
+		RetryAfter: 10 * time.Second,
+	})
+	g.Expect(err.Error()).To(Equal("CreateTunnel: Cloudflare API responded 429 Too Many Requests: " +
+		"Please wait and consider throttling your request speed (codes: [971])"))
+	g.Expect(ErrorCodes(err)).To(Equal([]int{971}))
+	g.Expect(isZoneNotFound(err)).To(BeFalse())
+	retryAfter, limited := IsRateLimited(err)
+	g.Expect(limited).To(BeTrue())
+	g.Expect(retryAfter).To(Equal(10 * time.Second))
+
+	var apiErr *APIError
+	g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+	g.Expect(apiErr.InternalErrorCodeIs(971)).To(BeTrue())
+	g.Expect(apiErr.InternalErrorCodeIs(10000)).To(BeFalse())
+}
@@ -0,0 +1,22 @@
+package cloudflare
+
+import (
+	"context"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// UpdateTunnelConfiguration replaces the remotely managed configuration of the tunnel.
+// Connected cloudflared applies it without restarting, and it takes precedence over the local config file.
+func (c client) UpdateTunnelConfiguration(
+	ctx context.Context,
+	accountID, tunnelID string,
+	config cloudflare.TunnelConfiguration,
+) error {
+	_, err := c.API.UpdateTunnelConfiguration(
+		ctx,
+		accountContainer(accountID),
+		cloudflare.TunnelConfigurationParams{TunnelID: tunnelID, Config: config},
+	)
+	return err
+}
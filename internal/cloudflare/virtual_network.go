@@ -0,0 +1,111 @@
+package cloudflare
+
+import (
+	"context"
+
+	"github.com/cloudflare/cloudflare-go"
+	"k8s.io/utils/ptr"
+)
+
+// VirtualNetwork separates private network routes, so that overlapping CIDRs can coexist in an account.
+type VirtualNetwork struct {
+	ID        string
+	Name      string
+	Comment   string
+	IsDefault bool
+}
+
+// GetVirtualNetwork finds a virtual network by its ID. It returns nil if there is no such virtual network.
+func (c client) GetVirtualNetwork(ctx context.Context, accountID, vnetID string) (*VirtualNetwork, error) {
+	return c.findVirtualNetwork(ctx, accountID, cloudflare.TunnelVirtualNetworksListParams{
+		ID:        vnetID,
+		IsDeleted: ptr.To(false),
+	})
+}
+
+// GetVirtualNetworkByName finds a virtual network by its name. It returns nil if there is no such virtual network.
+func (c client) GetVirtualNetworkByName(ctx context.Context, accountID, name string) (*VirtualNetwork, error) {
+	return c.findVirtualNetwork(ctx, accountID, cloudflare.TunnelVirtualNetworksListParams{
+		Name:      name,
+		IsDeleted: ptr.To(false),
+	})
+}
+
+func (c client) findVirtualNetwork(
+	ctx context.Context,
+	accountID string,
+	params cloudflare.TunnelVirtualNetworksListParams,
+) (*VirtualNetwork, error) {
+	vnets, err := c.API.ListTunnelVirtualNetworks(
+		ctx,
+		&cloudflare.ResourceContainer{
+			Identifier: accountID,
+			Type:       cloudflare.AccountType,
+		},
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(vnets) == 0 {
+		return nil, nil
+	}
+
+	return &VirtualNetwork{
+		ID:        vnets[0].ID,
+		Name:      vnets[0].Name,
+		Comment:   vnets[0].Comment,
+		IsDefault: vnets[0].IsDefaultNetwork,
+	}, nil
+}
+
+func (c client) CreateVirtualNetwork(ctx context.Context, accountID string, vnet VirtualNetwork) (string, error) {
+	res, err := c.API.CreateTunnelVirtualNetwork(
+		ctx,
+		&cloudflare.ResourceContainer{
+			Identifier: accountID,
+			Type:       cloudflare.AccountType,
+		},
+		cloudflare.TunnelVirtualNetworkCreateParams{
+			Name:      vnet.Name,
+			Comment:   vnet.Comment,
+			IsDefault: vnet.IsDefault,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+func (c client) UpdateVirtualNetwork(ctx context.Context, accountID string, vnet VirtualNetwork) error {
+	_, err := c.API.UpdateTunnelVirtualNetwork(
+		ctx,
+		&cloudflare.ResourceContainer{
+			Identifier: accountID,
+			Type:       cloudflare.AccountType,
+		},
+		cloudflare.TunnelVirtualNetworkUpdateParams{
+			VnetID:           vnet.ID,
+			Name:             vnet.Name,
+			Comment:          vnet.Comment,
+			IsDefaultNetwork: ptr.To(vnet.IsDefault),
+		},
+	)
+	return err
+}
+
+func (c client) DeleteVirtualNetwork(ctx context.Context, accountID, vnetID string) error {
+	err := c.API.DeleteTunnelVirtualNetwork(
+		ctx,
+		&cloudflare.ResourceContainer{
+			Identifier: accountID,
+			Type:       cloudflare.AccountType,
+		},
+		vnetID,
+	)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
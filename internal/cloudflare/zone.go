@@ -0,0 +1,257 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"golang.org/x/net/idna"
+	"k8s.io/utils/clock"
+)
+
+var (
+	errZoneNotFound  = errors.New("zone could not be found")
+	errAmbiguousZone = errors.New("ambiguous zone name; an account ID might help")
+)
+
+// ZoneRef optionally pins the zone of a hostname. Zone is found from the hostname if both fields are empty.
+type ZoneRef struct {
+	ID   string
+	Name string
+}
+
+// Cloudflare API error codes returned for requests against a zone that no longer exists.
+const (
+	errCodeInvalidZoneIdentifier   = 1001
+	errCodeInvalidObjectIdentifier = 7003
+)
+
+// zoneIndex maps normalized zone names to their IDs.
+// Empty ID means that there are multiple zones with the name.
+type zoneIndex map[string]string
+
+// maxZoneRefreshInterval bounds minRefreshInterval of zoneCache, which is the ttl if it is shorter.
+const maxZoneRefreshInterval = time.Minute
+
+// zoneCache keeps zone list of each account for ttl.
+type zoneCache struct {
+	mu    sync.RWMutex
+	ttl   time.Duration
+	clock clock.PassiveClock
+	// minRefreshInterval bounds how often zones missing from the list make the list fetched again.
+	minRefreshInterval time.Duration
+	entries            map[string]zoneCacheEntry
+	// fetchedAt survives invalidation, so that invalidating does not lift the bound of refreshes.
+	fetchedAt map[string]time.Time
+}
+
+type zoneCacheEntry struct {
+	zones     zoneIndex
+	expiresAt time.Time
+}
+
+func newZoneCache(ttl time.Duration, clk clock.PassiveClock) *zoneCache {
+	return &zoneCache{
+		ttl:                ttl,
+		clock:              clk,
+		minRefreshInterval: min(ttl, maxZoneRefreshInterval),
+		entries:            make(map[string]zoneCacheEntry),
+		fetchedAt:          make(map[string]time.Time),
+	}
+}
+
+func (c *zoneCache) get(accountID string) (zoneIndex, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[accountID]
+	c.mu.RUnlock()
+
+	switch {
+	case !ok:
+		zoneCacheLookups.WithLabelValues("miss").Inc()
+		return nil, false
+	case !c.clock.Now().Before(entry.expiresAt):
+		zoneCacheLookups.WithLabelValues("expired").Inc()
+		return nil, false
+	default:
+		zoneCacheLookups.WithLabelValues("hit").Inc()
+		return entry.zones, true
+	}
+}
+
+func (c *zoneCache) set(accountID string, zones zoneIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	c.entries[accountID] = zoneCacheEntry{zones: zones, expiresAt: now.Add(c.ttl)}
+	c.fetchedAt[accountID] = now
+}
+
+// mayRefresh reports whether the zone list of the account was fetched minRefreshInterval ago or earlier.
+func (c *zoneCache) mayRefresh(accountID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	fetchedAt, ok := c.fetchedAt[accountID]
+	return !ok || !c.clock.Now().Before(fetchedAt.Add(c.minRefreshInterval))
+}
+
+func (c *zoneCache) invalidate(accountID, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[accountID]; ok {
+		delete(c.entries, accountID)
+		zoneCacheInvalidations.WithLabelValues(reason).Inc()
+	}
+}
+
+// resolveZoneID finds the zone of the domain, preferring the explicitly given one.
+func (c client) resolveZoneID(ctx context.Context, accountID, domain string, zone ZoneRef) (string, error) {
+	switch {
+	case zone.ID != "":
+		return zone.ID, nil
+	case zone.Name != "":
+		return c.getZoneIDFromName(ctx, accountID, zone.Name)
+	default:
+		return c.getZoneIDFromDomain(ctx, accountID, domain)
+	}
+}
+
+func (c client) getZoneIDFromName(ctx context.Context, accountID, zoneName string) (string, error) {
+	zoneName = normalizeZoneName(zoneName)
+
+	zoneID, ok, err := c.findZone(ctx, accountID, func(zones zoneIndex) (string, bool) {
+		zoneID, ok := zones[zoneName]
+		return zoneID, ok
+	})
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errZoneNotFound
+	}
+	return zoneIDOrAmbiguous(zoneID)
+}
+
+// getZoneIDFromDomain finds the zone that the domain belongs to, by walking its labels from the most specific one.
+// So that a delegated subzone (e.g. dev.example.com) takes precedence over its parent zone,
+// and zones under private suffixes are found as well.
+func (c client) getZoneIDFromDomain(ctx context.Context, accountID, domain string) (string, error) {
+	domain = normalizeZoneName(domain)
+
+	zoneID, ok, err := c.findZone(ctx, accountID, func(zones zoneIndex) (string, bool) {
+		return findZoneOfDomain(zones, domain)
+	})
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w for %s", errZoneNotFound, domain)
+	}
+	return zoneIDOrAmbiguous(zoneID)
+}
+
+// findZoneOfDomain walks labels of the normalized domain from the most specific one.
+func findZoneOfDomain(zones zoneIndex, domain string) (string, bool) {
+	for candidate := domain; candidate != ""; {
+		if zoneID, ok := zones[candidate]; ok {
+			return zoneID, true
+		}
+		_, candidate, _ = strings.Cut(candidate, ".")
+	}
+	return "", false
+}
+
+// findZone looks the zone up from the cached list, and lists zones again if it is not found,
+// as the zone might have been added after the list was cached.
+// Lists are fetched again at most once per minRefreshInterval of the cache, so that hostnames out of every zone
+// do not list zones on every reconciliation.
+func (c client) findZone(
+	ctx context.Context,
+	accountID string,
+	find func(zones zoneIndex) (string, bool),
+) (string, bool, error) {
+	zones, err := c.listZones(ctx, accountID, false)
+	if err != nil {
+		return "", false, err
+	}
+	if zoneID, ok := find(zones); ok {
+		return zoneID, true, nil
+	}
+	if !c.zoneCache.mayRefresh(accountID) {
+		return "", false, nil
+	}
+
+	if zones, err = c.listZones(ctx, accountID, true); err != nil {
+		return "", false, err
+	}
+	zoneID, ok := find(zones)
+	return zoneID, ok, nil
+}
+
+// listZones returns all zones of the account. The list is cached until it expires or refresh is requested.
+func (c client) listZones(ctx context.Context, accountID string, refresh bool) (zoneIndex, error) {
+	if refresh {
+		c.zoneCache.invalidate(accountID, "zone_not_found")
+	} else if zones, ok := c.zoneCache.get(accountID); ok {
+		return zones, nil
+	}
+
+	res, err := c.API.ListZonesContext(ctx, cloudflare.WithZoneFilters("", accountID, ""))
+	if err != nil {
+		zoneListRequests.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("ListZonesContext command failed: %w", err)
+	}
+	zoneListRequests.WithLabelValues("success").Inc()
+
+	zones := make(zoneIndex, len(res.Result))
+	zoneIDs := make([]string, 0, len(res.Result))
+	for _, zone := range res.Result {
+		zoneIDs = append(zoneIDs, zone.ID)
+		name := normalizeZoneName(zone.Name)
+		if _, exists := zones[name]; exists {
+			zones[name] = ""
+			continue
+		}
+		zones[name] = zone.ID
+	}
+
+	c.zoneCache.set(accountID, zones)
+	c.limiters.setZoneAccount(accountID, zoneIDs)
+	return zones, nil
+}
+
+// invalidateZonesIfGone drops the cached zone list when Cloudflare reports that the zone is gone,
+// so that a zone re-added with a new ID is picked up by the next reconciliation.
+func (c client) invalidateZonesIfGone(accountID string, err error) error {
+	if isZoneNotFound(err) {
+		c.zoneCache.invalidate(accountID, "stale_zone_id")
+	}
+	return err
+}
+
+func isZoneNotFound(err error) bool {
+	var cfErr interface{ InternalErrorCodeIs(code int) bool }
+	if !errors.As(err, &cfErr) {
+		return false
+	}
+	return cfErr.InternalErrorCodeIs(errCodeInvalidZoneIdentifier) ||
+		cfErr.InternalErrorCodeIs(errCodeInvalidObjectIdentifier)
+}
+
+func zoneIDOrAmbiguous(zoneID string) (string, error) {
+	if zoneID == "" {
+		return "", errAmbiguousZone
+	}
+	return zoneID, nil
+}
+
+func normalizeZoneName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if n, err := idna.ToUnicode(name); err == nil {
+		return n
+	}
+	return name
+}
@@ -0,0 +1,158 @@
+package cloudflare
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/isac322/cloudflared-operator/internal/cloudflare/fake"
+)
+
+const (
+	testAccountID    = "account"
+	testZoneCacheTTL = time.Hour
+)
+
+// newTestClient does not retry, because retries wait for clk which the tests step explicitly.
+func newTestClient(g Gomega, server *fake.Server, clk clock.Clock) client {
+	limiters := newAccountLimiters(1000, 1000)
+	c, err := newClient(
+		"token",
+		server.URL+"/client/v4",
+		newHTTPClient(limiters, 0, clk),
+		limiters,
+		newZoneCache(testZoneCacheTTL, clk),
+	)
+	g.Expect(err).NotTo(HaveOccurred())
+	return c
+}
+
+func countZoneLists(server *fake.Server) int {
+	return len(server.Requests("GET", "^/zones$"))
+}
+
+func TestGetZoneIDFromDomain(t *testing.T) {
+	t.Parallel()
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	example := server.AddZone(testAccountID, "example.com")
+	dev := server.AddZone(testAccountID, "dev.example.com")
+	uk := server.AddZone(testAccountID, "example.co.uk")
+	idn := server.AddZone(testAccountID, "xn--3e0b707e.kr")
+	server.AddZone(testAccountID, "twice.com")
+	server.AddZone(testAccountID, "twice.com")
+	server.AddZone("other", "example.org")
+
+	tests := map[string]struct {
+		domain string
+		zoneID string
+		err    error
+	}{
+		"subdomain":                {domain: "www.example.com", zoneID: example.ID},
+		"apex":                     {domain: "example.com", zoneID: example.ID},
+		"trailing dot and case":    {domain: "WWW.Example.COM.", zoneID: example.ID},
+		"delegated subzone":        {domain: "api.dev.example.com", zoneID: dev.ID},
+		"apex of delegated":        {domain: "dev.example.com", zoneID: dev.ID},
+		"deep subdomain":           {domain: "a.b.c.example.co.uk", zoneID: uk.ID},
+		"unicode hostname":         {domain: "www.한국.kr", zoneID: idn.ID},
+		"punycode hostname":        {domain: "www.xn--3e0b707e.kr", zoneID: idn.ID},
+		"suffix not on label":      {domain: "notexample.com", err: errZoneNotFound},
+		"zone of another account":  {domain: "www.example.org", err: errZoneNotFound},
+		"public suffix only":       {domain: "co.uk", err: errZoneNotFound},
+		"zones of the same name":   {domain: "www.twice.com", err: errAmbiguousZone},
+		"unknown top level domain": {domain: "example.invalid", err: errZoneNotFound},
+	}
+	c := newTestClient(NewWithT(t), server, clocktesting.NewFakeClock(time.Now()))
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			zoneID, err := c.getZoneIDFromDomain(context.Background(), testAccountID, tc.domain)
+			if tc.err != nil {
+				g.Expect(err).To(MatchError(tc.err))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(zoneID).To(Equal(tc.zoneID))
+		})
+	}
+}
+
+func TestZoneCacheExpires(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	zone := server.AddZone(testAccountID, "example.com")
+	clk := clocktesting.NewFakeClock(time.Now())
+	c := newTestClient(g, server, clk)
+
+	for i := 0; i < 3; i++ {
+		g.Expect(c.getZoneIDFromDomain(context.Background(), testAccountID, "www.example.com")).To(Equal(zone.ID))
+	}
+	g.Expect(countZoneLists(server)).To(Equal(1))
+
+	clk.Step(testZoneCacheTTL)
+	g.Expect(c.getZoneIDFromDomain(context.Background(), testAccountID, "www.example.com")).To(Equal(zone.ID))
+	g.Expect(countZoneLists(server)).To(Equal(2))
+}
+
+func TestZoneCacheInvalidatesGoneZone(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	zone := server.AddZone(testAccountID, "example.com")
+	c := newTestClient(g, server, clocktesting.NewFakeClock(time.Now()))
+
+	g.Expect(c.getZoneIDFromDomain(context.Background(), testAccountID, "www.example.com")).To(Equal(zone.ID))
+
+	// the zone is re-added with a new ID
+	server.RemoveZone(zone.ID)
+	readded := server.AddZone(testAccountID, "example.com")
+	err := c.DeleteDNSRecord(context.Background(), testAccountID, "www.example.com", ZoneRef{})
+	g.Expect(isZoneNotFound(err)).To(BeTrue())
+
+	g.Expect(c.getZoneIDFromDomain(context.Background(), testAccountID, "www.example.com")).To(Equal(readded.ID))
+	g.Expect(countZoneLists(server)).To(Equal(2))
+}
+
+func TestZoneCacheBoundsRefreshesOfMissingZones(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	server.AddZone(testAccountID, "example.com")
+	clk := clocktesting.NewFakeClock(time.Now())
+	c := newTestClient(g, server, clk)
+
+	// the list fetched on miss is fresh enough, so it is not fetched again
+	_, err := c.getZoneIDFromDomain(context.Background(), testAccountID, "www.example.org")
+	g.Expect(err).To(MatchError(errZoneNotFound))
+	g.Expect(countZoneLists(server)).To(Equal(1))
+
+	for i := 0; i < 3; i++ {
+		clk.Step(10 * time.Second)
+		_, err = c.getZoneIDFromName(context.Background(), testAccountID, "example.org")
+		g.Expect(err).To(MatchError(errZoneNotFound))
+	}
+	g.Expect(countZoneLists(server)).To(Equal(1))
+
+	// zones added after the list was cached are found once the interval passes
+	zone := server.AddZone(testAccountID, "example.org")
+	clk.Step(maxZoneRefreshInterval)
+	g.Expect(c.getZoneIDFromDomain(context.Background(), testAccountID, "www.example.org")).To(Equal(zone.ID))
+	g.Expect(countZoneLists(server)).To(Equal(2))
+	g.Expect(c.getZoneIDFromDomain(context.Background(), testAccountID, "example.com")).NotTo(BeEmpty())
+	g.Expect(countZoneLists(server)).To(Equal(2))
+}
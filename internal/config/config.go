@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/clock"
+
+	"github.com/isac322/cloudflared-operator/api/config/v1alpha1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+var codecs = func() serializer.CodecFactory {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	return serializer.NewCodecFactory(scheme, serializer.EnableStrict)
+}()
+
+// Default returns the configuration used when no file is given.
+func Default() *v1alpha1.ControllerConfiguration {
+	cfg := &v1alpha1.ControllerConfiguration{}
+	cfg.Default()
+	return cfg
+}
+
+// Load reads ControllerConfiguration from the file, fills defaults and validates it.
+// Unknown fields are rejected, so that typos do not silently fall back to defaults.
+// Empty path returns Default.
+func Load(path string) (*v1alpha1.ControllerConfiguration, error) {
+	if path == "" {
+		return Default(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read controller configuration: %w", err)
+	}
+
+	cfg := &v1alpha1.ControllerConfiguration{}
+	if err = runtime.DecodeInto(codecs.UniversalDecoder(v1alpha1.GroupVersion), data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode controller configuration %s: %w", path, err)
+	}
+	cfg.Default()
+
+	if err = Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid controller configuration %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks a defaulted configuration.
+func Validate(cfg *v1alpha1.ControllerConfiguration) error {
+	var errs []error
+
+	for _, ns := range cfg.WatchNamespaces {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			errs = append(errs, fmt.Errorf("watchNamespaces: %q: %s", ns, msg))
+		}
+	}
+	for _, id := range cfg.AllowedAccountIDs {
+		if id == "" {
+			errs = append(errs, errors.New("allowedAccountIDs: empty account ID"))
+		}
+	}
+
+	if cfg.Cloudflare.BaseURL != "" {
+		if err := validateURL(cfg.Cloudflare.BaseURL); err != nil {
+			errs = append(errs, fmt.Errorf("cloudflare.baseURL: %w", err))
+		}
+	}
+	if cfg.Cloudflare.RequestsPerSecond < 0 {
+		errs = append(errs, errors.New("cloudflare.requestsPerSecond: must be positive"))
+	}
+	if cfg.Cloudflare.RequestBurst < 0 {
+		errs = append(errs, errors.New("cloudflare.requestBurst: must be positive"))
+	}
+	if *cfg.Cloudflare.MaxRetries < 0 {
+		errs = append(errs, errors.New("cloudflare.maxRetries: must not be negative"))
+	}
+	if cfg.Cloudflare.ZoneCacheTTL.Duration < 0 {
+		errs = append(errs, errors.New("cloudflare.zoneCacheTTL: must not be negative"))
+	}
+
+	resolver := cfg.Daemon.VersionResolver
+	switch resolver.Type {
+	case v1alpha1.DaemonVersionResolverGitHub:
+		if err := validateURL(resolver.ReleasesURL); err != nil {
+			errs = append(errs, fmt.Errorf("daemon.versionResolver.releasesURL: %w", err))
+		}
+	case v1alpha1.DaemonVersionResolverOCI:
+	case v1alpha1.DaemonVersionResolverStatic:
+		if len(resolver.Versions) == 0 {
+			errs = append(errs, errors.New("daemon.versionResolver.versions: required by Static resolver"))
+		}
+		if cfg.Daemon.DefaultVersion != "latest" && !slices.Contains(resolver.Versions, cfg.Daemon.DefaultVersion) {
+			errs = append(errs, fmt.Errorf(
+				"daemon.defaultVersion: %q is not in daemon.versionResolver.versions", cfg.Daemon.DefaultVersion,
+			))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("daemon.versionResolver.type: unknown type %q", resolver.Type))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("host is required")
+	}
+	return nil
+}
+
+// ClientOptions converts Cloudflare API configuration to options of cloudflare.ClientPool.
+func ClientOptions(cfg *v1alpha1.ControllerConfiguration) cloudflare.ClientOptions {
+	return cloudflare.ClientOptions{
+		ZoneCacheTTL:      cfg.Cloudflare.ZoneCacheTTL.Duration,
+		RequestsPerSecond: cfg.Cloudflare.RequestsPerSecond,
+		RequestBurst:      cfg.Cloudflare.RequestBurst,
+		MaxRetries:        *cfg.Cloudflare.MaxRetries,
+		BaseURL:           cfg.Cloudflare.BaseURL,
+		AllowedAccountIDs: cfg.AllowedAccountIDs,
+	}
+}
+
+// DaemonVersionResolver creates the resolver of cloudflared versions selected by the configuration.
+func DaemonVersionResolver(cfg *v1alpha1.ControllerConfiguration, clk clock.PassiveClock) cloudflare.DaemonVersionResolver {
+	resolver := cfg.Daemon.VersionResolver
+	switch resolver.Type {
+	case v1alpha1.DaemonVersionResolverOCI:
+		return cloudflare.NewOCIRegistryTags(resolver.Repository, resolver.PlainHTTP, clk)
+	case v1alpha1.DaemonVersionResolverStatic:
+		return cloudflare.NewStaticDaemonVersions(resolver.Versions)
+	default:
+		return cloudflare.NewGitHubReleases(resolver.ReleasesURL, clk)
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const (
+	accessServiceTokenFinalizerName = "accessservicetoken.cloudflared-operator.bhyoo.com/finalizer"
+
+	serviceTokenEventReasonLeaked = "LeakedToken"
+)
+
+// AccessServiceTokenReconciler reconciles a AccessServiceToken object
+type AccessServiceTokenReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Clock  clock.PassiveClock
+	// Recorder warns about service tokens left on Cloudflare
+	Recorder record.EventRecorder
+
+	CloudflareClients *cloudflare.ClientPool
+}
+
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=accessservicetokens,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=accessservicetokens/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=accessservicetokens/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnels,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
+func (r *AccessServiceTokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx).WithValues("accessServiceTokenName", req.Name)
+	ctx = log.IntoContext(ctx, l)
+
+	var token v1.AccessServiceToken
+	if err := r.Get(ctx, req.NamespacedName, &token); err != nil {
+		l.Error(err, "unable to fetch AccessServiceToken")
+		// we'll ignore not-found errors, since they can't be fixed by an immediate
+		// requeue (we'll need to wait for a new notification), and we can get them
+		// on deleted requests.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// examine DeletionTimestamp to determine if object is under deletion
+	if !token.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&token, accessServiceTokenFinalizerName) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.deleteAccessServiceToken(ctx, &token); err != nil {
+			// if fail to delete the external dependency here, return with error
+			// so that it can be retried.
+			return ctrl.Result{}, err
+		}
+
+		if controllerutil.RemoveFinalizer(&token, accessServiceTokenFinalizerName) {
+			return ctrl.Result{}, r.Update(ctx, &token)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// The object is not being deleted, so if it does not have our finalizer,
+	// then lets add the finalizer and update the object. This is equivalent
+	// to registering our finalizer.
+	if !controllerutil.ContainsFinalizer(&token, accessServiceTokenFinalizerName) {
+		controllerutil.AddFinalizer(&token, accessServiceTokenFinalizerName)
+		if err := r.Update(ctx, &token); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch token.Spec.TunnelRef.Kind {
+	case v1.TunnelKindTunnel:
+		tunnel, err := r.getTunnelFromToken(ctx, &token)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// Tunnel is not found, we'll wait for it to be created
+				return ctrl.Result{}, client.IgnoreNotFound(err)
+			}
+			l.Error(err, "unable to fetch Tunnel")
+			return ctrl.Result{}, err
+		}
+
+		secret, issued, err := r.reconcileToken(ctx, &token, tunnel)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err = r.reconcileTokenSecret(ctx, &token, secret, issued); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// wake up again when the token should be renewed
+		return ctrl.Result{RequeueAfter: r.untilRenewal(&token)}, nil
+
+	default:
+		return ctrl.Result{}, errors.New("unsupported tunnel type")
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AccessServiceTokenReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctx := context.Background()
+
+	if err := mgr.GetFieldIndexer().IndexField(
+		ctx,
+		&v1.AccessServiceToken{},
+		tunnelRefNameField,
+		indexTokenTunnelRefName,
+	); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(
+		ctx,
+		&v1.AccessServiceToken{},
+		tunnelRefKindField,
+		indexTokenTunnelRefKind,
+	); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.AccessServiceToken{}).
+		Owns(&corev1.Secret{}).
+		Watches(
+			&v1.Tunnel{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForTunnel),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Complete(requeueOnRateLimit(r))
+}
+
+// findObjectsForTunnel enqueues AccessServiceTokens waiting for the Tunnel to be created,
+// or whose account changes with the Tunnel.
+func (r *AccessServiceTokenReconciler) findObjectsForTunnel(
+	ctx context.Context,
+	tunnel client.Object,
+) []reconcile.Request {
+	var tokens v1.AccessServiceTokenList
+	if err := r.List(
+		ctx,
+		&tokens,
+		client.MatchingFields{tunnelRefNameField: tunnel.GetName(), tunnelRefKindField: string(v1.TunnelKindTunnel)},
+		client.InNamespace(tunnel.GetNamespace()),
+	); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing access service tokens matched with tunnel name")
+		return []reconcile.Request{}
+	}
+
+	requests := make([]reconcile.Request, len(tokens.Items))
+	for i, item := range tokens.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		}
+	}
+	return requests
+}
+
+func (r *AccessServiceTokenReconciler) buildConditionRecorder(
+	ctx context.Context,
+	token *v1.AccessServiceToken,
+	condType v1.AccessServiceTokenConditionType,
+) func(err error) error {
+	return func(err error) (cause error) {
+		defer func() {
+			if errors.Is(err, reconcile.TerminalError(nil)) &&
+				!errors.Is(cause, reconcile.TerminalError(nil)) {
+				cause = reconcile.TerminalError(cause)
+			}
+		}()
+
+		cause = err
+		var reason v1.AccessServiceTokenConditionReason = ""
+		var withReason ReasonedError[v1.AccessServiceTokenConditionReason]
+		if errors.As(err, &withReason) {
+			cause = withReason.Cause()
+			reason = withReason.Reason
+		}
+
+		newCond := v1.AccessServiceTokenStatusCondition{
+			Type:               condType,
+			Status:             corev1.ConditionFalse,
+			Message:            "",
+			Error:              fmt.Sprintf("%+v", cause),
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             reason,
+		}
+
+		if status, ok := cause.(apierrors.APIStatus); ok || errors.As(cause, &status) {
+			newCond.Error = string(status.Status().Reason)
+			newCond.Message = status.Status().Message
+		}
+
+		if !UpdateConditionIfChanged(&token.Status, newCond) {
+			return cause
+		}
+
+		if updateErr := r.Status().Update(ctx, token); updateErr != nil {
+			return errors.Join(cause, updateErr)
+		}
+		return cause
+	}
+}
+
+func (r *AccessServiceTokenReconciler) getTunnelFromToken(
+	ctx context.Context,
+	token *v1.AccessServiceToken,
+) (*v1.Tunnel, error) {
+	var tunnel v1.Tunnel
+	err := r.Get(ctx, client.ObjectKey{Namespace: token.GetNamespace(), Name: token.Spec.TunnelRef.Name}, &tunnel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tunnel, nil
+}
+
+func indexTokenTunnelRefName(rawObj client.Object) []string {
+	token := rawObj.(*v1.AccessServiceToken)
+	if token.Spec.TunnelRef.Name == "" {
+		return nil
+	}
+	return []string{token.Spec.TunnelRef.Name}
+}
+
+func indexTokenTunnelRefKind(rawObj client.Object) []string {
+	token := rawObj.(*v1.AccessServiceToken)
+	if token.Spec.TunnelRef.Kind == "" {
+		return nil
+	}
+	return []string{string(token.Spec.TunnelRef.Kind)}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func (r *AccessServiceTokenReconciler) deleteAccessServiceToken(
+	ctx context.Context,
+	token *v1.AccessServiceToken,
+) error {
+	l := log.FromContext(ctx)
+
+	if token.Status.TokenID == "" {
+		return nil
+	}
+
+	var tunnel *v1.Tunnel
+	switch token.Spec.TunnelRef.Kind {
+	case v1.TunnelKindTunnel:
+		var err error
+		tunnel, err = r.getTunnelFromToken(ctx, token)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// credentials of the service token are only known through the Tunnel
+				r.recordLeakedToken(token, err)
+				return nil
+			}
+			l.Error(err, "unable to fetch Tunnel")
+			return err
+		}
+
+	default:
+		return errors.New("unsupported tunnel type")
+	}
+
+	cfClient, err := newCloudflareClientForTunnel(
+		ctx,
+		r,
+		r.CloudflareClients,
+		tunnel,
+		v1.ServiceTokenReasonNoToken,
+		v1.ServiceTokenReasonFailedToConnectCF,
+		v1.ServiceTokenReasonAccountNotAllowed,
+	)
+	if err != nil {
+		if isUnusableAccountError(err) {
+			r.recordLeakedToken(token, err)
+			return nil
+		}
+		return err
+	}
+
+	return cfClient.DeleteAccessServiceToken(ctx, token.Status.AccountID, token.Status.TokenID)
+}
+
+// recordLeakedToken warns that the service token is left on Cloudflare, and has to be deleted manually.
+func (r *AccessServiceTokenReconciler) recordLeakedToken(token *v1.AccessServiceToken, cause error) {
+	r.Recorder.Eventf(token, corev1.EventTypeWarning, serviceTokenEventReasonLeaked,
+		"service token %s of account %s is left on Cloudflare and must be deleted manually: %v",
+		token.Status.TokenID, token.Status.AccountID, cause)
+}
@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare/fake"
+)
+
+// newTestTokenReconciler returns a reconciler of AccessServiceToken against server,
+// whose tokens refer a Tunnel named sample in namespace apps of testIngressAccountID.
+func newTestTokenReconciler(g Gomega, server *fake.Server, objects ...client.Object) *AccessServiceTokenReconciler {
+	opts := cloudflare.DefaultClientOptions()
+	opts.BaseURL = server.URL + "/client/v4"
+	opts.MaxRetries = 0
+
+	tunnel := newTestTunnel()
+	tunnel.Namespace = "apps"
+	tunnel.Spec.AccountID = testIngressAccountID
+	tunnel.Spec.APITokenSecretRef = v1.SecretKeyRef{Name: "token"}
+
+	base := newTestReconciler(g)
+	c := ctrlfake.NewClientBuilder().
+		WithScheme(base.Scheme).
+		WithObjects(newTestNamespace("apps"), tunnel).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "apps"},
+			Data:       map[string][]byte{apiTokenKey: []byte("token")},
+		}).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1.AccessServiceToken{}).
+		Build()
+
+	return &AccessServiceTokenReconciler{
+		Client:            c,
+		Scheme:            base.Scheme,
+		Clock:             clock.RealClock{},
+		Recorder:          record.NewFakeRecorder(10),
+		CloudflareClients: cloudflare.NewClientPool(opts, clock.RealClock{}),
+	}
+}
+
+func newTestAccessServiceToken() *v1.AccessServiceToken {
+	return &v1.AccessServiceToken{
+		ObjectMeta: metav1.ObjectMeta{Name: "ci", Namespace: "apps"},
+		Spec: v1.AccessServiceTokenSpec{
+			Name:      "ci",
+			TunnelRef: v1.TunnelRef{Kind: v1.TunnelKindTunnel, Name: "sample"},
+		},
+	}
+}
+
+// reconcileTestToken reconciles token and returns it and its Secret as stored afterward.
+func reconcileTestToken(
+	g Gomega,
+	r *AccessServiceTokenReconciler,
+	token *v1.AccessServiceToken,
+) (*v1.AccessServiceToken, *corev1.Secret) {
+	ctx := context.Background()
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(token)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var stored v1.AccessServiceToken
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(token), &stored)).To(Succeed())
+	var secret corev1.Secret
+	g.Expect(r.Get(
+		ctx,
+		client.ObjectKey{Namespace: token.Namespace, Name: token.Spec.TokenSecretName()},
+		&secret,
+	)).To(Succeed())
+	return &stored, &secret
+}
+
+func TestAccessServiceTokenCreatesToken(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	r := newTestTokenReconciler(g, server, newTestAccessServiceToken())
+
+	token, secret := reconcileTestToken(g, r, newTestAccessServiceToken())
+
+	created, ok := server.ServiceToken(token.Status.TokenID)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(created.AccountID).To(Equal(testIngressAccountID))
+	g.Expect(created.Name).To(Equal("ci"))
+	g.Expect(token.Status.AccountID).To(Equal(testIngressAccountID))
+	g.Expect(token.Status.ClientID).To(Equal(created.ClientID))
+	g.Expect(token.Status.PendingSince).To(BeNil())
+	g.Expect(token.Finalizers).To(ContainElement(accessServiceTokenFinalizerName))
+	g.Expect(secret.Data).To(HaveKeyWithValue(serviceTokenClientIDKey, []byte(created.ClientID)))
+	g.Expect(secret.Data).To(HaveKeyWithValue(serviceTokenClientSecretKey, []byte(created.ClientSecret)))
+
+	// nothing is issued again once the Secret is in place
+	_, _ = reconcileTestToken(g, r, token)
+	g.Expect(server.ServiceTokens(testIngressAccountID)).To(HaveLen(1))
+}
+
+func TestAccessServiceTokenAdoptsTokenOfLostStatus(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+
+	// the token was created, but saving its ID failed
+	token := newTestAccessServiceToken()
+	token.Finalizers = []string{accessServiceTokenFinalizerName}
+	token.Status.AccountID = testIngressAccountID
+	token.Status.PendingSince = &metav1.Time{Time: time.Now().Add(-time.Second)}
+	leaked := server.AddServiceToken(fake.ServiceToken{AccountID: testIngressAccountID, Name: "ci"})
+	// tokens of the same name created before are not ours
+	server.AddServiceToken(fake.ServiceToken{
+		AccountID: testIngressAccountID,
+		Name:      "ci",
+		CreatedAt: time.Now().Add(-time.Hour),
+	})
+	r := newTestTokenReconciler(g, server, token)
+
+	token, secret := reconcileTestToken(g, r, token)
+
+	g.Expect(server.ServiceTokens(testIngressAccountID)).To(HaveLen(2))
+	g.Expect(token.Status.TokenID).To(Equal(leaked.ID))
+	g.Expect(token.Status.PendingSince).To(BeNil())
+	rotated, _ := server.ServiceToken(leaked.ID)
+	g.Expect(rotated.ClientSecret).NotTo(Equal(leaked.ClientSecret))
+	g.Expect(secret.Data).To(HaveKeyWithValue(serviceTokenClientSecretKey, []byte(rotated.ClientSecret)))
+}
+
+func TestAccessServiceTokenRotatesOnLostSecret(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	r := newTestTokenReconciler(g, server, newTestAccessServiceToken())
+
+	token, secret := reconcileTestToken(g, r, newTestAccessServiceToken())
+	issued, _ := server.ServiceToken(token.Status.TokenID)
+	g.Expect(r.Delete(ctx, secret)).To(Succeed())
+
+	rotatedToken, secret := reconcileTestToken(g, r, token)
+
+	rotated, _ := server.ServiceToken(token.Status.TokenID)
+	g.Expect(rotatedToken.Status.TokenID).To(Equal(issued.ID))
+	g.Expect(rotated.ClientSecret).NotTo(Equal(issued.ClientSecret))
+	g.Expect(secret.Data).To(HaveKeyWithValue(serviceTokenClientSecretKey, []byte(rotated.ClientSecret)))
+	g.Expect(server.ServiceTokens(testIngressAccountID)).To(HaveLen(1))
+}
+
+func TestAccessServiceTokenRenewsToken(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	r := newTestTokenReconciler(g, server, newTestAccessServiceToken())
+
+	token, secret := reconcileTestToken(g, r, newTestAccessServiceToken())
+	// pretends the token is about to expire
+	token.Status.ExpiresAt = &metav1.Time{Time: time.Now().Add(time.Hour)}
+	g.Expect(r.Status().Update(ctx, token)).To(Succeed())
+
+	renewed, renewedSecret := reconcileTestToken(g, r, token)
+
+	g.Expect(server.Requests("POST", "/access/service_tokens/"+token.Status.TokenID+"/refresh$")).To(HaveLen(1))
+	g.Expect(renewed.Status.TokenID).To(Equal(token.Status.TokenID))
+	g.Expect(renewed.Status.ExpiresAt.Time).To(BeTemporally(">", time.Now().Add(defaultServiceTokenDuration/2)))
+	// the client secret does not change by renewal
+	g.Expect(renewedSecret.Data).To(Equal(secret.Data))
+}
+
+func TestAccessServiceTokenDeletesTokenOfPreviousAccount(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	r := newTestTokenReconciler(g, server, newTestAccessServiceToken())
+
+	token, _ := reconcileTestToken(g, r, newTestAccessServiceToken())
+	previousID := token.Status.TokenID
+
+	var tunnel v1.Tunnel
+	g.Expect(r.Get(ctx, client.ObjectKey{Namespace: "apps", Name: "sample"}, &tunnel)).To(Succeed())
+	tunnel.Spec.AccountID = "other-account"
+	g.Expect(r.Update(ctx, &tunnel)).To(Succeed())
+
+	token, _ = reconcileTestToken(g, r, token)
+
+	_, ok := server.ServiceToken(previousID)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(token.Status.AccountID).To(Equal("other-account"))
+	g.Expect(server.ServiceTokens("other-account")).To(ConsistOf(
+		HaveField("ID", token.Status.TokenID),
+	))
+}
+
+func TestAccessServiceTokenDeletesToken(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		deleteTunnel bool
+	}{
+		"with tunnel":    {},
+		"tunnel is gone": {deleteTunnel: true},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			server := fake.NewServer()
+			t.Cleanup(server.Close)
+			r := newTestTokenReconciler(g, server, newTestAccessServiceToken())
+			recorder := r.Recorder.(*record.FakeRecorder)
+
+			token, _ := reconcileTestToken(g, r, newTestAccessServiceToken())
+			if tc.deleteTunnel {
+				g.Expect(r.Delete(ctx, &v1.Tunnel{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "sample"}})).
+					To(Succeed())
+			}
+			g.Expect(r.Delete(ctx, token)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(token)})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(r.Get(ctx, client.ObjectKeyFromObject(token), token)).NotTo(Succeed())
+
+			_, left := server.ServiceToken(token.Status.TokenID)
+			g.Expect(left).To(Equal(tc.deleteTunnel))
+			if tc.deleteTunnel {
+				g.Expect(recorder.Events).To(Receive(And(
+					ContainSubstring(serviceTokenEventReasonLeaked),
+					ContainSubstring(token.Status.TokenID),
+				)))
+			} else {
+				g.Expect(recorder.Events).NotTo(Receive())
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+var errNotFoundClientSecret = errors.New("client secret is lost and no new one is issued")
+
+const (
+	serviceTokenClientIDKey     = "client-id"
+	serviceTokenClientSecretKey = "client-secret"
+
+	defaultServiceTokenDuration    = 365 * 24 * time.Hour
+	defaultServiceTokenRenewBefore = 30 * 24 * time.Hour
+	minServiceTokenRenewInterval   = time.Minute
+	maxServiceTokenClockSkew       = time.Minute
+)
+
+func (r *AccessServiceTokenReconciler) reconcileToken(
+	ctx context.Context,
+	token *v1.AccessServiceToken,
+	tunnel *v1.Tunnel,
+) (*corev1.Secret, cloudflare.AccessServiceToken, error) {
+	l := log.FromContext(ctx)
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, token, v1.AccessServiceTokenConditionTypeToken)
+
+	cfClient, err := newCloudflareClientForTunnel(
+		ctx,
+		r,
+		r.CloudflareClients,
+		tunnel,
+		v1.ServiceTokenReasonNoToken,
+		v1.ServiceTokenReasonFailedToConnectCF,
+		v1.ServiceTokenReasonAccountNotAllowed,
+	)
+	if err != nil {
+		return nil, cloudflare.AccessServiceToken{}, recordConditionFrom(err)
+	}
+
+	secret, err := r.getTokenSecret(ctx, token)
+	if err != nil {
+		return nil, cloudflare.AccessServiceToken{}, recordConditionFrom(
+			WrapError(err, v1.ServiceTokenSecretReasonFailedToGetExistingSecret),
+		)
+	}
+
+	accountID := tunnel.Spec.AccountID
+	var issued cloudflare.AccessServiceToken
+	switch {
+	case token.Status.TokenID == "" || token.Status.AccountID != accountID:
+		if token.Status.TokenID != "" {
+			// the Tunnel moved to another account, so the token of the previous account would never be used again
+			if err := r.deleteReplacedToken(ctx, cfClient, token); err != nil {
+				return nil, cloudflare.AccessServiceToken{}, recordConditionFrom(err)
+			}
+		}
+		issued, err = r.issueToken(ctx, cfClient, accountID, token)
+		if err != nil {
+			return nil, cloudflare.AccessServiceToken{}, recordConditionFrom(err)
+		}
+
+	case !hasServiceTokenKeys(secret):
+		// Cloudflare returns the client secret only once, so a lost Secret can only be recovered by rotation.
+		l.Info("client secret is missing. rotating service token...")
+		issued, err = cfClient.RotateAccessServiceToken(ctx, accountID, token.Status.TokenID)
+		if cloudflare.IsNotFound(err) {
+			issued, err = r.issueToken(ctx, cfClient, accountID, token)
+		} else if err != nil {
+			err = WrapError(err, v1.ServiceTokenReasonFailedToRotateToken)
+		}
+		if err != nil {
+			return nil, cloudflare.AccessServiceToken{}, recordConditionFrom(err)
+		}
+
+	case !r.Clock.Now().Before(renewalTime(token)):
+		l.Info("service token is about to expire. renewing it...")
+		issued, err = cfClient.RefreshAccessServiceToken(ctx, accountID, token.Status.TokenID)
+		if cloudflare.IsNotFound(err) {
+			issued, err = r.issueToken(ctx, cfClient, accountID, token)
+		} else if err != nil {
+			err = WrapError(err, v1.ServiceTokenReasonFailedToRenewToken)
+		}
+		if err != nil {
+			return nil, cloudflare.AccessServiceToken{}, recordConditionFrom(err)
+		}
+	}
+
+	var dirtyStatus bool
+	if issued.ID != "" {
+		dirtyStatus = true
+		token.Status.TokenID = issued.ID
+		token.Status.AccountID = accountID
+		token.Status.ClientID = issued.ClientID
+		token.Status.ExpiresAt = &metav1.Time{Time: issued.ExpiresAt}
+		token.Status.PendingSince = nil
+	}
+
+	if UpdateConditionIfChanged(&token.Status, v1.AccessServiceTokenStatusCondition{
+		Type:               v1.AccessServiceTokenConditionTypeToken,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	}) {
+		dirtyStatus = true
+	}
+
+	if dirtyStatus {
+		return secret, issued, r.Status().Update(ctx, token)
+	}
+	return secret, issued, nil
+}
+
+// issueToken creates a service token in the account.
+// The creation is marked in status beforehand, so that a token whose ID failed to be saved is adopted
+// on the next reconciliation instead of leaking on the account.
+func (r *AccessServiceTokenReconciler) issueToken(
+	ctx context.Context,
+	cfClient cloudflare.Client,
+	accountID string,
+	token *v1.AccessServiceToken,
+) (cloudflare.AccessServiceToken, error) {
+	l := log.FromContext(ctx)
+
+	if token.Status.PendingSince != nil && token.Status.AccountID == accountID {
+		pending, err := r.findPendingToken(ctx, cfClient, accountID, token)
+		if err != nil {
+			return cloudflare.AccessServiceToken{}, WrapError(err, v1.ServiceTokenReasonFailedToCreateToken)
+		}
+		if pending != nil {
+			// Cloudflare never returns the client secret of an existing token, so it has to be rotated
+			l.Info("adopting service token created by previous reconciliation", "tokenID", pending.ID)
+			issued, err := cfClient.RotateAccessServiceToken(ctx, accountID, pending.ID)
+			if err != nil {
+				return cloudflare.AccessServiceToken{}, WrapError(err, v1.ServiceTokenReasonFailedToRotateToken)
+			}
+			return issued, nil
+		}
+	} else {
+		token.Status.TokenID = ""
+		token.Status.AccountID = accountID
+		token.Status.ClientID = ""
+		token.Status.ExpiresAt = nil
+		token.Status.PendingSince = &metav1.Time{Time: r.Clock.Now()}
+		UpdateConditionIfChanged(&token.Status, v1.AccessServiceTokenStatusCondition{
+			Type:               v1.AccessServiceTokenConditionTypeToken,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.ServiceTokenReasonCreating,
+		})
+		if err := r.Status().Update(ctx, token); err != nil {
+			return cloudflare.AccessServiceToken{}, err
+		}
+	}
+
+	return r.createToken(ctx, cfClient, accountID, token)
+}
+
+// findPendingToken returns the latest service token of the same name created since status.pendingSince,
+// or nil if there is none.
+func (r *AccessServiceTokenReconciler) findPendingToken(
+	ctx context.Context,
+	cfClient cloudflare.Client,
+	accountID string,
+	token *v1.AccessServiceToken,
+) (*cloudflare.AccessServiceToken, error) {
+	candidates, err := cfClient.FindAccessServiceTokens(ctx, accountID, token.Spec.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// tolerates the clock of the cluster running ahead of Cloudflare
+	since := token.Status.PendingSince.Add(-maxServiceTokenClockSkew)
+	var found *cloudflare.AccessServiceToken
+	for i := range candidates {
+		if candidates[i].CreatedAt.Before(since) {
+			continue
+		}
+		if found == nil || candidates[i].CreatedAt.After(found.CreatedAt) {
+			found = &candidates[i]
+		}
+	}
+	return found, nil
+}
+
+func (r *AccessServiceTokenReconciler) createToken(
+	ctx context.Context,
+	cfClient cloudflare.Client,
+	accountID string,
+	token *v1.AccessServiceToken,
+) (cloudflare.AccessServiceToken, error) {
+	duration := defaultServiceTokenDuration
+	if token.Spec.Duration != nil {
+		duration = token.Spec.Duration.Duration
+	}
+
+	issued, err := cfClient.CreateAccessServiceToken(ctx, accountID, token.Spec.Name, duration)
+	if err != nil {
+		return cloudflare.AccessServiceToken{}, WrapError(err, v1.ServiceTokenReasonFailedToCreateToken)
+	}
+	return issued, nil
+}
+
+// deleteReplacedToken deletes the service token recorded in status, which belongs to the previous account.
+// The API token may have no access to the previous account, so permanent failures are reported as an event
+// instead of blocking the new token forever.
+func (r *AccessServiceTokenReconciler) deleteReplacedToken(
+	ctx context.Context,
+	cfClient cloudflare.Client,
+	token *v1.AccessServiceToken,
+) error {
+	l := log.FromContext(ctx)
+
+	err := cfClient.DeleteAccessServiceToken(ctx, token.Status.AccountID, token.Status.TokenID)
+	if err == nil {
+		return nil
+	}
+	if !cloudflare.IsPermanent(err) {
+		return WrapError(err, v1.ServiceTokenReasonFailedToDeleteToken)
+	}
+
+	l.Error(err, "failed to delete service token of previous account", "tokenID", token.Status.TokenID)
+	r.recordLeakedToken(token, err)
+	return nil
+}
+
+// reconcileTokenSecret stores newly issued client ID and secret.
+// Cloudflare never returns the client secret again, so an existing Secret is left untouched otherwise.
+func (r *AccessServiceTokenReconciler) reconcileTokenSecret(
+	ctx context.Context,
+	token *v1.AccessServiceToken,
+	secret *corev1.Secret,
+	issued cloudflare.AccessServiceToken,
+) error {
+	recordConditionFrom := r.buildConditionRecorder(ctx, token, v1.AccessServiceTokenConditionTypeSecret)
+
+	switch {
+	case issued.ClientSecret == "" && secret == nil:
+		return recordConditionFrom(WrapError(errNotFoundClientSecret, v1.ServiceTokenSecretReasonTokenRequired))
+
+	case issued.ClientSecret == "":
+		// nothing to update
+
+	case secret == nil:
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      token.Spec.TokenSecretName(),
+				Namespace: token.Namespace,
+			},
+		}
+		fillTokenSecret(secret, issued)
+		if err := ctrl.SetControllerReference(token, secret, r.Scheme); err != nil {
+			return recordConditionFrom(WrapError(err, v1.ServiceTokenSecretReasonFailedToCreateSecret))
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			return recordConditionFrom(WrapError(err, v1.ServiceTokenSecretReasonFailedToCreateSecret))
+		}
+
+	default:
+		fillTokenSecret(secret, issued)
+		if err := r.Update(ctx, secret); err != nil {
+			return recordConditionFrom(WrapError(err, v1.ServiceTokenSecretReasonFailedToUpdateSecret))
+		}
+	}
+
+	return r.updateConditionIfDiff(ctx, token, v1.AccessServiceTokenStatusCondition{
+		Type:               v1.AccessServiceTokenConditionTypeSecret,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	})
+}
+
+func (r *AccessServiceTokenReconciler) getTokenSecret(
+	ctx context.Context,
+	token *v1.AccessServiceToken,
+) (*corev1.Secret, error) {
+	var secret corev1.Secret
+	err := r.Get(ctx, client.ObjectKey{Namespace: token.Namespace, Name: token.Spec.TokenSecretName()}, &secret)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+func (r *AccessServiceTokenReconciler) updateConditionIfDiff(
+	ctx context.Context,
+	token *v1.AccessServiceToken,
+	cond v1.AccessServiceTokenStatusCondition,
+) error {
+	if UpdateConditionIfChanged(&token.Status, cond) {
+		return r.Status().Update(ctx, token)
+	}
+	return nil
+}
+
+func (r *AccessServiceTokenReconciler) untilRenewal(token *v1.AccessServiceToken) time.Duration {
+	if token.Status.ExpiresAt == nil {
+		return 0
+	}
+	return max(renewalTime(token).Sub(r.Clock.Now()), minServiceTokenRenewInterval)
+}
+
+func renewalTime(token *v1.AccessServiceToken) time.Time {
+	if token.Status.ExpiresAt == nil {
+		return time.Time{}
+	}
+	renewBefore := defaultServiceTokenRenewBefore
+	if token.Spec.RenewBefore != nil {
+		renewBefore = token.Spec.RenewBefore.Duration
+	}
+	return token.Status.ExpiresAt.Add(-renewBefore)
+}
+
+func hasServiceTokenKeys(secret *corev1.Secret) bool {
+	if secret == nil {
+		return false
+	}
+	_, hasID := GetDataFromSecret(secret, serviceTokenClientIDKey)
+	_, hasSecret := GetDataFromSecret(secret, serviceTokenClientSecretKey)
+	return hasID && hasSecret
+}
+
+func fillTokenSecret(secret *corev1.Secret, issued cloudflare.AccessServiceToken) {
+	delete(secret.StringData, serviceTokenClientIDKey)
+	delete(secret.StringData, serviceTokenClientSecretKey)
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte, 2)
+	}
+	secret.Data[serviceTokenClientIDKey] = []byte(issued.ClientID)
+	secret.Data[serviceTokenClientSecretKey] = []byte(issued.ClientSecret)
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+// newCloudflareClientForTunnel resolves API token of the tunnel's account and creates Cloudflare client with it.
+// Errors are wrapped with given reasons, so that each reconciler can record them to its own condition.
+func newCloudflareClientForTunnel[T Reasons](
+	ctx context.Context,
+	reader client.Reader,
+	pool *cloudflare.ClientPool,
+	tunnel *v1.Tunnel,
+	noTokenReason, failedToConnectReason, accountNotAllowedReason T,
+) (cloudflare.Client, error) {
+	return newCloudflareClient(
+		ctx,
+		reader,
+		pool,
+		tunnel.Spec.AccountID,
+		tunnel.Namespace,
+		tunnel.Spec.APITokenSecretRef,
+		noTokenReason,
+		failedToConnectReason,
+		accountNotAllowedReason,
+	)
+}
+
+var errAccountNotAllowed = errors.New("account is not allowed by controller configuration")
+
+// newCloudflareClient reads API token from tokenRef and takes Cloudflare client of it from the pool.
+// tokenRef without namespace is looked up in the given namespace.
+// Accounts outside of the pool's allow-list are refused before the token is even read.
+func newCloudflareClient[T Reasons](
+	ctx context.Context,
+	reader client.Reader,
+	pool *cloudflare.ClientPool,
+	accountID string,
+	namespace string,
+	tokenRef v1.SecretKeyRef,
+	noTokenReason, failedToConnectReason, accountNotAllowedReason T,
+) (cloudflare.Client, error) {
+	l := log.FromContext(ctx)
+
+	if !pool.IsAccountAllowed(accountID) {
+		return nil, reconcile.TerminalError(WrapError(errAccountNotAllowed, accountNotAllowedReason))
+	}
+
+	var secret corev1.Secret
+	if err := reader.Get(
+		ctx,
+		client.ObjectKey{
+			Namespace: ptr.Deref(tokenRef.Namespace, namespace),
+			Name:      tokenRef.Name,
+		},
+		&secret,
+	); err != nil {
+		l.Error(err, "unable to fetch apiTokenSecretRef")
+		err = WrapError(err, noTokenReason)
+		if apierrors.IsNotFound(err) {
+			err = reconcile.TerminalError(err)
+		}
+		return nil, err
+	}
+
+	secretKey := ptr.Deref(tokenRef.Key, apiTokenKey)
+	bytesToken, exists := GetDataFromSecret(&secret, secretKey)
+	if !exists {
+		return nil, WrapError(errNotFoundAPITokenKey, noTokenReason)
+	}
+
+	cli, err := pool.Get(string(bytesToken))
+	if err != nil {
+		l.Error(err, "failed to create Cloudflare client")
+		return nil, WrapError(err, failedToConnectReason)
+	}
+	return cli, nil
+}
+
+// isUnusableAccountError reports whether err means Cloudflare resources of the account can not be managed at all,
+// so that cleanup has nothing to do but to let the object go.
+func isUnusableAccountError(err error) bool {
+	return errors.Is(err, errNotFoundAPITokenKey) || errors.Is(err, errAccountNotAllowed) || apierrors.IsNotFound(err)
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+// cloudflaredConfigVersion is the release of cloudflared that the types below are copied from.
+// TestCloudflaredConfigMatchesUpstream of build tag upstream, which CI runs, compares them with the release.
+const cloudflaredConfigVersion = testDaemonVersion
+
+// cloudflaredConfigFile mirrors configFileSettings of github.com/cloudflare/cloudflared/config,
+// which cloudflared decodes config.yaml into.
+// The module of cloudflared is deliberately not a dependency, even of tests: its releases are not semantic versions
+// and it has replace directives that every module importing it has to copy.
+type cloudflaredConfigFile struct {
+	cloudflaredConfiguration `yaml:",inline"`
+	// keys other than above are read as flags of cloudflared
+	Settings map[string]interface{} `yaml:",inline"`
+}
+
+type cloudflaredConfiguration struct {
+	TunnelID      string                         `yaml:"tunnel"`
+	Ingress       []cloudflaredIngressRule       `yaml:"ingress"`
+	WarpRouting   cloudflaredWarpRoutingConfig   `yaml:"warp-routing"`
+	OriginRequest cloudflaredOriginRequestConfig `yaml:"originRequest"`
+}
+
+type cloudflaredIngressRule struct {
+	Hostname      string                         `yaml:"hostname"`
+	Path          string                         `yaml:"path"`
+	Service       string                         `yaml:"service"`
+	OriginRequest cloudflaredOriginRequestConfig `yaml:"originRequest"`
+}
+
+type cloudflaredWarpRoutingConfig struct {
+	// Enabled is ignored by recent releases, which always route private network traffic when a route exists.
+	Enabled        bool           `yaml:"enabled"`
+	ConnectTimeout *time.Duration `yaml:"connectTimeout"`
+	MaxActiveFlows *uint64        `yaml:"maxActiveFlows"`
+	TCPKeepAlive   *time.Duration `yaml:"tcpKeepAlive"`
+}
+
+type cloudflaredOriginRequestConfig struct {
+	ConnectTimeout         *time.Duration             `yaml:"connectTimeout"`
+	TLSTimeout             *time.Duration             `yaml:"tlsTimeout"`
+	TCPKeepAlive           *time.Duration             `yaml:"tcpKeepAlive"`
+	NoHappyEyeballs        *bool                      `yaml:"noHappyEyeballs"`
+	KeepAliveConnections   *int                       `yaml:"keepAliveConnections"`
+	KeepAliveTimeout       *time.Duration             `yaml:"keepAliveTimeout"`
+	HTTPHostHeader         *string                    `yaml:"httpHostHeader"`
+	OriginServerName       *string                    `yaml:"originServerName"`
+	CAPool                 *string                    `yaml:"caPool"`
+	NoTLSVerify            *bool                      `yaml:"noTLSVerify"`
+	DisableChunkedEncoding *bool                      `yaml:"disableChunkedEncoding"`
+	BastionMode            *bool                      `yaml:"bastionMode"`
+	ProxyAddress           *string                    `yaml:"proxyAddress"`
+	ProxyPort              *uint                      `yaml:"proxyPort"`
+	ProxyType              *string                    `yaml:"proxyType"`
+	IPRules                []cloudflaredIngressIPRule `yaml:"ipRules"`
+	HTTP2Origin            *bool                      `yaml:"http2Origin"`
+	Access                 *cloudflaredAccessConfig   `yaml:"access"`
+}
+
+type cloudflaredIngressIPRule struct {
+	Prefix *string `yaml:"prefix"`
+	Ports  []int   `yaml:"ports"`
+	Allow  bool    `yaml:"allow"`
+}
+
+type cloudflaredAccessConfig struct {
+	Required bool     `yaml:"required"`
+	TeamName string   `yaml:"teamName"`
+	AudTag   []string `yaml:"audTag"`
+}
+
+// cloudflaredFlags are flags of `cloudflared tunnel run` that may be set in config.yaml.
+var cloudflaredFlags = map[string]struct{}{
+	"compression-quality":    {},
+	"credentials-file":       {},
+	"edge-bind-address":      {},
+	"edge-ip-version":        {},
+	"grace-period":           {},
+	"ha-connections":         {},
+	"icmpv4-src":             {},
+	"icmpv6-src":             {},
+	"label":                  {},
+	"log-directory":          {},
+	"logfile":                {},
+	"loglevel":               {},
+	"management-diagnostics": {},
+	"max-fetch-size":         {},
+	"metrics":                {},
+	"no-autoupdate":          {},
+	"pidfile":                {},
+	"post-quantum":           {},
+	"protocol":               {},
+	"region":                 {},
+	"retries":                {},
+	"tag":                    {},
+	"transport-loglevel":     {},
+}
+
+// parseCloudflaredConfig decodes data the way cloudflared does, rejecting fields that cloudflared would warn unknown.
+func parseCloudflaredConfig(g Gomega, data []byte) cloudflaredConfigFile {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var config cloudflaredConfigFile
+	g.Expect(decoder.Decode(&config)).To(Succeed())
+	for key := range config.Settings {
+		g.Expect(cloudflaredFlags).To(HaveKey(key), "unknown flag of cloudflared")
+	}
+	return config
+}
@@ -0,0 +1,155 @@
+//go:build upstream
+
+package controller
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	. "github.com/onsi/gomega"
+
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+// upstreamConfigTypes maps the copied types to the types of github.com/cloudflare/cloudflared/config.
+var upstreamConfigTypes = map[string]reflect.Type{
+	"configFileSettings":     reflect.TypeOf(cloudflaredConfigFile{}),
+	"Configuration":          reflect.TypeOf(cloudflaredConfiguration{}),
+	"UnvalidatedIngressRule": reflect.TypeOf(cloudflaredIngressRule{}),
+	"WarpRoutingConfig":      reflect.TypeOf(cloudflaredWarpRoutingConfig{}),
+	"OriginRequestConfig":    reflect.TypeOf(cloudflaredOriginRequestConfig{}),
+	"IngressIPRule":          reflect.TypeOf(cloudflaredIngressIPRule{}),
+	"AccessConfig":           reflect.TypeOf(cloudflaredAccessConfig{}),
+}
+
+// TestCloudflaredConfigMatchesUpstream fails when yaml keys of the copied types differ from the ones of
+// cloudflaredConfigVersion. It downloads the source of cloudflared, so it runs only with build tag upstream.
+func TestCloudflaredConfigMatchesUpstream(t *testing.T) {
+	g := NewWithT(t)
+
+	src := fetchUpstream(g, "https://raw.githubusercontent.com/cloudflare/cloudflared/"+cloudflaredConfigVersion+
+		"/config/configuration.go")
+	file, err := parser.ParseFile(token.NewFileSet(), "configuration.go", src, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	upstream := make(map[string][]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		if st, ok := spec.Type.(*ast.StructType); ok {
+			upstream[spec.Name.Name] = upstreamYAMLKeys(g, st)
+		}
+		return false
+	})
+
+	for name, copied := range upstreamConfigTypes {
+		g.Expect(upstream).To(HaveKey(name))
+		g.Expect(copiedYAMLKeys(copied)).To(ConsistOf(upstream[name]), "yaml keys of %s", name)
+	}
+}
+
+// TestRunParameterSinceMatchesUpstream fails when a flag of runParameterSince is missing from the release it is
+// listed with, or is already in the release before.
+func TestRunParameterSinceMatchesUpstream(t *testing.T) {
+	g := NewWithT(t)
+
+	tags := upstreamReleases(g)
+	for _, param := range runParameterSince {
+		flag := strconv.Quote(param.name)
+
+		since := fetchUpstream(g, upstreamTunnelCmdURL(param.since))
+		g.Expect(string(since)).To(ContainSubstring(flag), "flags of %s", param.since)
+
+		var previous string
+		for _, tag := range tags {
+			if cloudflare.CompareDaemonVersions(tag, param.since) < 0 &&
+				(previous == "" || cloudflare.CompareDaemonVersions(tag, previous) > 0) {
+				previous = tag
+			}
+		}
+		g.Expect(previous).NotTo(BeEmpty())
+		before := fetchUpstream(g, upstreamTunnelCmdURL(previous))
+		g.Expect(string(before)).NotTo(ContainSubstring(flag), "flags of %s", previous)
+	}
+}
+
+func upstreamTunnelCmdURL(version string) string {
+	return "https://raw.githubusercontent.com/cloudflare/cloudflared/" + version + "/cmd/cloudflared/tunnel/cmd.go"
+}
+
+// upstreamReleases returns tags of cloudflared releases, enough to go back before every entry of runParameterSince.
+func upstreamReleases(g Gomega) []string {
+	var tags []string
+	for page := 1; page <= 5; page++ {
+		var pageTags []struct {
+			Name string `json:"name"`
+		}
+		g.Expect(json.Unmarshal(fetchUpstream(g, fmt.Sprintf(
+			"https://api.github.com/repos/cloudflare/cloudflared/tags?per_page=100&page=%d",
+			page,
+		)), &pageTags)).To(Succeed())
+		for _, tag := range pageTags {
+			tags = append(tags, tag.Name)
+		}
+	}
+	return tags
+}
+
+func fetchUpstream(g Gomega, url string) []byte {
+	res, err := http.Get(url) //nolint:gosec,noctx
+	g.Expect(err).NotTo(HaveOccurred())
+	defer res.Body.Close()
+	g.Expect(res.StatusCode).To(Equal(http.StatusOK), url)
+	body, err := io.ReadAll(res.Body)
+	g.Expect(err).NotTo(HaveOccurred())
+	return body
+}
+
+func upstreamYAMLKeys(g Gomega, st *ast.StructType) []string {
+	var keys []string
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		g.Expect(err).NotTo(HaveOccurred())
+		if key, ok := yamlKey(reflect.StructTag(tag)); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func copiedYAMLKeys(typ reflect.Type) []string {
+	var keys []string
+	for i := 0; i < typ.NumField(); i++ {
+		if key, ok := yamlKey(typ.Field(i).Tag); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// yamlKey returns the key of a yaml tag, or ",inline" for inlined fields.
+func yamlKey(tag reflect.StructTag) (string, bool) {
+	value, ok := tag.Lookup("yaml")
+	if !ok || value == "-" {
+		return "", false
+	}
+	name, opts, _ := strings.Cut(value, ",")
+	if opts == "inline" {
+		return ",inline", true
+	}
+	return name, true
+}
@@ -11,8 +11,15 @@ import (
 
 type TunnelConfig struct {
 	*v1.TunnelRunParameters `json:",inline"`
-	v1.OriginRequestConfig  `json:",inline"`
-	Ingress                 []v1.TunnelConfigIngress `json:"ingress"`
+	// OriginRequestConfig applies to every ingress rule. cloudflared reads it only from the originRequest key.
+	v1.OriginRequestConfig `json:"originRequest"`
+	Ingress                []v1.TunnelConfigIngress `json:"ingress"`
+	WarpRouting            *WarpRoutingConfig       `json:"warp-routing,omitempty"`
+}
+
+// WarpRoutingConfig lets cloudflared proxy private network traffic from WARP clients.
+type WarpRoutingConfig struct {
+	Enabled bool `json:"enabled"`
 }
 
 func (c TunnelConfig) Equals(o TunnelConfig) bool {
@@ -28,3 +35,14 @@ func (c TunnelConfig) Hash() (string, error) {
 	md5Sum := md5.Sum(marshal)
 	return hex.EncodeToString(md5Sum[:]), nil
 }
+
+// remoteParts returns the part of the config that is pushed to Cloudflare when the tunnel is remotely managed.
+func (c TunnelConfig) remoteParts() TunnelConfig {
+	return TunnelConfig{OriginRequestConfig: c.OriginRequestConfig, Ingress: c.Ingress, WarpRouting: c.WarpRouting}
+}
+
+// localParts returns the part of the config that cloudflared reads only on start when the tunnel is remotely
+// managed.
+func (c TunnelConfig) localParts() TunnelConfig {
+	return TunnelConfig{TunnelRunParameters: c.TunnelRunParameters}
+}
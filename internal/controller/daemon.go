@@ -4,26 +4,76 @@ import (
 	"context"
 	"fmt"
 	"maps"
-	"reflect"
+	"math"
+	"slices"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configv1alpha1 "github.com/isac322/cloudflared-operator/api/config/v1alpha1"
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
 	"github.com/isac322/cloudflared-operator/internal/cloudflare"
 )
 
-func getDaemonVersion(ctx context.Context, tunnel *v1.Tunnel) (string, error) {
-	version := tunnel.Spec.DaemonDeployment.DaemonVersion
+const (
+	// defaultDaemonGracePeriod is the default of --grace-period of cloudflared.
+	defaultDaemonGracePeriod = 30 * time.Second
+	// daemonTerminationMargin is added to the grace period of cloudflared, so that it exits before being killed.
+	daemonTerminationMargin = 5 * time.Second
+	// daemonReadinessPeriodSeconds is how often readiness of cloudflared is probed,
+	// which is shorter than the liveness probe so that pods become unready long before the drain ends.
+	daemonReadinessPeriodSeconds = 1
+	// daemonReadinessFailureThreshold tolerates a slow response of cloudflared without flapping readiness.
+	daemonReadinessFailureThreshold = 3
+)
+
+// minPreStopSleepVersion is the first Kubernetes release that enables the sleep action of preStop hooks by default.
+var minPreStopSleepVersion = utilversion.MajorMinor(1, 30)
+
+// daemonImage is the resolved container image of cloudflared.
+type daemonImage struct {
+	Reference string
+	// Version is the tag of the image, which may be empty if the image is pinned by digest only.
+	Version string
+	Digest  string
+}
+
+func buildDaemonImage(
+	tunnel *v1.Tunnel,
+	daemonCfg configv1alpha1.DaemonConfiguration,
+	version string,
+) daemonImage {
+	spec := ptr.Deref(tunnel.Spec.DaemonDeployment.Image, v1.DaemonImage{})
+
+	repository := spec.Repository
+	if repository == "" {
+		repository = daemonCfg.Image.MirroredRepository()
+	}
+
+	reference := repository
+	if version != "" {
+		reference += ":" + version
+	}
+	if spec.Digest != "" {
+		reference += "@" + spec.Digest
+	}
+	return daemonImage{Reference: reference, Version: version, Digest: spec.Digest}
+}
+
+func getDaemonVersion(ctx context.Context, resolver cloudflare.DaemonVersionResolver, version string) (string, error) {
 	if version == "latest" {
-		return cloudflare.GetLatestDaemonVersion(ctx)
+		return cloudflare.LatestDaemonVersion(ctx, resolver, cloudflare.DaemonVersionConstraint{})
 	}
 
-	isValid, err := cloudflare.VerifyDaemonVersion(ctx, version)
+	isValid, err := resolver.VerifyDaemonVersion(ctx, version)
 	if err != nil {
 		return "", err
 	}
@@ -33,19 +83,23 @@ func getDaemonVersion(ctx context.Context, tunnel *v1.Tunnel) (string, error) {
 	return version, nil
 }
 
-func buildDaemon(daemonVersion string, tunnel *v1.Tunnel, tunnelConfig TunnelConfig) (client.Object, error) {
-	image := "cloudflare/cloudflared:" + daemonVersion
+func buildDaemon(
+	image daemonImage,
+	tunnel *v1.Tunnel,
+	tunnelConfig TunnelConfig,
+	daemonCfg configv1alpha1.DaemonConfiguration,
+) (client.Object, error) {
 
-	configHash, err := tunnelConfig.Hash()
+	// remotely managed parts of the config are applied without restarting pods
+	hashedConfig := tunnelConfig
+	if isRemotelyManaged(tunnel) {
+		hashedConfig = tunnelConfig.localParts()
+	}
+	configHash, err := hashedConfig.Hash()
 	if err != nil {
 		return nil, err
 	}
 
-	var terminationGracePeriodSeconds *int64
-	if tunnel.Spec.TunnelRunParameters != nil && tunnel.Spec.TunnelRunParameters.GracePeriod != nil {
-		*terminationGracePeriodSeconds = int64(tunnel.Spec.TunnelRunParameters.GracePeriod.Seconds()) + 5
-	}
-
 	podAnnotations := maps.Clone(tunnel.Spec.DaemonDeployment.PodAnnotations)
 	if podAnnotations == nil {
 		podAnnotations = make(map[string]string, 1)
@@ -54,7 +108,11 @@ func buildDaemon(daemonVersion string, tunnel *v1.Tunnel, tunnelConfig TunnelCon
 
 	podTemplateSpec := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:      fillLabels(tunnel.Spec.DaemonDeployment.PodLabels, tunnel.Name, daemonVersion),
+			Labels: fillLabels(
+				withCommonLabels(daemonCfg.CommonLabels, tunnel.Spec.DaemonDeployment.PodLabels),
+				tunnel.Name,
+				image.Version,
+			),
 			Annotations: podAnnotations,
 		},
 		Spec: corev1.PodSpec{
@@ -86,26 +144,15 @@ func buildDaemon(daemonVersion string, tunnel *v1.Tunnel, tunnelConfig TunnelCon
 			},
 			InitContainers: nil,
 			Containers: []corev1.Container{{
-				Name:    "cloudflared",
-				Image:   image,
-				Command: nil,
-				Args: []string{
-					"tunnel",
-					"--no-autoupdate",
-					"--metrics",
-					"0.0.0.0:2000",
-					"--config",
-					"/etc/cloudflared/" + fileNameConfig,
-					"--credentials-file",
-					"/etc/cloudflared/creds/" + fileNameCredential,
-					"run",
-					tunnel.Spec.Name,
-				},
+				Name:          daemonContainerName,
+				Image:         image.Reference,
+				Command:       nil,
+				Args:          daemonArgs(tunnel),
 				WorkingDir:    "",
 				Ports:         nil,
 				EnvFrom:       nil,
 				Env:           nil,
-				Resources:     tunnel.Spec.DaemonDeployment.Resources,
+				Resources:     daemonResources(tunnel, daemonCfg),
 				ResizePolicy:  nil,
 				RestartPolicy: nil,
 				VolumeMounts: []corev1.VolumeMount{
@@ -120,36 +167,22 @@ func buildDaemon(daemonVersion string, tunnel *v1.Tunnel, tunnelConfig TunnelCon
 						MountPath: "/etc/cloudflared/creds",
 					},
 				},
-				VolumeDevices: nil,
-				LivenessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						HTTPGet: &corev1.HTTPGetAction{
-							Path: "/ready",
-							Port: intstr.FromInt32(2000),
-						},
-					},
-					InitialDelaySeconds: 10,
-					PeriodSeconds:       10,
-					FailureThreshold:    1,
-				},
-				ReadinessProbe:           nil,
+				VolumeDevices:            nil,
+				LivenessProbe:            daemonCfg.LivenessProbe.DeepCopy(),
+				ReadinessProbe:           daemonReadinessProbe(daemonCfg),
 				StartupProbe:             nil,
-				Lifecycle:                nil,
+				Lifecycle:                daemonLifecycle(tunnel),
 				TerminationMessagePath:   "",
 				TerminationMessagePolicy: "",
-				ImagePullPolicy:          "",
-				SecurityContext: &corev1.SecurityContext{
-					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
-					ReadOnlyRootFilesystem:   ptr.To(true),
-					AllowPrivilegeEscalation: ptr.To(false),
-				},
-				Stdin:     false,
-				StdinOnce: false,
-				TTY:       false,
+				ImagePullPolicy:          daemonImagePullPolicy(tunnel),
+				SecurityContext:          daemonCfg.SecurityContext.DeepCopy(),
+				Stdin:                    false,
+				StdinOnce:                false,
+				TTY:                      false,
 			}},
 			EphemeralContainers:           nil,
 			RestartPolicy:                 "",
-			TerminationGracePeriodSeconds: terminationGracePeriodSeconds,
+			TerminationGracePeriodSeconds: daemonTerminationGracePeriodSeconds(tunnel),
 			ActiveDeadlineSeconds:         nil,
 			DNSPolicy:                     tunnel.Spec.DaemonDeployment.DNSPolicy,
 			NodeSelector:                  tunnel.Spec.DaemonDeployment.NodeSelector,
@@ -157,48 +190,34 @@ func buildDaemon(daemonVersion string, tunnel *v1.Tunnel, tunnelConfig TunnelCon
 			AutomountServiceAccountToken:  nil,
 			NodeName:                      "",
 			ShareProcessNamespace:         nil,
-			SecurityContext: &corev1.PodSecurityContext{
-				RunAsUser:    ptr.To(int64(65532)),
-				RunAsNonRoot: ptr.To(true),
-				//Sysctls: []corev1.Sysctl{
-				//	{
-				//		Name:  "net.core.rmem_max",
-				//		Value: "2500000",
-				//	},
-				//	{
-				//		Name:  "net.core.wmem_max",
-				//		Value: "2500000",
-				//	},
-				//},
-			},
-			ImagePullSecrets:          nil,
-			Hostname:                  "",
-			Subdomain:                 "",
-			Affinity:                  tunnel.Spec.DaemonDeployment.Affinity,
-			SchedulerName:             "",
-			Tolerations:               tunnel.Spec.DaemonDeployment.Tolerations,
-			HostAliases:               nil,
-			PriorityClassName:         "",
-			Priority:                  nil,
-			DNSConfig:                 nil,
-			ReadinessGates:            nil,
-			RuntimeClassName:          nil,
-			EnableServiceLinks:        nil,
-			PreemptionPolicy:          nil,
-			Overhead:                  nil,
-			TopologySpreadConstraints: nil,
-			SetHostnameAsFQDN:         nil,
-			OS:                        nil,
-			HostUsers:                 nil,
-			SchedulingGates:           nil,
-			ResourceClaims:            nil,
+			SecurityContext:               daemonCfg.PodSecurityContext.DeepCopy(),
+			ImagePullSecrets:              daemonImagePullSecrets(tunnel, daemonCfg),
+			Hostname:                      "",
+			Subdomain:                     "",
+			Affinity:                      tunnel.Spec.DaemonDeployment.Affinity,
+			SchedulerName:                 "",
+			Tolerations:                   daemonTolerations(tunnel, daemonCfg),
+			HostAliases:                   nil,
+			PriorityClassName:             "",
+			Priority:                      nil,
+			DNSConfig:                     nil,
+			ReadinessGates:                nil,
+			RuntimeClassName:              nil,
+			EnableServiceLinks:            nil,
+			PreemptionPolicy:              nil,
+			Overhead:                      nil,
+			TopologySpreadConstraints:     nil,
+			SetHostnameAsFQDN:             nil,
+			OS:                            nil,
+			HostUsers:                     nil,
+			SchedulingGates:               nil,
+			ResourceClaims:                nil,
 		},
 	}
 
-	labelSelector := map[string]string{
-		"app.kubernetes.io/name":     "cloudflared",
-		"app.kubernetes.io/instance": tunnel.Name,
-		"app.kubernetes.io/part-of":  "cloudflared",
+	podTemplateSpec, err = applyPodTemplate(podTemplateSpec, tunnel.Spec.DaemonDeployment.PodTemplate)
+	if err != nil {
+		return nil, err
 	}
 
 	daemonAnnotations := maps.Clone(tunnel.Spec.DaemonDeployment.Annotations)
@@ -207,79 +226,111 @@ func buildDaemon(daemonVersion string, tunnel *v1.Tunnel, tunnelConfig TunnelCon
 	}
 	daemonAnnotations["cloudflared-operator.bhyoo.com/config-hash"] = configHash
 
-	if tunnel.Spec.DaemonDeployment.Kind == v1.DeploymentKindDeployment {
-		return &appsv1.Deployment{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:        buildDaemonName(tunnel),
-				Namespace:   tunnel.Namespace,
-				Labels:      fillLabels(tunnel.Spec.DaemonDeployment.Labels, tunnel.Name, daemonVersion),
-				Annotations: daemonAnnotations,
-			},
-			Spec: appsv1.DeploymentSpec{
-				Replicas: tunnel.Spec.DaemonDeployment.Replicas,
-				Selector: &metav1.LabelSelector{
-					MatchLabels: labelSelector,
-				},
-				Template:                podTemplateSpec,
-				Strategy:                tunnel.Spec.DaemonDeployment.DeploymentStrategy,
-				MinReadySeconds:         tunnel.Spec.DaemonDeployment.MinReadySeconds,
-				RevisionHistoryLimit:    tunnel.Spec.DaemonDeployment.RevisionHistoryLimit,
-				Paused:                  false,
-				ProgressDeadlineSeconds: nil,
-			},
-		}, nil
+	workload, err := daemonWorkloadOf(tunnel.Spec.DaemonDeployment.Kind)
+	if err != nil {
+		return nil, err
 	}
+	daemon := workload.build(tunnel, podTemplateSpec)
+	daemon.SetName(buildDaemonName(tunnel))
+	daemon.SetNamespace(tunnel.Namespace)
+	daemon.SetLabels(fillLabels(
+		withCommonLabels(daemonCfg.CommonLabels, tunnel.Spec.DaemonDeployment.Labels),
+		tunnel.Name,
+		image.Version,
+	))
+	daemon.SetAnnotations(daemonAnnotations)
+	setManagedKeys(daemon)
+	return daemon, nil
+}
 
-	return &appsv1.DaemonSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        "cloudflared-" + tunnel.Name + "-" + tunnel.Spec.Name,
-			Namespace:   tunnel.Namespace,
-			Labels:      tunnel.Spec.DaemonDeployment.Labels,
-			Annotations: daemonAnnotations,
+func isDaemonEqualTo(a, b client.Object, kind v1.DeploymentKind) bool {
+	workload, ok := daemonWorkloads[kind]
+	return ok && workload.specEqual(a, b)
+}
+
+// daemonDeploymentStrategy defaults the strategy to surge without unavailable pods for SurgeRollout.
+func daemonDeploymentStrategy(tunnel *v1.Tunnel) appsv1.DeploymentStrategy {
+	strategy := tunnel.Spec.DaemonDeployment.DeploymentStrategy
+	if tunnel.Spec.ConfigReloadStrategy != v1.ConfigReloadStrategySurgeRollout || strategy.Type != "" {
+		return strategy
+	}
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+			MaxSurge:       ptr.To(intstr.FromString("25%")),
 		},
-		Spec: appsv1.DaemonSetSpec{
-			Selector: &metav1.LabelSelector{
-				MatchLabels: labelSelector,
-			},
-			Template:             podTemplateSpec,
-			UpdateStrategy:       tunnel.Spec.DaemonDeployment.DaemonSetUpdateStrategy,
-			MinReadySeconds:      tunnel.Spec.DaemonDeployment.MinReadySeconds,
-			RevisionHistoryLimit: tunnel.Spec.DaemonDeployment.RevisionHistoryLimit,
+	}
+}
+
+// daemonSetUpdateStrategy defaults the strategy to surge without unavailable pods for SurgeRollout.
+func daemonSetUpdateStrategy(tunnel *v1.Tunnel) appsv1.DaemonSetUpdateStrategy {
+	strategy := tunnel.Spec.DaemonDeployment.DaemonSetUpdateStrategy
+	if tunnel.Spec.ConfigReloadStrategy != v1.ConfigReloadStrategySurgeRollout || strategy.Type != "" {
+		return strategy
+	}
+	return appsv1.DaemonSetUpdateStrategy{
+		Type: appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+			MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+			MaxSurge:       ptr.To(intstr.FromString("25%")),
 		},
-	}, nil
+	}
 }
 
-func isDaemonEqualTo(a, b client.Object, kind v1.DeploymentKind) bool {
-	switch kind {
-	case v1.DeploymentKindDaemonSet:
-		aa, ok := a.(*appsv1.DaemonSet)
-		if !ok {
-			return false
-		}
-		bb, ok := b.(*appsv1.DaemonSet)
-		if !ok {
-			return false
-		}
-		return reflect.DeepEqual(aa.Spec, bb.Spec)
-	case v1.DeploymentKindDeployment:
-		aa, ok := a.(*appsv1.Deployment)
-		if !ok {
-			return false
-		}
-		bb, ok := b.(*appsv1.Deployment)
-		if !ok {
-			return false
-		}
-		return reflect.DeepEqual(aa.Spec, bb.Spec)
-	default:
-		return false
+func daemonImagePullPolicy(tunnel *v1.Tunnel) corev1.PullPolicy {
+	if tunnel.Spec.DaemonDeployment.Image == nil {
+		return ""
+	}
+	return tunnel.Spec.DaemonDeployment.Image.PullPolicy
+}
+
+func daemonImagePullSecrets(tunnel *v1.Tunnel, cfg configv1alpha1.DaemonConfiguration) []corev1.LocalObjectReference {
+	secrets := slices.Clone(cfg.Image.PullSecrets)
+	if tunnel.Spec.DaemonDeployment.Image != nil {
+		secrets = append(secrets, tunnel.Spec.DaemonDeployment.Image.ImagePullSecrets...)
+	}
+	return secrets
+}
+
+func daemonResources(tunnel *v1.Tunnel, cfg configv1alpha1.DaemonConfiguration) corev1.ResourceRequirements {
+	resources := tunnel.Spec.DaemonDeployment.Resources
+	if len(resources.Limits) == 0 && len(resources.Requests) == 0 && len(resources.Claims) == 0 {
+		return *cfg.Resources.DeepCopy()
+	}
+	return resources
+}
+
+func daemonTolerations(tunnel *v1.Tunnel, cfg configv1alpha1.DaemonConfiguration) []corev1.Toleration {
+	if tunnel.Spec.DaemonDeployment.Tolerations != nil {
+		return tunnel.Spec.DaemonDeployment.Tolerations
+	}
+	return slices.Clone(cfg.Tolerations)
+}
+
+// withCommonLabels merges labels over common labels of the configuration.
+func withCommonLabels(common, labels map[string]string) map[string]string {
+	if len(common) == 0 {
+		return labels
 	}
+	dest := maps.Clone(common)
+	maps.Copy(dest, labels)
+	return dest
 }
 
 func buildDaemonName(tunnel *v1.Tunnel) string {
 	return "cloudflared-" + tunnel.Name + "-" + tunnel.Spec.Name
 }
 
+// daemonSelectorLabels selects pods of the daemon of the tunnel.
+func daemonSelectorLabels(tunnel *v1.Tunnel) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":     "cloudflared",
+		"app.kubernetes.io/instance": tunnel.Name,
+		"app.kubernetes.io/part-of":  "cloudflared",
+	}
+}
+
 func fillLabels(labels map[string]string, tunnelName, version string) map[string]string {
 	dest := maps.Clone(labels)
 	if dest == nil {
@@ -289,6 +340,100 @@ func fillLabels(labels map[string]string, tunnelName, version string) map[string
 	dest["app.kubernetes.io/instance"] = tunnelName
 	dest["app.kubernetes.io/component"] = "daemon"
 	dest["app.kubernetes.io/part-of"] = "cloudflared"
-	dest["app.kubernetes.io/version"] = version
+	if version != "" {
+		dest["app.kubernetes.io/version"] = version
+	}
 	return dest
 }
+
+func daemonArgs(tunnel *v1.Tunnel) []string {
+	args := []string{
+		"tunnel",
+		"--no-autoupdate",
+		"--metrics",
+		"0.0.0.0:2000",
+		"--config",
+		"/etc/cloudflared/" + fileNameConfig,
+		"--credentials-file",
+		"/etc/cloudflared/creds/" + fileNameCredential,
+		"--grace-period",
+		daemonGracePeriod(tunnel).String(),
+	}
+	args = append(args, runParameterArgs(tunnel.Spec.TunnelRunParameters)...)
+	return append(args, "run", tunnel.Spec.Name)
+}
+
+// daemonGracePeriod is the time that cloudflared waits for in-flight requests after SIGTERM.
+func daemonGracePeriod(tunnel *v1.Tunnel) time.Duration {
+	if params := tunnel.Spec.TunnelRunParameters; params != nil && params.GracePeriod != nil {
+		return params.GracePeriod.Duration
+	}
+	return defaultDaemonGracePeriod
+}
+
+// daemonTerminationGracePeriodSeconds lets pods wait out the pre-stop delay and drain before being killed.
+func daemonTerminationGracePeriodSeconds(tunnel *v1.Tunnel) *int64 {
+	period := daemonGracePeriod(tunnel) + daemonTerminationMargin + daemonPreStopDelay(tunnel)
+	return ptr.To(int64(math.Ceil(period.Seconds())))
+}
+
+// daemonPreStopDelay is PreStopDelay of the tunnel, which lasts at least as long as the readiness probe takes
+// to fail, or zero if it is not set.
+func daemonPreStopDelay(tunnel *v1.Tunnel) time.Duration {
+	delay := tunnel.Spec.DaemonDeployment.PreStopDelay
+	if delay == nil || delay.Duration <= 0 {
+		return 0
+	}
+	return max(delay.Duration, daemonReadinessPeriodSeconds*daemonReadinessFailureThreshold*time.Second)
+}
+
+// daemonLifecycle delays SIGTERM of cloudflared by sleeping in preStop hook.
+// cloudflared images have no shell, so the sleep action of kubelet is used rather than an exec hook.
+// API servers older than minPreStopSleepVersion may reject it, so TunnelReconciler drops PreStopDelay for them.
+func daemonLifecycle(tunnel *v1.Tunnel) *corev1.Lifecycle {
+	delay := daemonPreStopDelay(tunnel)
+	if delay == 0 {
+		return nil
+	}
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Sleep: &corev1.SleepAction{Seconds: int64(math.Ceil(delay.Seconds()))},
+		},
+	}
+}
+
+// SupportsPreStopSleep reports whether the API server accepts the sleep action of preStop hooks by default.
+// Servers of 1.29 may support it with the PodLifecycleSleepAction feature gate, but gates can not be discovered.
+func SupportsPreStopSleep(cfg *rest.Config) (bool, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return false, err
+	}
+	info, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return false, err
+	}
+	serverVersion, err := utilversion.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return false, err
+	}
+	return serverVersion.AtLeast(minPreStopSleepVersion), nil
+}
+
+// daemonReadinessProbe probes what the liveness probe does, but every second.
+// cloudflared unregisters from the edge as soon as it starts draining, so pods become unready within seconds,
+// before the drain of the grace period ends and regardless of how slowly the liveness probe notices it.
+// A few failures in a row are required, so that a single slow response does not flap readiness.
+// Rollouts wait for new pods to connect to the edge as well.
+func daemonReadinessProbe(cfg configv1alpha1.DaemonConfiguration) *corev1.Probe {
+	if cfg.LivenessProbe == nil {
+		return nil
+	}
+	return &corev1.Probe{
+		ProbeHandler:     *cfg.LivenessProbe.ProbeHandler.DeepCopy(),
+		TimeoutSeconds:   1,
+		PeriodSeconds:    daemonReadinessPeriodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: daemonReadinessFailureThreshold,
+	}
+}
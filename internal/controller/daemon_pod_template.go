@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/goccy/go-json"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+const daemonContainerName = "cloudflared"
+
+// operatorVolumes are volumes of the daemon pod that cloudflared can not run without.
+var operatorVolumes = []string{"credential", "config"}
+
+var errInvalidPodTemplate = errors.New("podTemplate changes fields owned by the operator")
+
+// applyPodTemplate merges the strategic merge patch over the generated pod template,
+// and rejects patches that break fields owned by the operator.
+func applyPodTemplate(generated corev1.PodTemplateSpec, patch *runtime.RawExtension) (corev1.PodTemplateSpec, error) {
+	if patch == nil || len(patch.Raw) == 0 {
+		return generated, nil
+	}
+
+	original, err := json.Marshal(generated)
+	if err != nil {
+		return corev1.PodTemplateSpec{}, err
+	}
+	mergedJSON, err := strategicpatch.StrategicMergePatch(original, patch.Raw, corev1.PodTemplateSpec{})
+	if err != nil {
+		return corev1.PodTemplateSpec{}, fmt.Errorf("%w: %w", errInvalidPodTemplate, err)
+	}
+	var merged corev1.PodTemplateSpec
+	if err = json.Unmarshal(mergedJSON, &merged); err != nil {
+		return corev1.PodTemplateSpec{}, fmt.Errorf("%w: %w", errInvalidPodTemplate, err)
+	}
+
+	if err = validatePodTemplate(generated, merged); err != nil {
+		return corev1.PodTemplateSpec{}, fmt.Errorf("%w: %w", errInvalidPodTemplate, err)
+	}
+	return merged, nil
+}
+
+func validatePodTemplate(generated, merged corev1.PodTemplateSpec) error {
+	var errs []error
+
+	for key, value := range generated.Labels {
+		if merged.Labels[key] != value {
+			errs = append(errs, fmt.Errorf("label %s conflicts with labels of the operator and podLabels", key))
+		}
+	}
+	for key, value := range generated.Annotations {
+		if merged.Annotations[key] != value {
+			errs = append(errs, fmt.Errorf("annotation %s conflicts with annotations of the operator and podAnnotations", key))
+		}
+	}
+
+	for _, name := range operatorVolumes {
+		if !equality.Semantic.DeepEqual(findVolume(generated.Spec.Volumes, name), findVolume(merged.Spec.Volumes, name)) {
+			errs = append(errs, fmt.Errorf("volume %s is managed by the operator", name))
+		}
+	}
+
+	generatedContainer := findContainer(generated.Spec.Containers, daemonContainerName)
+	mergedContainer := findContainer(merged.Spec.Containers, daemonContainerName)
+	if mergedContainer == nil {
+		return errors.Join(append(errs, fmt.Errorf("container %s is managed by the operator", daemonContainerName))...)
+	}
+	if mergedContainer.Image != generatedContainer.Image {
+		errs = append(errs, errors.New("image of cloudflared container is managed by the operator, use image instead"))
+	}
+	if !slices.Equal(mergedContainer.Command, generatedContainer.Command) ||
+		!slices.Equal(mergedContainer.Args, generatedContainer.Args) {
+		errs = append(errs, errors.New("command and args of cloudflared container are managed by the operator"))
+	}
+	for _, mount := range generatedContainer.VolumeMounts {
+		idx := slices.IndexFunc(mergedContainer.VolumeMounts, func(m corev1.VolumeMount) bool {
+			return m.Name == mount.Name && m.MountPath == mount.MountPath
+		})
+		if idx == -1 || !equality.Semantic.DeepEqual(mergedContainer.VolumeMounts[idx], mount) {
+			errs = append(errs, fmt.Errorf("volume mount %s of cloudflared container is managed by the operator", mount.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func findVolume(volumes []corev1.Volume, name string) *corev1.Volume {
+	idx := slices.IndexFunc(volumes, func(v corev1.Volume) bool { return v.Name == name })
+	if idx == -1 {
+		return nil
+	}
+	return &volumes[idx]
+}
+
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	idx := slices.IndexFunc(containers, func(c corev1.Container) bool { return c.Name == name })
+	if idx == -1 {
+		return nil
+	}
+	return &containers[idx]
+}
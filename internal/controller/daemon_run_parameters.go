@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+var errUnsupportedRunParameter = errors.New("unsupported run parameter")
+
+// runParameterSince is the first cloudflared release that supports each run parameter,
+// as announced in the release notes of cloudflared, https://github.com/cloudflare/cloudflared/releases.
+// Parameters that are not listed are supported by every release the operator deploys.
+// TestRunParameterSinceMatchesUpstream checks each release against the flags of cloudflared.
+var runParameterSince = []struct {
+	name  string
+	since string
+	isSet func(p *v1.TunnelRunParameters) bool
+}{
+	// https://github.com/cloudflare/cloudflared/releases/tag/2022.9.1
+	{"post-quantum", "2022.9.1", func(p *v1.TunnelRunParameters) bool { return p.PostQuantum != nil }},
+	// https://github.com/cloudflare/cloudflared/releases/tag/2022.12.0
+	{"edge-ip-version", "2022.12.0", func(p *v1.TunnelRunParameters) bool { return p.EdgeIPVersion != nil }},
+	// https://github.com/cloudflare/cloudflared/releases/tag/2023.2.1
+	{"icmpv4-src", "2023.2.1", func(p *v1.TunnelRunParameters) bool { return p.ICMPv4Src != nil }},
+	{"icmpv6-src", "2023.2.1", func(p *v1.TunnelRunParameters) bool { return p.ICMPv6Src != nil }},
+	// https://github.com/cloudflare/cloudflared/releases/tag/2023.2.2
+	{"edge-bind-address", "2023.2.2", func(p *v1.TunnelRunParameters) bool { return p.EdgeBindAddress != nil }},
+	// https://github.com/cloudflare/cloudflared/releases/tag/2023.4.1
+	{"region", "2023.4.1", func(p *v1.TunnelRunParameters) bool { return p.Region != nil }},
+	{"label", "2023.4.1", func(p *v1.TunnelRunParameters) bool { return p.Label != nil }},
+	// https://github.com/cloudflare/cloudflared/releases/tag/2023.10.0
+	{"management-diagnostics", "2023.10.0", func(p *v1.TunnelRunParameters) bool {
+		return p.ManagementDiagnostics != nil
+	}},
+}
+
+// runParameterValues are the values of parameters that were free-form strings before the schema restricted them.
+// Tunnels stored earlier may still have other values, which are reported instead of written in config.yaml.
+var runParameterValues = []struct {
+	name    string
+	allowed []string
+	value   func(p *v1.TunnelRunParameters) *string
+}{
+	{
+		"protocol",
+		[]string{string(v1.TunnelProtocolAuto), string(v1.TunnelProtocolHTTP2), string(v1.TunnelProtocolQUIC)},
+		func(p *v1.TunnelRunParameters) *string { return (*string)(p.Protocol) },
+	},
+	{
+		"region",
+		[]string{string(v1.EdgeRegionUS)},
+		func(p *v1.TunnelRunParameters) *string { return (*string)(p.Region) },
+	},
+}
+
+// checkRunParametersSupported returns errUnsupportedRunParameter if cloudflared of version does not support params,
+// or params have values that the schema no longer allows.
+func checkRunParametersSupported(params *v1.TunnelRunParameters, version string) error {
+	if params == nil {
+		return nil
+	}
+	var unsupported []error
+	for _, param := range runParameterSince {
+		if param.isSet(params) && !cloudflare.DaemonSupports(version, param.since) {
+			unsupported = append(unsupported, fmt.Errorf(
+				"%w: %s requires cloudflared %s or later, but got %s",
+				errUnsupportedRunParameter,
+				param.name,
+				param.since,
+				version,
+			))
+		}
+	}
+	for _, param := range runParameterValues {
+		if value := param.value(params); value != nil && !slices.Contains(param.allowed, *value) {
+			unsupported = append(unsupported, fmt.Errorf(
+				"%w: %s must be one of %v, but got %q",
+				errUnsupportedRunParameter,
+				param.name,
+				param.allowed,
+				*value,
+			))
+		}
+	}
+	return errors.Join(unsupported...)
+}
+
+// configRunParameters returns params to be written in config.yaml, leaving out ones passed as container args.
+func configRunParameters(params *v1.TunnelRunParameters) *v1.TunnelRunParameters {
+	if params == nil {
+		return nil
+	}
+	configParams := params.DeepCopy()
+	configParams.Tag = nil
+	// pods are given the same grace period, so it is passed along with terminationGracePeriodSeconds
+	configParams.GracePeriod = nil
+	return configParams
+}
+
+// runParameterArgs returns container args of params that config.yaml can not express.
+func runParameterArgs(params *v1.TunnelRunParameters) []string {
+	if params == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(params.Tag))
+	for key := range params.Tag {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var args []string
+	// config.yaml takes tags as a list of KEY=VALUE, which can not be inlined from a map
+	for _, key := range keys {
+		args = append(args, "--tag", key+"="+params.Tag[key])
+	}
+	return args
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/isac322/cloudflared-operator/api/config/v1alpha1"
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+const (
+	// metricConcurrentRequests is the metric of cloudflared that TargetConcurrentRequests scales on.
+	metricConcurrentRequests = "cloudflared_tunnel_concurrent_requests_per_tunnel"
+
+	defaultTargetCPUUtilization int32 = 80
+)
+
+// isAutoscaled reports whether the daemon of the tunnel is scaled by a HorizontalPodAutoscaler.
+func isAutoscaled(tunnel *v1.Tunnel) bool {
+	return tunnel.Spec.DaemonDeployment.Autoscaling != nil &&
+		tunnel.Spec.DaemonDeployment.Kind == v1.DeploymentKindDeployment
+}
+
+func buildDaemonDisruptionBudget(
+	tunnel *v1.Tunnel,
+	daemonCfg configv1alpha1.DaemonConfiguration,
+) *policyv1.PodDisruptionBudget {
+	spec := tunnel.Spec.DaemonDeployment.DisruptionBudget
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildDaemonName(tunnel),
+			Namespace: tunnel.Namespace,
+			Labels: fillLabels(
+				withCommonLabels(daemonCfg.CommonLabels, tunnel.Spec.DaemonDeployment.Labels),
+				tunnel.Name,
+				"",
+			),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:               spec.MinAvailable,
+			MaxUnavailable:             spec.MaxUnavailable,
+			Selector:                   &metav1.LabelSelector{MatchLabels: daemonSelectorLabels(tunnel)},
+			UnhealthyPodEvictionPolicy: spec.UnhealthyPodEvictionPolicy,
+		},
+	}
+}
+
+func buildDaemonAutoscaler(
+	tunnel *v1.Tunnel,
+	daemonCfg configv1alpha1.DaemonConfiguration,
+) *autoscalingv2.HorizontalPodAutoscaler {
+	spec := tunnel.Spec.DaemonDeployment.Autoscaling
+
+	metrics := make([]autoscalingv2.MetricSpec, 0, len(spec.Metrics)+2)
+	if spec.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, cpuUtilizationMetric(*spec.TargetCPUUtilizationPercentage))
+	}
+	if spec.TargetConcurrentRequests != nil {
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: metricConcurrentRequests},
+				Target: autoscalingv2.MetricTarget{
+					Type:         autoscalingv2.AverageValueMetricType,
+					AverageValue: spec.TargetConcurrentRequests,
+				},
+			},
+		})
+	}
+	metrics = append(metrics, spec.Metrics...)
+	if len(metrics) == 0 {
+		metrics = append(metrics, cpuUtilizationMetric(defaultTargetCPUUtilization))
+	}
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildDaemonName(tunnel),
+			Namespace: tunnel.Namespace,
+			Labels: fillLabels(
+				withCommonLabels(daemonCfg.CommonLabels, tunnel.Spec.DaemonDeployment.Labels),
+				tunnel.Name,
+				"",
+			),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				Name:       buildDaemonName(tunnel),
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+			},
+			MinReplicas: spec.MinReplicas,
+			MaxReplicas: spec.MaxReplicas,
+			Metrics:     metrics,
+			Behavior:    spec.Behavior,
+		},
+	}
+}
+
+func cpuUtilizationMetric(percentage int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: corev1.ResourceCPU,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: ptr.To(percentage),
+			},
+		},
+	}
+}
+
+// keepAutoscaledReplicas keeps replicas that the HorizontalPodAutoscaler set on the existing Deployment,
+// so that every reconcile does not reset them.
+func keepAutoscaledReplicas(tunnel *v1.Tunnel, existing, desired client.Object) {
+	if !isAutoscaled(tunnel) {
+		return
+	}
+	existingDeploy, ok := existing.(*appsv1.Deployment)
+	if !ok {
+		return
+	}
+	desiredDeploy, ok := desired.(*appsv1.Deployment)
+	if !ok {
+		return
+	}
+	desiredDeploy.Spec.Replicas = existingDeploy.Spec.Replicas
+}
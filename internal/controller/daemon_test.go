@@ -0,0 +1,573 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/config"
+)
+
+const testDaemonVersion = "2024.2.1"
+
+func newTestTunnel() *v1.Tunnel {
+	return &v1.Tunnel{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+		Spec: v1.TunnelSpec{
+			Name:             "sample-tunnel",
+			DaemonDeployment: v1.Deployment{Kind: v1.DeploymentKindDeployment},
+		},
+	}
+}
+
+// buildTestDaemon builds the daemon of tunnel with the default configuration of the operator,
+// and the config that buildConfig would write.
+func buildTestDaemon(g Gomega, tunnel *v1.Tunnel, version string) client.Object {
+	daemonCfg := config.Default().Daemon
+	image := buildDaemonImage(tunnel, daemonCfg, version)
+	tunnelConfig := TunnelConfig{
+		TunnelRunParameters: configRunParameters(tunnel.Spec.TunnelRunParameters),
+		Ingress:             []v1.TunnelConfigIngress{{Service: daemonCfg.CatchAllService}},
+	}
+	daemon, err := buildDaemon(image, tunnel, tunnelConfig, daemonCfg)
+	g.Expect(err).NotTo(HaveOccurred())
+	return daemon
+}
+
+func daemonPodTemplate(g Gomega, daemon client.Object) corev1.PodTemplateSpec {
+	switch d := daemon.(type) {
+	case *appsv1.Deployment:
+		return d.Spec.Template
+	case *appsv1.DaemonSet:
+		return d.Spec.Template
+	}
+	g.Expect(daemon).To(Or(BeAssignableToTypeOf(&appsv1.Deployment{}), BeAssignableToTypeOf(&appsv1.DaemonSet{})))
+	return corev1.PodTemplateSpec{}
+}
+
+func daemonContainer(g Gomega, daemon client.Object) corev1.Container {
+	containers := daemonPodTemplate(g, daemon).Spec.Containers
+	g.Expect(containers).To(HaveLen(1))
+	return containers[0]
+}
+
+// deploymentFieldCases are keyed by the json name of fields of v1.Deployment.
+var deploymentFieldCases = map[string]struct {
+	version string
+	mutate  func(d *v1.Deployment)
+	check   func(g Gomega, daemon, baseline client.Object)
+}{
+	"daemonVersion": {
+		version: "2024.3.0",
+		mutate:  func(d *v1.Deployment) { d.DaemonVersion = "2024.3.0" },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemonContainer(g, daemon).Image).To(HaveSuffix(":2024.3.0"))
+			g.Expect(daemon.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/version", "2024.3.0"))
+			g.Expect(daemonPodTemplate(g, daemon).Labels).To(HaveKeyWithValue("app.kubernetes.io/version", "2024.3.0"))
+		},
+	},
+	"image": {
+		mutate: func(d *v1.Deployment) {
+			d.Image = &v1.DaemonImage{
+				Repository:       "registry.example.com/cloudflared",
+				Digest:           "sha256:0123",
+				PullPolicy:       corev1.PullAlways,
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+			}
+		},
+		check: func(g Gomega, daemon, _ client.Object) {
+			container := daemonContainer(g, daemon)
+			g.Expect(container.Image).To(Equal("registry.example.com/cloudflared:" + testDaemonVersion + "@sha256:0123"))
+			g.Expect(container.ImagePullPolicy).To(Equal(corev1.PullAlways))
+			g.Expect(daemonPodTemplate(g, daemon).Spec.ImagePullSecrets).
+				To(ContainElement(corev1.LocalObjectReference{Name: "registry"}))
+		},
+	},
+	"upgradePolicy": {
+		mutate: func(d *v1.Deployment) { d.UpgradePolicy = &v1.DaemonUpgradePolicy{Constraint: "~2024.2"} },
+		check: func(g Gomega, daemon, baseline client.Object) {
+			// it only decides the version, which planDaemonVersion hands over
+			g.Expect(daemon).To(Equal(baseline))
+		},
+	},
+	"kind": {
+		mutate: func(d *v1.Deployment) { d.Kind = v1.DeploymentKindDaemonSet },
+		check: func(g Gomega, daemon, baseline client.Object) {
+			g.Expect(daemon).To(BeAssignableToTypeOf(&appsv1.DaemonSet{}))
+			// every kind of workload has the same metadata
+			g.Expect(daemon.GetName()).To(Equal(baseline.GetName()))
+			g.Expect(daemon.GetNamespace()).To(Equal(baseline.GetNamespace()))
+			g.Expect(daemon.GetLabels()).To(Equal(baseline.GetLabels()))
+			g.Expect(daemon.GetAnnotations()).To(Equal(baseline.GetAnnotations()))
+		},
+	},
+	"replicas": {
+		mutate: func(d *v1.Deployment) { d.Replicas = ptr.To[int32](3) },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemon.(*appsv1.Deployment).Spec.Replicas).To(HaveValue(BeEquivalentTo(3)))
+		},
+	},
+	"autoscaling": {
+		mutate: func(d *v1.Deployment) {
+			d.Replicas = ptr.To[int32](5)
+			d.Autoscaling = &v1.DaemonAutoscaling{MinReplicas: ptr.To[int32](2), MaxReplicas: 10}
+		},
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemon.(*appsv1.Deployment).Spec.Replicas).To(HaveValue(BeEquivalentTo(2)))
+		},
+	},
+	"disruptionBudget": {
+		mutate: func(d *v1.Deployment) {
+			d.DisruptionBudget = &v1.DaemonDisruptionBudget{MaxUnavailable: ptr.To(intstr.FromInt32(1))}
+		},
+		check: func(g Gomega, daemon, baseline client.Object) {
+			// it is a separate PodDisruptionBudget
+			g.Expect(daemon).To(Equal(baseline))
+		},
+	},
+	"DeploymentStrategy": {
+		mutate: func(d *v1.Deployment) {
+			d.DeploymentStrategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+		},
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemon.(*appsv1.Deployment).Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+		},
+	},
+	"updateStrategy": {
+		mutate: func(d *v1.Deployment) {
+			d.Kind = v1.DeploymentKindDaemonSet
+			d.DaemonSetUpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+		},
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemon.(*appsv1.DaemonSet).Spec.UpdateStrategy.Type).
+				To(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+		},
+	},
+	"minReadySeconds": {
+		mutate: func(d *v1.Deployment) { d.MinReadySeconds = 10 },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemon.(*appsv1.Deployment).Spec.MinReadySeconds).To(BeEquivalentTo(10))
+		},
+	},
+	"revisionHistoryLimit": {
+		mutate: func(d *v1.Deployment) { d.RevisionHistoryLimit = ptr.To[int32](3) },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemon.(*appsv1.Deployment).Spec.RevisionHistoryLimit).To(HaveValue(BeEquivalentTo(3)))
+		},
+	},
+	"labels": {
+		mutate: func(d *v1.Deployment) { d.Labels = map[string]string{"team": "edge"} },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemon.GetLabels()).To(HaveKeyWithValue("team", "edge"))
+			g.Expect(daemonPodTemplate(g, daemon).Labels).NotTo(HaveKey("team"))
+		},
+	},
+	"annotations": {
+		mutate: func(d *v1.Deployment) { d.Annotations = map[string]string{"owner": "edge"} },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemon.GetAnnotations()).To(HaveKeyWithValue("owner", "edge"))
+			g.Expect(daemon.GetAnnotations()).To(HaveKey("cloudflared-operator.bhyoo.com/config-hash"))
+		},
+	},
+	"podLabels": {
+		mutate: func(d *v1.Deployment) { d.PodLabels = map[string]string{"team": "edge"} },
+		check: func(g Gomega, daemon, _ client.Object) {
+			labels := daemonPodTemplate(g, daemon).Labels
+			g.Expect(labels).To(HaveKeyWithValue("team", "edge"))
+			g.Expect(labels).To(HaveKeyWithValue("app.kubernetes.io/instance", "sample"))
+		},
+	},
+	"podAnnotations": {
+		mutate: func(d *v1.Deployment) { d.PodAnnotations = map[string]string{"owner": "edge"} },
+		check: func(g Gomega, daemon, _ client.Object) {
+			annotations := daemonPodTemplate(g, daemon).Annotations
+			g.Expect(annotations).To(HaveKeyWithValue("owner", "edge"))
+			g.Expect(annotations).To(HaveKey("cloudflared-operator.bhyoo.com/config-hash"))
+		},
+	},
+	"dnsPolicy": {
+		mutate: func(d *v1.Deployment) { d.DNSPolicy = corev1.DNSClusterFirstWithHostNet },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemonPodTemplate(g, daemon).Spec.DNSPolicy).To(Equal(corev1.DNSClusterFirstWithHostNet))
+		},
+	},
+	"nodeSelector": {
+		mutate: func(d *v1.Deployment) { d.NodeSelector = map[string]string{"kubernetes.io/os": "linux"} },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemonPodTemplate(g, daemon).Spec.NodeSelector).To(HaveKeyWithValue("kubernetes.io/os", "linux"))
+		},
+	},
+	"affinity": {
+		mutate: func(d *v1.Deployment) {
+			d.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+		},
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemonPodTemplate(g, daemon).Spec.Affinity).
+				To(Equal(&corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}))
+		},
+	},
+	"tolerations": {
+		mutate: func(d *v1.Deployment) {
+			d.Tolerations = []corev1.Toleration{{Key: "edge", Operator: corev1.TolerationOpExists}}
+		},
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemonPodTemplate(g, daemon).Spec.Tolerations).
+				To(Equal([]corev1.Toleration{{Key: "edge", Operator: corev1.TolerationOpExists}}))
+		},
+	},
+	"resources": {
+		mutate: func(d *v1.Deployment) {
+			d.Resources = corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+			}
+		},
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemonContainer(g, daemon).Resources.Limits).
+				To(HaveKeyWithValue(corev1.ResourceMemory, resource.MustParse("256Mi")))
+		},
+	},
+	"preStopDelay": {
+		mutate: func(d *v1.Deployment) { d.PreStopDelay = &metav1.Duration{Duration: 10 * time.Second} },
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemonContainer(g, daemon).Lifecycle).To(Equal(&corev1.Lifecycle{
+				PreStop: &corev1.LifecycleHandler{Sleep: &corev1.SleepAction{Seconds: 10}},
+			}))
+			// pre-stop delay, default grace period of cloudflared and the margin
+			g.Expect(daemonPodTemplate(g, daemon).Spec.TerminationGracePeriodSeconds).To(HaveValue(BeEquivalentTo(45)))
+		},
+	},
+	"preStopDelay shorter than readiness probe": {
+		mutate: func(d *v1.Deployment) { d.PreStopDelay = &metav1.Duration{Duration: time.Second} },
+		check: func(g Gomega, daemon, _ client.Object) {
+			container := daemonContainer(g, daemon)
+			readiness := container.ReadinessProbe
+			g.Expect(container.Lifecycle.PreStop.Sleep.Seconds).
+				To(BeEquivalentTo(readiness.PeriodSeconds * readiness.FailureThreshold))
+		},
+	},
+	"podTemplate": {
+		mutate: func(d *v1.Deployment) {
+			d.PodTemplate = &runtime.RawExtension{Raw: []byte(`{"spec":{"priorityClassName":"edge"}}`)}
+		},
+		check: func(g Gomega, daemon, _ client.Object) {
+			g.Expect(daemonPodTemplate(g, daemon).Spec.PriorityClassName).To(Equal("edge"))
+		},
+	},
+}
+
+func TestBuildDaemonDeploymentFields(t *testing.T) {
+	t.Parallel()
+
+	baseline := buildTestDaemon(NewWithT(t), newTestTunnel(), testDaemonVersion)
+	for name, tc := range deploymentFieldCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			tunnel := newTestTunnel()
+			tc.mutate(&tunnel.Spec.DaemonDeployment)
+			version := tc.version
+			if version == "" {
+				version = testDaemonVersion
+			}
+			tc.check(g, buildTestDaemon(g, tunnel, version), baseline)
+		})
+	}
+}
+
+// runParameterCases are keyed by the json name of fields of v1.TunnelRunParameters.
+// Parameters without check are written in config.yaml, which only reaches pods through the config hash.
+var runParameterCases = map[string]struct {
+	mutate func(p *v1.TunnelRunParameters)
+	check  func(g Gomega, daemon client.Object)
+}{
+	"edge-bind-address": {mutate: func(p *v1.TunnelRunParameters) { p.EdgeBindAddress = ptr.To("10.0.0.1") }},
+	"edge-ip-version": {
+		mutate: func(p *v1.TunnelRunParameters) { p.EdgeIPVersion = ptr.To(v1.EdgeIPVersion6) },
+	},
+	"grace-period": {
+		mutate: func(p *v1.TunnelRunParameters) { p.GracePeriod = &metav1.Duration{Duration: time.Minute} },
+		check: func(g Gomega, daemon client.Object) {
+			g.Expect(daemonContainer(g, daemon).Args).To(ContainElements("--grace-period", "1m0s"))
+			g.Expect(daemonPodTemplate(g, daemon).Spec.TerminationGracePeriodSeconds).To(HaveValue(BeEquivalentTo(65)))
+		},
+	},
+	"ha-connections":         {mutate: func(p *v1.TunnelRunParameters) { p.HAConnections = ptr.To[int32](2) }},
+	"label":                  {mutate: func(p *v1.TunnelRunParameters) { p.Label = ptr.To("edge") }},
+	"logfile":                {mutate: func(p *v1.TunnelRunParameters) { p.Logfile = ptr.To("/tmp/cloudflared.log") }},
+	"log-directory":          {mutate: func(p *v1.TunnelRunParameters) { p.LogDirectory = ptr.To("/tmp") }},
+	"loglevel":               {mutate: func(p *v1.TunnelRunParameters) { p.Loglevel = ptr.To(v1.LogLevelDebug) }},
+	"transport-loglevel":     {mutate: func(p *v1.TunnelRunParameters) { p.TransportLoglevel = ptr.To(v1.LogLevelWarn) }},
+	"management-diagnostics": {mutate: func(p *v1.TunnelRunParameters) { p.ManagementDiagnostics = ptr.To(true) }},
+	"max-fetch-size":         {mutate: func(p *v1.TunnelRunParameters) { p.MaxFetchSize = ptr.To[int32](100) }},
+	"pidfile":                {mutate: func(p *v1.TunnelRunParameters) { p.Pidfile = ptr.To("/tmp/cloudflared.pid") }},
+	"post-quantum":           {mutate: func(p *v1.TunnelRunParameters) { p.PostQuantum = ptr.To(true) }},
+	"protocol":               {mutate: func(p *v1.TunnelRunParameters) { p.Protocol = ptr.To(v1.TunnelProtocolQUIC) }},
+	"region":                 {mutate: func(p *v1.TunnelRunParameters) { p.Region = ptr.To(v1.EdgeRegionUS) }},
+	"retries":                {mutate: func(p *v1.TunnelRunParameters) { p.Retries = ptr.To(3) }},
+	"compression-quality":    {mutate: func(p *v1.TunnelRunParameters) { p.CompressionQuality = ptr.To[int32](1) }},
+	"icmpv4-src":             {mutate: func(p *v1.TunnelRunParameters) { p.ICMPv4Src = ptr.To("10.0.0.1") }},
+	"icmpv6-src":             {mutate: func(p *v1.TunnelRunParameters) { p.ICMPv6Src = ptr.To("fd00::1") }},
+	"tag": {
+		mutate: func(p *v1.TunnelRunParameters) { p.Tag = map[string]string{"zone": "b", "env": "prod"} },
+		check: func(g Gomega, daemon client.Object) {
+			args := daemonContainer(g, daemon).Args
+			g.Expect(strings.Join(args, " ")).To(ContainSubstring("--tag env=prod --tag zone=b run sample-tunnel"))
+		},
+	},
+}
+
+func TestBuildDaemonRunParameters(t *testing.T) {
+	t.Parallel()
+
+	baseline := buildTestDaemon(NewWithT(t), newTestTunnel(), testDaemonVersion)
+	for name, tc := range runParameterCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			tunnel := newTestTunnel()
+			tunnel.Spec.TunnelRunParameters = &v1.TunnelRunParameters{}
+			tc.mutate(tunnel.Spec.TunnelRunParameters)
+			daemon := buildTestDaemon(g, tunnel, testDaemonVersion)
+
+			configJSON, err := json.Marshal(configRunParameters(tunnel.Spec.TunnelRunParameters))
+			g.Expect(err).NotTo(HaveOccurred())
+			var configKeys map[string]any
+			g.Expect(json.Unmarshal(configJSON, &configKeys)).To(Succeed())
+
+			if tc.check != nil {
+				// passed as container args rather than config.yaml
+				g.Expect(configKeys).NotTo(HaveKey(name))
+				tc.check(g, daemon)
+				return
+			}
+			g.Expect(configKeys).To(HaveKey(name))
+			g.Expect(daemonPodTemplate(g, daemon).Annotations).
+				NotTo(Equal(daemonPodTemplate(g, baseline).Annotations), "config hash must change")
+			g.Expect(daemonContainer(g, daemon).Args).To(Equal(daemonContainer(g, baseline).Args))
+		})
+	}
+}
+
+func TestBuildDaemonDrainsGracefully(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	daemon := buildTestDaemon(g, newTestTunnel(), testDaemonVersion)
+	container := daemonContainer(g, daemon)
+
+	g.Expect(container.Args).To(ContainElements("--grace-period", defaultDaemonGracePeriod.String()))
+	g.Expect(daemonPodTemplate(g, daemon).Spec.TerminationGracePeriodSeconds).To(HaveValue(BeEquivalentTo(35)))
+	g.Expect(container.Lifecycle).To(BeNil())
+
+	g.Expect(container.ReadinessProbe).NotTo(BeNil())
+	g.Expect(container.ReadinessProbe.ProbeHandler).To(Equal(container.LivenessProbe.ProbeHandler))
+	g.Expect(probeFailureWindow(container.ReadinessProbe)).
+		To(BeNumerically("<", probeFailureWindow(container.LivenessProbe)))
+	g.Expect(container.ReadinessProbe.InitialDelaySeconds).To(BeZero())
+}
+
+// probeFailureWindow is the longest time that kubelet takes to notice the endpoint of probe failing.
+func probeFailureWindow(probe *corev1.Probe) time.Duration {
+	timeout := max(probe.TimeoutSeconds, 1)
+	return time.Duration(probe.PeriodSeconds*max(probe.FailureThreshold, 1)+timeout) * time.Second
+}
+
+func TestBuildDaemonReadinessFailsBeforeDrain(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		gracePeriod *metav1.Duration
+		liveness    *corev1.Probe
+	}{
+		"default":            {},
+		"short grace period": {gracePeriod: &metav1.Duration{Duration: 5 * time.Second}},
+		"slow liveness probe": {
+			liveness: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/ready", Port: intstr.FromInt32(2000)},
+				},
+				InitialDelaySeconds: 30,
+				TimeoutSeconds:      10,
+				PeriodSeconds:       30,
+				FailureThreshold:    3,
+			},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			tunnel := newTestTunnel()
+			tunnel.Spec.TunnelRunParameters = &v1.TunnelRunParameters{GracePeriod: tc.gracePeriod}
+			daemonCfg := config.Default().Daemon
+			if tc.liveness != nil {
+				daemonCfg.LivenessProbe = tc.liveness
+			}
+			daemon, err := buildDaemon(
+				buildDaemonImage(tunnel, daemonCfg, testDaemonVersion),
+				tunnel,
+				TunnelConfig{TunnelRunParameters: configRunParameters(tunnel.Spec.TunnelRunParameters)},
+				daemonCfg,
+			)
+			g.Expect(err).NotTo(HaveOccurred())
+			container := daemonContainer(g, daemon)
+			readiness := container.ReadinessProbe
+			g.Expect(readiness).NotTo(BeNil())
+			g.Expect(readiness.ProbeHandler).To(Equal(daemonCfg.LivenessProbe.ProbeHandler))
+
+			// a single slow response does not make the pod unready
+			g.Expect(readiness.FailureThreshold).To(BeNumerically(">", 1))
+			// cloudflared fails the probe once it starts draining for the grace period,
+			// so the pod must be unready before the drain ends and before liveness notices it
+			g.Expect(probeFailureWindow(readiness)).To(BeNumerically("<", daemonGracePeriod(tunnel)))
+			g.Expect(probeFailureWindow(readiness)).To(BeNumerically("<", probeFailureWindow(container.LivenessProbe)))
+		})
+	}
+}
+
+func TestCheckRunParametersSupported(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	params := &v1.TunnelRunParameters{
+		Region:      ptr.To(v1.EdgeRegionUS),
+		PostQuantum: ptr.To(true),
+		Retries:     ptr.To(3),
+	}
+	g.Expect(checkRunParametersSupported(params, "2023.4.1")).To(Succeed())
+	g.Expect(checkRunParametersSupported(params, "custom")).To(Succeed())
+	g.Expect(checkRunParametersSupported(nil, "2022.1.0")).To(Succeed())
+
+	err := checkRunParametersSupported(params, "2022.10.0")
+	g.Expect(err).To(MatchError(errUnsupportedRunParameter))
+	g.Expect(err.Error()).To(ContainSubstring("region"))
+	g.Expect(err.Error()).NotTo(ContainSubstring("post-quantum"))
+}
+
+// TestCheckRunParametersSupportedSince rejects each parameter on the release just before the one that added it.
+func TestCheckRunParametersSupportedSince(t *testing.T) {
+	t.Parallel()
+
+	for _, param := range runParameterSince {
+		param := param
+		t.Run(param.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			g.Expect(runParameterCases).To(HaveKey(param.name))
+			params := &v1.TunnelRunParameters{}
+			runParameterCases[param.name].mutate(params)
+			g.Expect(param.isSet(params)).To(BeTrue())
+
+			g.Expect(checkRunParametersSupported(params, param.since)).To(Succeed())
+			err := checkRunParametersSupported(params, previousDaemonVersion(g, param.since))
+			g.Expect(err).To(MatchError(errUnsupportedRunParameter))
+			g.Expect(err.Error()).To(ContainSubstring(param.name))
+		})
+	}
+}
+
+// previousDaemonVersion returns a release of cloudflared that is older than version.
+func previousDaemonVersion(g Gomega, version string) string {
+	var year, month, patch int
+	_, err := fmt.Sscanf(version, "%d.%d.%d", &year, &month, &patch)
+	g.Expect(err).NotTo(HaveOccurred())
+	switch {
+	case patch > 0:
+		patch--
+	case month > 1:
+		month--
+	default:
+		year, month = year-1, 12
+	}
+	return fmt.Sprintf("%d.%d.%d", year, month, patch)
+}
+
+// TestCheckRunParametersOfEarlierSchema reports values that Tunnels stored before the enums of the schema may have.
+func TestCheckRunParametersOfEarlierSchema(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	params := &v1.TunnelRunParameters{
+		Protocol: ptr.To[v1.TunnelProtocol]("h2mux"),
+		Region:   ptr.To(v1.EdgeRegionUS),
+	}
+	err := checkRunParametersSupported(params, testDaemonVersion)
+	g.Expect(err).To(MatchError(errUnsupportedRunParameter))
+	g.Expect(err.Error()).To(ContainSubstring(`protocol must be one of [auto http2 quic], but got "h2mux"`))
+	g.Expect(err.Error()).NotTo(ContainSubstring("region"))
+}
+
+func TestReconcileDaemonRejectsUnsupportedRunParameters(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	tunnel := newTestTunnel()
+	tunnel.Spec.DaemonDeployment.Image = &v1.DaemonImage{Tag: "2023.3.0"}
+	tunnel.Spec.TunnelRunParameters = &v1.TunnelRunParameters{Label: ptr.To("edge")}
+	r := newTestDaemonReconciler(g, tunnel)
+
+	_, err := r.reconcileDaemon(ctx, tunnel, TunnelConfig{
+		TunnelRunParameters: configRunParameters(tunnel.Spec.TunnelRunParameters),
+		Ingress:             []v1.TunnelConfigIngress{{Service: r.DaemonConfig.CatchAllService}},
+	})
+	g.Expect(err).To(MatchError(errUnsupportedRunParameter))
+	g.Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+
+	var deployment appsv1.Deployment
+	err = r.Get(ctx, client.ObjectKey{Namespace: tunnel.Namespace, Name: buildDaemonName(tunnel)}, &deployment)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	var stored v1.Tunnel
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(tunnel), &stored)).To(Succeed())
+	g.Expect(stored.Status.Conditions).To(ContainElement(And(
+		HaveField("Type", v1.TunnelConditionTypeDaemon),
+		HaveField("Reason", v1.DaemonReasonUnsupportedRunParameters),
+	)))
+}
+
+// TestBuildDaemonCoversEveryField fails when a field is added without a case above.
+func TestBuildDaemonCoversEveryField(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	for _, name := range jsonFieldNames(reflect.TypeOf(v1.Deployment{})) {
+		g.Expect(deploymentFieldCases).To(HaveKey(name))
+	}
+	for _, name := range jsonFieldNames(reflect.TypeOf(v1.TunnelRunParameters{})) {
+		g.Expect(runParameterCases).To(HaveKey(name))
+	}
+}
+
+func jsonFieldNames(typ reflect.Type) []string {
+	names := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return names
+}
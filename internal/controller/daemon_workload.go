@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+var errUnknownDeploymentKind = errors.New("unknown kind of daemon deployment")
+
+// daemonWorkload is a kind of workload that runs pods of cloudflared.
+// Metadata of workloads is filled by buildDaemon, so that every kind gets the same name, labels and annotations.
+type daemonWorkload interface {
+	// newObject returns an empty object of the kind, e.g. to get the existing one.
+	newObject() client.Object
+	// build returns the workload of tunnel that runs template, without metadata.
+	build(tunnel *v1.Tunnel, template corev1.PodTemplateSpec) client.Object
+	// specEqual reports whether specs of existing and desired are the same.
+	specEqual(existing, desired client.Object) bool
+}
+
+var daemonWorkloads = map[v1.DeploymentKind]daemonWorkload{
+	v1.DeploymentKindDeployment: deploymentWorkload{},
+	v1.DeploymentKindDaemonSet:  daemonSetWorkload{},
+}
+
+func daemonWorkloadOf(kind v1.DeploymentKind) (daemonWorkload, error) {
+	workload, ok := daemonWorkloads[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownDeploymentKind, kind)
+	}
+	return workload, nil
+}
+
+const (
+	managedLabelsAnnotation      = "cloudflared-operator.bhyoo.com/managed-labels"
+	managedAnnotationsAnnotation = "cloudflared-operator.bhyoo.com/managed-annotations"
+)
+
+// setManagedKeys records keys of labels and annotations of the built workload in its annotations,
+// so that keys dropped from the spec later can be removed without touching the ones added by others.
+func setManagedKeys(daemon client.Object) {
+	annotations := maps.Clone(daemon.GetAnnotations())
+	if annotations == nil {
+		annotations = make(map[string]string, 2)
+	}
+	annotations[managedAnnotationsAnnotation] = joinKeys(annotations)
+	annotations[managedLabelsAnnotation] = joinKeys(daemon.GetLabels())
+	daemon.SetAnnotations(annotations)
+}
+
+func joinKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, ",")
+}
+
+// managedKeys returns keys that the operator set on existing before, recorded by setManagedKeys.
+// Workloads of earlier releases have no record, so nothing is removed from them.
+func managedKeys(existing client.Object, annotation string) []string {
+	keys := existing.GetAnnotations()[annotation]
+	if keys == "" {
+		return nil
+	}
+	return strings.Split(keys, ",")
+}
+
+// hasDaemonMetadata reports whether existing has labels and annotations that withDaemonMetadata would leave as is.
+func hasDaemonMetadata(existing, desired client.Object) bool {
+	merged := withDaemonMetadata(existing, desired)
+	return maps.Equal(existing.GetLabels(), merged.GetLabels()) &&
+		maps.Equal(existing.GetAnnotations(), merged.GetAnnotations())
+}
+
+// withDaemonMetadata returns a copy of existing that has labels and annotations of desired merged.
+// There is no separate migration for workloads of earlier releases, e.g. DaemonSets that lack app.kubernetes.io
+// labels; the next reconciliation patches them through this.
+func withDaemonMetadata(existing, desired client.Object) client.Object {
+	merged := existing.DeepCopyObject().(client.Object)
+	merged.SetLabels(desired.GetLabels())
+	merged.SetAnnotations(desired.GetAnnotations())
+	mergeDaemonMetadata(existing, merged)
+	return merged
+}
+
+// mergeDaemonMetadata merges labels and annotations of existing into desired, which overrides the same keys.
+// So updates of the workload keep labels and annotations added by others, as withDaemonMetadata does,
+// and remove only the ones that the operator set before but desired no longer has.
+func mergeDaemonMetadata(existing, desired client.Object) {
+	desired.SetLabels(mergedMetadata(
+		existing.GetLabels(),
+		desired.GetLabels(),
+		managedKeys(existing, managedLabelsAnnotation),
+	))
+	desired.SetAnnotations(mergedMetadata(
+		existing.GetAnnotations(),
+		desired.GetAnnotations(),
+		managedKeys(existing, managedAnnotationsAnnotation),
+	))
+}
+
+func mergedMetadata(existing, desired map[string]string, managed []string) map[string]string {
+	merged := maps.Clone(existing)
+	if merged == nil {
+		merged = make(map[string]string, len(desired))
+	}
+	for _, key := range managed {
+		if _, ok := desired[key]; !ok {
+			delete(merged, key)
+		}
+	}
+	maps.Copy(merged, desired)
+	return merged
+}
+
+type deploymentWorkload struct{}
+
+func (deploymentWorkload) newObject() client.Object {
+	return &appsv1.Deployment{}
+}
+
+func (deploymentWorkload) build(tunnel *v1.Tunnel, template corev1.PodTemplateSpec) client.Object {
+	replicas := tunnel.Spec.DaemonDeployment.Replicas
+	if isAutoscaled(tunnel) {
+		replicas = ptr.To(ptr.Deref(tunnel.Spec.DaemonDeployment.Autoscaling.MinReplicas, 1))
+	}
+	return &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: daemonSelectorLabels(tunnel),
+			},
+			Template:                template,
+			Strategy:                daemonDeploymentStrategy(tunnel),
+			MinReadySeconds:         tunnel.Spec.DaemonDeployment.MinReadySeconds,
+			RevisionHistoryLimit:    tunnel.Spec.DaemonDeployment.RevisionHistoryLimit,
+			Paused:                  false,
+			ProgressDeadlineSeconds: nil,
+		},
+	}
+}
+
+func (deploymentWorkload) specEqual(existing, desired client.Object) bool {
+	a, ok := existing.(*appsv1.Deployment)
+	if !ok {
+		return false
+	}
+	b, ok := desired.(*appsv1.Deployment)
+	if !ok {
+		return false
+	}
+	return reflect.DeepEqual(a.Spec, b.Spec)
+}
+
+type daemonSetWorkload struct{}
+
+func (daemonSetWorkload) newObject() client.Object {
+	return &appsv1.DaemonSet{}
+}
+
+func (daemonSetWorkload) build(tunnel *v1.Tunnel, template corev1.PodTemplateSpec) client.Object {
+	return &appsv1.DaemonSet{
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: daemonSelectorLabels(tunnel),
+			},
+			Template:             template,
+			UpdateStrategy:       daemonSetUpdateStrategy(tunnel),
+			MinReadySeconds:      tunnel.Spec.DaemonDeployment.MinReadySeconds,
+			RevisionHistoryLimit: tunnel.Spec.DaemonDeployment.RevisionHistoryLimit,
+		},
+	}
+}
+
+func (daemonSetWorkload) specEqual(existing, desired client.Object) bool {
+	a, ok := existing.(*appsv1.DaemonSet)
+	if !ok {
+		return false
+	}
+	b, ok := desired.(*appsv1.DaemonSet)
+	if !ok {
+		return false
+	}
+	return reflect.DeepEqual(a.Spec, b.Spec)
+}
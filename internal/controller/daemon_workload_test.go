@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func TestGetExistingDaemonsTakesOtherKindsAsOrphans(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tunnel := newTestTunnel()
+	deployment := buildTestDaemon(g, tunnel, testDaemonVersion)
+	tunnel.Spec.DaemonDeployment.Kind = v1.DeploymentKindDaemonSet
+	daemonSet := buildTestDaemon(g, tunnel, testDaemonVersion)
+	r := newTestReconciler(g, deployment, daemonSet)
+
+	existing, orphans, err := r.getExistingDaemons(context.Background(), tunnel)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(existing.workload).To(BeAssignableToTypeOf(&appsv1.DaemonSet{}))
+	g.Expect(orphans).To(HaveExactElements(BeAssignableToTypeOf(&appsv1.Deployment{})))
+
+	tunnel.Spec.DaemonDeployment.Kind = "StatefulSet"
+	_, _, err = r.getExistingDaemons(context.Background(), tunnel)
+	g.Expect(err).To(MatchError(errUnknownDeploymentKind))
+}
+
+// newTestDaemonReconciler returns a reconciler whose cluster has tunnel and objects.
+func newTestDaemonReconciler(g Gomega, tunnel *v1.Tunnel, objects ...client.Object) *TunnelReconciler {
+	r := newTestReconciler(g)
+	r.Client = ctrlfake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(tunnel).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1.Tunnel{}).
+		Build()
+	r.Clock = clock.RealClock{}
+	return r
+}
+
+func reconcileTestDaemon(g Gomega, r *TunnelReconciler, tunnel *v1.Tunnel) {
+	_, err := r.reconcileDaemon(context.Background(), tunnel, TunnelConfig{
+		TunnelRunParameters: configRunParameters(tunnel.Spec.TunnelRunParameters),
+		Ingress:             []v1.TunnelConfigIngress{{Service: r.DaemonConfig.CatchAllService}},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+// TestMigrateDaemonSetMetadata reconciles a DaemonSet of earlier releases,
+// which had only labels of the spec instead of the labels of Deployment.
+// There is no separate migration, the reconciliation patches its metadata.
+func TestMigrateDaemonSetMetadata(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	tunnel := newTestTunnel()
+	tunnel.Spec.DaemonDeployment.Kind = v1.DeploymentKindDaemonSet
+	tunnel.Spec.DaemonDeployment.Image = &v1.DaemonImage{Tag: testDaemonVersion}
+	tunnel.Spec.DaemonDeployment.Labels = map[string]string{"team": "edge"}
+	desired := buildTestDaemon(g, tunnel, testDaemonVersion)
+
+	legacy := desired.DeepCopyObject().(client.Object)
+	legacy.SetLabels(map[string]string{"team": "edge", "argocd.argoproj.io/instance": "edge"})
+	legacy.SetAnnotations(map[string]string{
+		"cloudflared-operator.bhyoo.com/config-hash": desired.GetAnnotations()["cloudflared-operator.bhyoo.com/config-hash"],
+	})
+	g.Expect(isDaemonEqualTo(legacy, desired, v1.DeploymentKindDaemonSet)).To(BeTrue())
+	g.Expect(hasDaemonMetadata(legacy, desired)).To(BeFalse())
+
+	r := newTestDaemonReconciler(g, tunnel, legacy)
+	reconcileTestDaemon(g, r, tunnel)
+
+	var patched appsv1.DaemonSet
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(desired), &patched)).To(Succeed())
+	g.Expect(hasDaemonMetadata(&patched, desired)).To(BeTrue())
+	g.Expect(patched.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "cloudflared"))
+	g.Expect(patched.Annotations).To(HaveKeyWithValue(managedLabelsAnnotation, joinKeys(desired.GetLabels())))
+	// labels of others are kept
+	g.Expect(patched.Labels).To(HaveKeyWithValue("argocd.argoproj.io/instance", "edge"))
+	g.Expect(patched.Spec).To(Equal(desired.(*appsv1.DaemonSet).Spec))
+}
+
+// TestReconcileDaemonKeepsMetadataOfOthers updates the workload of changed spec,
+// removing labels and annotations dropped from the spec but keeping the ones added by others.
+func TestReconcileDaemonKeepsMetadataOfOthers(t *testing.T) {
+	t.Parallel()
+
+	for _, kind := range []v1.DeploymentKind{v1.DeploymentKindDeployment, v1.DeploymentKindDaemonSet} {
+		kind := kind
+		t.Run(string(kind), func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			tunnel := newTestTunnel()
+			tunnel.Spec.DaemonDeployment.Kind = kind
+			tunnel.Spec.DaemonDeployment.Image = &v1.DaemonImage{Tag: testDaemonVersion}
+			tunnel.Spec.DaemonDeployment.Labels = map[string]string{"team": "edge", "tier": "front"}
+			tunnel.Spec.DaemonDeployment.Annotations = map[string]string{"owner": "edge"}
+			existing := buildTestDaemon(g, tunnel, testDaemonVersion)
+			existing.GetLabels()["argocd.argoproj.io/instance"] = "edge"
+			existing.GetAnnotations()["note"] = "kept"
+
+			for name, mutate := range map[string]func(tunnel *v1.Tunnel){
+				// the change of spec makes the reconciler update the workload
+				"update": func(tunnel *v1.Tunnel) { tunnel.Spec.DaemonDeployment.MinReadySeconds = 10 },
+				// metadata alone is patched
+				"patch": func(*v1.Tunnel) {},
+			} {
+				tunnel := tunnel.DeepCopy()
+				mutate(tunnel)
+				tunnel.Spec.DaemonDeployment.Labels = map[string]string{"team": "network"}
+				tunnel.Spec.DaemonDeployment.Annotations = nil
+				r := newTestDaemonReconciler(g, tunnel, existing.DeepCopyObject().(client.Object))
+				reconcileTestDaemon(g, r, tunnel)
+
+				updated := daemonWorkloads[kind].newObject()
+				g.Expect(r.Get(ctx, client.ObjectKeyFromObject(existing), updated)).To(Succeed())
+				g.Expect(updated.GetLabels()).To(HaveKeyWithValue("team", "network"), name)
+				g.Expect(updated.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/name", "cloudflared"), name)
+				g.Expect(updated.GetLabels()).To(HaveKeyWithValue("argocd.argoproj.io/instance", "edge"), name)
+				g.Expect(updated.GetLabels()).NotTo(HaveKey("tier"), name)
+				g.Expect(updated.GetAnnotations()).To(HaveKeyWithValue("note", "kept"), name)
+				g.Expect(updated.GetAnnotations()).NotTo(HaveKey("owner"), name)
+			}
+		})
+	}
+}
+
+func TestReconcileDaemonDropsUnsupportedPreStop(t *testing.T) {
+	t.Parallel()
+
+	for _, unsupported := range []bool{false, true} {
+		unsupported := unsupported
+		t.Run(fmt.Sprintf("unsupported=%t", unsupported), func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			tunnel := newTestTunnel()
+			tunnel.Spec.DaemonDeployment.Image = &v1.DaemonImage{Tag: testDaemonVersion}
+			tunnel.Spec.DaemonDeployment.PreStopDelay = &metav1.Duration{Duration: 10 * time.Second}
+			r := newTestDaemonReconciler(g, tunnel)
+			r.PreStopSleepUnsupported = unsupported
+			reconcileTestDaemon(g, r, tunnel)
+
+			var deployment appsv1.Deployment
+			g.Expect(r.Get(ctx, client.ObjectKey{Namespace: tunnel.Namespace, Name: buildDaemonName(tunnel)}, &deployment)).
+				To(Succeed())
+			container := daemonContainer(g, &deployment)
+			if unsupported {
+				g.Expect(container.Lifecycle).To(BeNil())
+				g.Expect(deployment.Spec.Template.Spec.TerminationGracePeriodSeconds).To(HaveValue(BeEquivalentTo(35)))
+			} else {
+				g.Expect(container.Lifecycle.PreStop.Sleep.Seconds).To(BeEquivalentTo(10))
+			}
+			// the spec of the tunnel is left as is
+			g.Expect(tunnel.Spec.DaemonDeployment.PreStopDelay).NotTo(BeNil())
+		})
+	}
+}
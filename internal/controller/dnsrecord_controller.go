@@ -0,0 +1,188 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const (
+	dnsRecordFinalizerName = "dnsrecord.cloudflared-operator.bhyoo.com/finalizer"
+
+	// dnsRecordResyncPeriod is how often records are compared with Cloudflare to restore manual edits.
+	dnsRecordResyncPeriod = 10 * time.Minute
+)
+
+// DNSRecordReconciler reconciles a DNSRecord object
+type DNSRecordReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Clock  clock.PassiveClock
+
+	CloudflareClients *cloudflare.ClientPool
+}
+
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=dnsrecords,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=dnsrecords/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=dnsrecords/finalizers,verbs=update
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
+func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx).WithValues("dnsRecordName", req.Name)
+	ctx = log.IntoContext(ctx, l)
+
+	var record v1.DNSRecord
+	if err := r.Get(ctx, req.NamespacedName, &record); err != nil {
+		l.Error(err, "unable to fetch DNSRecord")
+		// we'll ignore not-found errors, since they can't be fixed by an immediate
+		// requeue (we'll need to wait for a new notification), and we can get them
+		// on deleted requests.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// examine DeletionTimestamp to determine if object is under deletion
+	if !record.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&record, dnsRecordFinalizerName) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.deleteDNSRecord(ctx, &record); err != nil {
+			// if fail to delete the external dependency here, return with error
+			// so that it can be retried.
+			return ctrl.Result{}, err
+		}
+
+		if controllerutil.RemoveFinalizer(&record, dnsRecordFinalizerName) {
+			return ctrl.Result{}, r.Update(ctx, &record)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// The object is not being deleted, so if it does not have our finalizer,
+	// then lets add the finalizer and update the object. This is equivalent
+	// to registering our finalizer.
+	if !controllerutil.ContainsFinalizer(&record, dnsRecordFinalizerName) {
+		controllerutil.AddFinalizer(&record, dnsRecordFinalizerName)
+		if err := r.Update(ctx, &record); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.reconcileRecord(ctx, &record); err != nil {
+		return ctrl.Result{}, err
+	}
+	// Cloudflare does not notify changes, so check the drift periodically
+	return ctrl.Result{RequeueAfter: dnsRecordResyncPeriod}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DNSRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.DNSRecord{}).
+		Watches(
+			&v1.TunnelPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.findRecordsForPolicy),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findRecordsForNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
+		Complete(requeueOnRateLimit(r))
+}
+
+func (r *DNSRecordReconciler) buildConditionRecorder(
+	ctx context.Context,
+	record *v1.DNSRecord,
+	condType v1.DNSRecordConditionType,
+) func(err error) error {
+	return func(err error) (cause error) {
+		defer func() {
+			if errors.Is(err, reconcile.TerminalError(nil)) &&
+				!errors.Is(cause, reconcile.TerminalError(nil)) {
+				cause = reconcile.TerminalError(cause)
+			}
+		}()
+
+		cause = err
+		var reason v1.DNSRecordConditionReason = ""
+		var withReason ReasonedError[v1.DNSRecordConditionReason]
+		if errors.As(err, &withReason) {
+			cause = withReason.Cause()
+			reason = withReason.Reason
+		}
+
+		newCond := v1.DNSRecordStatusCondition{
+			Type:               condType,
+			Status:             corev1.ConditionFalse,
+			Message:            "",
+			Error:              fmt.Sprintf("%+v", cause),
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             reason,
+		}
+
+		if status, ok := cause.(apierrors.APIStatus); ok || errors.As(cause, &status) {
+			newCond.Error = string(status.Status().Reason)
+			newCond.Message = status.Status().Message
+		}
+
+		if !UpdateConditionIfChanged(&record.Status, newCond) {
+			return cause
+		}
+
+		if updateErr := r.Status().Update(ctx, record); updateErr != nil {
+			return errors.Join(cause, updateErr)
+		}
+		return cause
+	}
+}
+
+func (r *DNSRecordReconciler) updateConditionIfDiff(
+	ctx context.Context,
+	record *v1.DNSRecord,
+	cond v1.DNSRecordStatusCondition,
+) error {
+	if UpdateConditionIfChanged(&record.Status, cond) {
+		return r.Status().Update(ctx, record)
+	}
+	return nil
+}
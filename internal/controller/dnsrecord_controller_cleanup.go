@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"context"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func (r *DNSRecordReconciler) deleteDNSRecord(ctx context.Context, record *v1.DNSRecord) error {
+	if record.Status.RecordID == "" {
+		return nil
+	}
+
+	cfClient, err := r.getCloudflareClient(ctx, record)
+	if err != nil {
+		if isUnusableAccountError(err) {
+			return nil
+		}
+		return err
+	}
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, record, v1.DNSRecordConditionTypeRecord)
+	if err = cfClient.DeleteDNSRecordByID(
+		ctx,
+		record.Status.AccountID,
+		record.Status.Name,
+		record.Status.RecordID,
+	); err != nil {
+		return recordConditionFrom(WrapError(err, v1.RecordReasonFailedToDeleteRecord))
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/policy"
+)
+
+// autoTTL lets Cloudflare decide TTL of the record. Proxied records always have it.
+const autoTTL = 1
+
+func (r *DNSRecordReconciler) reconcileRecord(ctx context.Context, record *v1.DNSRecord) error {
+	l := log.FromContext(ctx)
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, record, v1.DNSRecordConditionTypeRecord)
+
+	if err := policy.CheckDNSRecord(ctx, r, record, false); err != nil {
+		return r.withdrawRecord(ctx, record, err)
+	}
+
+	cfClient, err := r.getCloudflareClient(ctx, record)
+	if err != nil {
+		return recordConditionFrom(err)
+	}
+	accountID := record.Spec.AccountID
+	desired := buildDNSRecord(record)
+
+	// name or account has been changed. remove previous one first, since the zone might be changed as well.
+	if record.Status.RecordID != "" && (record.Status.Name != desired.Name || record.Status.AccountID != accountID) {
+		l.Info("DNS record name has been changed. deleting previous one", "previous", record.Status.Name)
+		if err = cfClient.DeleteDNSRecordByID(
+			ctx,
+			record.Status.AccountID,
+			record.Status.Name,
+			record.Status.RecordID,
+		); err != nil {
+			return recordConditionFrom(WrapError(err, v1.RecordReasonFailedToDeleteRecord))
+		}
+		record.Status.RecordID = ""
+		record.Status.Name = ""
+		record.Status.AccountID = ""
+	}
+
+	var existing *cloudflare.DNSRecord
+	if record.Status.RecordID != "" {
+		existing, err = cfClient.GetDNSRecord(ctx, accountID, desired.Name, record.Status.RecordID)
+	} else {
+		// adopt the one that has been created manually or by previous reconciliation that failed to update status
+		existing, err = cfClient.FindDNSRecord(ctx, accountID, desired)
+	}
+	if err != nil {
+		return recordConditionFrom(WrapError(err, v1.RecordReasonFailedToGetRecord))
+	}
+
+	switch {
+	case existing == nil:
+		if err := r.updateConditionIfDiff(ctx, record, v1.DNSRecordStatusCondition{
+			Type:               v1.DNSRecordConditionTypeRecord,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.RecordReasonCreating,
+		}); err != nil {
+			return err
+		}
+		desired.ID, err = cfClient.CreateDNSRecord(ctx, accountID, desired)
+		if err != nil {
+			return recordConditionFrom(WrapError(err, v1.RecordReasonFailedToCreateRecord))
+		}
+
+	default:
+		desired.ID = existing.ID
+		if cloudflare.IsDNSRecordChanged(existing, &desired) {
+			// adopting a record that differs from spec overwrites the one created by others
+			if record.Status.RecordID == "" {
+				if err := policy.CheckDNSRecord(ctx, r, record, true); err != nil {
+					return r.withdrawRecord(ctx, record, err)
+				}
+			}
+			l.Info("DNS record differs from spec. restoring...")
+			if err = cfClient.UpdateDNSRecord(ctx, accountID, desired); err != nil {
+				return recordConditionFrom(WrapError(err, v1.RecordReasonFailedToUpdateRecord))
+			}
+		}
+	}
+
+	var dirtyStatus bool
+	if record.Status.RecordID != desired.ID || record.Status.Name != desired.Name ||
+		record.Status.AccountID != accountID {
+		dirtyStatus = true
+		record.Status.RecordID = desired.ID
+		record.Status.Name = desired.Name
+		record.Status.AccountID = accountID
+	}
+
+	if UpdateConditionIfChanged(&record.Status, v1.DNSRecordStatusCondition{
+		Type:               v1.DNSRecordConditionTypeRecord,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	}) {
+		dirtyStatus = true
+	}
+
+	if dirtyStatus {
+		return r.Status().Update(ctx, record)
+	}
+	return nil
+}
+
+// withdrawRecord records why the record is not permitted by TunnelPolicies.
+// The record created while it was permitted is deleted, as the policy no longer allows the namespace to claim it.
+func (r *DNSRecordReconciler) withdrawRecord(ctx context.Context, record *v1.DNSRecord, denied error) error {
+	recordConditionFrom := r.buildConditionRecorder(ctx, record, v1.DNSRecordConditionTypeRecord)
+	if !errors.Is(denied, policy.ErrViolation) {
+		return recordConditionFrom(denied)
+	}
+
+	if err := r.deleteDNSRecord(ctx, record); err != nil {
+		return err
+	}
+	record.Status.RecordID = ""
+	record.Status.Name = ""
+	record.Status.AccountID = ""
+	// TunnelPolicies and Namespaces are watched
+	return recordConditionFrom(reconcile.TerminalError(WrapError(denied, v1.RecordReasonPolicyViolation)))
+}
+
+func buildDNSRecord(record *v1.DNSRecord) cloudflare.DNSRecord {
+	res := cloudflare.DNSRecord{
+		Type:    record.Spec.Type,
+		Name:    record.Spec.Name,
+		Content: ptr.Deref(record.Spec.Content, ""),
+		Proxied: ptr.Deref(record.Spec.Proxied, false),
+		TTL:     int(ptr.Deref(record.Spec.TTL, autoTTL)),
+		Comment: ptr.Deref(record.Spec.Comment, ""),
+		Tags:    record.Spec.Tags,
+	}
+	if res.Proxied {
+		res.TTL = autoTTL
+	}
+	if record.Spec.Priority != nil {
+		res.Priority = ptr.To(uint16(*record.Spec.Priority))
+	}
+	if srv := record.Spec.SRV; srv != nil {
+		res.SRV = &cloudflare.SRVRecordData{
+			Weight: uint16(srv.Weight),
+			Port:   uint16(srv.Port),
+			Target: srv.Target,
+		}
+	}
+	if caa := record.Spec.CAA; caa != nil {
+		res.CAA = &cloudflare.CAARecordData{
+			Flags: uint8(caa.Flags),
+			Tag:   caa.Tag,
+			Value: caa.Value,
+		}
+	}
+	return res
+}
+
+func (r *DNSRecordReconciler) getCloudflareClient(
+	ctx context.Context,
+	record *v1.DNSRecord,
+) (cloudflare.Client, error) {
+	return newCloudflareClient(
+		ctx,
+		r,
+		r.CloudflareClients,
+		record.Spec.AccountID,
+		record.Namespace,
+		record.Spec.APITokenSecretRef,
+		v1.RecordReasonNoToken,
+		v1.RecordReasonFailedToConnectCF,
+		v1.RecordReasonAccountNotAllowed,
+	)
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cf "github.com/cloudflare/cloudflare-go"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare/fake"
+)
+
+// newTestRecordReconciler returns a reconciler of DNSRecord against server, whose records are in namespace apps.
+func newTestRecordReconciler(g Gomega, server *fake.Server, objects ...client.Object) *DNSRecordReconciler {
+	opts := cloudflare.DefaultClientOptions()
+	opts.BaseURL = server.URL + "/client/v4"
+	opts.MaxRetries = 0
+
+	base := newTestReconciler(g)
+	c := ctrlfake.NewClientBuilder().
+		WithScheme(base.Scheme).
+		WithObjects(newTestNamespace("apps")).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "apps"},
+			Data:       map[string][]byte{apiTokenKey: []byte("token")},
+		}).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1.DNSRecord{}).
+		Build()
+
+	return &DNSRecordReconciler{
+		Client:            c,
+		Scheme:            base.Scheme,
+		Clock:             clock.RealClock{},
+		CloudflareClients: cloudflare.NewClientPool(opts, clock.RealClock{}),
+	}
+}
+
+func newTestDNSRecord() *v1.DNSRecord {
+	return &v1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "web",
+			Namespace:  "apps",
+			Finalizers: []string{dnsRecordFinalizerName},
+		},
+		Spec: v1.DNSRecordSpec{
+			Name:              "web.example.com",
+			Type:              "A",
+			Content:           ptr.To("192.0.2.1"),
+			Proxied:           ptr.To(true),
+			AccountID:         testIngressAccountID,
+			APITokenSecretRef: v1.SecretKeyRef{Name: "token"},
+		},
+	}
+}
+
+func TestDNSRecordChecksPolicyBeforeOverwriting(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		allowOverwrite bool
+		overwritten    bool
+	}{
+		"denied":  {allowOverwrite: false, overwritten: false},
+		"allowed": {allowOverwrite: true, overwritten: true},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			server := fake.NewServer()
+			t.Cleanup(server.Close)
+			zone := server.AddZone(testIngressAccountID, "example.com")
+			// created by others, with the same content but not proxied
+			server.AddDNSRecord(cf.DNSRecord{ZoneID: zone.ID, Type: "A", Name: "web.example.com", Content: "192.0.2.1"})
+
+			record := newTestDNSRecord()
+			r := newTestRecordReconciler(g, server, record, &v1.TunnelPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "apps"},
+				Spec:       v1.TunnelPolicySpec{AllowOverwriteExistingDNS: tc.allowOverwrite},
+			})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(record)})
+			g.Expect(r.Get(ctx, client.ObjectKeyFromObject(record), record)).To(Succeed())
+			records := server.DNSRecords("web.example.com")
+			g.Expect(records).To(HaveLen(1))
+			g.Expect(ptr.Deref(records[0].Proxied, false)).To(Equal(tc.overwritten))
+
+			cond := record.Status.GetCondition(v1.DNSRecordConditionTypeRecord)
+			if tc.overwritten {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+				g.Expect(record.Status.RecordID).To(Equal(records[0].ID))
+				return
+			}
+			g.Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+			g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(cond.Reason).To(Equal(v1.RecordReasonPolicyViolation))
+			g.Expect(record.Status.RecordID).To(BeEmpty())
+		})
+	}
+}
+
+func TestDNSRecordWithdrawsRecordOfDeniedHostname(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	server.AddZone(testIngressAccountID, "example.com")
+
+	record := newTestDNSRecord()
+	policy := &v1.TunnelPolicy{ObjectMeta: metav1.ObjectMeta{Name: "apps"}}
+	r := newTestRecordReconciler(g, server, record, policy)
+	key := client.ObjectKeyFromObject(record)
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(server.DNSRecords("web.example.com")).To(HaveLen(1))
+
+	g.Expect(r.Get(ctx, client.ObjectKeyFromObject(policy), policy)).To(Succeed())
+	policy.Spec.AllowedHostnames = []v1.HostnamePattern{"*.other.example.com"}
+	g.Expect(r.Update(ctx, policy)).To(Succeed())
+
+	_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	g.Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+	g.Expect(server.DNSRecords("web.example.com")).To(BeEmpty())
+
+	g.Expect(r.Get(ctx, key, record)).To(Succeed())
+	g.Expect(record.Status.GetCondition(v1.DNSRecordConditionTypeRecord).Reason).
+		To(Equal(v1.RecordReasonPolicyViolation))
+	g.Expect(record.Status.RecordID).To(BeEmpty())
+}
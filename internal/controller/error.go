@@ -3,7 +3,9 @@ package controller
 import v1 "github.com/isac322/cloudflared-operator/api/v1"
 
 type Reasons interface {
-	v1.TunnelConditionReason | v1.TunnelIngressConditionReason
+	v1.TunnelConditionReason | v1.TunnelIngressConditionReason | v1.AccessServiceTokenConditionReason |
+		v1.TunnelNetworkRouteConditionReason | v1.VirtualNetworkConditionReason | v1.LoadBalancerPoolConditionReason |
+		v1.LoadBalancerConditionReason | v1.DNSRecordConditionReason
 }
 
 type ReasonedError[T Reasons] struct {
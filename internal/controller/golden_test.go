@@ -0,0 +1,382 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/config"
+)
+
+// go test ./internal/controller -run TestBuildTunnelGolden -update
+var updateGolden = flag.Bool("update", false, "regenerate golden files in testdata/golden")
+
+var goldenEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestIngress(namespace, name string, created int, spec v1.TunnelIngressSpec) *v1.TunnelIngress {
+	if spec.TunnelRef.Name == "" {
+		spec.TunnelRef = v1.TunnelRef{Name: "sample", Kind: v1.TunnelKindTunnel}
+	}
+	return &v1.TunnelIngress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			CreationTimestamp: metav1.NewTime(goldenEpoch.Add(time.Duration(created) * time.Minute)),
+		},
+		Spec: spec,
+	}
+}
+
+func newTestNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+// goldenCases are keyed by the name of golden file. objects are what buildConfig finds in the cluster.
+var goldenCases = map[string]struct {
+	mutate  func(tunnel *v1.Tunnel)
+	objects []client.Object
+}{
+	"deployment": {
+		mutate: func(*v1.Tunnel) {},
+		objects: []client.Object{
+			newTestIngress("default", "web", 0, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To("web.example.com"),
+					Service:  "http://web.default.svc:8080",
+				},
+			}),
+			newTestIngress("default", "api", 1, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To("web.example.com"),
+					Path:     ptr.To("^/api/"),
+					Service:  "http://api.default.svc:8080",
+					OriginRequest: &v1.OriginRequestConfig{
+						OriginHTTPSettings: &v1.OriginHTTPSettings{HTTPHostHeader: ptr.To("api.internal")},
+					},
+				},
+			}),
+		},
+	},
+	"daemonset": {
+		mutate: func(tunnel *v1.Tunnel) {
+			tunnel.Spec.DaemonDeployment.Kind = v1.DeploymentKindDaemonSet
+			tunnel.Spec.DaemonDeployment.NodeSelector = map[string]string{"node-role.kubernetes.io/edge": ""}
+		},
+		objects: []client.Object{
+			newTestIngress("default", "web", 0, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To("web.example.com"),
+					Service:  "http://web.default.svc:8080",
+				},
+			}),
+		},
+	},
+	"run-parameters": {
+		mutate: func(tunnel *v1.Tunnel) {
+			tunnel.Spec.DaemonDeployment.PreStopDelay = &metav1.Duration{Duration: 5 * time.Second}
+			tunnel.Spec.TunnelRunParameters = &v1.TunnelRunParameters{
+				EdgeIPVersion:         ptr.To(v1.EdgeIPVersion6),
+				GracePeriod:           &metav1.Duration{Duration: time.Minute},
+				HAConnections:         ptr.To[int32](2),
+				Loglevel:              ptr.To(v1.LogLevelDebug),
+				TransportLoglevel:     ptr.To(v1.LogLevelWarn),
+				ManagementDiagnostics: ptr.To(true),
+				PostQuantum:           ptr.To(true),
+				Protocol:              ptr.To(v1.TunnelProtocolQUIC),
+				Region:                ptr.To(v1.EdgeRegionUS),
+				Retries:               ptr.To(3),
+				Tag:                   map[string]string{"env": "prod", "team": "edge"},
+			}
+		},
+	},
+	"origin": {
+		mutate: func(tunnel *v1.Tunnel) {
+			tunnel.Spec.OriginConfiguration = &v1.OriginConfiguration{
+				TLSSettings: &v1.OriginTLSSettings{
+					NoTLSVerify: ptr.To(true),
+					TLSTimeout:  &metav1.Duration{Duration: 10 * time.Second},
+				},
+				ConnectionSettings: &v1.OriginConnectionSettings{
+					ConnectTimeout:   ptr.To("30s"),
+					KeepAliveTimeout: &metav1.Duration{Duration: 90 * time.Second},
+				},
+				AccessSettings: &v1.OriginAccessSettings{
+					Access: &v1.OriginAccessSettingsAccess{
+						Required: ptr.To(true),
+						TeamName: ptr.To("example"),
+						AudTag:   []string{"aud"},
+					},
+				},
+			}
+		},
+		objects: []client.Object{
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)}},
+				},
+			},
+			newTestIngress("default", "web", 0, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{Hostname: ptr.To("web.example.com")},
+				Origin: &v1.IngressOrigin{
+					Scheme:     v1.OriginSchemeHTTP,
+					BackendRef: &v1.BackendRef{Name: "web", Port: intstr.FromString("http")},
+				},
+			}),
+			newTestIngress("default", "socket", 1, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{Hostname: ptr.To("socket.example.com")},
+				Origin:              &v1.IngressOrigin{Scheme: v1.OriginSchemeUnix, Address: "/run/app.sock"},
+			}),
+			newTestIngress("default", "gone", 2, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{Hostname: ptr.To("gone.example.com")},
+				Origin: &v1.IngressOrigin{
+					Scheme:     v1.OriginSchemeHTTP,
+					BackendRef: &v1.BackendRef{Name: "missing", Port: intstr.FromInt32(80)},
+				},
+			}),
+			newTestIngress("default", "maintenance", 3, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{Hostname: ptr.To("old.example.com")},
+				Origin:              &v1.IngressOrigin{Scheme: v1.OriginSchemeHTTPStatus, Address: "503"},
+			}),
+		},
+	},
+	"cross-namespace": {
+		mutate: func(*v1.Tunnel) {},
+		objects: []client.Object{
+			newTestNamespace("team-a"),
+			newTestNamespace("team-b"),
+			&v1.TunnelReferenceGrant{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "default"},
+				Spec: v1.TunnelReferenceGrantSpec{
+					From: []v1.ReferenceGrantFrom{{Kind: v1.ReferenceGrantFromKindTunnelIngress, Namespace: "team-a"}},
+					To:   []v1.ReferenceGrantTo{{Kind: v1.ReferenceGrantToKindTunnel, Name: ptr.To("sample")}},
+				},
+			},
+			newTestIngress("team-a", "granted", 0, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To("a.example.com"),
+					Service:  "http://web.team-a.svc:8080",
+				},
+				TunnelRef: v1.TunnelRef{Name: "sample", Namespace: ptr.To("default"), Kind: v1.TunnelKindTunnel},
+			}),
+			newTestIngress("team-b", "not-granted", 1, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To("b.example.com"),
+					Service:  "http://web.team-b.svc:8080",
+				},
+				TunnelRef: v1.TunnelRef{Name: "sample", Namespace: ptr.To("default"), Kind: v1.TunnelKindTunnel},
+			}),
+		},
+	},
+	"surge-rollout": {
+		mutate: func(tunnel *v1.Tunnel) {
+			tunnel.Spec.ConfigReloadStrategy = v1.ConfigReloadStrategySurgeRollout
+			tunnel.Spec.DaemonDeployment.Replicas = ptr.To[int32](3)
+		},
+		objects: []client.Object{
+			newTestIngress("default", "web", 0, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To("web.example.com"),
+					Service:  "http://web.default.svc:8080",
+				},
+			}),
+		},
+	},
+	"remote-managed": {
+		mutate: func(tunnel *v1.Tunnel) {
+			tunnel.Spec.ConfigReloadStrategy = v1.ConfigReloadStrategyRemoteManaged
+			tunnel.Spec.TunnelRunParameters = &v1.TunnelRunParameters{Protocol: ptr.To(v1.TunnelProtocolHTTP2)}
+		},
+		objects: []client.Object{
+			newTestIngress("default", "ssh", 0, v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To("ssh.example.com"),
+					Service:  "ssh://bastion.default.svc:22",
+				},
+			}),
+			&v1.TunnelNetworkRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+				Spec: v1.TunnelNetworkRouteSpec{
+					TunnelRef: v1.TunnelRef{Name: "sample", Kind: v1.TunnelKindTunnel},
+					Network:   "10.96.0.0/12",
+				},
+			},
+		},
+	},
+}
+
+// newTestReconciler returns a TunnelReconciler of fake client that has objects and the indexes of the manager.
+func newTestReconciler(g Gomega, objects ...client.Object) *TunnelReconciler {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(v1.AddToScheme(scheme)).To(Succeed())
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(newTestNamespace("default")).
+		WithObjects(objects...).
+		WithIndex(&v1.TunnelIngress{}, tunnelRefField, indexIngressTunnelRef).
+		WithIndex(&v1.TunnelIngress{}, tunnelRefKindField, indexIngressTunnelRefKind).
+		WithIndex(&v1.Tunnel{}, waitForLabelField, indexTunnelWaitFor).
+		Build()
+	return &TunnelReconciler{Client: c, Scheme: scheme, DaemonConfig: config.Default().Daemon}
+}
+
+// renderObjects renders objects as a multi-document YAML in the form of kubectl get -o yaml.
+func renderObjects(g Gomega, scheme *runtime.Scheme, objects ...client.Object) []byte {
+	var buf bytes.Buffer
+	for i, obj := range objects {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		g.Expect(err).NotTo(HaveOccurred())
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+		rendered, err := yaml.Marshal(obj)
+		g.Expect(err).NotTo(HaveOccurred())
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(rendered)
+	}
+	return buf.Bytes()
+}
+
+func TestBuildTunnelGolden(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range goldenCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			tunnel := newTestTunnel()
+			tc.mutate(tunnel)
+			r := newTestReconciler(g, tc.objects...)
+
+			tunnelConfig, err := r.buildConfig(context.Background(), tunnel)
+			g.Expect(err).NotTo(HaveOccurred())
+			configMap, err := buildConfigMap(tunnel, tunnelConfig)
+			g.Expect(err).NotTo(HaveOccurred())
+			parseCloudflaredConfig(g, []byte(configMap.Data[fileNameConfig]))
+
+			image := buildDaemonImage(tunnel, r.DaemonConfig, testDaemonVersion)
+			daemon, err := buildDaemon(image, tunnel, tunnelConfig, r.DaemonConfig)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			rendered := renderObjects(g, r.Scheme, daemon, configMap)
+			path := filepath.Join("testdata", "golden", name+".yaml")
+			if *updateGolden {
+				g.Expect(os.WriteFile(path, rendered, 0o644)).To(Succeed())
+			}
+			golden, err := os.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred(), "run with -update to create the golden file")
+			g.Expect(string(rendered)).To(Equal(string(golden)), "run with -update if the change is intended")
+		})
+	}
+}
+
+func TestBuildConfigParsesWithCloudflared(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	tunnel := newTestTunnel()
+	goldenCases["origin"].mutate(tunnel)
+	r := newTestReconciler(g, goldenCases["origin"].objects...)
+	tunnelConfig, err := r.buildConfig(context.Background(), tunnel)
+	g.Expect(err).NotTo(HaveOccurred())
+	configMap, err := buildConfigMap(tunnel, tunnelConfig)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	parsed := parseCloudflaredConfig(g, []byte(configMap.Data[fileNameConfig]))
+	g.Expect(parsed.OriginRequest.NoTLSVerify).To(HaveValue(BeTrue()))
+	g.Expect(parsed.OriginRequest.ConnectTimeout).To(HaveValue(Equal(30 * time.Second)))
+	g.Expect(parsed.OriginRequest.KeepAliveTimeout).To(HaveValue(Equal(90 * time.Second)))
+	g.Expect(parsed.OriginRequest.Access).To(HaveValue(Equal(cloudflaredAccessConfig{
+		Required: true,
+		TeamName: "example",
+		AudTag:   []string{"aud"},
+	})))
+	// the ingress of missing backend is skipped, and the catch-all rule comes last
+	g.Expect(parsed.Ingress).To(Equal([]cloudflaredIngressRule{
+		{Hostname: "web.example.com", Service: "http://web.default.svc.cluster.local:80"},
+		{Hostname: "socket.example.com", Service: "unix:/run/app.sock"},
+		{Hostname: "old.example.com", Service: "http_status:503"},
+		{Service: r.DaemonConfig.CatchAllService},
+	}))
+}
+
+func TestReadTunnelConfig(t *testing.T) {
+	t.Parallel()
+
+	tunnel := newTestTunnel()
+	goldenCases["run-parameters"].mutate(tunnel)
+	tunnelConfig := TunnelConfig{
+		TunnelRunParameters: configRunParameters(tunnel.Spec.TunnelRunParameters),
+		OriginRequestConfig: v1.OriginRequestConfig{
+			OriginTLSSettings: &v1.OriginTLSSettings{TLSTimeout: &metav1.Duration{Duration: 10 * time.Second}},
+		},
+		Ingress:     []v1.TunnelConfigIngress{{Hostname: ptr.To("web.example.com"), Service: "http://web:80"}},
+		WarpRouting: &WarpRoutingConfig{Enabled: true},
+	}
+
+	t.Run("data", func(t *testing.T) {
+		t.Parallel()
+		g := NewWithT(t)
+
+		configMap, err := buildConfigMap(tunnel, tunnelConfig)
+		g.Expect(err).NotTo(HaveOccurred())
+		read, err := readTunnelConfig(*configMap)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(read.Equals(tunnelConfig)).To(BeTrue())
+
+		readHash, err := read.Hash()
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(tunnelConfig.Hash()).To(Equal(readHash))
+	})
+
+	t.Run("binaryData", func(t *testing.T) {
+		t.Parallel()
+		g := NewWithT(t)
+
+		configMap, err := buildConfigMap(tunnel, tunnelConfig)
+		g.Expect(err).NotTo(HaveOccurred())
+		configMap.BinaryData = map[string][]byte{fileNameConfig: []byte(configMap.Data[fileNameConfig])}
+		configMap.Data = nil
+		read, err := readTunnelConfig(*configMap)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(read.Equals(tunnelConfig)).To(BeTrue())
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		t.Parallel()
+		g := NewWithT(t)
+
+		_, err := readTunnelConfig(corev1.ConfigMap{Data: map[string]string{"other.yaml": ""}})
+		g.Expect(err).To(MatchError(errNotFoundConfigKey))
+	})
+
+	t.Run("unreadable", func(t *testing.T) {
+		t.Parallel()
+		g := NewWithT(t)
+
+		_, err := readTunnelConfig(corev1.ConfigMap{Data: map[string]string{fileNameConfig: "ingress: {"}})
+		g.Expect(err).To(MatchError(errUnReadableConfig))
+	})
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const loadBalancerFinalizerName = "loadbalancer.cloudflared-operator.bhyoo.com/finalizer"
+
+var errLoadBalancerPoolNotReady = errors.New("load balancer pool is not ready")
+
+// LoadBalancerReconciler reconciles a LoadBalancer object
+type LoadBalancerReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Clock  clock.PassiveClock
+
+	CloudflareClients *cloudflare.ClientPool
+}
+
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=loadbalancers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=loadbalancers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=loadbalancers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=loadbalancerpools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
+func (r *LoadBalancerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx).WithValues("loadBalancerName", req.Name)
+	ctx = log.IntoContext(ctx, l)
+
+	var lb v1.LoadBalancer
+	if err := r.Get(ctx, req.NamespacedName, &lb); err != nil {
+		l.Error(err, "unable to fetch LoadBalancer")
+		// we'll ignore not-found errors, since they can't be fixed by an immediate
+		// requeue (we'll need to wait for a new notification), and we can get them
+		// on deleted requests.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// examine DeletionTimestamp to determine if object is under deletion
+	if !lb.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&lb, loadBalancerFinalizerName) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.deleteLoadBalancer(ctx, &lb); err != nil {
+			// if fail to delete the external dependency here, return with error
+			// so that it can be retried.
+			return ctrl.Result{}, err
+		}
+
+		if controllerutil.RemoveFinalizer(&lb, loadBalancerFinalizerName) {
+			return ctrl.Result{}, r.Update(ctx, &lb)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// The object is not being deleted, so if it does not have our finalizer,
+	// then lets add the finalizer and update the object. This is equivalent
+	// to registering our finalizer.
+	if !controllerutil.ContainsFinalizer(&lb, loadBalancerFinalizerName) {
+		controllerutil.AddFinalizer(&lb, loadBalancerFinalizerName)
+		if err := r.Update(ctx, &lb); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.reconcileLoadBalancer(ctx, &lb); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *LoadBalancerReconciler) findObjectsForPool(ctx context.Context, pool client.Object) []reconcile.Request {
+	var lbs v1.LoadBalancerList
+	if err := r.List(ctx, &lbs, client.InNamespace(pool.GetNamespace())); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing load balancers")
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	for _, item := range lbs.Items {
+		if !slices.Contains(referredPoolNames(&item), pool.GetName()) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
+
+// referredPoolNames lists LoadBalancerPool resources that the load balancer refers by name.
+func referredPoolNames(lb *v1.LoadBalancer) []string {
+	var names []string
+	add := func(ref v1.LoadBalancerPoolRef) {
+		if ref.Name != nil {
+			names = append(names, *ref.Name)
+		}
+	}
+
+	for _, pool := range lb.Spec.DefaultPools {
+		add(pool.Ref())
+	}
+	if lb.Spec.FallbackPool != nil {
+		add(*lb.Spec.FallbackPool)
+	}
+	for _, poolMap := range []map[string][]v1.LoadBalancerPoolRef{
+		lb.Spec.RegionPools,
+		lb.Spec.CountryPools,
+		lb.Spec.PopPools,
+	} {
+		for _, refs := range poolMap {
+			for _, ref := range refs {
+				add(ref)
+			}
+		}
+	}
+	return names
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LoadBalancerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.LoadBalancer{}).
+		Watches(
+			&v1.LoadBalancerPool{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForPool),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&v1.TunnelPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.findLoadBalancersForPolicy),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findLoadBalancersForNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
+		Complete(requeueOnRateLimit(r))
+}
+
+func (r *LoadBalancerReconciler) buildConditionRecorder(
+	ctx context.Context,
+	lb *v1.LoadBalancer,
+	condType v1.LoadBalancerConditionType,
+) func(err error) error {
+	return func(err error) (cause error) {
+		defer func() {
+			if errors.Is(err, reconcile.TerminalError(nil)) &&
+				!errors.Is(cause, reconcile.TerminalError(nil)) {
+				cause = reconcile.TerminalError(cause)
+			}
+		}()
+
+		cause = err
+		var reason v1.LoadBalancerConditionReason = ""
+		var withReason ReasonedError[v1.LoadBalancerConditionReason]
+		if errors.As(err, &withReason) {
+			cause = withReason.Cause()
+			reason = withReason.Reason
+		}
+
+		newCond := v1.LoadBalancerStatusCondition{
+			Type:               condType,
+			Status:             corev1.ConditionFalse,
+			Message:            "",
+			Error:              fmt.Sprintf("%+v", cause),
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             reason,
+		}
+
+		if status, ok := cause.(apierrors.APIStatus); ok || errors.As(cause, &status) {
+			newCond.Error = string(status.Status().Reason)
+			newCond.Message = status.Status().Message
+		}
+
+		if !UpdateConditionIfChanged(&lb.Status, newCond) {
+			return cause
+		}
+
+		if updateErr := r.Status().Update(ctx, lb); updateErr != nil {
+			return errors.Join(cause, updateErr)
+		}
+		return cause
+	}
+}
+
+func (r *LoadBalancerReconciler) updateConditionIfDiff(
+	ctx context.Context,
+	lb *v1.LoadBalancer,
+	cond v1.LoadBalancerStatusCondition,
+) error {
+	if UpdateConditionIfChanged(&lb.Status, cond) {
+		return r.Status().Update(ctx, lb)
+	}
+	return nil
+}
+
+// resolvePoolID returns the Cloudflare ID of the referred pool.
+func (r *LoadBalancerReconciler) resolvePoolID(
+	ctx context.Context,
+	lb *v1.LoadBalancer,
+	ref v1.LoadBalancerPoolRef,
+) (string, error) {
+	if ref.ID != nil {
+		return *ref.ID, nil
+	}
+
+	name := ptr.Deref(ref.Name, "")
+	var pool v1.LoadBalancerPool
+	if err := r.Get(ctx, client.ObjectKey{Namespace: lb.Namespace, Name: name}, &pool); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("%w: %s is not found", errLoadBalancerPoolNotReady, name)
+		}
+		return "", err
+	}
+	if pool.Status.PoolID == "" {
+		return "", fmt.Errorf("%w: %s is not created on Cloudflare yet", errLoadBalancerPoolNotReady, name)
+	}
+	return pool.Status.PoolID, nil
+}
@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"context"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func (r *LoadBalancerReconciler) deleteLoadBalancer(ctx context.Context, lb *v1.LoadBalancer) error {
+	if lb.Status.LoadBalancerID == "" {
+		return nil
+	}
+
+	cfClient, err := r.getCloudflareClient(ctx, lb)
+	if err != nil {
+		if isUnusableAccountError(err) {
+			return nil
+		}
+		return err
+	}
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, lb, v1.LoadBalancerConditionTypeLoadBalancer)
+	if err = cfClient.DeleteLoadBalancer(
+		ctx,
+		lb.Status.AccountID,
+		lb.Status.Hostname,
+		lb.Status.LoadBalancerID,
+	); err != nil {
+		return recordConditionFrom(WrapError(err, v1.LoadBalancerReasonFailedToDelete))
+	}
+	return nil
+}
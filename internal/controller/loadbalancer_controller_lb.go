@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/policy"
+)
+
+const defaultSessionAffinity = "none"
+
+func (r *LoadBalancerReconciler) reconcileLoadBalancer(ctx context.Context, lb *v1.LoadBalancer) error {
+	l := log.FromContext(ctx)
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, lb, v1.LoadBalancerConditionTypeLoadBalancer)
+
+	if err := policy.CheckLoadBalancer(ctx, r, lb, false); err != nil {
+		return r.withdrawLoadBalancer(ctx, lb, err)
+	}
+
+	desired, err := r.buildLoadBalancer(ctx, lb)
+	if err != nil {
+		if !errors.Is(err, errLoadBalancerPoolNotReady) {
+			return err
+		}
+		// the LoadBalancerPool watch brings us back once the pool is created on Cloudflare
+		return r.updateConditionIfDiff(ctx, lb, v1.LoadBalancerStatusCondition{
+			Type:               v1.LoadBalancerConditionTypeLoadBalancer,
+			Status:             corev1.ConditionFalse,
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.LoadBalancerReasonPoolNotReady,
+		})
+	}
+
+	cfClient, err := r.getCloudflareClient(ctx, lb)
+	if err != nil {
+		return recordConditionFrom(err)
+	}
+	accountID := lb.Spec.AccountID
+
+	// hostname or account has been changed. remove previous one first.
+	if lb.Status.LoadBalancerID != "" && (lb.Status.Hostname != desired.Hostname || lb.Status.AccountID != accountID) {
+		l.Info("load balancer hostname has been changed. deleting previous one", "previous", lb.Status.Hostname)
+		if err = cfClient.DeleteLoadBalancer(
+			ctx,
+			lb.Status.AccountID,
+			lb.Status.Hostname,
+			lb.Status.LoadBalancerID,
+		); err != nil {
+			return recordConditionFrom(WrapError(err, v1.LoadBalancerReasonFailedToDelete))
+		}
+		lb.Status.LoadBalancerID = ""
+		lb.Status.Hostname = ""
+		lb.Status.AccountID = ""
+	}
+
+	existing, err := cfClient.GetLoadBalancer(ctx, accountID, desired.Hostname)
+	if err != nil {
+		return recordConditionFrom(WrapError(err, v1.LoadBalancerReasonFailedToGet))
+	}
+
+	switch {
+	case existing == nil:
+		if err := r.updateConditionIfDiff(ctx, lb, v1.LoadBalancerStatusCondition{
+			Type:               v1.LoadBalancerConditionTypeLoadBalancer,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.LoadBalancerReasonCreating,
+		}); err != nil {
+			return err
+		}
+		desired.ID, err = cfClient.CreateLoadBalancer(ctx, accountID, desired)
+		if err != nil {
+			return recordConditionFrom(WrapError(err, v1.LoadBalancerReasonFailedToCreate))
+		}
+
+	default:
+		desired.ID = existing.ID
+		if isLoadBalancerChanged(existing, &desired) {
+			// adopting a load balancer that differs from spec overwrites the one created by others
+			if lb.Status.LoadBalancerID != existing.ID {
+				if err := policy.CheckLoadBalancer(ctx, r, lb, true); err != nil {
+					return r.withdrawLoadBalancer(ctx, lb, err)
+				}
+			}
+			l.Info("load balancer has been changed. updating...")
+			if err = cfClient.UpdateLoadBalancer(ctx, accountID, desired); err != nil {
+				return recordConditionFrom(WrapError(err, v1.LoadBalancerReasonFailedToUpdate))
+			}
+		}
+	}
+
+	var dirtyStatus bool
+	if lb.Status.LoadBalancerID != desired.ID || lb.Status.Hostname != desired.Hostname ||
+		lb.Status.AccountID != accountID {
+		dirtyStatus = true
+		lb.Status.LoadBalancerID = desired.ID
+		lb.Status.Hostname = desired.Hostname
+		lb.Status.AccountID = accountID
+	}
+
+	if UpdateConditionIfChanged(&lb.Status, v1.LoadBalancerStatusCondition{
+		Type:               v1.LoadBalancerConditionTypeLoadBalancer,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	}) {
+		dirtyStatus = true
+	}
+
+	if dirtyStatus {
+		return r.Status().Update(ctx, lb)
+	}
+	return nil
+}
+
+// withdrawLoadBalancer records why the load balancer is not permitted by TunnelPolicies.
+// The load balancer created while it was permitted is deleted, as the policy no longer allows the namespace to claim it.
+func (r *LoadBalancerReconciler) withdrawLoadBalancer(ctx context.Context, lb *v1.LoadBalancer, denied error) error {
+	recordConditionFrom := r.buildConditionRecorder(ctx, lb, v1.LoadBalancerConditionTypeLoadBalancer)
+	if !errors.Is(denied, policy.ErrViolation) {
+		return recordConditionFrom(denied)
+	}
+
+	if err := r.deleteLoadBalancer(ctx, lb); err != nil {
+		return err
+	}
+	lb.Status.LoadBalancerID = ""
+	lb.Status.Hostname = ""
+	lb.Status.AccountID = ""
+	// TunnelPolicies and Namespaces are watched
+	return recordConditionFrom(reconcile.TerminalError(WrapError(denied, v1.LoadBalancerReasonPolicyViolation)))
+}
+
+// buildLoadBalancer converts spec into Cloudflare load balancer, resolving pool references into IDs.
+func (r *LoadBalancerReconciler) buildLoadBalancer(
+	ctx context.Context,
+	lb *v1.LoadBalancer,
+) (cloudflare.LoadBalancer, error) {
+	res := cloudflare.LoadBalancer{
+		Hostname:           lb.Spec.Hostname,
+		Description:        ptr.Deref(lb.Spec.Description, ""),
+		Enabled:            ptr.Deref(lb.Spec.Enabled, true),
+		Proxied:            ptr.Deref(lb.Spec.Proxied, true),
+		TTL:                int(ptr.Deref(lb.Spec.TTL, 0)),
+		SteeringPolicy:     ptr.Deref(lb.Spec.SteeringPolicy, ""),
+		DefaultPools:       make([]string, len(lb.Spec.DefaultPools)),
+		SessionAffinity:    ptr.Deref(lb.Spec.SessionAffinity, defaultSessionAffinity),
+		SessionAffinityTTL: int(ptr.Deref(lb.Spec.SessionAffinityTTL, 0)),
+	}
+
+	for i, pool := range lb.Spec.DefaultPools {
+		poolID, err := r.resolvePoolID(ctx, lb, pool.Ref())
+		if err != nil {
+			return cloudflare.LoadBalancer{}, err
+		}
+		res.DefaultPools[i] = poolID
+
+		if pool.Weight != nil {
+			if res.PoolWeights == nil {
+				res.PoolWeights = make(map[string]float64, len(lb.Spec.DefaultPools))
+			}
+			res.PoolWeights[poolID] = float64(*pool.Weight) / 100
+		}
+	}
+
+	if lb.Spec.FallbackPool != nil {
+		poolID, err := r.resolvePoolID(ctx, lb, *lb.Spec.FallbackPool)
+		if err != nil {
+			return cloudflare.LoadBalancer{}, err
+		}
+		res.FallbackPool = poolID
+	} else {
+		res.FallbackPool = res.DefaultPools[len(res.DefaultPools)-1]
+	}
+
+	var err error
+	if res.RegionPools, err = r.resolvePoolMap(ctx, lb, lb.Spec.RegionPools); err != nil {
+		return cloudflare.LoadBalancer{}, err
+	}
+	if res.CountryPools, err = r.resolvePoolMap(ctx, lb, lb.Spec.CountryPools); err != nil {
+		return cloudflare.LoadBalancer{}, err
+	}
+	if res.PopPools, err = r.resolvePoolMap(ctx, lb, lb.Spec.PopPools); err != nil {
+		return cloudflare.LoadBalancer{}, err
+	}
+	return res, nil
+}
+
+func (r *LoadBalancerReconciler) resolvePoolMap(
+	ctx context.Context,
+	lb *v1.LoadBalancer,
+	poolMap map[string][]v1.LoadBalancerPoolRef,
+) (map[string][]string, error) {
+	if len(poolMap) == 0 {
+		return nil, nil
+	}
+
+	res := make(map[string][]string, len(poolMap))
+	for key, refs := range poolMap {
+		poolIDs := make([]string, len(refs))
+		for i, ref := range refs {
+			poolID, err := r.resolvePoolID(ctx, lb, ref)
+			if err != nil {
+				return nil, err
+			}
+			poolIDs[i] = poolID
+		}
+		res[key] = poolIDs
+	}
+	return res, nil
+}
+
+func isLoadBalancerChanged(existing, desired *cloudflare.LoadBalancer) bool {
+	return existing.Description != desired.Description ||
+		existing.Enabled != desired.Enabled ||
+		existing.Proxied != desired.Proxied ||
+		existing.FallbackPool != desired.FallbackPool ||
+		existing.SessionAffinity != desired.SessionAffinity ||
+		// Cloudflare fills defaults for the fields below when they are not specified
+		(desired.TTL != 0 && existing.TTL != desired.TTL) ||
+		(desired.SteeringPolicy != "" && existing.SteeringPolicy != desired.SteeringPolicy) ||
+		(desired.SessionAffinityTTL != 0 && existing.SessionAffinityTTL != desired.SessionAffinityTTL) ||
+		!slices.Equal(existing.DefaultPools, desired.DefaultPools) ||
+		!maps.Equal(existing.PoolWeights, desired.PoolWeights) ||
+		!maps.EqualFunc(existing.RegionPools, desired.RegionPools, slices.Equal[[]string]) ||
+		!maps.EqualFunc(existing.CountryPools, desired.CountryPools, slices.Equal[[]string]) ||
+		!maps.EqualFunc(existing.PopPools, desired.PopPools, slices.Equal[[]string])
+}
+
+func (r *LoadBalancerReconciler) getCloudflareClient(
+	ctx context.Context,
+	lb *v1.LoadBalancer,
+) (cloudflare.Client, error) {
+	return newCloudflareClient(
+		ctx,
+		r,
+		r.CloudflareClients,
+		lb.Spec.AccountID,
+		lb.Namespace,
+		lb.Spec.APITokenSecretRef,
+		v1.LoadBalancerReasonNoToken,
+		v1.LoadBalancerReasonFailedToConnectCF,
+		v1.LoadBalancerReasonAccountNotAllowed,
+	)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const loadBalancerPoolFinalizerName = "loadbalancerpool.cloudflared-operator.bhyoo.com/finalizer"
+
+// LoadBalancerPoolReconciler reconciles a LoadBalancerPool object
+type LoadBalancerPoolReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Clock  clock.PassiveClock
+
+	CloudflareClients *cloudflare.ClientPool
+}
+
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=loadbalancerpools,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=loadbalancerpools/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=loadbalancerpools/finalizers,verbs=update
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnels,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
+func (r *LoadBalancerPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx).WithValues("loadBalancerPoolName", req.Name)
+	ctx = log.IntoContext(ctx, l)
+
+	var pool v1.LoadBalancerPool
+	if err := r.Get(ctx, req.NamespacedName, &pool); err != nil {
+		l.Error(err, "unable to fetch LoadBalancerPool")
+		// we'll ignore not-found errors, since they can't be fixed by an immediate
+		// requeue (we'll need to wait for a new notification), and we can get them
+		// on deleted requests.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// examine DeletionTimestamp to determine if object is under deletion
+	if !pool.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&pool, loadBalancerPoolFinalizerName) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.deleteLoadBalancerPool(ctx, &pool); err != nil {
+			// if fail to delete the external dependency here, return with error
+			// so that it can be retried.
+			return ctrl.Result{}, err
+		}
+
+		if controllerutil.RemoveFinalizer(&pool, loadBalancerPoolFinalizerName) {
+			return ctrl.Result{}, r.Update(ctx, &pool)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// The object is not being deleted, so if it does not have our finalizer,
+	// then lets add the finalizer and update the object. This is equivalent
+	// to registering our finalizer.
+	if !controllerutil.ContainsFinalizer(&pool, loadBalancerPoolFinalizerName) {
+		controllerutil.AddFinalizer(&pool, loadBalancerPoolFinalizerName)
+		if err := r.Update(ctx, &pool); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	monitorID, err := r.reconcileMonitor(ctx, &pool)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err = r.reconcilePool(ctx, &pool, monitorID); err != nil {
+		return ctrl.Result{}, err
+	}
+	// monitor can be deleted only after the pool stops using it
+	if err = r.deleteUnusedMonitor(ctx, &pool); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// findObjectsForTunnel enqueues pools that select the tunnel, or that have the tunnel as an origin.
+// The latter is for the tunnel whose labels no longer match.
+func (r *LoadBalancerPoolReconciler) findObjectsForTunnel(ctx context.Context, tunnel client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+
+	var pools v1.LoadBalancerPoolList
+	if err := r.List(ctx, &pools, client.InNamespace(tunnel.GetNamespace())); err != nil {
+		l.Error(err, "failed to listing load balancer pools")
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	for _, item := range pools.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&item.Spec.TunnelSelector)
+		selected := err == nil && selector.Matches(labels.Set(tunnel.GetLabels()))
+		registered := slices.ContainsFunc(item.Status.Origins, func(o v1.LoadBalancerPoolOrigin) bool {
+			return o.TunnelName == tunnel.GetName()
+		})
+		if !selected && !registered {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LoadBalancerPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.LoadBalancerPool{}).
+		Watches(
+			&v1.Tunnel{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForTunnel),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Complete(requeueOnRateLimit(r))
+}
+
+func (r *LoadBalancerPoolReconciler) buildConditionRecorder(
+	ctx context.Context,
+	pool *v1.LoadBalancerPool,
+	condType v1.LoadBalancerPoolConditionType,
+) func(err error) error {
+	return func(err error) (cause error) {
+		defer func() {
+			if errors.Is(err, reconcile.TerminalError(nil)) &&
+				!errors.Is(cause, reconcile.TerminalError(nil)) {
+				cause = reconcile.TerminalError(cause)
+			}
+		}()
+
+		cause = err
+		var reason v1.LoadBalancerPoolConditionReason = ""
+		var withReason ReasonedError[v1.LoadBalancerPoolConditionReason]
+		if errors.As(err, &withReason) {
+			cause = withReason.Cause()
+			reason = withReason.Reason
+		}
+
+		newCond := v1.LoadBalancerPoolStatusCondition{
+			Type:               condType,
+			Status:             corev1.ConditionFalse,
+			Message:            "",
+			Error:              fmt.Sprintf("%+v", cause),
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             reason,
+		}
+
+		if status, ok := cause.(apierrors.APIStatus); ok || errors.As(cause, &status) {
+			newCond.Error = string(status.Status().Reason)
+			newCond.Message = status.Status().Message
+		}
+
+		if !UpdateConditionIfChanged(&pool.Status, newCond) {
+			return cause
+		}
+
+		if updateErr := r.Status().Update(ctx, pool); updateErr != nil {
+			return errors.Join(cause, updateErr)
+		}
+		return cause
+	}
+}
+
+func (r *LoadBalancerPoolReconciler) updateConditionIfDiff(
+	ctx context.Context,
+	pool *v1.LoadBalancerPool,
+	cond v1.LoadBalancerPoolStatusCondition,
+) error {
+	if UpdateConditionIfChanged(&pool.Status, cond) {
+		return r.Status().Update(ctx, pool)
+	}
+	return nil
+}
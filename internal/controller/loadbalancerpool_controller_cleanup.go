@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func (r *LoadBalancerPoolReconciler) deleteLoadBalancerPool(ctx context.Context, pool *v1.LoadBalancerPool) error {
+	if pool.Status.PoolID == "" && pool.Status.MonitorID == "" {
+		return nil
+	}
+
+	cfClient, err := r.getCloudflareClient(ctx, pool)
+	if err != nil {
+		if isUnusableAccountError(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Cloudflare refuses to delete a pool that is still used by load balancers, so it is retried until they are gone.
+	if pool.Status.PoolID != "" {
+		recordConditionFrom := r.buildConditionRecorder(ctx, pool, v1.LoadBalancerPoolConditionTypePool)
+		if err = cfClient.DeleteLoadBalancerPool(ctx, pool.Status.AccountID, pool.Status.PoolID); err != nil {
+			return recordConditionFrom(WrapError(err, v1.PoolReasonFailedToDeletePool))
+		}
+	}
+
+	if pool.Status.MonitorID != "" {
+		recordConditionFrom := r.buildConditionRecorder(ctx, pool, v1.LoadBalancerPoolConditionTypeMonitor)
+		if err = cfClient.DeleteLoadBalancerMonitor(ctx, pool.Status.AccountID, pool.Status.MonitorID); err != nil {
+			return recordConditionFrom(WrapError(err, v1.MonitorReasonFailedToDeleteMonitor))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const (
+	defaultMonitorType          = "http"
+	defaultMonitorInterval      = 60
+	defaultMonitorTimeout       = 5
+	defaultMonitorRetries       = 2
+	defaultMonitorHTTPMethod    = "GET"
+	defaultMonitorHTTPPath      = "/"
+	defaultMonitorExpectedCodes = "200"
+)
+
+// reconcileMonitor creates or updates the health monitor of the pool and returns its ID.
+// It returns empty ID if the pool has no monitor.
+func (r *LoadBalancerPoolReconciler) reconcileMonitor(ctx context.Context, pool *v1.LoadBalancerPool) (string, error) {
+	if pool.Spec.Monitor == nil {
+		return "", r.updateConditionIfDiff(ctx, pool, v1.LoadBalancerPoolStatusCondition{
+			Type:               v1.LoadBalancerPoolConditionTypeMonitor,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+		})
+	}
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, pool, v1.LoadBalancerPoolConditionTypeMonitor)
+
+	cfClient, err := r.getCloudflareClient(ctx, pool)
+	if err != nil {
+		return "", recordConditionFrom(err)
+	}
+	accountID := pool.Spec.AccountID
+
+	var existing *cloudflare.LoadBalancerMonitor
+	if pool.Status.MonitorID != "" && pool.Status.AccountID == accountID {
+		existing, err = cfClient.GetLoadBalancerMonitor(ctx, accountID, pool.Status.MonitorID)
+		if err != nil {
+			return "", recordConditionFrom(WrapError(err, v1.MonitorReasonFailedToGetMonitor))
+		}
+	}
+
+	desired := buildMonitor(pool)
+	switch {
+	case existing == nil:
+		desired.ID, err = cfClient.CreateLoadBalancerMonitor(ctx, accountID, desired)
+		if err != nil {
+			return "", recordConditionFrom(WrapError(err, v1.MonitorReasonFailedToCreateMonitor))
+		}
+
+	default:
+		desired.ID = existing.ID
+		if !reflect.DeepEqual(*existing, desired) {
+			log.FromContext(ctx).Info("health monitor has been changed. updating...")
+			if err = cfClient.UpdateLoadBalancerMonitor(ctx, accountID, desired); err != nil {
+				return "", recordConditionFrom(WrapError(err, v1.MonitorReasonFailedToUpdateMonitor))
+			}
+		}
+	}
+
+	var dirtyStatus bool
+	if pool.Status.MonitorID != desired.ID || pool.Status.AccountID != accountID {
+		dirtyStatus = true
+		pool.Status.MonitorID = desired.ID
+		pool.Status.AccountID = accountID
+	}
+
+	if UpdateConditionIfChanged(&pool.Status, v1.LoadBalancerPoolStatusCondition{
+		Type:               v1.LoadBalancerPoolConditionTypeMonitor,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	}) {
+		dirtyStatus = true
+	}
+
+	if dirtyStatus {
+		return desired.ID, r.Status().Update(ctx, pool)
+	}
+	return desired.ID, nil
+}
+
+// deleteUnusedMonitor deletes the monitor that has been removed from spec.
+func (r *LoadBalancerPoolReconciler) deleteUnusedMonitor(ctx context.Context, pool *v1.LoadBalancerPool) error {
+	if pool.Spec.Monitor != nil || pool.Status.MonitorID == "" {
+		return nil
+	}
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, pool, v1.LoadBalancerPoolConditionTypeMonitor)
+
+	cfClient, err := r.getCloudflareClient(ctx, pool)
+	if err != nil {
+		return recordConditionFrom(err)
+	}
+	if err = cfClient.DeleteLoadBalancerMonitor(ctx, pool.Status.AccountID, pool.Status.MonitorID); err != nil {
+		return recordConditionFrom(WrapError(err, v1.MonitorReasonFailedToDeleteMonitor))
+	}
+
+	pool.Status.MonitorID = ""
+	return r.Status().Update(ctx, pool)
+}
+
+func buildMonitor(pool *v1.LoadBalancerPool) cloudflare.LoadBalancerMonitor {
+	spec := pool.Spec.Monitor
+
+	monitor := cloudflare.LoadBalancerMonitor{
+		Type:            ptr.Deref(spec.Type, defaultMonitorType),
+		Description:     ptr.Deref(spec.Description, pool.Spec.Name),
+		Header:          spec.Header,
+		Port:            uint16(ptr.Deref(spec.Port, 0)),
+		Timeout:         int(ptr.Deref(spec.Timeout, defaultMonitorTimeout)),
+		Retries:         int(ptr.Deref(spec.Retries, defaultMonitorRetries)),
+		Interval:        int(ptr.Deref(spec.Interval, defaultMonitorInterval)),
+		ConsecutiveUp:   int(ptr.Deref(spec.ConsecutiveUp, 0)),
+		ConsecutiveDown: int(ptr.Deref(spec.ConsecutiveDown, 0)),
+		ExpectedBody:    ptr.Deref(spec.ExpectedBody, ""),
+		FollowRedirects: spec.FollowRedirects,
+		AllowInsecure:   spec.AllowInsecure,
+		ProbeZone:       ptr.Deref(spec.ProbeZone, ""),
+	}
+	// HTTP specific fields are rejected by Cloudflare for other types
+	if monitor.Type == "http" || monitor.Type == "https" {
+		monitor.Method = ptr.Deref(spec.Method, defaultMonitorHTTPMethod)
+		monitor.Path = ptr.Deref(spec.Path, defaultMonitorHTTPPath)
+		monitor.ExpectedCodes = ptr.Deref(spec.ExpectedCodes, defaultMonitorExpectedCodes)
+	}
+	return monitor
+}
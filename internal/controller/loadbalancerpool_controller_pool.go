@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const tunnelOriginSuffix = ".cfargotunnel.com"
+
+func (r *LoadBalancerPoolReconciler) reconcilePool(
+	ctx context.Context,
+	pool *v1.LoadBalancerPool,
+	monitorID string,
+) error {
+	l := log.FromContext(ctx)
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, pool, v1.LoadBalancerPoolConditionTypePool)
+
+	origins, originStatus, err := r.buildOrigins(ctx, pool)
+	if err != nil {
+		return recordConditionFrom(err)
+	}
+
+	cfClient, err := r.getCloudflareClient(ctx, pool)
+	if err != nil {
+		return recordConditionFrom(err)
+	}
+	accountID := pool.Spec.AccountID
+
+	var existing *cloudflare.LoadBalancerPool
+	if pool.Status.PoolID != "" && pool.Status.AccountID == accountID {
+		existing, err = cfClient.GetLoadBalancerPool(ctx, accountID, pool.Status.PoolID)
+		if err != nil {
+			return recordConditionFrom(WrapError(err, v1.PoolReasonFailedToGetPool))
+		}
+	}
+
+	if len(origins) == 0 {
+		// keep the registered origins until any tunnel becomes ready. the Tunnel watch brings us back.
+		return r.updateConditionIfDiff(ctx, pool, v1.LoadBalancerPoolStatusCondition{
+			Type:               v1.LoadBalancerPoolConditionTypePool,
+			Status:             corev1.ConditionFalse,
+			Message:            "no selected tunnel is created on Cloudflare yet",
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.PoolReasonNoOrigin,
+		})
+	}
+
+	desired := cloudflare.LoadBalancerPool{
+		Name:                 pool.Spec.Name,
+		Description:          ptr.Deref(pool.Spec.Description, ""),
+		Enabled:              ptr.Deref(pool.Spec.Enabled, true),
+		MinimumOrigins:       int(ptr.Deref(pool.Spec.MinimumOrigins, 1)),
+		MonitorID:            monitorID,
+		Origins:              origins,
+		OriginSteeringPolicy: ptr.Deref(pool.Spec.OriginSteeringPolicy, ""),
+		NotificationEmail:    ptr.Deref(pool.Spec.NotificationEmail, ""),
+		CheckRegions:         pool.Spec.CheckRegions,
+	}
+
+	switch {
+	case existing == nil:
+		if err := r.updateConditionIfDiff(ctx, pool, v1.LoadBalancerPoolStatusCondition{
+			Type:               v1.LoadBalancerPoolConditionTypePool,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.LoadBalancerPoolReasonCreating,
+		}); err != nil {
+			return err
+		}
+		desired.ID, err = cfClient.CreateLoadBalancerPool(ctx, accountID, desired)
+		if err != nil {
+			return recordConditionFrom(WrapError(err, v1.PoolReasonFailedToCreatePool))
+		}
+
+	default:
+		desired.ID = existing.ID
+		if isPoolChanged(existing, &desired) {
+			l.Info("load balancer pool has been changed. updating...")
+			if err = cfClient.UpdateLoadBalancerPool(ctx, accountID, desired); err != nil {
+				return recordConditionFrom(WrapError(err, v1.PoolReasonFailedToUpdatePool))
+			}
+		}
+	}
+
+	var dirtyStatus bool
+	if pool.Status.PoolID != desired.ID || pool.Status.AccountID != accountID ||
+		!slices.Equal(pool.Status.Origins, originStatus) {
+		dirtyStatus = true
+		pool.Status.PoolID = desired.ID
+		pool.Status.AccountID = accountID
+		pool.Status.Origins = originStatus
+	}
+
+	if UpdateConditionIfChanged(&pool.Status, v1.LoadBalancerPoolStatusCondition{
+		Type:               v1.LoadBalancerPoolConditionTypePool,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	}) {
+		dirtyStatus = true
+	}
+
+	if dirtyStatus {
+		return r.Status().Update(ctx, pool)
+	}
+	return nil
+}
+
+// buildOrigins turns the selected tunnels into origins. Tunnels that are not created on Cloudflare yet,
+// being deleted, or in another account are skipped.
+func (r *LoadBalancerPoolReconciler) buildOrigins(
+	ctx context.Context,
+	pool *v1.LoadBalancerPool,
+) ([]cloudflare.LoadBalancerOrigin, []v1.LoadBalancerPoolOrigin, error) {
+	l := log.FromContext(ctx)
+
+	selector, err := metav1.LabelSelectorAsSelector(&pool.Spec.TunnelSelector)
+	if err != nil {
+		return nil, nil, reconcile.TerminalError(WrapError(err, v1.PoolReasonInvalidTunnelSelector))
+	}
+
+	var tunnels v1.TunnelList
+	if err = r.List(
+		ctx,
+		&tunnels,
+		client.InNamespace(pool.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return nil, nil, WrapError(err, v1.PoolReasonFailedToListTunnels)
+	}
+	slices.SortFunc(tunnels.Items, func(a, b v1.Tunnel) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var origins []cloudflare.LoadBalancerOrigin
+	var originStatus []v1.LoadBalancerPoolOrigin
+	for _, tunnel := range tunnels.Items {
+		if !tunnel.DeletionTimestamp.IsZero() || tunnel.Status.TunnelID == "" {
+			continue
+		}
+		if tunnel.Spec.AccountID != pool.Spec.AccountID {
+			l.Info("skipping tunnel of another account", "tunnel", tunnel.Name)
+			continue
+		}
+
+		address := tunnel.Status.TunnelID + tunnelOriginSuffix
+		origins = append(origins, cloudflare.LoadBalancerOrigin{
+			Name:    tunnel.Name,
+			Address: address,
+			Enabled: true,
+			Weight:  1,
+			Host:    ptr.Deref(pool.Spec.OriginHost, ""),
+		})
+		originStatus = append(originStatus, v1.LoadBalancerPoolOrigin{
+			TunnelName: tunnel.Name,
+			Address:    address,
+		})
+	}
+	return origins, originStatus, nil
+}
+
+func isPoolChanged(existing, desired *cloudflare.LoadBalancerPool) bool {
+	return existing.Name != desired.Name ||
+		existing.Description != desired.Description ||
+		existing.Enabled != desired.Enabled ||
+		existing.MinimumOrigins != desired.MinimumOrigins ||
+		existing.MonitorID != desired.MonitorID ||
+		existing.NotificationEmail != desired.NotificationEmail ||
+		// Cloudflare fills the default policy when it is not specified
+		(desired.OriginSteeringPolicy != "" && existing.OriginSteeringPolicy != desired.OriginSteeringPolicy) ||
+		!slices.Equal(existing.CheckRegions, desired.CheckRegions) ||
+		!slices.Equal(existing.Origins, desired.Origins)
+}
+
+func (r *LoadBalancerPoolReconciler) getCloudflareClient(
+	ctx context.Context,
+	pool *v1.LoadBalancerPool,
+) (cloudflare.Client, error) {
+	return newCloudflareClient(
+		ctx,
+		r,
+		r.CloudflareClients,
+		pool.Spec.AccountID,
+		pool.Namespace,
+		pool.Spec.APITokenSecretRef,
+		v1.LoadBalancerPoolReasonNoToken,
+		v1.LoadBalancerPoolReasonFailedToConnectCF,
+		v1.LoadBalancerPoolReasonAccountNotAllowed,
+	)
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+var errUnresolvableOrigin = errors.New("unresolvable origin")
+
+// resolveIngressOrigin resolves the origin into the service of cloudflared ingress rule.
+// It returns errUnresolvableOrigin if the origin refers a Service or port that does not exist,
+// and errRefNotPermitted if the Service is in another namespace without TunnelReferenceGrant.
+func resolveIngressOrigin(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	origin v1.IngressOrigin,
+) (string, error) {
+	switch origin.Scheme {
+	case v1.OriginSchemeUnix, v1.OriginSchemeUnixTLS, v1.OriginSchemeHTTPStatus:
+		return string(origin.Scheme) + ":" + origin.Address, nil
+	}
+
+	if origin.BackendRef == nil {
+		return string(origin.Scheme) + "://" + origin.Address, nil
+	}
+
+	ref := origin.BackendRef
+	key := backendKey(namespace, *ref)
+	if err := checkReferencePermitted(
+		ctx,
+		reader,
+		v1.ReferenceGrantFromKindTunnelIngress,
+		namespace,
+		v1.ReferenceGrantToKindService,
+		key,
+	); err != nil {
+		return "", err
+	}
+	var service corev1.Service
+	if err := reader.Get(ctx, key, &service); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("%w: service %s is not found", errUnresolvableOrigin, key)
+		}
+		return "", err
+	}
+
+	port, err := findServicePort(service, ref.Port)
+	if err != nil {
+		return "", err
+	}
+
+	host := service.Name + "." + service.Namespace + ".svc.cluster.local"
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		host = service.Spec.ExternalName
+	}
+	return fmt.Sprintf("%s://%s:%d", origin.Scheme, host, port.Port), nil
+}
+
+// backendKey is the key of Service that ref refers, from the referrer in namespace.
+func backendKey(namespace string, ref v1.BackendRef) client.ObjectKey {
+	return client.ObjectKey{Namespace: ptr.Deref(ref.Namespace, namespace), Name: ref.Name}
+}
+
+// findServicePort finds the port of service by its name or number.
+func findServicePort(service corev1.Service, ref intstr.IntOrString) (corev1.ServicePort, error) {
+	for _, port := range service.Spec.Ports {
+		if ref.Type == intstr.String && port.Name == ref.StrVal ||
+			ref.Type == intstr.Int && port.Port == ref.IntVal {
+			return port, nil
+		}
+	}
+	// ExternalName services may not declare ports
+	if ref.Type == intstr.Int && service.Spec.Type == corev1.ServiceTypeExternalName {
+		return corev1.ServicePort{Port: ref.IntVal}, nil
+	}
+	return corev1.ServicePort{}, fmt.Errorf(
+		"%w: service %s/%s has no port %s",
+		errUnresolvableOrigin,
+		service.Namespace,
+		service.Name,
+		ref.String(),
+	)
+}
+
+// servicePortRef refers the port by its name, or its number if the port is unnamed.
+func servicePortRef(port corev1.ServicePort) intstr.IntOrString {
+	if port.Name != "" {
+		return intstr.FromString(port.Name)
+	}
+	return intstr.FromInt32(port.Port)
+}
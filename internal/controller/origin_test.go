@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+// newTunnelIngressSchemaValidator validates objects against the schema of generated TunnelIngress CRD.
+func newTunnelIngressSchemaValidator(g Gomega) validation.SchemaValidator {
+	data, err := os.ReadFile(filepath.Join(
+		"..", "..", "config", "crd", "bases", "cloudflared-operator.bhyoo.com_tunnelingresses.yaml",
+	))
+	g.Expect(err).NotTo(HaveOccurred())
+	var crd apiextensionsv1.CustomResourceDefinition
+	g.Expect(yaml.Unmarshal(data, &crd)).To(Succeed())
+	g.Expect(crd.Spec.Versions).To(HaveLen(1))
+
+	var schema apiextensions.JSONSchemaProps
+	g.Expect(apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+		crd.Spec.Versions[0].Schema.OpenAPIV3Schema,
+		&schema,
+		nil,
+	)).To(Succeed())
+	validator, _, err := validation.NewSchemaValidator(&schema)
+	g.Expect(err).NotTo(HaveOccurred())
+	return validator
+}
+
+// TestTunnelIngressCRDAcceptsLegacyService keeps TunnelIngresses that Services were exposed with by earlier releases
+// writable, e.g. to remove their finalizers.
+func TestTunnelIngressCRDAcceptsLegacyService(t *testing.T) {
+	t.Parallel()
+	validator := newTunnelIngressSchemaValidator(NewWithT(t))
+
+	tests := map[string]bool{
+		"web.default.svc.cluster.local:80": true,
+		"http://web.default.svc:8080":      true,
+		"ssh://bastion:22":                 true,
+		"unix:/run/app.sock":               true,
+		"http_status:404":                  true,
+		"web.default.svc.cluster.local":    false,
+		"ftp://web:21":                     false,
+		"http_status:99":                   false,
+	}
+	for service, valid := range tests {
+		service, valid := service, valid
+		t.Run(service, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			// as created by the ServiceReconciler of earlier releases
+			obj := map[string]any{
+				"apiVersion": "cloudflared-operator.bhyoo.com/v1",
+				"kind":       "TunnelIngress",
+				"metadata": map[string]any{
+					"name":       "sample-web-80",
+					"namespace":  "default",
+					"finalizers": []any{tunnelIngressFinalizerName},
+				},
+				"spec": map[string]any{
+					"hostname":  "web.example.com",
+					"service":   service,
+					"tunnelRef": map[string]any{"kind": "Tunnel", "name": "sample"},
+				},
+			}
+			errs := validation.ValidateCustomResource(nil, obj, validator)
+			if valid {
+				g.Expect(errs).To(BeEmpty())
+			} else {
+				g.Expect(errs).NotTo(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestBuildConfigReadsLegacyServiceAsHTTP(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	g.Expect(v1.IsLegacyService("web.default.svc.cluster.local:80")).To(BeTrue())
+	g.Expect(v1.IsLegacyService("http_status:404")).To(BeFalse())
+	g.Expect(v1.IsLegacyService("http://web:80")).To(BeFalse())
+
+	tunnel := newTestTunnel()
+	r := newTestReconciler(g, tunnel, newTestIngress("default", "web", 0, v1.TunnelIngressSpec{
+		TunnelConfigIngress: v1.TunnelConfigIngress{
+			Hostname: ptr.To("web.example.com"),
+			Service:  "web.default.svc.cluster.local:80",
+		},
+	}))
+
+	tunnelConfig, err := r.buildConfig(context.Background(), tunnel)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tunnelConfig.Ingress[0].Service).To(Equal("http://web.default.svc.cluster.local:80"))
+}
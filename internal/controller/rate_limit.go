@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const defaultRateLimitRequeue = time.Minute
+
+// requeueOnRateLimit requeues requests failed by Cloudflare API rate limit after the delay Cloudflare asked for,
+// instead of letting workqueue retry them right away and hit the limit again.
+func requeueOnRateLimit(r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		res, err := r.Reconcile(ctx, req)
+		retryAfter, limited := cloudflare.IsRateLimited(err)
+		if !limited {
+			return res, err
+		}
+
+		if retryAfter == 0 {
+			retryAfter = defaultRateLimitRequeue
+		}
+		log.FromContext(ctx).Info("Cloudflare API rate limit exceeded. requeueing...", "after", retryAfter)
+		return reconcile.Result{RequeueAfter: retryAfter}, nil
+	})
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+func TestRequeueOnRateLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err  error
+		want reconcile.Result
+	}{
+		"429 without Retry-After": {
+			err:  &cloudflare.APIError{StatusCode: 429},
+			want: reconcile.Result{RequeueAfter: defaultRateLimitRequeue},
+		},
+		"429 with Retry-After": {
+			err:  &cloudflare.APIError{StatusCode: 429, RetryAfter: 2 * time.Minute},
+			want: reconcile.Result{RequeueAfter: 2 * time.Minute},
+		},
+		"5xx with Retry-After longer than the transport waits for": {
+			err:  fmt.Errorf("update tunnel: %w", &cloudflare.APIError{StatusCode: 503, RetryAfter: 2 * time.Minute}),
+			want: reconcile.Result{RequeueAfter: 2 * time.Minute},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			r := requeueOnRateLimit(reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, tc.err
+			}))
+			res, err := r.Reconcile(context.Background(), reconcile.Request{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(res).To(Equal(tc.want))
+		})
+	}
+
+	t.Run("other errors are returned", func(t *testing.T) {
+		t.Parallel()
+		g := NewWithT(t)
+
+		apiErr := &cloudflare.APIError{StatusCode: 500}
+		r := requeueOnRateLimit(reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			return reconcile.Result{}, apiErr
+		}))
+		_, err := r.Reconcile(context.Background(), reconcile.Request{})
+		g.Expect(err).To(MatchError(apiErr))
+	})
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+var errRefNotPermitted = errors.New("reference is not permitted")
+
+// tunnelRefKey is the key of Tunnel that ref refers, from the referrer in namespace.
+func tunnelRefKey(namespace string, ref v1.TunnelRef) client.ObjectKey {
+	return client.ObjectKey{Namespace: ptr.Deref(ref.Namespace, namespace), Name: ref.Name}
+}
+
+// checkReferencePermitted returns errRefNotPermitted unless the referrer of fromKind in fromNamespace may refer
+// the object of toKind. References in the same namespace are always permitted,
+// and ones to other namespaces need a TunnelReferenceGrant in the namespace of the referent.
+func checkReferencePermitted(
+	ctx context.Context,
+	reader client.Reader,
+	fromKind v1.ReferenceGrantFromKind,
+	fromNamespace string,
+	toKind v1.ReferenceGrantToKind,
+	to types.NamespacedName,
+) error {
+	if fromNamespace == to.Namespace {
+		return nil
+	}
+
+	var grants v1.TunnelReferenceGrantList
+	if err := reader.List(ctx, &grants, client.InNamespace(to.Namespace)); err != nil {
+		return err
+	}
+	for _, grant := range grants.Items {
+		fromGranted := slices.ContainsFunc(grant.Spec.From, func(from v1.ReferenceGrantFrom) bool {
+			return from.Kind == fromKind && from.Namespace == fromNamespace
+		})
+		toGranted := slices.ContainsFunc(grant.Spec.To, func(grantTo v1.ReferenceGrantTo) bool {
+			return grantTo.Kind == toKind && (grantTo.Name == nil || *grantTo.Name == to.Name)
+		})
+		if fromGranted && toGranted {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"%w: %s of namespace %s may not refer %s %s",
+		errRefNotPermitted,
+		fromKind,
+		fromNamespace,
+		toKind,
+		to,
+	)
+}
+
+// findIngressesForGrant enqueues TunnelIngresses of namespaces that the TunnelReferenceGrant permits.
+func (r *TunnelIngressReconciler) findIngressesForGrant(ctx context.Context, obj client.Object) []reconcile.Request {
+	grant := obj.(*v1.TunnelReferenceGrant)
+
+	var requests []reconcile.Request
+	for _, from := range grant.Spec.From {
+		if from.Kind != v1.ReferenceGrantFromKindTunnelIngress {
+			continue
+		}
+		var ingresses v1.TunnelIngressList
+		if err := r.List(ctx, &ingresses, client.InNamespace(from.Namespace)); err != nil {
+			l := log.FromContext(ctx)
+			l.Error(err, "failed to listing tunnel ingresses of reference grant")
+			return nil
+		}
+		for _, ingress := range ingresses.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// findObjectsForGrant enqueues Tunnels in the namespace of the TunnelReferenceGrant,
+// which may gain or lose ingresses of other namespaces.
+func (r *TunnelReconciler) findObjectsForGrant(ctx context.Context, grant client.Object) []reconcile.Request {
+	var tunnels v1.TunnelList
+	if err := r.List(ctx, &tunnels, client.InNamespace(grant.GetNamespace())); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing tunnels of reference grant")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(tunnels.Items))
+	for i, tunnel := range tunnels.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: tunnel.Name, Namespace: tunnel.Namespace},
+		}
+	}
+	return requests
+}
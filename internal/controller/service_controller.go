@@ -1,126 +1,360 @@
-/*
-Copyright 2024 Jinha Jeong.
-
-Licensed under the Apache License, Version 2.0 (the "License");
-you may not use this file except in compliance with the License.
-You may obtain a copy of the License at
-
-    http://www.apache.org/licenses/LICENSE-2.0
-
-Unless required by applicable law or agreed to in writing, software
-distributed under the License is distributed on an "AS IS" BASIS,
-WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-See the License for the specific language governing permissions and
-limitations under the License.
-*/
-
 package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
 )
 
 const (
-	HostNameAnnotation          = "cloudflared-operator.bhyoo.com/host-name"
+	// HostNameAnnotation is the hostname that ports are exposed with. It may be overridden per port with
+	// cloudflared-operator.bhyoo.com/host-name-<port name or number>.
+	HostNameAnnotation = "cloudflared-operator.bhyoo.com/host-name"
+	// PortTunnelMappingAnnotation suffixed with a port name or number maps the port to the Tunnel of the annotation
+	// value.
 	PortTunnelMappingAnnotation = "cloudflared-operator.bhyoo.com/port-"
+
+	// serviceNameLabel marks TunnelIngresses created for the Service of the label value.
+	serviceNameLabel = "cloudflared-operator.bhyoo.com/service-name"
+
+	// serviceConditionTypeTunnelsFound is False while Tunnels mapped to ports of the Service are missing.
+	// TunnelNotFound is recorded when the condition changes, so that it is recorded once rather than on every requeue,
+	// even across restarts of the operator.
+	serviceConditionTypeTunnelsFound = "cloudflared-operator.bhyoo.com/TunnelsFound"
+
+	// reasons of events recorded on Services
+	serviceEventReasonExposed           = "Exposed"
+	serviceEventReasonPruned            = "Pruned"
+	serviceEventReasonTunnelNotFound    = "TunnelNotFound"
+	serviceEventReasonFailedToExpose    = "FailedToExpose"
+	serviceEventReasonInvalidAnnotation = "InvalidAnnotation"
+	serviceEventReasonMissingHostName   = "MissingHostName"
+
+	// serviceTunnelNotFoundRequeue is when Services mapped to a missing Tunnel are reconciled again,
+	// in case the creation of the Tunnel is missed.
+	serviceTunnelNotFoundRequeue = time.Minute
 )
 
 // ServiceReconciler reconciles a Service object
 type ServiceReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
-//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=services/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core,resources=services/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnels,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelingresses,verbs=get;list;watch;create;update;patch;delete
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the Service object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
+// Reconcile exposes ports of an annotated Service through TunnelIngresses, one per port.
+// TunnelIngresses of ports whose annotation or port is removed are deleted,
+// and all of them are garbage collected with the Service.
+// TunnelIngresses of ports whose Tunnel is missing or whose annotations are invalid are kept,
+// and the Service is requeued until the Tunnel exists.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
 func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
+	l := log.FromContext(ctx).WithValues("serviceName", req.Name)
+	ctx = log.IntoContext(ctx, l)
 
 	var service corev1.Service
 	if err := r.Get(ctx, req.NamespacedName, &service); err != nil {
-		logger.Error(err, "unable to fetch Service")
+		// TunnelIngresses of deleted services are garbage collected
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !service.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	desired, pending, missing, err := r.buildTunnelIngresses(ctx, &service)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	annotations := service.Annotations
-	hostName := annotations[HostNameAnnotation]
-	ports := service.Spec.Ports
-	var tunnel v1.Tunnel
+	existing, err := r.getExistingTunnelIngresses(ctx, &service)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
-	for _, port := range ports {
-		tunnelName := annotations[PortTunnelMappingAnnotation+port.Name]
-		if err := r.Get(ctx, client.ObjectKey{Name: tunnelName, Namespace: service.Namespace}, &tunnel); err != nil {
-			logger.Error(err, "No such Tunnel object exists with given name")
-			continue
+	// a port failing to be applied does not hold back the others
+	var applyErrs []error
+	for _, ingress := range desired {
+		if err := r.applyTunnelIngress(ctx, &service, ingress, existing[ingress.Name]); err != nil {
+			r.Recorder.Eventf(&service, corev1.EventTypeWarning, serviceEventReasonFailedToExpose,
+				"failed to apply TunnelIngress %s: %v", ingress.Name, err)
+			applyErrs = append(applyErrs, err)
 		}
-		tunnelIngress := createTunnelIngress(tunnelName, hostName, service, port)
-		if err := ctrl.SetControllerReference(&tunnel, tunnelIngress, r.Scheme); err != nil {
-			// TODO: error handling
+		delete(existing, ingress.Name)
+	}
+
+	// keep TunnelIngresses of ports whose Tunnel is missing, as it may be only recreating,
+	// and of ports with invalid annotations until they are fixed
+	for _, name := range pending {
+		delete(existing, name)
+	}
+
+	// prune TunnelIngresses of removed ports or annotations
+	for _, ingress := range existing {
+		if err := r.Delete(ctx, ingress); client.IgnoreNotFound(err) != nil {
 			return ctrl.Result{}, err
 		}
-		if err := ctrl.SetControllerReference(&service, tunnelIngress, r.Scheme); err != nil {
-			// TODO: error handling
-			return ctrl.Result{}, err
+		r.Recorder.Eventf(&service, corev1.EventTypeNormal, serviceEventReasonPruned,
+			"deleted TunnelIngress %s", ingress.Name)
+	}
+
+	if err := r.reportMissingTunnels(ctx, &service, missing); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(applyErrs) != 0 {
+		return ctrl.Result{}, errors.Join(applyErrs...)
+	}
+	if len(missing) != 0 {
+		return ctrl.Result{RequeueAfter: serviceTunnelNotFoundRequeue}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// getExistingTunnelIngresses returns TunnelIngresses of the service keyed by name.
+// TunnelIngresses that earlier releases named after the Tunnel have no serviceNameLabel,
+// so ones owned by the service without the label are included to be pruned.
+func (r *ServiceReconciler) getExistingTunnelIngresses(
+	ctx context.Context,
+	service *corev1.Service,
+) (map[string]*v1.TunnelIngress, error) {
+	var labeled v1.TunnelIngressList
+	if err := r.List(
+		ctx,
+		&labeled,
+		client.InNamespace(service.Namespace),
+		client.MatchingLabels{serviceNameLabel: service.Name},
+	); err != nil {
+		return nil, err
+	}
+	unlabeledSelector, err := labels.NewRequirement(serviceNameLabel, selection.DoesNotExist, nil)
+	if err != nil {
+		return nil, err
+	}
+	var unlabeled v1.TunnelIngressList
+	if err := r.List(
+		ctx,
+		&unlabeled,
+		client.InNamespace(service.Namespace),
+		client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*unlabeledSelector)},
+	); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]*v1.TunnelIngress, len(labeled.Items))
+	for i := range labeled.Items {
+		ingress := &labeled.Items[i]
+		if metav1.IsControlledBy(ingress, service) {
+			existing[ingress.Name] = ingress
 		}
-		if err := r.Update(ctx, tunnelIngress); err != nil {
-			// TODO: error handling
-			return ctrl.Result{}, err
+	}
+	for i := range unlabeled.Items {
+		ingress := &unlabeled.Items[i]
+		if isOwnedBy(ingress, service) {
+			existing[ingress.Name] = ingress
 		}
-		if err := r.Create(ctx, tunnelIngress); err != nil {
-			logger.Error(err, "Failed to create TunnelIngress")
-			return ctrl.Result{}, err
+	}
+	return existing, nil
+}
+
+// isOwnedBy reports whether the service is one of the owners of obj, whether it is the controller or not.
+func isOwnedBy(obj metav1.Object, service *corev1.Service) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind == "Service" && ref.Name == service.Name && ref.UID == service.UID {
+			return true
 		}
 	}
-	return ctrl.Result{}, nil
+	return false
+}
+
+// reportMissingTunnels sets serviceConditionTypeTunnelsFound of the service to False with missing,
+// or removes it once nothing is missing.
+func (r *ServiceReconciler) reportMissingTunnels(ctx context.Context, service *corev1.Service, missing []string) error {
+	message := strings.Join(missing, ", ")
+	current := meta.FindStatusCondition(service.Status.Conditions, serviceConditionTypeTunnelsFound)
+	switch {
+	case len(missing) == 0 && current == nil:
+		return nil
+	case len(missing) != 0 && current != nil && current.Message == message:
+		return nil
+	}
+
+	original := service.DeepCopy()
+	if len(missing) == 0 {
+		meta.RemoveStatusCondition(&service.Status.Conditions, serviceConditionTypeTunnelsFound)
+	} else {
+		meta.SetStatusCondition(&service.Status.Conditions, metav1.Condition{
+			Type:               serviceConditionTypeTunnelsFound,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: service.Generation,
+			Reason:             serviceEventReasonTunnelNotFound,
+			Message:            message,
+		})
+	}
+	// conditions are merged by type, so that ones of others are kept
+	if err := r.Status().Patch(ctx, service, client.StrategicMergeFrom(original)); err != nil {
+		return err
+	}
+	if len(missing) != 0 {
+		r.Recorder.Event(service, corev1.EventTypeWarning, serviceEventReasonTunnelNotFound, message)
+	}
+	return nil
+}
+
+// buildTunnelIngresses builds TunnelIngresses of ports that are mapped to an existing Tunnel.
+// It also returns names of TunnelIngresses to be kept as they are, of ports whose Tunnel is missing or whose
+// annotations are invalid, and descriptions of missing Tunnels.
+func (r *ServiceReconciler) buildTunnelIngresses(
+	ctx context.Context,
+	service *corev1.Service,
+) ([]*v1.TunnelIngress, []string, []string, error) {
+	var ingresses []*v1.TunnelIngress
+	var pending []string
+	var missing []string
+	for _, port := range service.Spec.Ports {
+		exposure, exposed, err := parseServiceExposure(*service, port)
+		if errors.Is(err, errMissingHostName) {
+			// the port is left unexposed, like ports without mapping, but the user is told why
+			r.Recorder.Eventf(service, corev1.EventTypeWarning, serviceEventReasonMissingHostName,
+				"port %s is mapped to a Tunnel but not exposed: %v", portToString(port), err)
+			continue
+		}
+		if err != nil {
+			r.Recorder.Eventf(service, corev1.EventTypeWarning, serviceEventReasonInvalidAnnotation,
+				"port %s: %v", portToString(port), err)
+			pending = append(pending, buildServiceTunnelIngressName(*service, port))
+			continue
+		}
+		if !exposed {
+			continue
+		}
+
+		var tunnel v1.Tunnel
+		if err := r.Get(
+			ctx,
+			client.ObjectKey{Name: exposure.tunnelName, Namespace: service.Namespace},
+			&tunnel,
+		); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, nil, nil, err
+			}
+			missing = append(missing, fmt.Sprintf(
+				"Tunnel %s of port %s does not exist", exposure.tunnelName, portToString(port),
+			))
+			pending = append(pending, buildServiceTunnelIngressName(*service, port))
+			continue
+		}
+
+		tunnelIngress := createTunnelIngress(exposure, *service, port)
+		if err := ctrl.SetControllerReference(service, tunnelIngress, r.Scheme); err != nil {
+			return nil, nil, nil, err
+		}
+		ingresses = append(ingresses, tunnelIngress)
+	}
+	return ingresses, pending, missing, nil
 }
 
-func createTunnelIngress(tunnelName, hostName string, service corev1.Service, port corev1.ServicePort) *v1.TunnelIngress {
-	portStr := portToString(port)
+func (r *ServiceReconciler) applyTunnelIngress(
+	ctx context.Context,
+	service *corev1.Service,
+	desired, existing *v1.TunnelIngress,
+) error {
+	if existing == nil {
+		if err := r.Create(ctx, desired); err != nil {
+			return err
+		}
+		r.Recorder.Eventf(service, corev1.EventTypeNormal, serviceEventReasonExposed,
+			"created TunnelIngress %s for %s", desired.Name, ptr.Deref(desired.Spec.Hostname, ""))
+		return nil
+	}
+
+	if reflect.DeepEqual(existing.Spec, desired.Spec) &&
+		reflect.DeepEqual(existing.OwnerReferences, desired.OwnerReferences) &&
+		maps.Equal(existing.Labels, desired.Labels) {
+		return nil
+	}
+	existing.Spec = desired.Spec
+	existing.OwnerReferences = desired.OwnerReferences
+	existing.Labels = desired.Labels
+	if err := r.Update(ctx, existing); err != nil {
+		return err
+	}
+	r.Recorder.Eventf(service, corev1.EventTypeNormal, serviceEventReasonExposed,
+		"updated TunnelIngress %s for %s", desired.Name, ptr.Deref(desired.Spec.Hostname, ""))
+	return nil
+}
+
+func createTunnelIngress(exposure serviceExposure, service corev1.Service, port corev1.ServicePort) *v1.TunnelIngress {
 	tunnelIngress := &v1.TunnelIngress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      tunnelName,
+			Name:      buildServiceTunnelIngressName(service, port),
 			Namespace: service.Namespace,
+			Labels:    map[string]string{serviceNameLabel: service.Name},
 		},
 		Spec: v1.TunnelIngressSpec{
 			TunnelConfigIngress: v1.TunnelConfigIngress{
-				Hostname: &hostName,
-				Service:  fmt.Sprintf("%s.%s.svc.cluster.local:%s", service.Name, service.Namespace, portStr),
+				Hostname:      &exposure.hostName,
+				Path:          exposure.path,
+				OriginRequest: exposure.origin,
 			},
+			Origin: exposure.ingressOrigin(service, port),
 			TunnelRef: v1.TunnelRef{
 				Kind: v1.TunnelKindTunnel,
-				Name: tunnelName,
+				Name: exposure.tunnelName,
 			},
+			OverwriteExistingDNS: exposure.overwriteDNS,
 		},
 	}
 	return tunnelIngress
 }
 
+// buildServiceTunnelIngressName names TunnelIngress after the service and the name of port,
+// or the port number if the port is unnamed. Names joined with dashes are ambiguous,
+// e.g. port b-c of service a and port c of service a-b, so a hash of the service and the port is appended.
+func buildServiceTunnelIngressName(service corev1.Service, port corev1.ServicePort) string {
+	portKey := port.Name
+	if portKey == "" {
+		portKey = portToString(port)
+	}
+	sum := sha256.Sum256([]byte(service.Namespace + "/" + service.Name + "/" + portKey))
+	return service.Name + "-" + portKey + "-" + hex.EncodeToString(sum[:])[:8]
+}
+
 func portToString(port corev1.ServicePort) string {
 	portNum := int64(port.Port)
 	portStr := strconv.FormatInt(portNum, 10)
@@ -130,139 +364,89 @@ func portToString(port corev1.ServicePort) string {
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Service{}).
-		//Watches(
-		//	&v1.Tunnel{},
-		//	handler.EnqueueRequestsFromMapFunc(r.findRelatedServiceObject),
-		//	//builder.WithPredicates(onlyResponseOnTunnelCreation()),
-		//).
-		WithEventFilter(predicate.Funcs{
-			UpdateFunc: checkServiceUpdateForReconciliation(),
-			CreateFunc: checkServiceCreationForReconciliation(),
-		}).
+		For(
+			&corev1.Service{},
+			builder.WithPredicates(predicate.Funcs{
+				// services without annotations are also reconciled on creation,
+				// since they may own TunnelIngresses of annotations removed while the operator was not running
+				UpdateFunc: checkServiceUpdateForReconciliation(),
+				// TunnelIngresses are garbage collected with the service
+				DeleteFunc: func(event.DeleteEvent) bool { return false },
+			}),
+		).
+		Owns(&v1.TunnelIngress{}).
+		Watches(
+			&v1.Tunnel{},
+			handler.EnqueueRequestsFromMapFunc(r.findRelatedServiceObject),
+			builder.WithPredicates(onlyResponseOnTunnelCreation()),
+		).
 		Complete(r)
 }
 
-//func onlyResponseOnTunnelCreation() predicate.Funcs {
-//	return predicate.Funcs{
-//		CreateFunc: func(e event.CreateEvent) bool {
-//			return true
-//		},
-//		DeleteFunc: func(e event.DeleteEvent) bool {
-//			return false
-//		},
-//		UpdateFunc: func(e event.UpdateEvent) bool {
-//			return false
-//		},
-//		GenericFunc: func(e event.GenericEvent) bool {
-//			return false
-//		},
-//	}
-//}
-
-func checkServiceCreationForReconciliation() func(e event.CreateEvent) bool {
-	return func(e event.CreateEvent) bool {
-		annotations := e.Object.GetAnnotations()
-
-		if _, hostNameExists := findHostName(annotations); !hostNameExists {
+func onlyResponseOnTunnelCreation() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
 			return false
-		}
-
-		newService, isService := e.Object.(*corev1.Service)
-		if !isService {
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
 			return false
-		}
-
-		for _, port := range newService.Spec.Ports {
-			// check if all required annotations are present
-			if _, tunnelNameExists := findTunnelMappingByPortName(annotations, port.Name); tunnelNameExists {
-				return true
-			}
-		}
-		return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
 	}
 }
 
 func checkServiceUpdateForReconciliation() func(e event.UpdateEvent) bool {
 	return func(e event.UpdateEvent) bool {
-		oldAnnotations := e.ObjectOld.GetAnnotations()
-		newAnnotations := e.ObjectNew.GetAnnotations()
-
-		// if no changes in annotations, return false
-		if reflect.DeepEqual(oldAnnotations, newAnnotations) {
-			return false
-		}
-
-		hostName, hostNameExists := findHostName(newAnnotations)
-		if !hostNameExists {
+		oldService, isService := e.ObjectOld.(*corev1.Service)
+		if !isService {
 			return false
 		}
-
-		updatedService, isService := e.ObjectNew.(*corev1.Service)
+		newService, isService := e.ObjectNew.(*corev1.Service)
 		if !isService {
 			return false
 		}
 
-		for _, port := range updatedService.Spec.Ports {
-			portName := port.Name
-			// check if portToTunnel mapping annotation exists with given port name
-			portToTunnel, mappingExists := findTunnelMappingByPortName(newAnnotations, portName)
-			if !mappingExists {
-				// continue to see if other ports have correct annotations to create TunnelIngress object
-				continue
-			}
-			// check if there are any changes in tunnel mapping or host name for any port.
-			if portToTunnel != oldAnnotations[PortTunnelMappingAnnotation+portName] || hostName != oldAnnotations[HostNameAnnotation] {
-				return true
-			}
-		}
-		return false
+		// removed annotations prune TunnelIngresses, so any change of annotations or ports is reconciled
+		return !reflect.DeepEqual(oldService.Annotations, newService.Annotations) ||
+			!reflect.DeepEqual(oldService.Spec.Ports, newService.Spec.Ports)
 	}
 }
 
-//func (r *ServiceReconciler) findRelatedServiceObject(ctx context.Context, tunnel client.Object) []reconcile.Request {
-//	tunnelName := tunnel.GetName()
-//	tunnelNamespace := tunnel.GetNamespace()
-//
-//	var list corev1.ServiceList
-//	if err := r.List(
-//		ctx,
-//		&list,
-//		client.InNamespace(tunnelNamespace),
-//	); err != nil {
-//		return []reconcile.Request{}
-//	}
-//
-//	for _, item := range list.Items {
-//		annotations := item.Annotations
-//		if _, hostNameExists := findHostName(annotations); !hostNameExists {
-//			continue
-//		}
-//		for _, port := range item.Spec.Ports {
-//			if tunnelName == annotations[PortTunnelMappingAnnotation+port.Name] {
-//				// if there's a service that already defines annotations for that specific Tunnel,
-//				// create Reconcile request
-//				return []reconcile.Request{{
-//					NamespacedName: types.NamespacedName{
-//						Name:      item.Name,
-//						Namespace: item.Namespace,
-//					},
-//				}}
-//			}
-//		}
-//	}
-//	return []reconcile.Request{}
-//}
-
-// Check if the service has a hostname annotation ("cloudflared-operator.bhyoo.com/host-name") specified.
-// This annotation is crucial for identifying the target host name for the TunnelIngress creation.
-// Without a specified host name, the TunnelIngress cannot be created
-func findHostName(newAnnotations map[string]string) (string, bool) {
-	hostName, hostNameExists := newAnnotations[HostNameAnnotation]
-	return hostName, hostNameExists
-}
+func (r *ServiceReconciler) findRelatedServiceObject(ctx context.Context, tunnel client.Object) []reconcile.Request {
+	tunnelName := tunnel.GetName()
+	tunnelNamespace := tunnel.GetNamespace()
+
+	var list corev1.ServiceList
+	if err := r.List(
+		ctx,
+		&list,
+		client.InNamespace(tunnelNamespace),
+	); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing services mapped to tunnel")
+		return []reconcile.Request{}
+	}
 
-func findTunnelMappingByPortName(annotations map[string]string, portName string) (string, bool) {
-	portToTunnel, mappingExists := annotations[PortTunnelMappingAnnotation+portName]
-	return portToTunnel, mappingExists
+	var requests []reconcile.Request
+	for _, item := range list.Items {
+		for _, port := range item.Spec.Ports {
+			if mapped, ok := findTunnelMapping(item.Annotations, port); ok && mapped == tunnelName {
+				// if there's a service that already defines annotations for that specific Tunnel,
+				// create Reconcile request
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      item.Name,
+						Namespace: item.Namespace,
+					},
+				})
+				break
+			}
+		}
+	}
+	return requests
 }
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+// Annotations of Service that configure its TunnelIngresses. Ones marked per-port may be suffixed with
+// -<port name or number> to override the value of the port, e.g. cloudflared-operator.bhyoo.com/scheme-grpc.
+const (
+	// PathAnnotation is the regular expression of paths to expose. (per-port)
+	PathAnnotation = "cloudflared-operator.bhyoo.com/path"
+	// SchemeAnnotation is the scheme of the origin, one of http, https, tcp, ssh, rdp, smb and unix.
+	// Defaults to http.
+	// (per-port)
+	SchemeAnnotation = "cloudflared-operator.bhyoo.com/scheme"
+	// SocketPathAnnotation is the path of unix socket to proxy to, required by the unix scheme. (per-port)
+	SocketPathAnnotation = "cloudflared-operator.bhyoo.com/socket-path"
+
+	OriginServerNameAnnotation = "cloudflared-operator.bhyoo.com/origin-server-name"
+	CAPoolAnnotation           = "cloudflared-operator.bhyoo.com/ca-pool"
+	NoTLSVerifyAnnotation      = "cloudflared-operator.bhyoo.com/no-tls-verify"
+	HTTP2OriginAnnotation      = "cloudflared-operator.bhyoo.com/http2-origin"
+	HTTPHostHeaderAnnotation   = "cloudflared-operator.bhyoo.com/http-host-header"
+
+	AccessRequiredAnnotation = "cloudflared-operator.bhyoo.com/access-required"
+	AccessTeamNameAnnotation = "cloudflared-operator.bhyoo.com/access-team-name"
+	// AccessAudTagAnnotation is comma separated audience tags of Access applications.
+	AccessAudTagAnnotation = "cloudflared-operator.bhyoo.com/access-aud-tag"
+
+	OverwriteDNSAnnotation = "cloudflared-operator.bhyoo.com/overwrite-dns"
+)
+
+var (
+	errInvalidServiceAnnotation = errors.New("invalid annotation")
+	// errMissingHostName is returned for ports mapped to a Tunnel without hostname to expose them with.
+	errMissingHostName = errors.New("missing hostname")
+
+	serviceOriginSchemes = []v1.OriginScheme{
+		v1.OriginSchemeHTTP,
+		v1.OriginSchemeHTTPS,
+		v1.OriginSchemeTCP,
+		v1.OriginSchemeSSH,
+		v1.OriginSchemeRDP,
+		v1.OriginSchemeSMB,
+		v1.OriginSchemeUnix,
+	}
+)
+
+// serviceExposure is the parsed annotations of a port of Service.
+type serviceExposure struct {
+	tunnelName   string
+	hostName     string
+	path         *string
+	scheme       v1.OriginScheme
+	socketPath   string
+	overwriteDNS bool
+	origin       *v1.OriginRequestConfig
+}
+
+// parseServiceExposure parses annotations of the port. It returns false if the port is not mapped to a Tunnel,
+// and errMissingHostName if it is mapped without hostname.
+func parseServiceExposure(service corev1.Service, port corev1.ServicePort) (serviceExposure, bool, error) {
+	annotations := service.Annotations
+
+	tunnelName, mappingExists := findTunnelMapping(annotations, port)
+	if !mappingExists {
+		return serviceExposure{}, false, nil
+	}
+
+	var errs []error
+	exposure := serviceExposure{tunnelName: tunnelName, scheme: v1.OriginSchemeHTTP}
+
+	hostName, ok := findPortAnnotation(annotations, HostNameAnnotation, port)
+	if !ok {
+		return serviceExposure{}, false, fmt.Errorf("%w: set %s or %s",
+			errMissingHostName, HostNameAnnotation, portAnnotationKeys(HostNameAnnotation+"-", port)[0])
+	}
+	if msgs := validation.IsWildcardDNS1123Subdomain(hostName); len(msgs) != 0 &&
+		len(validation.IsDNS1123Subdomain(hostName)) != 0 {
+		errs = append(errs, annotationError(HostNameAnnotation, hostName, strings.Join(msgs, ", ")))
+	}
+	exposure.hostName = hostName
+
+	if path, ok := findPortAnnotation(annotations, PathAnnotation, port); ok {
+		if _, err := regexp.Compile(path); err != nil {
+			errs = append(errs, annotationError(PathAnnotation, path, err.Error()))
+		}
+		exposure.path = &path
+	}
+
+	if scheme, ok := findPortAnnotation(annotations, SchemeAnnotation, port); ok {
+		if !slices.Contains(serviceOriginSchemes, v1.OriginScheme(scheme)) {
+			errs = append(errs, annotationError(SchemeAnnotation, scheme, "must be one of http, https, tcp, ssh, rdp, smb, unix"))
+		}
+		exposure.scheme = v1.OriginScheme(scheme)
+	}
+	if exposure.scheme == v1.OriginSchemeUnix {
+		socketPath, ok := findPortAnnotation(annotations, SocketPathAnnotation, port)
+		if !ok || !strings.HasPrefix(socketPath, "/") {
+			errs = append(errs, annotationError(SocketPathAnnotation, socketPath, "unix scheme requires an absolute path"))
+		}
+		exposure.socketPath = socketPath
+	}
+
+	overwriteDNS, err := parseBoolAnnotation(annotations, OverwriteDNSAnnotation)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	exposure.overwriteDNS = ptr.Deref(overwriteDNS, false)
+
+	exposure.origin, err = parseServiceOrigin(annotations)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 0 {
+		return serviceExposure{}, false, errors.Join(errs...)
+	}
+	return exposure, true, nil
+}
+
+func parseServiceOrigin(annotations map[string]string) (*v1.OriginRequestConfig, error) {
+	var errs []error
+	var origin v1.OriginRequestConfig
+
+	tls := v1.OriginTLSSettings{}
+	if v, ok := annotations[OriginServerNameAnnotation]; ok {
+		tls.OriginServerName = &v
+	}
+	if v, ok := annotations[CAPoolAnnotation]; ok {
+		tls.CAPool = &v
+	}
+	var err error
+	if tls.NoTLSVerify, err = parseBoolAnnotation(annotations, NoTLSVerifyAnnotation); err != nil {
+		errs = append(errs, err)
+	}
+	if tls.HTTP2Origin, err = parseBoolAnnotation(annotations, HTTP2OriginAnnotation); err != nil {
+		errs = append(errs, err)
+	}
+	if tls != (v1.OriginTLSSettings{}) {
+		origin.OriginTLSSettings = &tls
+	}
+
+	if v, ok := annotations[HTTPHostHeaderAnnotation]; ok {
+		origin.OriginHTTPSettings = &v1.OriginHTTPSettings{HTTPHostHeader: &v}
+	}
+
+	access := v1.OriginAccessSettingsAccess{}
+	if access.Required, err = parseBoolAnnotation(annotations, AccessRequiredAnnotation); err != nil {
+		errs = append(errs, err)
+	}
+	if v, ok := annotations[AccessTeamNameAnnotation]; ok {
+		access.TeamName = &v
+	}
+	if v, ok := annotations[AccessAudTagAnnotation]; ok {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				access.AudTag = append(access.AudTag, tag)
+			}
+		}
+	}
+	if ptr.Deref(access.Required, false) && (access.TeamName == nil || len(access.AudTag) == 0) {
+		errs = append(errs, annotationError(
+			AccessRequiredAnnotation,
+			"true",
+			"requires "+AccessTeamNameAnnotation+" and "+AccessAudTagAnnotation,
+		))
+	}
+	if access.Required != nil || access.TeamName != nil || access.AudTag != nil {
+		origin.OriginAccessSettings = &v1.OriginAccessSettings{Access: &access}
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	if origin == (v1.OriginRequestConfig{}) {
+		return nil, nil
+	}
+	return &origin, nil
+}
+
+// ingressOrigin returns the origin of the exposure, which refers the port of the service unless it is a unix socket.
+func (e serviceExposure) ingressOrigin(service corev1.Service, port corev1.ServicePort) *v1.IngressOrigin {
+	if e.scheme == v1.OriginSchemeUnix {
+		return &v1.IngressOrigin{Scheme: e.scheme, Address: e.socketPath}
+	}
+	return &v1.IngressOrigin{Scheme: e.scheme, BackendRef: &v1.BackendRef{Name: service.Name, Port: servicePortRef(port)}}
+}
+
+// findPortAnnotation finds the annotation of the port, which overrides the annotation of the service.
+// The port is looked up by its name, then its number.
+func findPortAnnotation(annotations map[string]string, key string, port corev1.ServicePort) (string, bool) {
+	for _, portKey := range portAnnotationKeys(key+"-", port) {
+		if v, ok := annotations[portKey]; ok {
+			return v, true
+		}
+	}
+	v, ok := annotations[key]
+	return v, ok
+}
+
+// findTunnelMapping finds the name of the Tunnel that the port is mapped to.
+// Unlike findPortAnnotation, there is no mapping of the whole service.
+func findTunnelMapping(annotations map[string]string, port corev1.ServicePort) (string, bool) {
+	for _, portKey := range portAnnotationKeys(PortTunnelMappingAnnotation, port) {
+		if v, ok := annotations[portKey]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// portAnnotationKeys returns keys of the annotation of the port, which are prefix suffixed with its name first
+// if named, then its number.
+func portAnnotationKeys(prefix string, port corev1.ServicePort) []string {
+	if port.Name != "" {
+		return []string{prefix + port.Name, prefix + portToString(port)}
+	}
+	return []string{prefix + portToString(port)}
+}
+
+func parseBoolAnnotation(annotations map[string]string, key string) (*bool, error) {
+	v, ok := annotations[key]
+	if !ok {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, annotationError(key, v, "must be true or false")
+	}
+	return &b, nil
+}
+
+func annotationError(key, value, msg string) error {
+	return fmt.Errorf("%w %s=%q: %s", errInvalidServiceAnnotation, key, value, msg)
+}
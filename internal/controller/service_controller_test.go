@@ -0,0 +1,314 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func newTestService(name string, annotations map[string]string, ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+		Spec:       corev1.ServiceSpec{Ports: ports},
+	}
+}
+
+func TestBuildServiceTunnelIngressName(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	names := map[string]struct{}{}
+	for _, tc := range []struct {
+		service string
+		port    corev1.ServicePort
+	}{
+		{service: "a", port: corev1.ServicePort{Name: "b-c", Port: 80}},
+		{service: "a-b", port: corev1.ServicePort{Name: "c", Port: 80}},
+		{service: "a", port: corev1.ServicePort{Port: 80}},
+		{service: "a-80", port: corev1.ServicePort{Port: 80}},
+	} {
+		name := buildServiceTunnelIngressName(*newTestService(tc.service, nil), tc.port)
+		g.Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty())
+		g.Expect(names).NotTo(HaveKey(name), "%s of service %s", name, tc.service)
+		names[name] = struct{}{}
+	}
+
+	// names are stable, so that the TunnelIngress of a port is updated rather than replaced
+	service := newTestService("web", nil)
+	port := corev1.ServicePort{Name: "http", Port: 80}
+	g.Expect(buildServiceTunnelIngressName(*service, port)).To(Equal(buildServiceTunnelIngressName(*service, port)))
+	g.Expect(buildServiceTunnelIngressName(*service, port)).To(HavePrefix("web-http-"))
+}
+
+func newTestServiceReconciler(g Gomega, objects ...client.Object) (*ServiceReconciler, *record.FakeRecorder) {
+	r := newTestReconciler(g, objects...)
+	recorder := record.NewFakeRecorder(100)
+	return &ServiceReconciler{Client: r.Client, Scheme: r.Scheme, Recorder: recorder}, recorder
+}
+
+// drainEvents returns events recorded so far.
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestServiceKeepsTunnelIngressesOfMissingTunnel(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	tunnel := newTestTunnel()
+	service := newTestService("web", map[string]string{
+		PortTunnelMappingAnnotation + "http": tunnel.Name,
+		HostNameAnnotation:                   "web.example.com",
+	}, corev1.ServicePort{Name: "http", Port: 80})
+	r, recorder := newTestServiceReconciler(g, service, tunnel)
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(service)}
+	listIngresses := func() []v1.TunnelIngress {
+		var list v1.TunnelIngressList
+		g.Expect(r.List(ctx, &list)).To(Succeed())
+		return list.Items
+	}
+
+	g.Expect(r.Reconcile(ctx, req)).To(Equal(reconcile.Result{}))
+	g.Expect(listIngresses()).To(HaveLen(1))
+
+	g.Expect(r.Delete(ctx, tunnel)).To(Succeed())
+	drainEvents(recorder)
+	for i := 0; i < 3; i++ {
+		g.Expect(r.Reconcile(ctx, req)).To(Equal(reconcile.Result{RequeueAfter: serviceTunnelNotFoundRequeue}))
+		g.Expect(listIngresses()).To(HaveLen(1))
+	}
+	g.Expect(drainEvents(recorder)).To(HaveExactElements(HavePrefix("Warning " + serviceEventReasonTunnelNotFound)))
+	g.Expect(r.Get(ctx, req.NamespacedName, service)).To(Succeed())
+	g.Expect(service.Status.Conditions).To(HaveExactElements(And(
+		HaveField("Type", serviceConditionTypeTunnelsFound),
+		HaveField("Status", metav1.ConditionFalse),
+		HaveField("Message", ContainSubstring("Tunnel "+tunnel.Name+" of port 80")),
+	)))
+
+	// the event is not recorded again by a restarted operator
+	restarted, restartedRecorder := newTestServiceReconciler(g)
+	restarted.Client = r.Client
+	g.Expect(restarted.Reconcile(ctx, req)).To(Equal(reconcile.Result{RequeueAfter: serviceTunnelNotFoundRequeue}))
+	g.Expect(drainEvents(restartedRecorder)).To(BeEmpty())
+
+	// the event is recorded again once the Tunnel goes missing again
+	tunnel = newTestTunnel()
+	g.Expect(r.Create(ctx, tunnel)).To(Succeed())
+	g.Expect(r.Reconcile(ctx, req)).To(Equal(reconcile.Result{}))
+	g.Expect(r.Get(ctx, req.NamespacedName, service)).To(Succeed())
+	g.Expect(service.Status.Conditions).To(BeEmpty())
+	g.Expect(r.Delete(ctx, tunnel)).To(Succeed())
+	g.Expect(r.Reconcile(ctx, req)).To(Equal(reconcile.Result{RequeueAfter: serviceTunnelNotFoundRequeue}))
+	g.Expect(drainEvents(recorder)).To(ContainElement(HavePrefix("Warning " + serviceEventReasonTunnelNotFound)))
+	g.Expect(listIngresses()).To(HaveLen(1))
+
+	// ports whose annotation is removed are pruned regardless of the Tunnel
+	g.Expect(r.Get(ctx, req.NamespacedName, service)).To(Succeed())
+	delete(service.Annotations, PortTunnelMappingAnnotation+"http")
+	g.Expect(r.Update(ctx, service)).To(Succeed())
+	g.Expect(r.Reconcile(ctx, req)).To(Equal(reconcile.Result{}))
+	g.Expect(listIngresses()).To(BeEmpty())
+}
+
+func TestServicePortAnnotationKeys(t *testing.T) {
+	t.Parallel()
+
+	tunnel := newTestTunnel()
+	tests := map[string]struct {
+		annotations map[string]string
+		port        corev1.ServicePort
+		hostName    string
+	}{
+		"named port by name": {
+			annotations: map[string]string{
+				PortTunnelMappingAnnotation + "http": tunnel.Name,
+				HostNameAnnotation + "-http":         "web.example.com",
+			},
+			port:     corev1.ServicePort{Name: "http", Port: 80},
+			hostName: "web.example.com",
+		},
+		"named port by number": {
+			annotations: map[string]string{
+				PortTunnelMappingAnnotation + "80": tunnel.Name,
+				HostNameAnnotation + "-80":         "web.example.com",
+			},
+			port:     corev1.ServicePort{Name: "http", Port: 80},
+			hostName: "web.example.com",
+		},
+		"unnamed port": {
+			annotations: map[string]string{
+				PortTunnelMappingAnnotation + "80": tunnel.Name,
+				HostNameAnnotation:                 "web.example.com",
+			},
+			port:     corev1.ServicePort{Port: 80},
+			hostName: "web.example.com",
+		},
+		"name overrides number": {
+			annotations: map[string]string{
+				PortTunnelMappingAnnotation + "http": tunnel.Name,
+				HostNameAnnotation + "-80":           "number.example.com",
+				HostNameAnnotation + "-http":         "name.example.com",
+			},
+			port:     corev1.ServicePort{Name: "http", Port: 80},
+			hostName: "name.example.com",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			service := newTestService("web", tc.annotations, tc.port)
+			r, _ := newTestServiceReconciler(g, service, tunnel)
+
+			exposure, exposed, err := parseServiceExposure(*service, tc.port)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(exposed).To(BeTrue())
+			g.Expect(exposure.tunnelName).To(Equal(tunnel.Name))
+			g.Expect(exposure.hostName).To(Equal(tc.hostName))
+
+			// the Service is reconciled when the Tunnel of the same key is created
+			g.Expect(r.findRelatedServiceObject(ctx, tunnel)).To(HaveExactElements(
+				reconcile.Request{NamespacedName: client.ObjectKeyFromObject(service)},
+			))
+		})
+	}
+}
+
+func TestServiceWarnsPortsWithoutHostName(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	tunnel := newTestTunnel()
+	service := newTestService("web", map[string]string{
+		PortTunnelMappingAnnotation + "http":  tunnel.Name,
+		PortTunnelMappingAnnotation + "admin": tunnel.Name,
+		HostNameAnnotation + "-http":          "web.example.com",
+	}, corev1.ServicePort{Name: "http", Port: 80}, corev1.ServicePort{Name: "admin", Port: 8080})
+	r, recorder := newTestServiceReconciler(g, service, tunnel)
+
+	g.Expect(r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(service)})).
+		To(Equal(reconcile.Result{}))
+
+	var list v1.TunnelIngressList
+	g.Expect(r.List(ctx, &list)).To(Succeed())
+	g.Expect(list.Items).To(HaveExactElements(HaveField("Spec.Hostname", HaveValue(Equal("web.example.com")))))
+	g.Expect(drainEvents(recorder)).To(ContainElement(And(
+		HavePrefix("Warning "+serviceEventReasonMissingHostName),
+		ContainSubstring(HostNameAnnotation+"-admin"),
+	)))
+}
+
+func TestServiceReportsInvalidAnnotationsPerPort(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	tunnel := newTestTunnel()
+	httpPort := corev1.ServicePort{Name: "http", Port: 80}
+	adminPort := corev1.ServicePort{Name: "admin", Port: 8080}
+	service := newTestService("web", map[string]string{
+		PortTunnelMappingAnnotation + "http":  tunnel.Name,
+		PortTunnelMappingAnnotation + "admin": tunnel.Name,
+		HostNameAnnotation + "-http":          "web.example.com",
+		HostNameAnnotation + "-admin":         "admin.example.com",
+	}, httpPort, adminPort)
+	r, recorder := newTestServiceReconciler(g, service, tunnel)
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(service)}
+
+	g.Expect(r.Reconcile(ctx, req)).To(Equal(reconcile.Result{}))
+
+	g.Expect(r.Get(ctx, req.NamespacedName, service)).To(Succeed())
+	service.Annotations[SchemeAnnotation+"-admin"] = "ftp"
+	service.Annotations[HostNameAnnotation+"-http"] = "www.example.com"
+	g.Expect(r.Update(ctx, service)).To(Succeed())
+	drainEvents(recorder)
+	g.Expect(r.Reconcile(ctx, req)).To(Equal(reconcile.Result{}))
+
+	g.Expect(drainEvents(recorder)).To(ContainElement(And(
+		HavePrefix("Warning "+serviceEventReasonInvalidAnnotation),
+		ContainSubstring("port 8080"),
+		ContainSubstring(SchemeAnnotation+`="ftp"`),
+	)))
+	// the other port is still reconciled, and the TunnelIngress of the invalid port is kept as it was
+	var httpIngress, adminIngress v1.TunnelIngress
+	g.Expect(r.Get(ctx, client.ObjectKey{
+		Namespace: service.Namespace,
+		Name:      buildServiceTunnelIngressName(*service, httpPort),
+	}, &httpIngress)).To(Succeed())
+	g.Expect(httpIngress.Spec.Hostname).To(HaveValue(Equal("www.example.com")))
+	g.Expect(r.Get(ctx, client.ObjectKey{
+		Namespace: service.Namespace,
+		Name:      buildServiceTunnelIngressName(*service, adminPort),
+	}, &adminIngress)).To(Succeed())
+	g.Expect(adminIngress.Spec.Hostname).To(HaveValue(Equal("admin.example.com")))
+}
+
+// TestServicePrunesLegacyTunnelIngresses prunes TunnelIngresses that earlier releases named after the Tunnel
+// without serviceNameLabel.
+func TestServicePrunesLegacyTunnelIngresses(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	tunnel := newTestTunnel()
+	port := corev1.ServicePort{Name: "http", Port: 80}
+	service := newTestService("web", map[string]string{
+		PortTunnelMappingAnnotation + "http": tunnel.Name,
+		HostNameAnnotation:                   "web.example.com",
+	}, port)
+	service.UID = "web-uid"
+	newLegacyIngress := func(name string, owners ...metav1.OwnerReference) *v1.TunnelIngress {
+		return &v1.TunnelIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", OwnerReferences: owners},
+			Spec: v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To("web.example.com"),
+					Service:  "web.default.svc.cluster.local:80",
+				},
+				TunnelRef: v1.TunnelRef{Kind: v1.TunnelKindTunnel, Name: tunnel.Name},
+			},
+		}
+	}
+	legacy := newLegacyIngress(tunnel.Name, metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Name:       service.Name,
+		UID:        service.UID,
+	})
+	// TunnelIngresses of others are left alone
+	others := newLegacyIngress("others")
+	r, _ := newTestServiceReconciler(g, service, tunnel, legacy, others)
+
+	g.Expect(r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(service)})).
+		To(Equal(reconcile.Result{}))
+
+	var list v1.TunnelIngressList
+	g.Expect(r.List(ctx, &list)).To(Succeed())
+	g.Expect(list.Items).To(ConsistOf(
+		HaveField("Name", buildServiceTunnelIngressName(*service, port)),
+		HaveField("Name", others.Name),
+	))
+}
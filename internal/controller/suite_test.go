@@ -17,21 +17,30 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	cloudflaredoperatorv1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare/fake"
+	"github.com/isac322/cloudflared-operator/internal/config"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -42,6 +51,8 @@ var (
 	cfg       *rest.Config
 	k8sClient client.Client
 	testEnv   *envtest.Environment
+	fakeCF    *fake.Server
+	cancelMgr context.CancelFunc
 )
 
 func TestControllers(t *testing.T) {
@@ -55,18 +66,26 @@ func TestControllers(t *testing.T) {
 var _ = BeforeSuite(func() {
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
 
+	// The BinaryAssetsDirectory is only required if you want to run the tests directly
+	// without call the makefile target test. If not informed it will look for the
+	// default path defined in controller-runtime which is /usr/local/kubebuilder/.
+	// Note that you must have the required binaries setup under the bin directory to perform
+	// the tests directly. When we run make test it will be setup and used automatically.
+	binaryAssetsDirectory := filepath.Join("..", "..", "bin", "k8s",
+		fmt.Sprintf("1.28.3-%s-%s", runtime.GOOS, runtime.GOARCH))
+	// Only an explicit opt-out skips the suite, so that CI missing the binaries fails instead of passing silently.
+	if os.Getenv("SKIP_ENVTEST") == "true" {
+		Skip("SKIP_ENVTEST is set")
+	}
+	if _, err := os.Stat(binaryAssetsDirectory); os.Getenv("KUBEBUILDER_ASSETS") == "" && err != nil {
+		Fail("envtest binaries are not found. run `make test`, set KUBEBUILDER_ASSETS or set SKIP_ENVTEST=true")
+	}
+
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
 		ErrorIfCRDPathMissing: true,
-
-		// The BinaryAssetsDirectory is only required if you want to run the tests directly
-		// without call the makefile target test. If not informed it will look for the
-		// default path defined in controller-runtime which is /usr/local/kubebuilder/.
-		// Note that you must have the required binaries setup under the bin directory to perform
-		// the tests directly. When we run make test it will be setup and used automatically.
-		BinaryAssetsDirectory: filepath.Join("..", "..", "bin", "k8s",
-			fmt.Sprintf("1.28.3-%s-%s", runtime.GOOS, runtime.GOARCH)),
+		BinaryAssetsDirectory: binaryAssetsDirectory,
 	}
 
 	var err error
@@ -83,10 +102,63 @@ var _ = BeforeSuite(func() {
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
+
+	By("starting fake Cloudflare API")
+	fakeCF = fake.NewServer()
+
+	By("starting controllers")
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme.Scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	cfClientOpts := cloudflare.DefaultClientOptions()
+	cfClientOpts.BaseURL = fakeCF.URL
+	cfClientOpts.RequestsPerSecond = 100
+	cfClientOpts.MaxRetries = 1
+	cfClients := cloudflare.NewClientPool(cfClientOpts, clock.RealClock{})
+
+	Expect((&TunnelReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Clock:             clock.RealClock{},
+		CloudflareClients: cfClients,
+		DaemonConfig:      config.Default().Daemon,
+		DaemonVersions:    cloudflare.NewGitHubReleases(fakeCF.URL, clock.RealClock{}),
+	}).SetupWithManager(mgr)).To(Succeed())
+	Expect((&TunnelIngressReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Clock:             clock.RealClock{},
+		CloudflareClients: cfClients,
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	var ctx context.Context
+	ctx, cancelMgr = context.WithCancel(context.Background())
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(ctx)).To(Succeed())
+	}()
 })
 
 var _ = AfterSuite(func() {
+	if testEnv == nil {
+		return
+	}
+
 	By("tearing down the test environment")
+	if cancelMgr != nil {
+		cancelMgr()
+	}
+	if fakeCF != nil {
+		fakeCF.Close()
+	}
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
+
+const (
+	eventuallyTimeout  = 10 * time.Second
+	eventuallyInterval = 250 * time.Millisecond
+)
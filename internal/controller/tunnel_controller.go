@@ -22,10 +22,13 @@ import (
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/clock"
@@ -38,13 +41,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	configv1alpha1 "github.com/isac322/cloudflared-operator/api/config/v1alpha1"
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
 )
 
 const (
 	apiTokenKey        = "token"
 	secretNameField    = ".spec.apiTokenSecretRef.name"
 	tunnelRefNameField = ".spec.tunnelRef.name"
+	tunnelRefField     = ".spec.tunnelRef"
 	tunnelRefKindField = ".spec.tunnelRef.kind"
 	fileNameCredential = "credential.json"
 	fileNameConfig     = "config.yaml"
@@ -57,6 +63,14 @@ type TunnelReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Clock  clock.PassiveClock
+
+	CloudflareClients *cloudflare.ClientPool
+	// DaemonConfig holds defaults of cloudflared workloads. It is expected to be defaulted.
+	DaemonConfig configv1alpha1.DaemonConfiguration
+	// DaemonVersions resolves and verifies versions of cloudflared.
+	DaemonVersions cloudflare.DaemonVersionResolver
+	// PreStopSleepUnsupported drops PreStopDelay of tunnels, for API servers that may reject the sleep action.
+	PreStopSleepUnsupported bool
 }
 
 //+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnels,verbs=get;list;watch;create;update;patch;delete
@@ -64,10 +78,16 @@ type TunnelReconciler struct {
 //+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnels/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get
-//+kubebuilder:rbac:groups=apps,resources=daemonset,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=apps,resources=daemonset/status,verbs=get
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=daemonsets/status,verbs=get
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=secrets/status,verbs=get
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelreferencegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -118,6 +138,12 @@ func (r *TunnelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 	}
 
+	if markRemotelyManaged(&tunnel) {
+		if err := r.Update(ctx, &tunnel); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	if err := r.reconcileCredential(ctx, &tunnel); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -139,11 +165,12 @@ func (r *TunnelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
-	if err := r.reconcileDaemon(ctx, &tunnel, tunnelConfig); err != nil {
+	requeueAfter, err := r.reconcileDaemon(ctx, &tunnel, tunnelConfig)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 func (r *TunnelReconciler) findObjectsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
@@ -179,17 +206,90 @@ func (r *TunnelReconciler) findObjectsForTunnelIngress(
 	if ingress.Spec.TunnelRef.Kind != v1.TunnelKindTunnel {
 		return nil
 	}
+	// denied ingresses are reconciled as well, so that their rules are dropped
 	ingressCondition := ingress.Status.GetCondition(v1.TunnelIngressConditionTypeDNSRecord)
-	if ingressCondition.Status != corev1.ConditionTrue {
+	if ingressCondition.Status != corev1.ConditionTrue &&
+		ingressCondition.Reason != v1.DNSRecordReasonRefNotPermitted &&
+		ingressCondition.Reason != v1.DNSRecordReasonPolicyViolation {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: tunnelRefKey(ingress.Namespace, ingress.Spec.TunnelRef)}}
+}
+
+// findObjectsForBackend enqueues Tunnels of TunnelIngresses referring the Service, so that ports are resolved again.
+func (r *TunnelReconciler) findObjectsForBackend(ctx context.Context, service client.Object) []reconcile.Request {
+	ingresses, err := listIngressesForService(ctx, r, service.GetNamespace(), service.GetName())
+	if err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing tunnel ingresses referring service")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ingress := range ingresses {
+		if ingress.Spec.TunnelRef.Kind != v1.TunnelKindTunnel {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: tunnelRefKey(ingress.Namespace, ingress.Spec.TunnelRef),
+		})
+	}
+	return requests
+}
+
+func (r *TunnelReconciler) findObjectsForTunnelNetworkRoute(
+	_ context.Context,
+	tunnelNetworkRoute client.Object,
+) []reconcile.Request {
+	route := tunnelNetworkRoute.(*v1.TunnelNetworkRoute)
+	if route.Spec.TunnelRef.Kind != v1.TunnelKindTunnel {
 		return nil
 	}
 
 	return []reconcile.Request{{NamespacedName: types.NamespacedName{
-		Name:      ingress.Spec.TunnelRef.Name,
-		Namespace: tunnelIngress.GetNamespace(),
+		Name:      route.Spec.TunnelRef.Name,
+		Namespace: tunnelNetworkRoute.GetNamespace(),
 	}}}
 }
 
+// findTunnelsWaitingFor enqueues Tunnels whose upgrade waits for the given Tunnel, so that staged rollouts proceed
+// as soon as an earlier stage settles.
+func (r *TunnelReconciler) findTunnelsWaitingFor(ctx context.Context, obj client.Object) []reconcile.Request {
+	keys := []string{waitForAnyLabel}
+	for key := range obj.GetLabels() {
+		keys = append(keys, key)
+	}
+
+	candidates := make(map[types.UID]v1.Tunnel)
+	for _, key := range keys {
+		var tunnels v1.TunnelList
+		if err := r.List(ctx, &tunnels, client.MatchingFields{waitForLabelField: key}); err != nil {
+			l := log.FromContext(ctx)
+			l.Error(err, "failed to listing tunnels waiting for upgrade")
+			return nil
+		}
+		for _, item := range tunnels.Items {
+			candidates[item.UID] = item
+		}
+	}
+
+	var requests []reconcile.Request
+	for _, item := range candidates {
+		if item.UID == obj.GetUID() {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(item.Spec.DaemonDeployment.UpgradePolicy.WaitFor)
+		if err != nil || !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: item.Name, Namespace: item.Namespace},
+		})
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *TunnelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	ctx := context.Background()
@@ -209,17 +309,15 @@ func (r *TunnelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1.Tunnel{}, waitForLabelField, indexTunnelWaitFor); err != nil {
+		return err
+	}
+
 	if err := mgr.GetFieldIndexer().IndexField(
 		ctx,
 		&v1.TunnelIngress{},
-		tunnelRefNameField,
-		func(rawObj client.Object) []string {
-			tunnelIngress := rawObj.(*v1.TunnelIngress)
-			if tunnelIngress.Spec.TunnelRef.Name == "" {
-				return nil
-			}
-			return []string{tunnelIngress.Spec.TunnelRef.Name}
-		},
+		tunnelRefField,
+		indexIngressTunnelRef,
 	); err != nil {
 		return err
 	}
@@ -228,13 +326,7 @@ func (r *TunnelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		ctx,
 		&v1.TunnelIngress{},
 		tunnelRefKindField,
-		func(rawObj client.Object) []string {
-			tunnelIngress := rawObj.(*v1.TunnelIngress)
-			if tunnelIngress.Spec.TunnelRef.Kind == "" {
-				return nil
-			}
-			return []string{string(tunnelIngress.Spec.TunnelRef.Kind)}
-		},
+		indexIngressTunnelRefKind,
 	); err != nil {
 		return err
 	}
@@ -243,6 +335,8 @@ func (r *TunnelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&v1.Tunnel{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&appsv1.DaemonSet{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.ConfigMap{}).
 		Watches(
@@ -254,7 +348,35 @@ func (r *TunnelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&v1.TunnelIngress{},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForTunnelIngress),
 		).
-		Complete(r)
+		Watches(
+			&corev1.Service{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForBackend),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&v1.TunnelNetworkRoute{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForTunnelNetworkRoute),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&v1.Tunnel{},
+			handler.EnqueueRequestsFromMapFunc(r.findTunnelsWaitingFor),
+			builder.WithPredicates(upgradeStageChanged),
+		).
+		Watches(
+			&v1.TunnelReferenceGrant{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForGrant),
+		).
+		Watches(
+			&v1.TunnelPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForPolicy),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
+		Complete(requeueOnRateLimit(r))
 }
 
 func (r *TunnelReconciler) buildConditionRecorder(
@@ -313,3 +435,20 @@ func (r *TunnelReconciler) updateConditionIfDiff(
 	}
 	return nil
 }
+
+// indexIngressTunnelRef indexes TunnelIngresses by namespace/name of the tunnel that tunnelRef refers.
+func indexIngressTunnelRef(rawObj client.Object) []string {
+	tunnelIngress := rawObj.(*v1.TunnelIngress)
+	if tunnelIngress.Spec.TunnelRef.Name == "" {
+		return nil
+	}
+	return []string{tunnelRefKey(tunnelIngress.Namespace, tunnelIngress.Spec.TunnelRef).String()}
+}
+
+func indexIngressTunnelRefKind(rawObj client.Object) []string {
+	tunnelIngress := rawObj.(*v1.TunnelIngress)
+	if tunnelIngress.Spec.TunnelRef.Kind == "" {
+		return nil
+	}
+	return []string{string(tunnelIngress.Spec.TunnelRef.Kind)}
+}
@@ -2,9 +2,6 @@ package controller
 
 import (
 	"context"
-	"errors"
-
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
 )
@@ -16,7 +13,7 @@ func (r *TunnelReconciler) deleteTunnel(ctx context.Context, tunnel *v1.Tunnel)
 
 	client, err := r.getCloudflareClient(ctx, tunnel)
 	if err != nil {
-		if errors.Is(err, errNotFoundAPITokenKey) || apierrors.IsNotFound(err) {
+		if isUnusableAccountError(err) {
 			return nil
 		}
 		return err
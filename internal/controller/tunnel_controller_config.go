@@ -14,6 +14,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/policy"
 )
 
 var (
@@ -57,20 +58,11 @@ func (r *TunnelReconciler) reconcileConfig(ctx context.Context, tunnel *v1.Tunne
 	case apierrors.IsNotFound(err):
 		dirtyStatus = true
 
-		marshaledConfig, err := yaml.Marshal(config)
+		newConfigMap, err := buildConfigMap(tunnel, config)
 		if err != nil {
 			return TunnelConfig{}, recordConditionFrom(WrapError(err, v1.ConfigReasonFailedToCreateConfigMap))
 		}
-
-		configMap = corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:        tunnel.Spec.ConfigName(),
-				Namespace:   tunnel.Namespace,
-				Labels:      nil,
-				Annotations: nil,
-			},
-			Data: map[string]string{fileNameConfig: string(marshaledConfig)},
-		}
+		configMap = *newConfigMap
 		if err := ctrl.SetControllerReference(tunnel, &configMap, r.Scheme); err != nil {
 			return TunnelConfig{}, recordConditionFrom(WrapError(err, v1.ConfigReasonFailedToCreateConfigMap))
 		}
@@ -83,6 +75,14 @@ func (r *TunnelReconciler) reconcileConfig(ctx context.Context, tunnel *v1.Tunne
 		return TunnelConfig{}, recordConditionFrom(WrapError(err, v1.ConfigReasonFailedToGetExistingConfig))
 	}
 
+	if isRemotelyManaged(tunnel) {
+		changed, err := r.reconcileRemoteConfig(ctx, tunnel, config)
+		if err != nil {
+			return TunnelConfig{}, recordConditionFrom(err)
+		}
+		dirtyStatus = dirtyStatus || changed
+	}
+
 	if UpdateConditionIfChanged(&tunnel.Status, v1.TunnelStatusCondition{
 		Type:               v1.TunnelConditionTypeConfig,
 		Status:             corev1.ConditionTrue,
@@ -102,14 +102,16 @@ func (r *TunnelReconciler) buildConfig(ctx context.Context, tunnel *v1.Tunnel) (
 	if err := r.List(
 		ctx,
 		&ingressList,
-		client.MatchingFields{tunnelRefNameField: tunnel.Name, tunnelRefKindField: string(v1.TunnelKindTunnel)},
-		client.InNamespace(tunnel.Namespace),
+		client.MatchingFields{
+			tunnelRefField:     client.ObjectKeyFromObject(tunnel).String(),
+			tunnelRefKindField: string(v1.TunnelKindTunnel),
+		},
 	); err != nil {
 		return TunnelConfig{}, err
 	}
 
 	config := TunnelConfig{
-		TunnelRunParameters: tunnel.Spec.TunnelRunParameters,
+		TunnelRunParameters: configRunParameters(tunnel.Spec.TunnelRunParameters),
 		OriginRequestConfig: v1.OriginRequestConfig{},
 		Ingress:             make([]v1.TunnelConfigIngress, 0, len(ingressList.Items)+1),
 	}
@@ -117,9 +119,65 @@ func (r *TunnelReconciler) buildConfig(ctx context.Context, tunnel *v1.Tunnel) (
 		return a.CreationTimestamp.Time.Compare(b.CreationTimestamp.Time)
 	})
 	for _, ingress := range ingressList.Items {
-		config.Ingress = append(config.Ingress, ingress.Spec.TunnelConfigIngress)
+		// ingresses of other namespaces are ignored until the namespace of tunnel grants them
+		if err := checkReferencePermitted(
+			ctx,
+			r,
+			v1.ReferenceGrantFromKindTunnelIngress,
+			ingress.Namespace,
+			v1.ReferenceGrantToKindTunnel,
+			client.ObjectKeyFromObject(tunnel),
+		); err != nil {
+			if errors.Is(err, errRefNotPermitted) {
+				log.FromContext(ctx).Error(err, "skipping ingress", "ingress", client.ObjectKeyFromObject(&ingress))
+				continue
+			}
+			return TunnelConfig{}, err
+		}
+		if err := policy.CheckTunnelIngress(ctx, r, &ingress); err != nil {
+			if errors.Is(err, policy.ErrViolation) {
+				log.FromContext(ctx).Error(err, "skipping ingress", "ingress", client.ObjectKeyFromObject(&ingress))
+				continue
+			}
+			return TunnelConfig{}, err
+		}
+
+		rule := ingress.Spec.TunnelConfigIngress
+		if ingress.Spec.Origin != nil {
+			service, err := resolveIngressOrigin(ctx, r, ingress.Namespace, *ingress.Spec.Origin)
+			if errors.Is(err, errUnresolvableOrigin) || errors.Is(err, errRefNotPermitted) {
+				// an ingress of missing backend must not break the others
+				log.FromContext(ctx).Error(err, "skipping ingress", "ingress", client.ObjectKeyFromObject(&ingress))
+				continue
+			}
+			if err != nil {
+				return TunnelConfig{}, err
+			}
+			rule.Service = service
+		}
+		if v1.IsLegacyService(rule.Service) {
+			log.FromContext(ctx).Info(
+				"service of ingress is in the deprecated host:port form",
+				"ingress", client.ObjectKeyFromObject(&ingress),
+			)
+			rule.Service = "http://" + rule.Service
+		}
+		config.Ingress = append(config.Ingress, rule)
+	}
+	config.Ingress = append(config.Ingress, v1.TunnelConfigIngress{Service: r.DaemonConfig.CatchAllService})
+
+	// the indexes of TunnelNetworkRoute belong to TunnelNetworkRouteReconciler, which may not be set up
+	var routeList v1.TunnelNetworkRouteList
+	if err := r.List(ctx, &routeList, client.InNamespace(tunnel.Namespace)); err != nil {
+		return TunnelConfig{}, err
+	}
+	if slices.ContainsFunc(routeList.Items, func(route v1.TunnelNetworkRoute) bool {
+		return route.DeletionTimestamp.IsZero() &&
+			route.Spec.TunnelRef.Kind == v1.TunnelKindTunnel &&
+			route.Spec.TunnelRef.Name == tunnel.Name
+	}) {
+		config.WarpRouting = &WarpRoutingConfig{Enabled: true}
 	}
-	config.Ingress = append(config.Ingress, v1.TunnelConfigIngress{Service: "http_status:404"})
 
 	if tunnel.Spec.OriginConfiguration != nil {
 		config.OriginRequestConfig.OriginTLSSettings = tunnel.Spec.OriginConfiguration.TLSSettings
@@ -131,6 +189,22 @@ func (r *TunnelReconciler) buildConfig(ctx context.Context, tunnel *v1.Tunnel) (
 	return config, nil
 }
 
+// buildConfigMap builds the ConfigMap that cloudflared of tunnel reads config from.
+func buildConfigMap(tunnel *v1.Tunnel, config TunnelConfig) (*corev1.ConfigMap, error) {
+	marshaledConfig, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tunnel.Spec.ConfigName(),
+			Namespace: tunnel.Namespace,
+		},
+		Data: map[string]string{fileNameConfig: string(marshaledConfig)},
+	}, nil
+}
+
 func readTunnelConfig(cm corev1.ConfigMap) (config TunnelConfig, err error) {
 	v, ok := cm.Data[fileNameConfig]
 	bytesV := []byte(v)
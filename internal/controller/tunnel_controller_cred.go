@@ -12,7 +12,6 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
 	"github.com/isac322/cloudflared-operator/internal/cloudflare"
@@ -194,35 +193,13 @@ func verifyCredSecret(
 }
 
 func (r *TunnelReconciler) getCloudflareClient(ctx context.Context, tunnel *v1.Tunnel) (cloudflare.Client, error) {
-	l := log.FromContext(ctx)
-
-	var secret corev1.Secret
-	if err := r.Get(
+	return newCloudflareClientForTunnel(
 		ctx,
-		client.ObjectKey{
-			Namespace: ptr.Deref(tunnel.Spec.APITokenSecretRef.Namespace, tunnel.Namespace),
-			Name:      tunnel.Spec.APITokenSecretRef.Name,
-		},
-		&secret,
-	); err != nil {
-		l.Error(err, "unable to fetch apiTokenSecretRef")
-		err = WrapError(err, v1.CredentialReasonNoToken)
-		if apierrors.IsNotFound(err) {
-			err = reconcile.TerminalError(err)
-		}
-		return nil, err
-	}
-
-	secretKey := ptr.Deref(tunnel.Spec.APITokenSecretRef.Key, apiTokenKey)
-	bytesToken, exists := GetDataFromSecret(&secret, secretKey)
-	if !exists {
-		return nil, WrapError(errNotFoundAPITokenKey, v1.CredentialReasonNoToken)
-	}
-
-	cli, err := cloudflare.NewClient(string(bytesToken))
-	if err != nil {
-		l.Error(err, "failed to create Cloudflare client")
-		return nil, WrapError(err, v1.CredentialReasonFailedToConnectCF)
-	}
-	return cli, nil
+		r,
+		r.CloudflareClients,
+		tunnel,
+		v1.CredentialReasonNoToken,
+		v1.CredentialReasonFailedToConnectCF,
+		v1.CredentialReasonAccountNotAllowed,
+	)
 }
@@ -2,29 +2,39 @@ package controller
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"time"
 
-	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
 )
 
-func (r *TunnelReconciler) reconcileDaemon(ctx context.Context, tunnel *v1.Tunnel, tunnelConfig TunnelConfig) error {
+// reconcileDaemon deploys cloudflared, and returns when the daemon version has to be planned again.
+func (r *TunnelReconciler) reconcileDaemon(
+	ctx context.Context,
+	tunnel *v1.Tunnel,
+	tunnelConfig TunnelConfig,
+) (time.Duration, error) {
 	recordConditionFrom := r.buildConditionRecorder(ctx, tunnel, v1.TunnelConditionTypeDaemon)
 
-	target, orphan, err := r.getExistingDaemons(ctx, tunnel)
+	existing, orphans, err := r.getExistingDaemons(ctx, tunnel)
 	if err != nil {
-		return recordConditionFrom(err)
+		return 0, recordConditionFrom(err)
 	}
+	target := existing.workload
 
 	var dirtyStatus bool
-	// delete orphan
-	if orphan != nil {
+	// delete orphans
+	if len(orphans) > 0 {
 		dirtyStatus = true
 		if err := r.updateConditionIfDiff(ctx, tunnel, v1.TunnelStatusCondition{
 			Type:               v1.TunnelConditionTypeDaemon,
@@ -32,41 +42,65 @@ func (r *TunnelReconciler) reconcileDaemon(ctx context.Context, tunnel *v1.Tunne
 			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
 			Reason:             v1.DaemonReasonDeletingOrphans,
 		}); err != nil {
-			return err
+			return 0, err
 		}
-		if err := r.Delete(ctx, orphan); err != nil {
-			return recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeleteOrphans))
+		for _, orphan := range orphans {
+			if err := r.Delete(ctx, orphan); client.IgnoreNotFound(err) != nil {
+				return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeleteOrphans))
+			}
 		}
 	}
 
-	daemonVersion, err := getDaemonVersion(ctx, tunnel)
+	plan, err := r.planDaemonVersion(ctx, tunnel, target)
 	if err != nil {
-		return recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
+		return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
+	}
+	image := buildDaemonImage(tunnel, r.DaemonConfig, plan.version)
+	if err = checkRunParametersSupported(tunnel.Spec.TunnelRunParameters, image.Version); err != nil {
+		return 0, recordConditionFrom(reconcile.TerminalError(WrapError(err, v1.DaemonReasonUnsupportedRunParameters)))
 	}
-	newTarget, err := buildDaemon(daemonVersion, tunnel, tunnelConfig)
+	newTarget, err := buildDaemon(image, r.withoutUnsupportedPreStop(ctx, tunnel), tunnelConfig, r.DaemonConfig)
 	if err != nil {
-		return recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
+		if errors.Is(err, errInvalidPodTemplate) {
+			return 0, recordConditionFrom(reconcile.TerminalError(WrapError(err, v1.DaemonReasonInvalidPodTemplate)))
+		}
+		return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
 	}
 	if err = ctrl.SetControllerReference(tunnel, newTarget, r.Scheme); err != nil {
-		return recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
+		return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
 	}
 
 	if target != nil {
+		keepAutoscaledReplicas(tunnel, target, newTarget)
+		mergeDaemonMetadata(target, newTarget)
 		if err = r.Update(ctx, newTarget, client.DryRunAll); err != nil {
-			return recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
+			return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
 		}
-		if !isDaemonEqualTo(target, newTarget, tunnel.Spec.DaemonDeployment.Kind) {
+		switch {
+		case !isDaemonEqualTo(target, newTarget, tunnel.Spec.DaemonDeployment.Kind):
 			dirtyStatus = true
 			if err = r.Update(ctx, newTarget); err != nil {
-				return recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
+				return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
+			}
+		case !hasDaemonMetadata(target, newTarget):
+			// e.g. DaemonSets of earlier releases that lack app.kubernetes.io labels
+			if err = r.Patch(ctx, withDaemonMetadata(target, newTarget), client.MergeFrom(target)); err != nil {
+				return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
 			}
 		}
 	} else {
 		if err = r.Create(ctx, newTarget); err != nil {
-			return recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
+			return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeploy))
 		}
 	}
 
+	if err = r.reconcileDaemonDisruptionBudget(ctx, tunnel, existing.disruptionBudget); err != nil {
+		return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeployDisruptionBudget))
+	}
+	if err = r.reconcileDaemonAutoscaler(ctx, tunnel, existing.autoscaler); err != nil {
+		return 0, recordConditionFrom(WrapError(err, v1.DaemonReasonFailedToDeployAutoscaler))
+	}
+
 	if UpdateConditionIfChanged(&tunnel.Status, v1.TunnelStatusCondition{
 		Type:               v1.TunnelConditionTypeDaemon,
 		Status:             corev1.ConditionTrue,
@@ -75,60 +109,89 @@ func (r *TunnelReconciler) reconcileDaemon(ctx context.Context, tunnel *v1.Tunne
 		dirtyStatus = true
 	}
 
-	if tunnel.Status.DaemonVersion != daemonVersion {
-		tunnel.Status.DaemonVersion = daemonVersion
+	if plan.apply(&tunnel.Status, r.Clock.Now()) {
+		dirtyStatus = true
+	}
+	if tunnel.Status.DaemonVersion != image.Version || tunnel.Status.DaemonImage != image.Reference ||
+		tunnel.Status.DaemonImageDigest != image.Digest {
+		tunnel.Status.DaemonVersion = image.Version
+		tunnel.Status.DaemonImage = image.Reference
+		tunnel.Status.DaemonImageDigest = image.Digest
 		dirtyStatus = true
 	}
 
 	if dirtyStatus {
-		return r.Status().Update(ctx, tunnel)
+		return plan.requeueAfter, r.Status().Update(ctx, tunnel)
+	}
+	return plan.requeueAfter, nil
+}
+
+// withoutUnsupportedPreStop returns tunnel without PreStopDelay if the API server may not support its hook.
+func (r *TunnelReconciler) withoutUnsupportedPreStop(ctx context.Context, tunnel *v1.Tunnel) *v1.Tunnel {
+	if !r.PreStopSleepUnsupported || tunnel.Spec.DaemonDeployment.PreStopDelay == nil {
+		return tunnel
 	}
-	return nil
+	log.FromContext(ctx).Info("ignoring preStopDelay, which needs Kubernetes 1.30 or later")
+	tunnel = tunnel.DeepCopy()
+	tunnel.Spec.DaemonDeployment.PreStopDelay = nil
+	return tunnel
+}
+
+// existingDaemon holds objects of the daemon that the spec of the tunnel still declares.
+type existingDaemon struct {
+	workload         client.Object
+	disruptionBudget *policyv1.PodDisruptionBudget
+	autoscaler       *autoscalingv2.HorizontalPodAutoscaler
 }
 
+// getExistingDaemons returns existing objects of the daemon, and orphans which are no longer declared by the spec,
+// e.g. the Deployment after switching to DaemonSet, or the PodDisruptionBudget after removing disruptionBudget.
 func (r *TunnelReconciler) getExistingDaemons(
 	ctx context.Context,
 	tunnel *v1.Tunnel,
-) (target, orphan client.Object, err error) {
+) (existing existingDaemon, orphans []client.Object, err error) {
 	objectKey := client.ObjectKey{Namespace: tunnel.Namespace, Name: buildDaemonName(tunnel)}
-	var deployNotExists, daemonSetNotExists bool
-	var deployment appsv1.Deployment
-	if err := r.Get(ctx, objectKey, &deployment); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, nil, err
-		}
-		deployNotExists = true
+	if _, err := daemonWorkloadOf(tunnel.Spec.DaemonDeployment.Kind); err != nil {
+		return existingDaemon{}, nil, WrapError(err, v1.ConfigReasonFailedToGetExistingConfig)
 	}
-	var daemonSet appsv1.DaemonSet
-	if err := r.Get(ctx, objectKey, &daemonSet); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, nil, err
-		}
-		daemonSetNotExists = true
-	}
-
-	switch k := tunnel.Spec.DaemonDeployment.Kind; k {
-	case v1.DeploymentKindDaemonSet:
-		if !daemonSetNotExists {
-			target = &daemonSet
+	for kind, workload := range daemonWorkloads {
+		obj := workload.newObject()
+		if err := r.Get(ctx, objectKey, obj); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return existingDaemon{}, nil, err
+			}
+			continue
 		}
-		if !deployNotExists {
-			orphan = &deployment
+		if kind == tunnel.Spec.DaemonDeployment.Kind {
+			existing.workload = obj
+		} else {
+			orphans = append(orphans, obj)
 		}
+	}
 
-	case v1.DeploymentKindDeployment:
-		if !deployNotExists {
-			target = &deployment
-		}
-		if !daemonSetNotExists {
-			orphan = &daemonSet
-		}
+	// PodDisruptionBudget and HorizontalPodAutoscaler share the name of the workload,
+	// so only ones controlled by the tunnel are taken as orphans.
+	var pdb policyv1.PodDisruptionBudget
+	switch err := r.Get(ctx, objectKey, &pdb); {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return existingDaemon{}, nil, err
+	case tunnel.Spec.DaemonDeployment.DisruptionBudget != nil:
+		existing.disruptionBudget = &pdb
+	case metav1.IsControlledBy(&pdb, tunnel):
+		orphans = append(orphans, &pdb)
+	}
 
-	default:
-		return nil, nil, WrapError(
-			fmt.Errorf("unknown kind: %s", k),
-			v1.ConfigReasonFailedToGetExistingConfig,
-		)
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	switch err := r.Get(ctx, objectKey, &hpa); {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return existingDaemon{}, nil, err
+	case isAutoscaled(tunnel):
+		existing.autoscaler = &hpa
+	case metav1.IsControlledBy(&hpa, tunnel):
+		orphans = append(orphans, &hpa)
 	}
-	return target, orphan, nil
+
+	return existing, orphans, nil
 }
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	policyv1 "k8s.io/api/policy/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func (r *TunnelReconciler) reconcileDaemonDisruptionBudget(
+	ctx context.Context,
+	tunnel *v1.Tunnel,
+	existing *policyv1.PodDisruptionBudget,
+) error {
+	if tunnel.Spec.DaemonDeployment.DisruptionBudget == nil {
+		return nil
+	}
+
+	desired := buildDaemonDisruptionBudget(tunnel, r.DaemonConfig)
+	if err := ctrl.SetControllerReference(tunnel, desired, r.Scheme); err != nil {
+		return err
+	}
+	if existing == nil {
+		return r.Create(ctx, desired)
+	}
+
+	desired.ResourceVersion = existing.ResourceVersion
+	if err := r.Update(ctx, desired, client.DryRunAll); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(existing.Spec, desired.Spec) {
+		return nil
+	}
+	return r.Update(ctx, desired)
+}
+
+func (r *TunnelReconciler) reconcileDaemonAutoscaler(
+	ctx context.Context,
+	tunnel *v1.Tunnel,
+	existing *autoscalingv2.HorizontalPodAutoscaler,
+) error {
+	if !isAutoscaled(tunnel) {
+		return nil
+	}
+
+	desired := buildDaemonAutoscaler(tunnel, r.DaemonConfig)
+	if err := ctrl.SetControllerReference(tunnel, desired, r.Scheme); err != nil {
+		return err
+	}
+	if existing == nil {
+		return r.Create(ctx, desired)
+	}
+
+	desired.ResourceVersion = existing.ResourceVersion
+	if err := r.Update(ctx, desired, client.DryRunAll); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(existing.Spec, desired.Spec) {
+		return nil
+	}
+	return r.Update(ctx, desired)
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+// remotelyManagedAnnotation marks Tunnels whose config has been pushed to Cloudflare.
+// It is kept in metadata rather than status, so that it survives e.g. restoring the Tunnel from a backup.
+const remotelyManagedAnnotation = "cloudflared-operator.bhyoo.com/remotely-managed"
+
+// isRemotelyManaged reports whether the config of the tunnel is pushed to Cloudflare.
+// Cloudflare does not let a remotely managed tunnel go back to local config, so it stays remotely managed once pushed.
+// RemoteConfigHash is checked as well for tunnels pushed before the annotation was introduced.
+func isRemotelyManaged(tunnel *v1.Tunnel) bool {
+	return tunnel.Spec.ConfigReloadStrategy == v1.ConfigReloadStrategyRemoteManaged ||
+		tunnel.Annotations[remotelyManagedAnnotation] == "true" ||
+		tunnel.Status.RemoteConfigHash != ""
+}
+
+// markRemotelyManaged annotates the tunnel if it is remotely managed, and reports whether the annotation is added.
+// It has to be persisted before the config is pushed, as switching ConfigReloadStrategy back does not undo the push.
+func markRemotelyManaged(tunnel *v1.Tunnel) bool {
+	if !isRemotelyManaged(tunnel) || tunnel.Annotations[remotelyManagedAnnotation] == "true" {
+		return false
+	}
+	if tunnel.Annotations == nil {
+		tunnel.Annotations = make(map[string]string, 1)
+	}
+	tunnel.Annotations[remotelyManagedAnnotation] = "true"
+	return true
+}
+
+// reconcileRemoteConfig pushes ingress rules, warp routing and origin request config to Cloudflare,
+// and reports whether the status is changed.
+func (r *TunnelReconciler) reconcileRemoteConfig(
+	ctx context.Context,
+	tunnel *v1.Tunnel,
+	config TunnelConfig,
+) (bool, error) {
+	remote := config.remoteParts()
+	hash, err := remote.Hash()
+	if err != nil {
+		return false, WrapError(err, v1.ConfigReasonInvalidConfig)
+	}
+	if tunnel.Status.RemoteConfigHash == hash {
+		return false, nil
+	}
+
+	remoteConfig, err := buildRemoteConfig(remote)
+	if err != nil {
+		return false, WrapError(err, v1.ConfigReasonInvalidConfig)
+	}
+
+	cfClient, err := r.getCloudflareClient(ctx, tunnel)
+	if err != nil {
+		return false, err
+	}
+	if err := cfClient.UpdateTunnelConfiguration(
+		ctx,
+		tunnel.Spec.AccountID,
+		tunnel.Status.TunnelID,
+		remoteConfig,
+	); err != nil {
+		return false, WrapError(err, v1.ConfigReasonFailedToUpdateRemoteConfig)
+	}
+
+	tunnel.Status.RemoteConfigHash = hash
+	return true, nil
+}
+
+func buildRemoteConfig(config TunnelConfig) (cloudflare.TunnelConfiguration, error) {
+	originRequest, err := buildRemoteOriginRequest(config.OriginRequestConfig)
+	if err != nil {
+		return cloudflare.TunnelConfiguration{}, err
+	}
+
+	remote := cloudflare.TunnelConfiguration{
+		Ingress:       make([]cloudflare.UnvalidatedIngressRule, 0, len(config.Ingress)),
+		OriginRequest: originRequest,
+	}
+	for _, ingress := range config.Ingress {
+		rule := cloudflare.UnvalidatedIngressRule{
+			Hostname: ptr.Deref(ingress.Hostname, ""),
+			Path:     ptr.Deref(ingress.Path, ""),
+			Service:  ingress.Service,
+		}
+		if ingress.OriginRequest != nil {
+			originRequest, err := buildRemoteOriginRequest(*ingress.OriginRequest)
+			if err != nil {
+				return cloudflare.TunnelConfiguration{}, err
+			}
+			rule.OriginRequest = &originRequest
+		}
+		remote.Ingress = append(remote.Ingress, rule)
+	}
+	if config.WarpRouting != nil {
+		remote.WarpRouting = &cloudflare.WarpRoutingConfig{Enabled: config.WarpRouting.Enabled}
+	}
+	return remote, nil
+}
+
+func buildRemoteOriginRequest(config v1.OriginRequestConfig) (cloudflare.OriginRequestConfig, error) {
+	var remote cloudflare.OriginRequestConfig
+
+	if tls := config.OriginTLSSettings; tls != nil {
+		remote.OriginServerName = tls.OriginServerName
+		remote.CAPool = tls.CAPool
+		remote.NoTLSVerify = tls.NoTLSVerify
+		if tls.TLSTimeout != nil {
+			remote.TLSTimeout = &cloudflare.TunnelDuration{Duration: tls.TLSTimeout.Duration}
+		}
+		remote.Http2Origin = tls.HTTP2Origin
+	}
+
+	if httpSettings := config.OriginHTTPSettings; httpSettings != nil {
+		remote.HTTPHostHeader = httpSettings.HTTPHostHeader
+		remote.DisableChunkedEncoding = httpSettings.DisableChunkedEncoding
+	}
+
+	if conn := config.OriginConnectionSettings; conn != nil {
+		if conn.ConnectTimeout != nil {
+			timeout, err := time.ParseDuration(*conn.ConnectTimeout)
+			if err != nil {
+				return cloudflare.OriginRequestConfig{}, err
+			}
+			remote.ConnectTimeout = &cloudflare.TunnelDuration{Duration: timeout}
+		}
+		remote.NoHappyEyeballs = conn.NoHappyEyeballs
+		remote.ProxyType = conn.ProxyType
+		remote.ProxyAddress = conn.ProxyAddress
+		if conn.ProxyPort != nil {
+			remote.ProxyPort = ptr.To(uint(*conn.ProxyPort))
+		}
+		if conn.KeepAliveTimeout != nil {
+			remote.KeepAliveTimeout = &cloudflare.TunnelDuration{Duration: conn.KeepAliveTimeout.Duration}
+		}
+		remote.KeepAliveConnections = conn.KeepAliveConnections
+		if conn.TCPKeepAlive != nil {
+			remote.TCPKeepAlive = &cloudflare.TunnelDuration{Duration: conn.TCPKeepAlive.Duration}
+		}
+	}
+
+	if access := config.OriginAccessSettings; access != nil && access.Access != nil {
+		remote.Access = &cloudflare.AccessConfig{
+			Required: ptr.Deref(access.Access.Required, false),
+			TeamName: ptr.Deref(access.Access.TeamName, ""),
+			AudTag:   access.Access.AudTag,
+		}
+	}
+
+	return remote, nil
+}
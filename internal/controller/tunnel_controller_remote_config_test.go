@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func TestBuildRemoteOriginRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config v1.OriginRequestConfig
+		want   cloudflare.OriginRequestConfig
+		err    bool
+	}{
+		"empty": {},
+		"tls": {
+			config: v1.OriginRequestConfig{OriginTLSSettings: &v1.OriginTLSSettings{
+				OriginServerName: ptr.To("origin.example.com"),
+				CAPool:           ptr.To("/etc/ca.pem"),
+				NoTLSVerify:      ptr.To(true),
+				TLSTimeout:       &metav1.Duration{Duration: 5 * time.Second},
+				HTTP2Origin:      ptr.To(true),
+			}},
+			want: cloudflare.OriginRequestConfig{
+				OriginServerName: ptr.To("origin.example.com"),
+				CAPool:           ptr.To("/etc/ca.pem"),
+				NoTLSVerify:      ptr.To(true),
+				TLSTimeout:       &cloudflare.TunnelDuration{Duration: 5 * time.Second},
+				Http2Origin:      ptr.To(true),
+			},
+		},
+		"http": {
+			config: v1.OriginRequestConfig{OriginHTTPSettings: &v1.OriginHTTPSettings{
+				HTTPHostHeader:         ptr.To("internal.example.com"),
+				DisableChunkedEncoding: ptr.To(true),
+			}},
+			want: cloudflare.OriginRequestConfig{
+				HTTPHostHeader:         ptr.To("internal.example.com"),
+				DisableChunkedEncoding: ptr.To(true),
+			},
+		},
+		"connection": {
+			config: v1.OriginRequestConfig{OriginConnectionSettings: &v1.OriginConnectionSettings{
+				ConnectTimeout:       ptr.To("1m30s"),
+				NoHappyEyeballs:      ptr.To(true),
+				ProxyType:            ptr.To("socks"),
+				ProxyAddress:         ptr.To("127.0.0.1"),
+				ProxyPort:            ptr.To(1080),
+				KeepAliveTimeout:     &metav1.Duration{Duration: time.Minute},
+				KeepAliveConnections: ptr.To(10),
+				TCPKeepAlive:         &metav1.Duration{Duration: 30 * time.Second},
+			}},
+			want: cloudflare.OriginRequestConfig{
+				ConnectTimeout:       &cloudflare.TunnelDuration{Duration: 90 * time.Second},
+				NoHappyEyeballs:      ptr.To(true),
+				ProxyType:            ptr.To("socks"),
+				ProxyAddress:         ptr.To("127.0.0.1"),
+				ProxyPort:            ptr.To(uint(1080)),
+				KeepAliveTimeout:     &cloudflare.TunnelDuration{Duration: time.Minute},
+				KeepAliveConnections: ptr.To(10),
+				TCPKeepAlive:         &cloudflare.TunnelDuration{Duration: 30 * time.Second},
+			},
+		},
+		"invalid connect timeout": {
+			config: v1.OriginRequestConfig{OriginConnectionSettings: &v1.OriginConnectionSettings{
+				ConnectTimeout: ptr.To("30"),
+			}},
+			err: true,
+		},
+		"access": {
+			config: v1.OriginRequestConfig{OriginAccessSettings: &v1.OriginAccessSettings{
+				Access: &v1.OriginAccessSettingsAccess{
+					Required: ptr.To(true),
+					TeamName: ptr.To("team"),
+					AudTag:   []string{"aud"},
+				},
+			}},
+			want: cloudflare.OriginRequestConfig{
+				Access: &cloudflare.AccessConfig{Required: true, TeamName: "team", AudTag: []string{"aud"}},
+			},
+		},
+		"access without settings": {
+			config: v1.OriginRequestConfig{OriginAccessSettings: &v1.OriginAccessSettings{}},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			remote, err := buildRemoteOriginRequest(tc.config)
+			if tc.err {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(remote).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestBuildRemoteConfig(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	remote, err := buildRemoteConfig(TunnelConfig{
+		OriginRequestConfig: v1.OriginRequestConfig{OriginHTTPSettings: &v1.OriginHTTPSettings{
+			DisableChunkedEncoding: ptr.To(true),
+		}},
+		Ingress: []v1.TunnelConfigIngress{
+			{
+				Hostname: ptr.To("app.example.com"),
+				Path:     ptr.To("/api"),
+				Service:  "http://app.default.svc:8080",
+				OriginRequest: &v1.OriginRequestConfig{OriginTLSSettings: &v1.OriginTLSSettings{
+					NoTLSVerify: ptr.To(true),
+				}},
+			},
+			{Service: "http_status:404"},
+		},
+		WarpRouting: &WarpRoutingConfig{Enabled: true},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(remote).To(Equal(cloudflare.TunnelConfiguration{
+		Ingress: []cloudflare.UnvalidatedIngressRule{
+			{
+				Hostname:      "app.example.com",
+				Path:          "/api",
+				Service:       "http://app.default.svc:8080",
+				OriginRequest: &cloudflare.OriginRequestConfig{NoTLSVerify: ptr.To(true)},
+			},
+			{Service: "http_status:404"},
+		},
+		WarpRouting:   &cloudflare.WarpRoutingConfig{Enabled: true},
+		OriginRequest: cloudflare.OriginRequestConfig{DisableChunkedEncoding: ptr.To(true)},
+	}))
+
+	_, err = buildRemoteConfig(TunnelConfig{
+		Ingress: []v1.TunnelConfigIngress{{
+			Service: "http://app.default.svc:8080",
+			OriginRequest: &v1.OriginRequestConfig{OriginConnectionSettings: &v1.OriginConnectionSettings{
+				ConnectTimeout: ptr.To("soon"),
+			}},
+		}},
+	})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestMarkRemotelyManaged(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		tunnel  func(tunnel *v1.Tunnel)
+		marked  bool
+		managed bool
+	}{
+		"local config": {
+			tunnel:  func(*v1.Tunnel) {},
+			marked:  false,
+			managed: false,
+		},
+		"remote managed strategy": {
+			tunnel: func(tunnel *v1.Tunnel) {
+				tunnel.Spec.ConfigReloadStrategy = v1.ConfigReloadStrategyRemoteManaged
+			},
+			marked:  true,
+			managed: true,
+		},
+		"pushed before the annotation": {
+			tunnel:  func(tunnel *v1.Tunnel) { tunnel.Status.RemoteConfigHash = "hash" },
+			marked:  true,
+			managed: true,
+		},
+		"switched back after push": {
+			tunnel: func(tunnel *v1.Tunnel) {
+				tunnel.Annotations = map[string]string{remotelyManagedAnnotation: "true"}
+			},
+			marked:  false,
+			managed: true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			tunnel := newTestTunnel()
+			tc.tunnel(tunnel)
+			g.Expect(markRemotelyManaged(tunnel)).To(Equal(tc.marked))
+			g.Expect(isRemotelyManaged(tunnel)).To(Equal(tc.managed))
+
+			// the status may be lost, e.g. by restoring the Tunnel from a backup
+			tunnel.Spec.ConfigReloadStrategy = v1.ConfigReloadStrategyRestart
+			tunnel.Status = v1.TunnelStatus{}
+			g.Expect(isRemotelyManaged(tunnel)).To(Equal(tc.managed))
+		})
+	}
+}
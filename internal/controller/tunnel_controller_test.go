@@ -0,0 +1,249 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	cf "github.com/cloudflare/cloudflare-go"
+	"github.com/goccy/go-json"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare/fake"
+)
+
+var _ = Describe("Tunnel lifecycle", Ordered, func() {
+	const (
+		accountID  = "lifecycle-account"
+		namespace  = "default"
+		tokenName  = "lifecycle-api-token"
+		tunnelName = "lifecycle"
+		hostname   = "app.lifecycle.example"
+	)
+
+	var (
+		zone     cf.Zone
+		tunnel   *v1.Tunnel
+		ingress  *v1.TunnelIngress
+		tunnelID string
+	)
+
+	BeforeAll(func(ctx SpecContext) {
+		zone = fakeCF.AddZone(accountID, "lifecycle.example")
+
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: tokenName, Namespace: namespace},
+			StringData: map[string]string{apiTokenKey: "lifecycle-token"},
+		})).To(Succeed())
+	})
+
+	It("creates the tunnel on Cloudflare and stores its credential", func(ctx SpecContext) {
+		tunnel = &v1.Tunnel{
+			ObjectMeta: metav1.ObjectMeta{Name: tunnelName, Namespace: namespace},
+			Spec: v1.TunnelSpec{
+				Name:              tunnelName,
+				AccountID:         accountID,
+				APITokenSecretRef: v1.SecretKeyRef{Name: tokenName},
+				DaemonDeployment: v1.Deployment{
+					Kind:          v1.DeploymentKindDeployment,
+					DaemonVersion: "2024.4.1",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, tunnel)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tunnel), tunnel)).To(Succeed())
+			g.Expect(tunnel.Status.TunnelID).NotTo(BeEmpty())
+		}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+		tunnelID = tunnel.Status.TunnelID
+
+		created, ok := fakeCF.Tunnel(tunnelID)
+		Expect(ok).To(BeTrue())
+		Expect(created.Name).To(Equal(tunnelName))
+		Expect(created.AccountID).To(Equal(accountID))
+
+		var secret corev1.Secret
+		Expect(k8sClient.Get(
+			ctx,
+			client.ObjectKey{Namespace: namespace, Name: tunnel.Spec.CredentialSecretName()},
+			&secret,
+		)).To(Succeed())
+		var credential cloudflare.TunnelCredential
+		Expect(json.Unmarshal(secret.Data[fileNameCredential], &credential)).To(Succeed())
+		Expect(credential).To(Equal(cloudflare.TunnelCredential{
+			AccountTag:   accountID,
+			TunnelID:     tunnelID,
+			TunnelSecret: created.Secret,
+		}))
+	})
+
+	It("writes the config of the tunnel to ConfigMap", func(ctx SpecContext) {
+		Eventually(func(g Gomega) {
+			g.Expect(readConfigOf(ctx, tunnel)).To(HaveField("Ingress", ConsistOf(
+				v1.TunnelConfigIngress{Service: "http_status:404"},
+			)))
+		}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+	})
+
+	It("runs cloudflared with the credential and the config", func(ctx SpecContext) {
+		Eventually(func(g Gomega) {
+			var deployment appsv1.Deployment
+			g.Expect(k8sClient.Get(
+				ctx,
+				client.ObjectKey{Namespace: namespace, Name: buildDaemonName(tunnel)},
+				&deployment,
+			)).To(Succeed())
+			g.Expect(deployment.Spec.Template.Spec.Containers).To(ContainElement(
+				HaveField("Image", "cloudflare/cloudflared:2024.4.1"),
+			))
+			g.Expect(deployment.OwnerReferences).To(ContainElement(HaveField("UID", tunnel.UID)))
+		}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+	})
+
+	It("routes the hostname of TunnelIngress to the tunnel", func(ctx SpecContext) {
+		ingress = &v1.TunnelIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "lifecycle-app", Namespace: namespace},
+			Spec: v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To(hostname),
+					Service:  "http://app.default.svc:80",
+				},
+				TunnelRef: v1.TunnelRef{Name: tunnelName, Kind: v1.TunnelKindTunnel},
+			},
+		}
+		Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(fakeCF.DNSRecords(hostname)).To(ConsistOf(And(
+				HaveField("Type", "CNAME"),
+				HaveField("Content", tunnelID+".cfargotunnel.com"),
+				HaveField("ZoneID", zone.ID),
+			)))
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ingress), ingress)).To(Succeed())
+			g.Expect(ingress.Status.GetCondition(v1.TunnelIngressConditionTypeDNSRecord).Status).
+				To(Equal(corev1.ConditionTrue))
+			g.Expect(readConfigOf(ctx, tunnel)).To(HaveField("Ingress", ContainElement(
+				HaveField("Hostname", HaveValue(Equal(hostname))),
+			)))
+		}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+	})
+
+	It("reports a conflicting DNS record without overwriting it", func(ctx SpecContext) {
+		const conflicting = "taken.lifecycle.example"
+		fakeCF.AddDNSRecord(cf.DNSRecord{ZoneID: zone.ID, Type: "A", Name: conflicting, Content: "192.0.2.1"})
+
+		taken := &v1.TunnelIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "lifecycle-taken", Namespace: namespace},
+			Spec: v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To(conflicting),
+					Service:  "http://taken.default.svc:80",
+				},
+				TunnelRef: v1.TunnelRef{Name: tunnelName, Kind: v1.TunnelKindTunnel},
+			},
+		}
+		Expect(k8sClient.Create(ctx, taken)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(taken), taken)).To(Succeed())
+			cond := taken.Status.GetCondition(v1.TunnelIngressConditionTypeDNSRecord)
+			g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(cond.Reason).To(Equal(v1.DNSRecordReasonFailedToCreateRecord))
+		}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+		Expect(fakeCF.DNSRecords(conflicting)).To(ConsistOf(HaveField("Type", "A")))
+
+		Expect(k8sClient.Delete(ctx, taken)).To(Succeed())
+	})
+
+	It("requeues when Cloudflare API is rate limited", func(ctx SpecContext) {
+		const limited = "limited.lifecycle.example"
+		fakeCF.InjectFailure(fake.Failure{
+			Method:     http.MethodPut,
+			Path:       "/tunnels/" + tunnelID + "/routes$",
+			StatusCode: http.StatusTooManyRequests,
+			Code:       10000,
+			Message:    "Rate limited",
+			RetryAfter: time.Second,
+			Times:      2,
+		})
+
+		Expect(k8sClient.Create(ctx, &v1.TunnelIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "lifecycle-limited", Namespace: namespace},
+			Spec: v1.TunnelIngressSpec{
+				TunnelConfigIngress: v1.TunnelConfigIngress{
+					Hostname: ptr.To(limited),
+					Service:  "http://limited.default.svc:80",
+				},
+				TunnelRef: v1.TunnelRef{Name: tunnelName, Kind: v1.TunnelKindTunnel},
+			},
+		})).To(Succeed())
+
+		Eventually(func() []cf.DNSRecord {
+			return fakeCF.DNSRecords(limited)
+		}, eventuallyTimeout, eventuallyInterval).Should(HaveLen(1))
+		Expect(len(fakeCF.Requests(http.MethodPut, "/tunnels/"+tunnelID+"/routes$"))).To(BeNumerically(">=", 3))
+	})
+
+	It("removes the DNS record when TunnelIngress is deleted", func(ctx SpecContext) {
+		Expect(k8sClient.Delete(ctx, ingress)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(fakeCF.DNSRecords(hostname)).To(BeEmpty())
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(ingress), ingress)
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+	})
+
+	It("deletes the tunnel with its connections when Tunnel is deleted", func(ctx SpecContext) {
+		fakeCF.SetConnections(tunnelID, cf.TunnelConnection{ID: "conn", ColoName: "ICN", ClientID: "client"})
+
+		Expect(k8sClient.Delete(ctx, tunnel)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			deleted, ok := fakeCF.Tunnel(tunnelID)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(deleted.DeletedAt).NotTo(BeNil())
+			g.Expect(deleted.Connections).To(BeEmpty())
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tunnel), tunnel)
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+	})
+})
+
+func readConfigOf(ctx context.Context, tunnel *v1.Tunnel) (TunnelConfig, error) {
+	var configMap corev1.ConfigMap
+	if err := k8sClient.Get(
+		ctx,
+		client.ObjectKey{Namespace: tunnel.Namespace, Name: tunnel.Spec.ConfigName()},
+		&configMap,
+	); err != nil {
+		return TunnelConfig{}, err
+	}
+	return readTunnelConfig(configMap)
+}
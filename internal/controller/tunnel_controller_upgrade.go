@@ -0,0 +1,399 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const (
+	waitForLabelField = ".spec.daemonDeployment.upgradePolicy.waitFor"
+	// waitForAnyLabel indexes waitFor that may select Tunnels without any particular label.
+	waitForAnyLabel = "*"
+
+	defaultUpgradeCheckInterval = time.Hour
+	defaultUpgradeHealthTimeout = 10 * time.Minute
+	// failedResolutionRetry is when version lookup is retried while the running version is kept.
+	failedResolutionRetry = time.Minute
+)
+
+// upgradePlan is the decided daemon version and the upgrade status to record once the daemon is deployed with it.
+type upgradePlan struct {
+	version          string
+	availableVersion string
+	status           v1.DaemonUpgradeStatus
+	condition        *v1.TunnelStatusCondition
+	requeueAfter     time.Duration
+	// followsLatest is whether the version comes from the upgrade policy.
+	followsLatest bool
+}
+
+// planDaemonVersion decides the version to deploy. Explicit tags, digests and pinned versions are used as they are,
+// and only a DaemonVersion of latest goes through the upgrade policy.
+func (r *TunnelReconciler) planDaemonVersion(
+	ctx context.Context,
+	tunnel *v1.Tunnel,
+	current client.Object,
+) (upgradePlan, error) {
+	image := ptr.Deref(tunnel.Spec.DaemonDeployment.Image, v1.DaemonImage{})
+	if image.Tag != "" {
+		return upgradePlan{version: image.Tag}, nil
+	}
+
+	requested := tunnel.Spec.DaemonDeployment.DaemonVersion
+	if requested == "" {
+		requested = r.DaemonConfig.DefaultVersion
+	}
+	if image.Digest != "" {
+		// the digest alone decides the image, so that pinned images never need the resolver
+		if requested == "latest" {
+			return upgradePlan{}, nil
+		}
+		return upgradePlan{version: requested}, nil
+	}
+	if requested != "latest" {
+		version, err := getDaemonVersion(ctx, r.DaemonVersions, requested)
+		return upgradePlan{version: version}, err
+	}
+
+	return r.planUpgrade(ctx, tunnel, current)
+}
+
+func (r *TunnelReconciler) planUpgrade(
+	ctx context.Context,
+	tunnel *v1.Tunnel,
+	current client.Object,
+) (upgradePlan, error) {
+	l := log.FromContext(ctx)
+	policy := ptr.Deref(tunnel.Spec.DaemonDeployment.UpgradePolicy, v1.DaemonUpgradePolicy{})
+	now := r.Clock.Now()
+	running := tunnel.Status.DaemonVersion
+
+	plan := upgradePlan{
+		version:          running,
+		availableVersion: tunnel.Status.AvailableVersion,
+		status:           *tunnel.Status.Upgrade.DeepCopy(),
+		followsLatest:    true,
+	}
+	setCondition := func(reason v1.TunnelConditionReason, message string) {
+		plan.condition = &v1.TunnelStatusCondition{
+			Type:               v1.TunnelConditionTypeUpgrade,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Time{Time: now},
+			Reason:             reason,
+			Message:            message,
+		}
+	}
+	requeueIn := func(d time.Duration) {
+		if d > 0 && (plan.requeueAfter == 0 || d < plan.requeueAfter) {
+			plan.requeueAfter = d
+		}
+	}
+
+	constraint, err := cloudflare.ParseDaemonVersionConstraint(policy.Constraint)
+	if err != nil {
+		return plan, err
+	}
+
+	// look up releases periodically, not on every reconcile
+	checkInterval := durationOr(policy.CheckInterval, defaultUpgradeCheckInterval)
+	if plan.availableVersion == "" || plan.status.LastCheckTime == nil ||
+		now.Sub(plan.status.LastCheckTime.Time) >= checkInterval {
+		available, err := cloudflare.LatestDaemonVersion(
+			ctx,
+			r.DaemonVersions,
+			constraint,
+			plan.status.FailedVersion,
+		)
+		if err != nil {
+			if running == "" {
+				return plan, err
+			}
+			l.Error(err, "failed to look up daemon versions, keeping the running version")
+			setCondition(v1.UpgradeReasonFailedToResolveVersion, err.Error())
+			requeueIn(failedResolutionRetry)
+			return plan, nil
+		}
+		if available != plan.availableVersion {
+			plan.availableVersion = available
+			plan.status.AvailableSince = &metav1.Time{Time: now}
+		}
+		plan.status.LastCheckTime = &metav1.Time{Time: now}
+	}
+	requeueIn(plan.status.LastCheckTime.Add(checkInterval).Sub(now))
+	available := plan.availableVersion
+
+	// nothing runs yet, so there is nothing to protect
+	if running == "" {
+		plan.version = available
+		return plan, nil
+	}
+
+	if plan.status.StartedAt != nil {
+		healthTimeout := durationOr(policy.HealthTimeout, defaultUpgradeHealthTimeout)
+		switch {
+		case isDaemonReady(current):
+			plan.status.StartedAt = nil
+			plan.status.PreviousVersion = ""
+		case now.Sub(plan.status.StartedAt.Time) >= healthTimeout && plan.status.PreviousVersion != "":
+			l.Info("rolling back daemon upgrade", "failedVersion", running, "version", plan.status.PreviousVersion)
+			plan.version = plan.status.PreviousVersion
+			plan.status.FailedVersion = running
+			plan.status.StartedAt = nil
+			plan.status.PreviousVersion = ""
+			// look up again without the failed version
+			plan.status.LastCheckTime = nil
+			setCondition(
+				v1.UpgradeReasonRolledBack,
+				fmt.Sprintf("daemon %s did not become ready in %s", running, healthTimeout),
+			)
+			return plan, nil
+		default:
+			setCondition(v1.UpgradeReasonRollingOut, "waiting for daemon "+running+" to become ready")
+			requeueIn(plan.status.StartedAt.Add(healthTimeout).Sub(now))
+			return plan, nil
+		}
+	}
+
+	if available == running ||
+		(cloudflare.CompareDaemonVersions(available, running) < 0 && constraint.Check(running)) {
+		return plan, nil
+	}
+
+	if minAge := durationOr(policy.MinReleaseAge, 0); plan.status.AvailableSince != nil &&
+		now.Sub(plan.status.AvailableSince.Time) < minAge {
+		setCondition(
+			v1.UpgradeReasonWaitingForReleaseAge,
+			fmt.Sprintf("%s is found at %s", available, plan.status.AvailableSince.Format(time.RFC3339)),
+		)
+		requeueIn(plan.status.AvailableSince.Add(minAge).Sub(now))
+		return plan, nil
+	}
+
+	open, nextStart, err := maintenanceWindowState(policy.MaintenanceWindows, now)
+	if err != nil {
+		return plan, err
+	}
+	if !open {
+		setCondition(
+			v1.UpgradeReasonWaitingForMaintenanceWindow,
+			fmt.Sprintf("%s will be rolled out at %s", available, nextStart.Format(time.RFC3339)),
+		)
+		requeueIn(nextStart.Sub(now))
+		return plan, nil
+	}
+
+	pending, err := r.tunnelsBehind(ctx, tunnel, policy.WaitFor, available)
+	if err != nil {
+		return plan, err
+	}
+	if len(pending) > 0 {
+		// tunnels of earlier stages trigger this one on their status changes
+		setCondition(
+			v1.UpgradeReasonWaitingForStage,
+			fmt.Sprintf("waiting for %v to run %s", pending, available),
+		)
+		return plan, nil
+	}
+
+	l.Info("upgrading daemon", "from", running, "to", available)
+	plan.version = available
+	plan.status.StartedAt = &metav1.Time{Time: now}
+	plan.status.PreviousVersion = running
+	setCondition(v1.UpgradeReasonRollingOut, "upgrading daemon from "+running+" to "+available)
+	requeueIn(durationOr(policy.HealthTimeout, defaultUpgradeHealthTimeout))
+	return plan, nil
+}
+
+// apply records the plan to the status, and reports whether anything changed.
+func (p upgradePlan) apply(status *v1.TunnelStatus, now time.Time) bool {
+	if !p.followsLatest {
+		changed := status.AvailableVersion != "" || !equality.Semantic.DeepEqual(status.Upgrade, v1.DaemonUpgradeStatus{})
+		status.AvailableVersion = ""
+		status.Upgrade = v1.DaemonUpgradeStatus{}
+		return changed
+	}
+
+	changed := status.AvailableVersion != p.availableVersion || !equality.Semantic.DeepEqual(status.Upgrade, p.status)
+	status.AvailableVersion = p.availableVersion
+	status.Upgrade = p.status
+
+	condition := ptr.Deref(p.condition, v1.TunnelStatusCondition{
+		Type:               v1.TunnelConditionTypeUpgrade,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: now},
+	})
+	return UpdateConditionIfChanged(status, condition) || changed
+}
+
+// tunnelsBehind lists Tunnels selected by waitFor that do not run the version with ready daemon yet.
+func (r *TunnelReconciler) tunnelsBehind(
+	ctx context.Context,
+	tunnel *v1.Tunnel,
+	waitFor *metav1.LabelSelector,
+	version string,
+) ([]string, error) {
+	if waitFor == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(waitFor)
+	if err != nil {
+		return nil, err
+	}
+
+	var tunnels v1.TunnelList
+	if err = r.List(ctx, &tunnels, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, other := range tunnels.Items {
+		if other.UID == tunnel.UID {
+			continue
+		}
+		if !isUpgradeSettled(&other, version) {
+			pending = append(pending, other.Namespace+"/"+other.Name)
+		}
+	}
+	slices.Sort(pending)
+	return pending, nil
+}
+
+// indexTunnelWaitFor indexes Tunnels by label keys that Tunnels selected by their waitFor have to have,
+// so that a Tunnel finds the ones waiting for it by its own label keys.
+func indexTunnelWaitFor(rawObj client.Object) []string {
+	tunnel := rawObj.(*v1.Tunnel)
+	policy := tunnel.Spec.DaemonDeployment.UpgradePolicy
+	if policy == nil || policy.WaitFor == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(policy.WaitFor)
+	if err != nil {
+		return nil
+	}
+
+	requirements, _ := selector.Requirements()
+	var keys []string
+	for _, requirement := range requirements {
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In, selection.Exists:
+			keys = append(keys, requirement.Key())
+		}
+	}
+	if len(keys) == 0 {
+		return []string{waitForAnyLabel}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// upgradeStageChanged passes updates of Tunnels that may settle or unsettle the stage that others wait for,
+// which are changes of labels or of what isUpgradeSettled looks at.
+var upgradeStageChanged = predicate.Or(
+	predicate.LabelChangedPredicate{},
+	predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldTunnel, ok := e.ObjectOld.(*v1.Tunnel)
+			if !ok {
+				return false
+			}
+			newTunnel, ok := e.ObjectNew.(*v1.Tunnel)
+			if !ok {
+				return false
+			}
+			return oldTunnel.Status.DaemonVersion != newTunnel.Status.DaemonVersion ||
+				!equality.Semantic.DeepEqual(oldTunnel.Status.Upgrade.StartedAt, newTunnel.Status.Upgrade.StartedAt) ||
+				oldTunnel.Status.GetCondition(v1.TunnelConditionTypeDaemon).Status !=
+					newTunnel.Status.GetCondition(v1.TunnelConditionTypeDaemon).Status
+		},
+	},
+)
+
+// isUpgradeSettled reports whether the tunnel runs the version or newer, with ready daemon.
+func isUpgradeSettled(tunnel *v1.Tunnel, version string) bool {
+	return cloudflare.CompareDaemonVersions(tunnel.Status.DaemonVersion, version) >= 0 &&
+		tunnel.Status.Upgrade.StartedAt == nil &&
+		tunnel.Status.GetCondition(v1.TunnelConditionTypeDaemon).Status == corev1.ConditionTrue
+}
+
+// isDaemonReady reports whether the workload has rolled out its latest spec and every pod of it is available.
+func isDaemonReady(daemon client.Object) bool {
+	switch d := daemon.(type) {
+	case *appsv1.Deployment:
+		replicas := ptr.Deref(d.Spec.Replicas, 1)
+		return d.Status.ObservedGeneration >= d.Generation &&
+			d.Status.UpdatedReplicas == replicas &&
+			d.Status.Replicas == replicas &&
+			d.Status.AvailableReplicas >= replicas
+	case *appsv1.DaemonSet:
+		return d.Status.ObservedGeneration >= d.Generation &&
+			d.Status.UpdatedNumberScheduled == d.Status.DesiredNumberScheduled &&
+			d.Status.NumberAvailable == d.Status.DesiredNumberScheduled
+	default:
+		return false
+	}
+}
+
+// maintenanceWindowState reports whether now is in any of windows, or else when the next one starts.
+// No window means always open.
+func maintenanceWindowState(windows []v1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	if len(windows) == 0 {
+		return true, time.Time{}, nil
+	}
+
+	var nextStart time.Time
+	for _, window := range windows {
+		loc := time.UTC
+		if window.TimeZone != "" {
+			var err error
+			if loc, err = time.LoadLocation(window.TimeZone); err != nil {
+				return false, time.Time{}, err
+			}
+		}
+		startOfDay, err := time.ParseInLocation("15:04", window.Start, loc)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+
+		local := now.In(loc)
+		// windows may last for days, so the ones started in the last week may still be open
+		for offset := -7; offset <= 7; offset++ {
+			day := local.AddDate(0, 0, offset)
+			start := time.Date(
+				day.Year(), day.Month(), day.Day(), startOfDay.Hour(), startOfDay.Minute(), 0, 0, loc,
+			)
+			if len(window.Days) > 0 && !slices.Contains(window.Days, start.Weekday().String()[:3]) {
+				continue
+			}
+			if !start.After(now) && now.Before(start.Add(window.Duration.Duration)) {
+				return true, time.Time{}, nil
+			}
+			if start.After(now) && (nextStart.IsZero() || start.Before(nextStart)) {
+				nextStart = start
+			}
+		}
+	}
+	return false, nextStart, nil
+}
+
+func durationOr(d *metav1.Duration, defaultValue time.Duration) time.Duration {
+	if d == nil {
+		return defaultValue
+	}
+	return d.Duration
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func newTestStagedTunnel(name string, stageLabels map[string]string, waitFor *metav1.LabelSelector) *v1.Tunnel {
+	tunnel := newTestTunnel()
+	tunnel.Name = name
+	tunnel.UID = types.UID(name)
+	tunnel.Labels = stageLabels
+	if waitFor != nil {
+		tunnel.Spec.DaemonDeployment.UpgradePolicy = &v1.DaemonUpgradePolicy{WaitFor: waitFor}
+	}
+	return tunnel
+}
+
+func TestFindTunnelsWaitingFor(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	canary := newTestStagedTunnel("canary", map[string]string{"stage": "canary"}, nil)
+	r := newTestReconciler(g,
+		canary,
+		newTestStagedTunnel("by-label", nil, &metav1.LabelSelector{
+			MatchLabels: map[string]string{"stage": "canary"},
+		}),
+		newTestStagedTunnel("by-expression", nil, &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "stage",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"canary", "early"},
+			}},
+		}),
+		newTestStagedTunnel("by-absence", nil, &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "stage",
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{"late"},
+			}},
+		}),
+		newTestStagedTunnel("other-stage", nil, &metav1.LabelSelector{
+			MatchLabels: map[string]string{"stage": "early"},
+		}),
+		newTestStagedTunnel("other-key", nil, &metav1.LabelSelector{
+			MatchLabels: map[string]string{"team": "edge"},
+		}),
+		newTestStagedTunnel("not-waiting", nil, nil),
+	)
+
+	requests := r.findTunnelsWaitingFor(context.Background(), canary)
+	g.Expect(requests).To(ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "by-label"}},
+		reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "by-expression"}},
+		reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "by-absence"}},
+	))
+}
+
+func TestUpgradeStageChanged(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		update func(tunnel *v1.Tunnel)
+		want   bool
+	}{
+		"spec": {
+			update: func(tunnel *v1.Tunnel) { tunnel.Spec.Name = "renamed" },
+			want:   false,
+		},
+		"other condition": {
+			update: func(tunnel *v1.Tunnel) {
+				tunnel.Status.Conditions = append(tunnel.Status.Conditions, v1.TunnelStatusCondition{
+					Type:   v1.TunnelConditionTypeConfig,
+					Status: corev1.ConditionTrue,
+				})
+			},
+			want: false,
+		},
+		"labels": {
+			update: func(tunnel *v1.Tunnel) { tunnel.Labels = map[string]string{"stage": "canary"} },
+			want:   true,
+		},
+		"daemon version": {
+			update: func(tunnel *v1.Tunnel) { tunnel.Status.DaemonVersion = "2024.2.1" },
+			want:   true,
+		},
+		"upgrade started": {
+			update: func(tunnel *v1.Tunnel) { tunnel.Status.Upgrade.StartedAt = &metav1.Time{} },
+			want:   true,
+		},
+		"daemon ready": {
+			update: func(tunnel *v1.Tunnel) {
+				tunnel.Status.Conditions = append(tunnel.Status.Conditions, v1.TunnelStatusCondition{
+					Type:   v1.TunnelConditionTypeDaemon,
+					Status: corev1.ConditionTrue,
+				})
+			},
+			want: true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			oldTunnel := newTestTunnel()
+			newTunnel := oldTunnel.DeepCopy()
+			tc.update(newTunnel)
+			NewWithT(t).Expect(upgradeStageChanged.Update(event.UpdateEvent{ObjectOld: oldTunnel, ObjectNew: newTunnel})).
+				To(Equal(tc.want))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+// findIngressesForPolicy enqueues every TunnelIngress,
+// since namespaces that the TunnelPolicy selected before the change are unknown.
+func (r *TunnelIngressReconciler) findIngressesForPolicy(ctx context.Context, _ client.Object) []reconcile.Request {
+	return r.findIngresses(ctx)
+}
+
+// findIngressesForNamespace enqueues TunnelIngresses of the Namespace, whose labels may change selecting TunnelPolicies.
+func (r *TunnelIngressReconciler) findIngressesForNamespace(ctx context.Context, ns client.Object) []reconcile.Request {
+	return r.findIngresses(ctx, client.InNamespace(ns.GetName()))
+}
+
+// findIngressesForTunnel enqueues TunnelIngresses of the Tunnel, whose account may change what TunnelPolicies allow.
+// The tunnelRef index belongs to TunnelReconciler, which may not be set up, so ingresses are filtered here.
+func (r *TunnelIngressReconciler) findIngressesForTunnel(ctx context.Context, tunnel client.Object) []reconcile.Request {
+	var ingresses v1.TunnelIngressList
+	if err := r.List(ctx, &ingresses); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing tunnel ingresses of tunnel")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ingress := range ingresses.Items {
+		if ingress.Spec.TunnelRef.Kind == v1.TunnelKindTunnel &&
+			tunnelRefKey(ingress.Namespace, ingress.Spec.TunnelRef) == client.ObjectKeyFromObject(tunnel) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+func (r *TunnelIngressReconciler) findIngresses(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
+	var ingresses v1.TunnelIngressList
+	if err := r.List(ctx, &ingresses, opts...); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing tunnel ingresses of tunnel policy")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(ingresses.Items))
+	for i, ingress := range ingresses.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace},
+		}
+	}
+	return requests
+}
+
+// findObjectsForPolicy enqueues every Tunnel, whose ingresses may be allowed or denied by the TunnelPolicy.
+func (r *TunnelReconciler) findObjectsForPolicy(ctx context.Context, _ client.Object) []reconcile.Request {
+	var tunnels v1.TunnelList
+	if err := r.List(ctx, &tunnels); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing tunnels of tunnel policy")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(tunnels.Items))
+	for i, tunnel := range tunnels.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: tunnel.Name, Namespace: tunnel.Namespace},
+		}
+	}
+	return requests
+}
+
+// findObjectsForNamespace enqueues Tunnels of TunnelIngresses in the Namespace,
+// whose labels may change selecting TunnelPolicies.
+func (r *TunnelReconciler) findObjectsForNamespace(ctx context.Context, ns client.Object) []reconcile.Request {
+	var ingresses v1.TunnelIngressList
+	if err := r.List(ctx, &ingresses, client.InNamespace(ns.GetName())); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing tunnel ingresses of namespace")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ingress := range ingresses.Items {
+		if ingress.Spec.TunnelRef.Kind != v1.TunnelKindTunnel {
+			continue
+		}
+		request := reconcile.Request{NamespacedName: tunnelRefKey(ingress.Namespace, ingress.Spec.TunnelRef)}
+		if !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// findRecordsForPolicy enqueues every DNSRecord,
+// since namespaces that the TunnelPolicy selected before the change are unknown.
+func (r *DNSRecordReconciler) findRecordsForPolicy(ctx context.Context, _ client.Object) []reconcile.Request {
+	return r.findRecords(ctx)
+}
+
+// findRecordsForNamespace enqueues DNSRecords of the Namespace, whose labels may change selecting TunnelPolicies.
+func (r *DNSRecordReconciler) findRecordsForNamespace(ctx context.Context, ns client.Object) []reconcile.Request {
+	return r.findRecords(ctx, client.InNamespace(ns.GetName()))
+}
+
+func (r *DNSRecordReconciler) findRecords(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
+	var records v1.DNSRecordList
+	if err := r.List(ctx, &records, opts...); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing dns records of tunnel policy")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(records.Items))
+	for i, record := range records.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: record.Name, Namespace: record.Namespace},
+		}
+	}
+	return requests
+}
+
+// findLoadBalancersForPolicy enqueues every LoadBalancer,
+// since namespaces that the TunnelPolicy selected before the change are unknown.
+func (r *LoadBalancerReconciler) findLoadBalancersForPolicy(ctx context.Context, _ client.Object) []reconcile.Request {
+	return r.findLoadBalancers(ctx)
+}
+
+// findLoadBalancersForNamespace enqueues LoadBalancers of the Namespace, whose labels may change selecting TunnelPolicies.
+func (r *LoadBalancerReconciler) findLoadBalancersForNamespace(
+	ctx context.Context,
+	ns client.Object,
+) []reconcile.Request {
+	return r.findLoadBalancers(ctx, client.InNamespace(ns.GetName()))
+}
+
+func (r *LoadBalancerReconciler) findLoadBalancers(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
+	var lbs v1.LoadBalancerList
+	if err := r.List(ctx, &lbs, opts...); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing load balancers of tunnel policy")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(lbs.Items))
+	for i, lb := range lbs.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: lb.Name, Namespace: lb.Namespace},
+		}
+	}
+	return requests
+}
@@ -22,17 +22,23 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/policy"
 )
 
 const tunnelIngressFinalizerName = "tunnelingress.cloudflared-operator.bhyoo.com/finalizer"
@@ -42,11 +48,18 @@ type TunnelIngressReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Clock  clock.PassiveClock
+
+	CloudflareClients *cloudflare.ClientPool
 }
 
 //+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelingresses,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelingresses/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelingresses/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelreferencegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -94,6 +107,11 @@ func (r *TunnelIngressReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	switch ingress.Spec.TunnelRef.Kind {
 	case v1.TunnelKindTunnel:
+		// nothing is written to Cloudflare before the ingress is permitted
+		if err := r.checkIngressPermitted(ctx, &ingress); err != nil {
+			return ctrl.Result{}, r.withdrawIngress(ctx, &ingress, err)
+		}
+
 		tunnel, err := r.getTunnelFromIngress(ctx, &ingress)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
@@ -103,15 +121,22 @@ func (r *TunnelIngressReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			l.Error(err, "unable to fetch Tunnel")
 			return ctrl.Result{}, err
 		}
-		if err := ctrl.SetControllerReference(tunnel, &ingress, r.Scheme); err != nil {
-			// TODO: error handling
-			return ctrl.Result{}, err
+		// TunnelIngresses created for Services are controlled by the Service,
+		// and owners of other namespaces are not allowed
+		if metav1.GetControllerOf(&ingress) == nil && tunnel.Namespace == ingress.Namespace {
+			if err := ctrl.SetControllerReference(tunnel, &ingress, r.Scheme); err != nil {
+				// TODO: error handling
+				return ctrl.Result{}, err
+			}
+			if err := r.Update(ctx, &ingress); err != nil {
+				// TODO: error handling
+				return ctrl.Result{}, err
+			}
 		}
-		if err := r.Update(ctx, &ingress); err != nil {
-			// TODO: error handling
+		if err = r.reconcileDNSRecord(ctx, &ingress, tunnel); err != nil {
 			return ctrl.Result{}, err
 		}
-		if err = r.reconcileDNSRecord(ctx, &ingress, tunnel); err != nil {
+		if err = r.reconcileBackend(ctx, &ingress); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -121,11 +146,111 @@ func (r *TunnelIngressReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 }
 
+// checkIngressPermitted checks that the ingress may refer its Tunnel and backend, and conforms to TunnelPolicies.
+// Errors of denial are wrapped with the reason of DNSRecord condition.
+func (r *TunnelIngressReconciler) checkIngressPermitted(ctx context.Context, ingress *v1.TunnelIngress) error {
+	if err := checkReferencePermitted(
+		ctx,
+		r,
+		v1.ReferenceGrantFromKindTunnelIngress,
+		ingress.Namespace,
+		v1.ReferenceGrantToKindTunnel,
+		tunnelRefKey(ingress.Namespace, ingress.Spec.TunnelRef),
+	); err != nil {
+		if errors.Is(err, errRefNotPermitted) {
+			return WrapError(err, v1.DNSRecordReasonRefNotPermitted)
+		}
+		return err
+	}
+
+	if err := policy.CheckTunnelIngress(ctx, r, ingress); err != nil {
+		if errors.Is(err, policy.ErrViolation) {
+			return WrapError(err, v1.DNSRecordReasonPolicyViolation)
+		}
+		return err
+	}
+
+	// the Tunnel skips rules of backends not permitted, so their hostnames are not routed either
+	if ingress.Spec.Origin != nil && ingress.Spec.Origin.BackendRef != nil {
+		if err := checkReferencePermitted(
+			ctx,
+			r,
+			v1.ReferenceGrantFromKindTunnelIngress,
+			ingress.Namespace,
+			v1.ReferenceGrantToKindService,
+			backendKey(ingress.Namespace, *ingress.Spec.Origin.BackendRef),
+		); err != nil {
+			if errors.Is(err, errRefNotPermitted) {
+				return WrapError(err, v1.DNSRecordReasonRefNotPermitted)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// withdrawIngress records why the ingress is not permitted. The DNS record created while it was permitted,
+// e.g. before its TunnelReferenceGrant was revoked, is deleted, as the Tunnel drops the rule of the ingress as well.
+func (r *TunnelIngressReconciler) withdrawIngress(ctx context.Context, ingress *v1.TunnelIngress, denied error) error {
+	recordConditionFrom := r.buildConditionRecorder(ctx, ingress, v1.TunnelIngressConditionTypeDNSRecord)
+	if !isIngressDenied(denied) {
+		return recordConditionFrom(denied)
+	}
+
+	// records are created only by the ingress whose condition is true, so records of others are left alone
+	if ingress.Status.GetCondition(v1.TunnelIngressConditionTypeDNSRecord).Status == corev1.ConditionTrue {
+		if err := r.deleteTunnelIngress(ctx, ingress); err != nil {
+			return recordConditionFrom(WrapError(err, v1.DNSRecordReasonFailedToDeleteRecord))
+		}
+	}
+	// TunnelReferenceGrants, TunnelPolicies and Namespaces are watched
+	return recordConditionFrom(reconcile.TerminalError(denied))
+}
+
+func isIngressDenied(err error) bool {
+	return errors.Is(err, errRefNotPermitted) || errors.Is(err, policy.ErrViolation)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *TunnelIngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&v1.TunnelIngress{},
+		backendRefField,
+		indexBackendRef,
+	); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1.TunnelIngress{}).
-		Complete(r)
+		Watches(
+			&corev1.Service{},
+			handler.EnqueueRequestsFromMapFunc(r.findIngressesForBackend),
+		).
+		Watches(
+			&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(r.findIngressesForEndpointSlice),
+		).
+		Watches(
+			&v1.TunnelReferenceGrant{},
+			handler.EnqueueRequestsFromMapFunc(r.findIngressesForGrant),
+		).
+		Watches(
+			&v1.TunnelPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.findIngressesForPolicy),
+		).
+		Watches(
+			&v1.Tunnel{},
+			handler.EnqueueRequestsFromMapFunc(r.findIngressesForTunnel),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findIngressesForNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
+		Complete(requeueOnRateLimit(r))
 }
 
 func (r *TunnelIngressReconciler) buildConditionRecorder(
@@ -180,7 +305,7 @@ func (r *TunnelIngressReconciler) getTunnelFromIngress(ctx context.Context, ingr
 	}
 
 	var tunnel v1.Tunnel
-	err := r.Get(ctx, client.ObjectKey{Namespace: ingress.GetNamespace(), Name: ingress.Spec.TunnelRef.Name}, &tunnel)
+	err := r.Get(ctx, tunnelRefKey(ingress.Namespace, ingress.Spec.TunnelRef), &tunnel)
 	if err != nil {
 		return nil, err
 	}
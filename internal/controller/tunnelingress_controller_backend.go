@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+const backendRefField = ".spec.origin.backendRef"
+
+// reconcileBackend reports whether the Service of backendRef has ready endpoints.
+// Services and EndpointSlices are watched, so missing backends are not retried.
+func (r *TunnelIngressReconciler) reconcileBackend(ctx context.Context, ingress *v1.TunnelIngress) error {
+	if ingress.Spec.Origin == nil || ingress.Spec.Origin.BackendRef == nil {
+		return nil
+	}
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, ingress, v1.TunnelIngressConditionTypeBackendReady)
+
+	key := backendKey(ingress.Namespace, *ingress.Spec.Origin.BackendRef)
+	if err := checkReferencePermitted(
+		ctx,
+		r,
+		v1.ReferenceGrantFromKindTunnelIngress,
+		ingress.Namespace,
+		v1.ReferenceGrantToKindService,
+		key,
+	); err != nil {
+		if errors.Is(err, errRefNotPermitted) {
+			return recordConditionFrom(reconcile.TerminalError(WrapError(err, v1.BackendReasonRefNotPermitted)))
+		}
+		return recordConditionFrom(err)
+	}
+
+	var service corev1.Service
+	if err := r.Get(ctx, key, &service); err != nil {
+		if apierrors.IsNotFound(err) {
+			return recordConditionFrom(reconcile.TerminalError(WrapError(err, v1.BackendReasonServiceNotFound)))
+		}
+		return recordConditionFrom(err)
+	}
+
+	port, err := findServicePort(service, ingress.Spec.Origin.BackendRef.Port)
+	if err != nil {
+		return recordConditionFrom(reconcile.TerminalError(WrapError(err, v1.BackendReasonPortNotFound)))
+	}
+
+	cond := v1.TunnelIngressStatusCondition{
+		Type:               v1.TunnelIngressConditionTypeBackendReady,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	}
+	// ExternalName services have no endpoints
+	if service.Spec.Type != corev1.ServiceTypeExternalName {
+		ready, err := r.hasReadyEndpoints(ctx, service, port)
+		if err != nil {
+			return recordConditionFrom(err)
+		}
+		if !ready {
+			cond.Status = corev1.ConditionFalse
+			cond.Reason = v1.BackendReasonNoReadyEndpoints
+		}
+	}
+
+	if UpdateConditionIfChanged(&ingress.Status, cond) {
+		return r.Status().Update(ctx, ingress)
+	}
+	return nil
+}
+
+func (r *TunnelIngressReconciler) hasReadyEndpoints(
+	ctx context.Context,
+	service corev1.Service,
+	port corev1.ServicePort,
+) (bool, error) {
+	var sliceList discoveryv1.EndpointSliceList
+	if err := r.List(
+		ctx,
+		&sliceList,
+		client.InNamespace(service.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: service.Name},
+	); err != nil {
+		return false, err
+	}
+
+	for _, slice := range sliceList.Items {
+		servesPort := false
+		for _, slicePort := range slice.Ports {
+			if ptr.Deref(slicePort.Name, "") == port.Name {
+				servesPort = true
+				break
+			}
+		}
+		if !servesPort {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			// nil means ready, as documented on EndpointConditions
+			if ptr.Deref(endpoint.Conditions.Ready, true) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// findIngressesForBackend enqueues TunnelIngresses of which backendRef refers the Service.
+func (r *TunnelIngressReconciler) findIngressesForBackend(ctx context.Context, service client.Object) []reconcile.Request {
+	return findIngressesForService(ctx, r, service.GetNamespace(), service.GetName())
+}
+
+// findIngressesForEndpointSlice enqueues TunnelIngresses of which backendRef refers the Service of the EndpointSlice.
+func (r *TunnelIngressReconciler) findIngressesForEndpointSlice(
+	ctx context.Context,
+	slice client.Object,
+) []reconcile.Request {
+	serviceName, ok := slice.GetLabels()[discoveryv1.LabelServiceName]
+	if !ok {
+		return nil
+	}
+	return findIngressesForService(ctx, r, slice.GetNamespace(), serviceName)
+}
+
+func findIngressesForService(ctx context.Context, reader client.Reader, namespace, name string) []reconcile.Request {
+	ingresses, err := listIngressesForService(ctx, reader, namespace, name)
+	if err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing tunnel ingresses referring service")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(ingresses))
+	for i, ingress := range ingresses {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace},
+		}
+	}
+	return requests
+}
+
+func listIngressesForService(
+	ctx context.Context,
+	reader client.Reader,
+	namespace, name string,
+) ([]v1.TunnelIngress, error) {
+	var ingresses v1.TunnelIngressList
+	if err := reader.List(
+		ctx,
+		&ingresses,
+		client.MatchingFields{backendRefField: types.NamespacedName{Namespace: namespace, Name: name}.String()},
+	); err != nil {
+		return nil, err
+	}
+	return ingresses.Items, nil
+}
+
+// indexBackendRef indexes TunnelIngresses by namespace/name of the Service that backendRef refers.
+func indexBackendRef(rawObj client.Object) []string {
+	ingress := rawObj.(*v1.TunnelIngress)
+	if ingress.Spec.Origin == nil || ingress.Spec.Origin.BackendRef == nil {
+		return nil
+	}
+	return []string{backendKey(ingress.Namespace, *ingress.Spec.Origin.BackendRef).String()}
+}
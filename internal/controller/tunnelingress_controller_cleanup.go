@@ -43,5 +43,5 @@ func (r *TunnelIngressReconciler) deleteTunnelIngress(ctx context.Context, ingre
 		return err
 	}
 
-	return cfClient.DeleteDNSRecord(ctx, tunnel.Spec.AccountID, *ingress.Spec.Hostname)
+	return cfClient.DeleteDNSRecord(ctx, tunnel.Spec.AccountID, *ingress.Spec.Hostname, zoneRefOf(ingress))
 }
@@ -4,12 +4,8 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/isac322/cloudflared-operator/api/v1"
 	"github.com/isac322/cloudflared-operator/internal/cloudflare"
@@ -44,6 +40,7 @@ func (r *TunnelIngressReconciler) reconcileDNSRecord(
 		tunnel.Spec.AccountID,
 		tunnel.Status.TunnelID,
 		*targetDomain,
+		zoneRefOf(ingress),
 		ingress.Spec.OverwriteExistingDNS,
 	)
 	if err != nil {
@@ -64,35 +61,20 @@ func (r *TunnelIngressReconciler) getCloudflareClient(
 	ctx context.Context,
 	tunnel *v1.Tunnel,
 ) (cloudflare.Client, error) {
-	l := log.FromContext(ctx)
-
-	var secret corev1.Secret
-	if err := r.Get(
+	return newCloudflareClientForTunnel(
 		ctx,
-		client.ObjectKey{
-			Namespace: ptr.Deref(tunnel.Spec.APITokenSecretRef.Namespace, tunnel.Namespace),
-			Name:      tunnel.Spec.APITokenSecretRef.Name,
-		},
-		&secret,
-	); err != nil {
-		l.Error(err, "unable to fetch apiTokenSecretRef")
-		err = WrapError(err, v1.DNSRecordReasonNoToken)
-		if apierrors.IsNotFound(err) {
-			err = reconcile.TerminalError(err)
-		}
-		return nil, err
-	}
-
-	secretKey := ptr.Deref(tunnel.Spec.APITokenSecretRef.Key, apiTokenKey)
-	bytesToken, exists := GetDataFromSecret(&secret, secretKey)
-	if !exists {
-		return nil, WrapError(errNotFoundAPITokenKey, v1.DNSRecordReasonNoToken)
-	}
+		r,
+		r.CloudflareClients,
+		tunnel,
+		v1.DNSRecordReasonNoToken,
+		v1.DNSRecordReasonFailedToConnectCF,
+		v1.DNSRecordReasonAccountNotAllowed,
+	)
+}
 
-	cli, err := cloudflare.NewClient(string(bytesToken))
-	if err != nil {
-		l.Error(err, "failed to create Cloudflare client")
-		return nil, WrapError(err, v1.DNSRecordReasonFailedToConnectCF)
+func zoneRefOf(ingress *v1.TunnelIngress) cloudflare.ZoneRef {
+	return cloudflare.ZoneRef{
+		ID:   ptr.Deref(ingress.Spec.ZoneID, ""),
+		Name: ptr.Deref(ingress.Spec.ZoneName, ""),
 	}
-	return cli, nil
 }
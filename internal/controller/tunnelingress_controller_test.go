@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	cf "github.com/cloudflare/cloudflare-go"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare/fake"
+)
+
+const testIngressAccountID = "account"
+
+// newTestIngressReconciler returns a reconciler of TunnelIngress against server,
+// with a Tunnel named sample in namespace tunnels that is created on Cloudflare already.
+func newTestIngressReconciler(g Gomega, server *fake.Server, objects ...client.Object) *TunnelIngressReconciler {
+	opts := cloudflare.DefaultClientOptions()
+	opts.BaseURL = server.URL + "/client/v4"
+	opts.MaxRetries = 0
+	pool := cloudflare.NewClientPool(opts, clock.RealClock{})
+	cfClient, err := pool.Get("token")
+	g.Expect(err).NotTo(HaveOccurred())
+	credential, err := cfClient.GetOrCreateTunnel(context.Background(), testIngressAccountID, "sample-tunnel")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tunnel := newTestTunnel()
+	tunnel.Namespace = "tunnels"
+	tunnel.Spec.AccountID = testIngressAccountID
+	tunnel.Spec.APITokenSecretRef = v1.SecretKeyRef{Name: "token"}
+	tunnel.Status.TunnelID = credential.TunnelID
+
+	base := newTestReconciler(g)
+	c := ctrlfake.NewClientBuilder().
+		WithScheme(base.Scheme).
+		WithObjects(newTestNamespace("tunnels"), newTestNamespace("apps"), tunnel).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "tunnels"},
+			Data:       map[string][]byte{apiTokenKey: []byte("token")},
+		}).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1.TunnelIngress{}).
+		Build()
+
+	return &TunnelIngressReconciler{
+		Client:            c,
+		Scheme:            base.Scheme,
+		Clock:             clock.RealClock{},
+		CloudflareClients: pool,
+	}
+}
+
+func newTestTunnelGrant(to v1.ReferenceGrantToKind) *v1.TunnelReferenceGrant {
+	return &v1.TunnelReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: "apps-" + string(to), Namespace: "tunnels"},
+		Spec: v1.TunnelReferenceGrantSpec{
+			From: []v1.ReferenceGrantFrom{{Kind: v1.ReferenceGrantFromKindTunnelIngress, Namespace: "apps"}},
+			To:   []v1.ReferenceGrantTo{{Kind: to}},
+		},
+	}
+}
+
+func newTestCrossNamespaceIngress(origin *v1.IngressOrigin) *v1.TunnelIngress {
+	return &v1.TunnelIngress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "web",
+			Namespace:  "apps",
+			Finalizers: []string{tunnelIngressFinalizerName},
+		},
+		Spec: v1.TunnelIngressSpec{
+			TunnelConfigIngress: v1.TunnelConfigIngress{Hostname: ptr.To("web.example.com")},
+			Origin:              origin,
+			TunnelRef:           v1.TunnelRef{Kind: v1.TunnelKindTunnel, Name: "sample", Namespace: ptr.To("tunnels")},
+		},
+	}
+}
+
+func TestTunnelIngressWithdrawsRecordOfRevokedGrant(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	zone := server.AddZone(testIngressAccountID, "example.com")
+	grant := newTestTunnelGrant(v1.ReferenceGrantToKindTunnel)
+	ingress := newTestCrossNamespaceIngress(nil)
+	r := newTestIngressReconciler(g, server, grant, ingress)
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(ingress)}
+
+	g.Expect(r.Reconcile(ctx, req)).To(Equal(reconcile.Result{}))
+	g.Expect(server.DNSRecords("web.example.com")).To(HaveLen(1))
+
+	g.Expect(r.Delete(ctx, grant)).To(Succeed())
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(MatchError(errRefNotPermitted))
+	g.Expect(server.DNSRecords("web.example.com")).To(BeEmpty())
+
+	g.Expect(r.Get(ctx, req.NamespacedName, ingress)).To(Succeed())
+	g.Expect(ingress.Status.GetCondition(v1.TunnelIngressConditionTypeDNSRecord)).To(And(
+		HaveField("Status", corev1.ConditionFalse),
+		HaveField("Reason", v1.DNSRecordReasonRefNotPermitted),
+	))
+
+	// records of the hostname created by others afterwards are left alone
+	server.AddDNSRecord(cf.DNSRecord{
+		ZoneID:  zone.ID,
+		Type:    "CNAME",
+		Name:    "web.example.com",
+		Content: "other.cfargotunnel.com",
+	})
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(MatchError(errRefNotPermitted))
+	g.Expect(server.DNSRecords("web.example.com")).To(HaveLen(1))
+}
+
+func TestTunnelIngressChecksBackendBeforeRouting(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	server.AddZone(testIngressAccountID, "example.com")
+	ingress := newTestCrossNamespaceIngress(&v1.IngressOrigin{
+		Scheme:     v1.OriginSchemeHTTP,
+		BackendRef: &v1.BackendRef{Name: "web", Namespace: ptr.To("backends"), Port: intstr.FromInt32(80)},
+	})
+	r := newTestIngressReconciler(g, server, newTestTunnelGrant(v1.ReferenceGrantToKindTunnel), ingress)
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(ingress)})
+	g.Expect(err).To(MatchError(errRefNotPermitted))
+	g.Expect(server.Requests("PUT", "/routes$")).To(BeEmpty())
+	g.Expect(server.DNSRecords("web.example.com")).To(BeEmpty())
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const tunnelNetworkRouteFinalizerName = "tunnelnetworkroute.cloudflared-operator.bhyoo.com/finalizer"
+
+// TunnelNetworkRouteReconciler reconciles a TunnelNetworkRoute object
+type TunnelNetworkRouteReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Clock  clock.PassiveClock
+
+	CloudflareClients *cloudflare.ClientPool
+}
+
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelnetworkroutes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelnetworkroutes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=tunnelnetworkroutes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=virtualnetworks,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
+func (r *TunnelNetworkRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx).WithValues("tunnelNetworkRouteName", req.Name)
+	ctx = log.IntoContext(ctx, l)
+
+	var route v1.TunnelNetworkRoute
+	if err := r.Get(ctx, req.NamespacedName, &route); err != nil {
+		l.Error(err, "unable to fetch TunnelNetworkRoute")
+		// we'll ignore not-found errors, since they can't be fixed by an immediate
+		// requeue (we'll need to wait for a new notification), and we can get them
+		// on deleted requests.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// examine DeletionTimestamp to determine if object is under deletion
+	if !route.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&route, tunnelNetworkRouteFinalizerName) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.deleteTunnelNetworkRoute(ctx, &route); err != nil {
+			// if fail to delete the external dependency here, return with error
+			// so that it can be retried.
+			return ctrl.Result{}, err
+		}
+
+		if controllerutil.RemoveFinalizer(&route, tunnelNetworkRouteFinalizerName) {
+			return ctrl.Result{}, r.Update(ctx, &route)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// The object is not being deleted, so if it does not have our finalizer,
+	// then lets add the finalizer and update the object. This is equivalent
+	// to registering our finalizer.
+	if !controllerutil.ContainsFinalizer(&route, tunnelNetworkRouteFinalizerName) {
+		controllerutil.AddFinalizer(&route, tunnelNetworkRouteFinalizerName)
+		if err := r.Update(ctx, &route); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch route.Spec.TunnelRef.Kind {
+	case v1.TunnelKindTunnel:
+		tunnel, err := r.getTunnelFromRoute(ctx, &route)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// Tunnel is not found, we'll wait for it to be created
+				return ctrl.Result{}, client.IgnoreNotFound(err)
+			}
+			l.Error(err, "unable to fetch Tunnel")
+			return ctrl.Result{}, err
+		}
+		if err = r.reconcileRoute(ctx, &route, tunnel); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	default:
+		return ctrl.Result{}, errors.New("unsupported tunnel type")
+	}
+}
+
+func (r *TunnelNetworkRouteReconciler) findObjectsForTunnel(ctx context.Context, tunnel client.Object) []reconcile.Request {
+	var routes v1.TunnelNetworkRouteList
+	if err := r.List(
+		ctx,
+		&routes,
+		client.MatchingFields{tunnelRefNameField: tunnel.GetName(), tunnelRefKindField: string(v1.TunnelKindTunnel)},
+		client.InNamespace(tunnel.GetNamespace()),
+	); err != nil {
+		l := log.FromContext(ctx)
+		l.Error(err, "failed to listing network routes matched with tunnel name")
+		return []reconcile.Request{}
+	}
+
+	requests := make([]reconcile.Request, len(routes.Items))
+	for i, item := range routes.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		}
+	}
+	return requests
+}
+
+func (r *TunnelNetworkRouteReconciler) findObjectsForVirtualNetwork(
+	ctx context.Context,
+	vnet client.Object,
+) []reconcile.Request {
+	l := log.FromContext(ctx)
+
+	var tunnels v1.TunnelList
+	if err := r.List(ctx, &tunnels, client.InNamespace(vnet.GetNamespace())); err != nil {
+		l.Error(err, "failed to listing tunnels")
+		return []reconcile.Request{}
+	}
+	tunnelsUsingVnet := make(map[string]struct{})
+	for _, tunnel := range tunnels.Items {
+		if tunnel.Spec.VirtualNetworkRef != nil && tunnel.Spec.VirtualNetworkRef.Name == vnet.GetName() {
+			tunnelsUsingVnet[tunnel.Name] = struct{}{}
+		}
+	}
+
+	var routes v1.TunnelNetworkRouteList
+	if err := r.List(ctx, &routes, client.InNamespace(vnet.GetNamespace())); err != nil {
+		l.Error(err, "failed to listing network routes")
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	for _, item := range routes.Items {
+		switch {
+		case item.Spec.VirtualNetworkID != nil:
+			continue
+		case item.Spec.VirtualNetworkRef != nil:
+			if item.Spec.VirtualNetworkRef.Name != vnet.GetName() {
+				continue
+			}
+		case item.Spec.TunnelRef.Kind == v1.TunnelKindTunnel:
+			if _, ok := tunnelsUsingVnet[item.Spec.TunnelRef.Name]; !ok {
+				continue
+			}
+		default:
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TunnelNetworkRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctx := context.Background()
+
+	if err := mgr.GetFieldIndexer().IndexField(
+		ctx,
+		&v1.TunnelNetworkRoute{},
+		tunnelRefNameField,
+		indexRouteTunnelRefName,
+	); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(
+		ctx,
+		&v1.TunnelNetworkRoute{},
+		tunnelRefKindField,
+		indexRouteTunnelRefKind,
+	); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.TunnelNetworkRoute{}).
+		Watches(
+			&v1.Tunnel{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForTunnel),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&v1.VirtualNetwork{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForVirtualNetwork),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Complete(requeueOnRateLimit(r))
+}
+
+func (r *TunnelNetworkRouteReconciler) buildConditionRecorder(
+	ctx context.Context,
+	route *v1.TunnelNetworkRoute,
+	condType v1.TunnelNetworkRouteConditionType,
+) func(err error) error {
+	return func(err error) (cause error) {
+		defer func() {
+			if errors.Is(err, reconcile.TerminalError(nil)) &&
+				!errors.Is(cause, reconcile.TerminalError(nil)) {
+				cause = reconcile.TerminalError(cause)
+			}
+		}()
+
+		cause = err
+		var reason v1.TunnelNetworkRouteConditionReason = ""
+		var withReason ReasonedError[v1.TunnelNetworkRouteConditionReason]
+		if errors.As(err, &withReason) {
+			cause = withReason.Cause()
+			reason = withReason.Reason
+		}
+
+		newCond := v1.TunnelNetworkRouteStatusCondition{
+			Type:               condType,
+			Status:             corev1.ConditionFalse,
+			Message:            "",
+			Error:              fmt.Sprintf("%+v", cause),
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             reason,
+		}
+
+		if status, ok := cause.(apierrors.APIStatus); ok || errors.As(cause, &status) {
+			newCond.Error = string(status.Status().Reason)
+			newCond.Message = status.Status().Message
+		}
+
+		if !UpdateConditionIfChanged(&route.Status, newCond) {
+			return cause
+		}
+
+		if updateErr := r.Status().Update(ctx, route); updateErr != nil {
+			return errors.Join(cause, updateErr)
+		}
+		return cause
+	}
+}
+
+func (r *TunnelNetworkRouteReconciler) getTunnelFromRoute(
+	ctx context.Context,
+	route *v1.TunnelNetworkRoute,
+) (*v1.Tunnel, error) {
+	var tunnel v1.Tunnel
+	err := r.Get(ctx, client.ObjectKey{Namespace: route.GetNamespace(), Name: route.Spec.TunnelRef.Name}, &tunnel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tunnel, nil
+}
+
+func indexRouteTunnelRefName(rawObj client.Object) []string {
+	route := rawObj.(*v1.TunnelNetworkRoute)
+	if route.Spec.TunnelRef.Name == "" {
+		return nil
+	}
+	return []string{route.Spec.TunnelRef.Name}
+}
+
+func indexRouteTunnelRefKind(rawObj client.Object) []string {
+	route := rawObj.(*v1.TunnelNetworkRoute)
+	if route.Spec.TunnelRef.Kind == "" {
+		return nil
+	}
+	return []string{string(route.Spec.TunnelRef.Kind)}
+}
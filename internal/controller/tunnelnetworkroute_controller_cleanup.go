@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func (r *TunnelNetworkRouteReconciler) deleteTunnelNetworkRoute(
+	ctx context.Context,
+	route *v1.TunnelNetworkRoute,
+) error {
+	l := log.FromContext(ctx)
+
+	if route.Status.Network == "" {
+		return nil
+	}
+
+	var tunnel *v1.Tunnel
+	switch route.Spec.TunnelRef.Kind {
+	case v1.TunnelKindTunnel:
+		var err error
+		tunnel, err = r.getTunnelFromRoute(ctx, route)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			l.Error(err, "unable to fetch Tunnel")
+			return err
+		}
+
+	default:
+		return errors.New("unsupported tunnel type")
+	}
+
+	cfClient, err := newCloudflareClientForTunnel(
+		ctx,
+		r,
+		r.CloudflareClients,
+		tunnel,
+		v1.RouteReasonNoToken,
+		v1.RouteReasonFailedToConnectCF,
+		v1.RouteReasonAccountNotAllowed,
+	)
+	if err != nil {
+		if isUnusableAccountError(err) {
+			return nil
+		}
+		return err
+	}
+
+	return deleteOwnedNetworkRoute(
+		ctx,
+		cfClient,
+		tunnel.Spec.AccountID,
+		route.Status.Network,
+		route.Status.VirtualNetworkID,
+		route.Status.TunnelID,
+	)
+}
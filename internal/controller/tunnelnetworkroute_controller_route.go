@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+// errVirtualNetworkNotReady is for virtual networks that the VirtualNetwork watch will bring back.
+var errVirtualNetworkNotReady = errors.New("virtual network is not ready")
+
+func (r *TunnelNetworkRouteReconciler) reconcileRoute(
+	ctx context.Context,
+	route *v1.TunnelNetworkRoute,
+	tunnel *v1.Tunnel,
+) error {
+	l := log.FromContext(ctx)
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, route, v1.TunnelNetworkRouteConditionTypeRoute)
+
+	prefix, err := netip.ParsePrefix(route.Spec.Network)
+	if err != nil {
+		return recordConditionFrom(reconcile.TerminalError(WrapError(err, v1.RouteReasonInvalidNetwork)))
+	}
+	network := prefix.Masked().String()
+
+	if tunnel.Status.TunnelID == "" {
+		// the Tunnel watch brings us back once the tunnel is created on Cloudflare
+		return r.updateConditionIfDiff(ctx, route, v1.TunnelNetworkRouteStatusCondition{
+			Type:               v1.TunnelNetworkRouteConditionTypeRoute,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.RouteReasonTunnelNotReady,
+		})
+	}
+
+	vnetID, err := r.resolveVirtualNetworkID(ctx, route, tunnel)
+	if err != nil && !errors.Is(err, errVirtualNetworkNotReady) {
+		return recordConditionFrom(WrapError(err, v1.RouteReasonVirtualNetworkNotReady))
+	}
+	if err != nil {
+		// the VirtualNetwork watch brings us back once the virtual network is created on Cloudflare
+		return r.updateConditionIfDiff(ctx, route, v1.TunnelNetworkRouteStatusCondition{
+			Type:               v1.TunnelNetworkRouteConditionTypeRoute,
+			Status:             corev1.ConditionFalse,
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.RouteReasonVirtualNetworkNotReady,
+		})
+	}
+
+	cfClient, err := newCloudflareClientForTunnel(
+		ctx,
+		r,
+		r.CloudflareClients,
+		tunnel,
+		v1.RouteReasonNoToken,
+		v1.RouteReasonFailedToConnectCF,
+		v1.RouteReasonAccountNotAllowed,
+	)
+	if err != nil {
+		return recordConditionFrom(err)
+	}
+	accountID := tunnel.Spec.AccountID
+
+	// network or virtual network has been changed. remove previous one first.
+	if route.Status.Network != "" && (route.Status.Network != network || route.Status.VirtualNetworkID != vnetID) {
+		l.Info("network route has been changed. deleting previous one", "previous", route.Status.Network)
+		if err = deleteOwnedNetworkRoute(
+			ctx,
+			cfClient,
+			accountID,
+			route.Status.Network,
+			route.Status.VirtualNetworkID,
+			route.Status.TunnelID,
+		); err != nil {
+			return recordConditionFrom(WrapError(err, v1.RouteReasonFailedToDeleteRoute))
+		}
+		route.Status.Network = ""
+		route.Status.VirtualNetworkID = ""
+		route.Status.TunnelID = ""
+	}
+
+	desired := cloudflare.NetworkRoute{
+		Network:          network,
+		TunnelID:         tunnel.Status.TunnelID,
+		Comment:          ptr.Deref(route.Spec.Comment, ""),
+		VirtualNetworkID: vnetID,
+	}
+
+	existing, err := cfClient.GetNetworkRoute(ctx, accountID, network, vnetID)
+	if err != nil {
+		return recordConditionFrom(WrapError(err, v1.RouteReasonFailedToGetRoute))
+	}
+
+	switch {
+	case existing == nil:
+		if err := r.updateConditionIfDiff(ctx, route, v1.TunnelNetworkRouteStatusCondition{
+			Type:               v1.TunnelNetworkRouteConditionTypeRoute,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.RouteReasonCreating,
+		}); err != nil {
+			return err
+		}
+		if err = cfClient.CreateNetworkRoute(ctx, accountID, desired); err != nil {
+			return recordConditionFrom(WrapError(err, v1.RouteReasonFailedToCreateRoute))
+		}
+
+	// the route belongs to someone else. we never take it over.
+	case existing.TunnelID != tunnel.Status.TunnelID && existing.TunnelID != route.Status.TunnelID:
+		route.Status.ConflictingTunnelID = existing.TunnelID
+		return recordConditionFrom(WrapError(
+			fmt.Errorf("network %s is already routed to tunnel %s (%s)", network, existing.TunnelName, existing.TunnelID),
+			v1.RouteReasonConflict,
+		))
+
+	// the tunnel has been recreated, or the comment has been changed.
+	case existing.TunnelID != tunnel.Status.TunnelID || existing.Comment != desired.Comment:
+		if err = cfClient.UpdateNetworkRoute(ctx, accountID, desired); err != nil {
+			return recordConditionFrom(WrapError(err, v1.RouteReasonFailedToUpdateRoute))
+		}
+	}
+
+	var dirtyStatus bool
+	if route.Status.Network != network || route.Status.VirtualNetworkID != vnetID ||
+		route.Status.TunnelID != tunnel.Status.TunnelID || route.Status.ConflictingTunnelID != "" {
+		dirtyStatus = true
+		route.Status.Network = network
+		route.Status.VirtualNetworkID = vnetID
+		route.Status.TunnelID = tunnel.Status.TunnelID
+		route.Status.ConflictingTunnelID = ""
+	}
+
+	if UpdateConditionIfChanged(&route.Status, v1.TunnelNetworkRouteStatusCondition{
+		Type:               v1.TunnelNetworkRouteConditionTypeRoute,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	}) {
+		dirtyStatus = true
+	}
+
+	if dirtyStatus {
+		return r.Status().Update(ctx, route)
+	}
+	return nil
+}
+
+// resolveVirtualNetworkID picks the virtual network of the route in order of
+// spec.virtualNetworkID, spec.virtualNetworkRef and the tunnel's spec.virtualNetworkRef.
+// An empty ID means the account's default virtual network.
+func (r *TunnelNetworkRouteReconciler) resolveVirtualNetworkID(
+	ctx context.Context,
+	route *v1.TunnelNetworkRoute,
+	tunnel *v1.Tunnel,
+) (string, error) {
+	if route.Spec.VirtualNetworkID != nil {
+		return *route.Spec.VirtualNetworkID, nil
+	}
+
+	ref := route.Spec.VirtualNetworkRef
+	if ref == nil {
+		ref = tunnel.Spec.VirtualNetworkRef
+	}
+	if ref == nil {
+		return "", nil
+	}
+
+	var vnet v1.VirtualNetwork
+	if err := r.Get(ctx, client.ObjectKey{Namespace: route.Namespace, Name: ref.Name}, &vnet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("%w: virtual network %s is not found", errVirtualNetworkNotReady, ref.Name)
+		}
+		return "", err
+	}
+	if vnet.Status.VirtualNetworkID == "" {
+		return "", fmt.Errorf("%w: virtual network %s is not created yet", errVirtualNetworkNotReady, ref.Name)
+	}
+	if vnet.Status.AccountID != tunnel.Spec.AccountID {
+		return "", fmt.Errorf("%w: virtual network %s belongs to another account", errVirtualNetworkNotReady, ref.Name)
+	}
+	return vnet.Status.VirtualNetworkID, nil
+}
+
+func (r *TunnelNetworkRouteReconciler) updateConditionIfDiff(
+	ctx context.Context,
+	route *v1.TunnelNetworkRoute,
+	cond v1.TunnelNetworkRouteStatusCondition,
+) error {
+	if UpdateConditionIfChanged(&route.Status, cond) {
+		return r.Status().Update(ctx, route)
+	}
+	return nil
+}
+
+// deleteOwnedNetworkRoute deletes the route only if it still points to the given tunnel.
+func deleteOwnedNetworkRoute(
+	ctx context.Context,
+	cfClient cloudflare.Client,
+	accountID, network, vnetID, tunnelID string,
+) error {
+	existing, err := cfClient.GetNetworkRoute(ctx, accountID, network, vnetID)
+	if err != nil {
+		return err
+	}
+	if existing == nil || existing.TunnelID != tunnelID {
+		return nil
+	}
+	return cfClient.DeleteNetworkRoute(ctx, accountID, existing.Network, existing.VirtualNetworkID)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+const virtualNetworkFinalizerName = "virtualnetwork.cloudflared-operator.bhyoo.com/finalizer"
+
+// VirtualNetworkReconciler reconciles a VirtualNetwork object
+type VirtualNetworkReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Clock  clock.PassiveClock
+
+	CloudflareClients *cloudflare.ClientPool
+}
+
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=virtualnetworks,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=virtualnetworks/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cloudflared-operator.bhyoo.com,resources=virtualnetworks/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
+func (r *VirtualNetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx).WithValues("virtualNetworkName", req.Name)
+	ctx = log.IntoContext(ctx, l)
+
+	var vnet v1.VirtualNetwork
+	if err := r.Get(ctx, req.NamespacedName, &vnet); err != nil {
+		l.Error(err, "unable to fetch VirtualNetwork")
+		// we'll ignore not-found errors, since they can't be fixed by an immediate
+		// requeue (we'll need to wait for a new notification), and we can get them
+		// on deleted requests.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// examine DeletionTimestamp to determine if object is under deletion
+	if !vnet.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&vnet, virtualNetworkFinalizerName) {
+			return ctrl.Result{}, nil
+		}
+		if vnet.Spec.DeletionProtection {
+			// keep the finalizer. disabling the protection triggers another reconciliation.
+			l.Info("virtual network is protected from deletion")
+			return ctrl.Result{}, r.updateConditionIfDiff(ctx, &vnet, v1.VirtualNetworkStatusCondition{
+				Type:               v1.VirtualNetworkConditionTypeVirtualNetwork,
+				Status:             corev1.ConditionFalse,
+				Message:            "disable spec.deletionProtection to delete the virtual network",
+				LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+				Reason:             v1.VirtualNetworkReasonDeletionProtected,
+			})
+		}
+		if err := r.deleteVirtualNetwork(ctx, &vnet); err != nil {
+			// if fail to delete the external dependency here, return with error
+			// so that it can be retried.
+			return ctrl.Result{}, err
+		}
+
+		if controllerutil.RemoveFinalizer(&vnet, virtualNetworkFinalizerName) {
+			return ctrl.Result{}, r.Update(ctx, &vnet)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// The object is not being deleted, so if it does not have our finalizer,
+	// then lets add the finalizer and update the object. This is equivalent
+	// to registering our finalizer.
+	if !controllerutil.ContainsFinalizer(&vnet, virtualNetworkFinalizerName) {
+		controllerutil.AddFinalizer(&vnet, virtualNetworkFinalizerName)
+		if err := r.Update(ctx, &vnet); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.reconcileVirtualNetwork(ctx, &vnet); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VirtualNetworkReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.VirtualNetwork{}).
+		Complete(requeueOnRateLimit(r))
+}
+
+func (r *VirtualNetworkReconciler) buildConditionRecorder(
+	ctx context.Context,
+	vnet *v1.VirtualNetwork,
+	condType v1.VirtualNetworkConditionType,
+) func(err error) error {
+	return func(err error) (cause error) {
+		defer func() {
+			if errors.Is(err, reconcile.TerminalError(nil)) &&
+				!errors.Is(cause, reconcile.TerminalError(nil)) {
+				cause = reconcile.TerminalError(cause)
+			}
+		}()
+
+		cause = err
+		var reason v1.VirtualNetworkConditionReason = ""
+		var withReason ReasonedError[v1.VirtualNetworkConditionReason]
+		if errors.As(err, &withReason) {
+			cause = withReason.Cause()
+			reason = withReason.Reason
+		}
+
+		newCond := v1.VirtualNetworkStatusCondition{
+			Type:               condType,
+			Status:             corev1.ConditionFalse,
+			Message:            "",
+			Error:              fmt.Sprintf("%+v", cause),
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             reason,
+		}
+
+		if status, ok := cause.(apierrors.APIStatus); ok || errors.As(cause, &status) {
+			newCond.Error = string(status.Status().Reason)
+			newCond.Message = status.Status().Message
+		}
+
+		if !UpdateConditionIfChanged(&vnet.Status, newCond) {
+			return cause
+		}
+
+		if updateErr := r.Status().Update(ctx, vnet); updateErr != nil {
+			return errors.Join(cause, updateErr)
+		}
+		return cause
+	}
+}
+
+func (r *VirtualNetworkReconciler) updateConditionIfDiff(
+	ctx context.Context,
+	vnet *v1.VirtualNetwork,
+	cond v1.VirtualNetworkStatusCondition,
+) error {
+	if UpdateConditionIfChanged(&vnet.Status, cond) {
+		return r.Status().Update(ctx, vnet)
+	}
+	return nil
+}
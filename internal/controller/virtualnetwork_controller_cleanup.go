@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"context"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func (r *VirtualNetworkReconciler) deleteVirtualNetwork(ctx context.Context, vnet *v1.VirtualNetwork) error {
+	if vnet.Status.VirtualNetworkID == "" {
+		return nil
+	}
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, vnet, v1.VirtualNetworkConditionTypeVirtualNetwork)
+
+	cfClient, err := newCloudflareClient(
+		ctx,
+		r,
+		r.CloudflareClients,
+		vnet.Spec.AccountID,
+		vnet.Namespace,
+		vnet.Spec.APITokenSecretRef,
+		v1.VirtualNetworkReasonNoToken,
+		v1.VirtualNetworkReasonFailedToConnectCF,
+		v1.VirtualNetworkReasonAccountNotAllowed,
+	)
+	if err != nil {
+		if isUnusableAccountError(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Cloudflare refuses to delete a virtual network that still has routes, so it is retried until they are gone.
+	if err = cfClient.DeleteVirtualNetwork(ctx, vnet.Status.AccountID, vnet.Status.VirtualNetworkID); err != nil {
+		return recordConditionFrom(WrapError(err, v1.VirtualNetworkReasonFailedToDelete))
+	}
+	return nil
+}
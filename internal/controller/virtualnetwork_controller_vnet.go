@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/cloudflare"
+)
+
+func (r *VirtualNetworkReconciler) reconcileVirtualNetwork(ctx context.Context, vnet *v1.VirtualNetwork) error {
+	l := log.FromContext(ctx)
+
+	recordConditionFrom := r.buildConditionRecorder(ctx, vnet, v1.VirtualNetworkConditionTypeVirtualNetwork)
+
+	cfClient, err := newCloudflareClient(
+		ctx,
+		r,
+		r.CloudflareClients,
+		vnet.Spec.AccountID,
+		vnet.Namespace,
+		vnet.Spec.APITokenSecretRef,
+		v1.VirtualNetworkReasonNoToken,
+		v1.VirtualNetworkReasonFailedToConnectCF,
+		v1.VirtualNetworkReasonAccountNotAllowed,
+	)
+	if err != nil {
+		return recordConditionFrom(err)
+	}
+	accountID := vnet.Spec.AccountID
+
+	var existing *cloudflare.VirtualNetwork
+	if vnet.Status.VirtualNetworkID != "" && vnet.Status.AccountID == accountID {
+		existing, err = cfClient.GetVirtualNetwork(ctx, accountID, vnet.Status.VirtualNetworkID)
+		if err != nil {
+			return recordConditionFrom(WrapError(err, v1.VirtualNetworkReasonFailedToGet))
+		}
+	}
+	if existing == nil {
+		// adopt the one that has been created manually or by previous reconciliation that failed to update status
+		existing, err = cfClient.GetVirtualNetworkByName(ctx, accountID, vnet.Spec.Name)
+		if err != nil {
+			return recordConditionFrom(WrapError(err, v1.VirtualNetworkReasonFailedToGet))
+		}
+	}
+
+	desired := cloudflare.VirtualNetwork{
+		Name:      vnet.Spec.Name,
+		Comment:   ptr.Deref(vnet.Spec.Comment, ""),
+		IsDefault: vnet.Spec.IsDefault,
+	}
+
+	switch {
+	case existing == nil:
+		if err := r.updateConditionIfDiff(ctx, vnet, v1.VirtualNetworkStatusCondition{
+			Type:               v1.VirtualNetworkConditionTypeVirtualNetwork,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+			Reason:             v1.VirtualNetworkReasonCreating,
+		}); err != nil {
+			return err
+		}
+		desired.ID, err = cfClient.CreateVirtualNetwork(ctx, accountID, desired)
+		if err != nil {
+			return recordConditionFrom(WrapError(err, v1.VirtualNetworkReasonFailedToCreate))
+		}
+
+	default:
+		desired.ID = existing.ID
+		// an account always has exactly one default virtual network, so it can only be moved to another one.
+		desired.IsDefault = existing.IsDefault || vnet.Spec.IsDefault
+		if existing.Name != desired.Name || existing.Comment != desired.Comment ||
+			existing.IsDefault != desired.IsDefault {
+			l.Info("virtual network has been changed. updating...")
+			if err = cfClient.UpdateVirtualNetwork(ctx, accountID, desired); err != nil {
+				return recordConditionFrom(WrapError(err, v1.VirtualNetworkReasonFailedToUpdate))
+			}
+		}
+	}
+
+	var dirtyStatus bool
+	if vnet.Status.VirtualNetworkID != desired.ID || vnet.Status.AccountID != accountID ||
+		vnet.Status.IsDefault != desired.IsDefault {
+		dirtyStatus = true
+		vnet.Status.VirtualNetworkID = desired.ID
+		vnet.Status.AccountID = accountID
+		vnet.Status.IsDefault = desired.IsDefault
+	}
+
+	if UpdateConditionIfChanged(&vnet.Status, v1.VirtualNetworkStatusCondition{
+		Type:               v1.VirtualNetworkConditionTypeVirtualNetwork,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: r.Clock.Now()},
+	}) {
+		dirtyStatus = true
+	}
+
+	if dirtyStatus {
+		return r.Status().Update(ctx, vnet)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+var ErrViolation = errors.New("violates tunnel policy")
+
+// CheckTunnelIngress returns ErrViolation if ingress does not satisfy every TunnelPolicy that selects its namespace.
+// The account of its Tunnel is checked as well, once the Tunnel exists.
+func CheckTunnelIngress(ctx context.Context, reader client.Reader, ingress *v1.TunnelIngress) error {
+	var accountID *string
+	if ingress.Spec.TunnelRef.Kind == v1.TunnelKindTunnel {
+		var tunnel v1.Tunnel
+		err := reader.Get(ctx, client.ObjectKey{
+			Namespace: ptr.Deref(ingress.Spec.TunnelRef.Namespace, ingress.Namespace),
+			Name:      ingress.Spec.TunnelRef.Name,
+		}, &tunnel)
+		switch {
+		case err == nil:
+			accountID = &tunnel.Spec.AccountID
+		case !apierrors.IsNotFound(err):
+			return err
+		}
+	}
+
+	return check(ctx, reader, ingress.Namespace, func(policy v1.TunnelPolicy) []error {
+		violations := checkIngress(policy, ingress)
+		if accountID != nil {
+			violations = append(violations, checkAccount(policy, *accountID)...)
+		}
+		return violations
+	})
+}
+
+// CheckDNSRecord returns ErrViolation if record does not satisfy every TunnelPolicy that selects its namespace.
+// overwrite tells whether the record takes over an existing one on Cloudflare that differs from its spec.
+func CheckDNSRecord(ctx context.Context, reader client.Reader, record *v1.DNSRecord, overwrite bool) error {
+	return check(ctx, reader, record.Namespace, func(policy v1.TunnelPolicy) []error {
+		return checkClaim(policy, record.Spec.Name, record.Spec.AccountID, overwrite)
+	})
+}
+
+// CheckLoadBalancer returns ErrViolation if lb does not satisfy every TunnelPolicy that selects its namespace.
+// overwrite tells whether lb takes over an existing load balancer on Cloudflare that differs from its spec.
+func CheckLoadBalancer(ctx context.Context, reader client.Reader, lb *v1.LoadBalancer, overwrite bool) error {
+	return check(ctx, reader, lb.Namespace, func(policy v1.TunnelPolicy) []error {
+		return checkClaim(policy, lb.Spec.Hostname, lb.Spec.AccountID, overwrite)
+	})
+}
+
+// check collects violations of every TunnelPolicy that selects the namespace.
+func check(
+	ctx context.Context,
+	reader client.Reader,
+	namespaceName string,
+	violationsOf func(policy v1.TunnelPolicy) []error,
+) error {
+	var namespace corev1.Namespace
+	if err := reader.Get(ctx, client.ObjectKey{Name: namespaceName}, &namespace); err != nil {
+		return err
+	}
+	var policies v1.TunnelPolicyList
+	if err := reader.List(ctx, &policies); err != nil {
+		return err
+	}
+
+	var violations []error
+	for _, policy := range policies.Items {
+		selected, err := selectsNamespace(policy, namespace)
+		if err != nil {
+			// policies that can not be evaluated deny rather than allow
+			violations = append(violations, fmt.Errorf("TunnelPolicy %s has invalid namespaceSelector: %w", policy.Name, err))
+			continue
+		}
+		if selected {
+			violations = append(violations, violationsOf(policy)...)
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrViolation, errors.Join(violations...))
+}
+
+func selectsNamespace(policy v1.TunnelPolicy, namespace corev1.Namespace) (bool, error) {
+	if policy.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+func checkIngress(policy v1.TunnelPolicy, ingress *v1.TunnelIngress) []error {
+	spec := policy.Spec
+	violations := checkClaim(policy, ptr.Deref(ingress.Spec.Hostname, ""), "", ingress.Spec.OverwriteExistingDNS)
+
+	tunnelNamespace := ptr.Deref(ingress.Spec.TunnelRef.Namespace, ingress.Namespace)
+	if len(spec.AllowedTunnels) > 0 && !slices.ContainsFunc(spec.AllowedTunnels, func(ref v1.PolicyTunnelRef) bool {
+		return ref.Kind == ingress.Spec.TunnelRef.Kind &&
+			ptr.Deref(ref.Namespace, ingress.Namespace) == tunnelNamespace &&
+			(ref.Name == "*" || ref.Name == ingress.Spec.TunnelRef.Name)
+	}) {
+		violations = append(violations, fmt.Errorf(
+			"TunnelPolicy %s does not allow %s %s/%s",
+			policy.Name,
+			ingress.Spec.TunnelRef.Kind,
+			tunnelNamespace,
+			ingress.Spec.TunnelRef.Name,
+		))
+	}
+
+	scheme := ingressScheme(ingress.Spec)
+	if len(spec.AllowedOriginSchemes) > 0 && !slices.Contains(spec.AllowedOriginSchemes, scheme) {
+		violations = append(violations, fmt.Errorf("TunnelPolicy %s does not allow origin scheme %q", policy.Name, scheme))
+	}
+
+	return violations
+}
+
+// checkClaim checks hostname, account and overwriting existing DNS of Cloudflare that every kind claims.
+// Empty accountID is not checked.
+func checkClaim(policy v1.TunnelPolicy, hostname, accountID string, overwrite bool) []error {
+	spec := policy.Spec
+	var violations []error
+
+	hostname = strings.ToLower(hostname)
+	if len(spec.AllowedHostnames) > 0 && !slices.ContainsFunc(spec.AllowedHostnames, func(p v1.HostnamePattern) bool {
+		return matchHostname(p, hostname)
+	}) {
+		violations = append(violations, fmt.Errorf("TunnelPolicy %s does not allow hostname %q", policy.Name, hostname))
+	}
+
+	if accountID != "" {
+		violations = append(violations, checkAccount(policy, accountID)...)
+	}
+
+	if overwrite && !spec.AllowOverwriteExistingDNS {
+		violations = append(violations, fmt.Errorf("TunnelPolicy %s does not allow overwriting existing DNS", policy.Name))
+	}
+	return violations
+}
+
+func checkAccount(policy v1.TunnelPolicy, accountID string) []error {
+	if len(policy.Spec.AllowedAccountIDs) == 0 || slices.Contains(policy.Spec.AllowedAccountIDs, accountID) {
+		return nil
+	}
+	return []error{fmt.Errorf("TunnelPolicy %s does not allow account %q", policy.Name, accountID)}
+}
+
+// matchHostname tells whether hostname is pattern, or a subdomain of the wildcard pattern like `*.example.com`.
+func matchHostname(pattern v1.HostnamePattern, hostname string) bool {
+	p := strings.ToLower(string(pattern))
+	if suffix, ok := strings.CutPrefix(p, "*"); ok {
+		return len(hostname) > len(suffix) && strings.HasSuffix(hostname, suffix)
+	}
+	return p == hostname
+}
+
+// ingressScheme is the scheme of typed origin, or the one parsed from service of cloudflared ingress rule.
+func ingressScheme(spec v1.TunnelIngressSpec) v1.OriginScheme {
+	if spec.Origin != nil {
+		return spec.Origin.Scheme
+	}
+	if scheme, _, ok := strings.Cut(spec.Service, "://"); ok {
+		return v1.OriginScheme(scheme)
+	}
+	// unix:, unix+tls: and http_status: have no slashes, while hello_world and the like have no colon
+	scheme, _, _ := strings.Cut(spec.Service, ":")
+	return v1.OriginScheme(scheme)
+}
@@ -0,0 +1,158 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/isac322/cloudflared-operator/api/v1"
+)
+
+func newTestReader(g Gomega, objects ...client.Object) client.Reader {
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(v1.AddToScheme(scheme)).To(Succeed())
+
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps", Labels: map[string]string{"team": "a"}}}).
+		WithObjects(objects...).
+		Build()
+}
+
+func newTestPolicy(spec v1.TunnelPolicySpec) *v1.TunnelPolicy {
+	return &v1.TunnelPolicy{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}, Spec: spec}
+}
+
+func TestCheckTunnelIngressAccount(t *testing.T) {
+	t.Parallel()
+
+	ingress := &v1.TunnelIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+		Spec: v1.TunnelIngressSpec{
+			TunnelConfigIngress: v1.TunnelConfigIngress{
+				Hostname: ptr.To("web.example.com"),
+				Service:  "http://web",
+			},
+			TunnelRef: v1.TunnelRef{Name: "public", Namespace: ptr.To("shared"), Kind: v1.TunnelKindTunnel},
+		},
+	}
+	newTunnel := func(accountID string) *v1.Tunnel {
+		return &v1.Tunnel{
+			ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "shared"},
+			Spec:       v1.TunnelSpec{AccountID: accountID},
+		}
+	}
+
+	tests := map[string]struct {
+		objects []client.Object
+		denied  bool
+	}{
+		"allowed account": {
+			objects: []client.Object{
+				newTestPolicy(v1.TunnelPolicySpec{AllowedAccountIDs: []string{"account-a"}}),
+				newTunnel("account-a"),
+			},
+		},
+		"other account": {
+			objects: []client.Object{
+				newTestPolicy(v1.TunnelPolicySpec{AllowedAccountIDs: []string{"account-a"}}),
+				newTunnel("account-b"),
+			},
+			denied: true,
+		},
+		"every account is allowed if omitted": {
+			objects: []client.Object{newTestPolicy(v1.TunnelPolicySpec{}), newTunnel("account-b")},
+		},
+		"missing tunnel is left to the reconciler": {
+			objects: []client.Object{newTestPolicy(v1.TunnelPolicySpec{AllowedAccountIDs: []string{"account-a"}})},
+		},
+		"policy of other namespaces": {
+			objects: []client.Object{
+				newTestPolicy(v1.TunnelPolicySpec{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}},
+					AllowedAccountIDs: []string{"account-a"},
+				}),
+				newTunnel("account-b"),
+			},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			err := CheckTunnelIngress(context.Background(), newTestReader(g, tc.objects...), ingress)
+			if tc.denied {
+				g.Expect(errors.Is(err, ErrViolation)).To(BeTrue())
+				g.Expect(err).To(MatchError(ContainSubstring(`does not allow account "account-b"`)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestCheckClaims(t *testing.T) {
+	t.Parallel()
+
+	policy := newTestPolicy(v1.TunnelPolicySpec{
+		AllowedHostnames:  []v1.HostnamePattern{"*.team-a.example.com"},
+		AllowedAccountIDs: []string{"account-a"},
+	})
+	tests := map[string]struct {
+		hostname  string
+		accountID string
+		overwrite bool
+		violation string
+	}{
+		"allowed":        {hostname: "web.team-a.example.com", accountID: "account-a"},
+		"other hostname": {hostname: "web.example.com", accountID: "account-a", violation: "hostname"},
+		"other account":  {hostname: "web.team-a.example.com", accountID: "account-b", violation: "account"},
+		"overwrite is denied": {
+			hostname:  "web.team-a.example.com",
+			accountID: "account-a",
+			overwrite: true,
+			violation: "overwriting",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			ctx := context.Background()
+			reader := newTestReader(g, policy)
+
+			record := &v1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+				Spec:       v1.DNSRecordSpec{Name: tc.hostname, AccountID: tc.accountID},
+			}
+			lb := &v1.LoadBalancer{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+				Spec:       v1.LoadBalancerSpec{Hostname: tc.hostname, AccountID: tc.accountID},
+			}
+			for _, err := range []error{
+				CheckDNSRecord(ctx, reader, record, tc.overwrite),
+				CheckLoadBalancer(ctx, reader, lb, tc.overwrite),
+			} {
+				if tc.violation == "" {
+					g.Expect(err).NotTo(HaveOccurred())
+					continue
+				}
+				g.Expect(errors.Is(err, ErrViolation)).To(BeTrue())
+				g.Expect(err).To(MatchError(ContainSubstring("does not allow " + tc.violation)))
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cloudflaredoperatorv1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/policy"
+)
+
+// log is for logging in this package.
+var dnsrecordlog = logf.Log.WithName("dnsrecord-resource")
+
+// SetupDNSRecordWebhookWithManager registers the webhook for DNSRecord in the manager.
+func SetupDNSRecordWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&cloudflaredoperatorv1.DNSRecord{}).
+		WithValidator(&DNSRecordCustomValidator{Reader: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-cloudflared-operator-bhyoo-com-v1-dnsrecord,mutating=false,failurePolicy=fail,sideEffects=None,groups=cloudflared-operator.bhyoo.com,resources=dnsrecords,verbs=create;update,versions=v1,name=vdnsrecord.kb.io,admissionReviewVersions=v1
+
+// DNSRecordCustomValidator rejects DNSRecords that violate TunnelPolicies.
+// Existing ones on Cloudflare are unknown at admission, so overwriting them is checked by the reconciler.
+type DNSRecordCustomValidator struct {
+	Reader client.Reader
+}
+
+var _ webhook.CustomValidator = &DNSRecordCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type DNSRecord.
+func (v *DNSRecordCustomValidator) ValidateCreate(
+	ctx context.Context,
+	obj runtime.Object,
+) (admission.Warnings, error) {
+	record, ok := obj.(*cloudflaredoperatorv1.DNSRecord)
+	if !ok {
+		return nil, fmt.Errorf("expected a DNSRecord object but got %T", obj)
+	}
+	dnsrecordlog.Info("Validation for DNSRecord upon creation", "name", record.GetName())
+
+	return nil, policy.CheckDNSRecord(ctx, v.Reader, record, false)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type DNSRecord.
+func (v *DNSRecordCustomValidator) ValidateUpdate(
+	ctx context.Context,
+	oldObj, newObj runtime.Object,
+) (admission.Warnings, error) {
+	oldDNSRecord, ok := oldObj.(*cloudflaredoperatorv1.DNSRecord)
+	if !ok {
+		return nil, fmt.Errorf("expected a DNSRecord object for the oldObj but got %T", oldObj)
+	}
+	record, ok := newObj.(*cloudflaredoperatorv1.DNSRecord)
+	if !ok {
+		return nil, fmt.Errorf("expected a DNSRecord object for the newObj but got %T", newObj)
+	}
+	dnsrecordlog.Info("Validation for DNSRecord upon update", "name", record.GetName())
+
+	// metadata and status must stay writable, so that finalizers of objects created before a policy can be removed
+	if equality.Semantic.DeepEqual(oldDNSRecord.Spec, record.Spec) {
+		return nil, nil
+	}
+	return nil, policy.CheckDNSRecord(ctx, v.Reader, record, false)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type DNSRecord.
+func (v *DNSRecordCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
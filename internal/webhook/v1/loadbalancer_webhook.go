@@ -0,0 +1,95 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cloudflaredoperatorv1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/policy"
+)
+
+// log is for logging in this package.
+var loadbalancerlog = logf.Log.WithName("loadbalancer-resource")
+
+// SetupLoadBalancerWebhookWithManager registers the webhook for LoadBalancer in the manager.
+func SetupLoadBalancerWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&cloudflaredoperatorv1.LoadBalancer{}).
+		WithValidator(&LoadBalancerCustomValidator{Reader: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-cloudflared-operator-bhyoo-com-v1-loadbalancer,mutating=false,failurePolicy=fail,sideEffects=None,groups=cloudflared-operator.bhyoo.com,resources=loadbalancers,verbs=create;update,versions=v1,name=vloadbalancer.kb.io,admissionReviewVersions=v1
+
+// LoadBalancerCustomValidator rejects LoadBalancers that violate TunnelPolicies.
+// Existing ones on Cloudflare are unknown at admission, so overwriting them is checked by the reconciler.
+type LoadBalancerCustomValidator struct {
+	Reader client.Reader
+}
+
+var _ webhook.CustomValidator = &LoadBalancerCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type LoadBalancer.
+func (v *LoadBalancerCustomValidator) ValidateCreate(
+	ctx context.Context,
+	obj runtime.Object,
+) (admission.Warnings, error) {
+	lb, ok := obj.(*cloudflaredoperatorv1.LoadBalancer)
+	if !ok {
+		return nil, fmt.Errorf("expected a LoadBalancer object but got %T", obj)
+	}
+	loadbalancerlog.Info("Validation for LoadBalancer upon creation", "name", lb.GetName())
+
+	return nil, policy.CheckLoadBalancer(ctx, v.Reader, lb, false)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type LoadBalancer.
+func (v *LoadBalancerCustomValidator) ValidateUpdate(
+	ctx context.Context,
+	oldObj, newObj runtime.Object,
+) (admission.Warnings, error) {
+	oldLoadBalancer, ok := oldObj.(*cloudflaredoperatorv1.LoadBalancer)
+	if !ok {
+		return nil, fmt.Errorf("expected a LoadBalancer object for the oldObj but got %T", oldObj)
+	}
+	lb, ok := newObj.(*cloudflaredoperatorv1.LoadBalancer)
+	if !ok {
+		return nil, fmt.Errorf("expected a LoadBalancer object for the newObj but got %T", newObj)
+	}
+	loadbalancerlog.Info("Validation for LoadBalancer upon update", "name", lb.GetName())
+
+	// metadata and status must stay writable, so that finalizers of objects created before a policy can be removed
+	if equality.Semantic.DeepEqual(oldLoadBalancer.Spec, lb.Spec) {
+		return nil, nil
+	}
+	return nil, policy.CheckLoadBalancer(ctx, v.Reader, lb, false)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type LoadBalancer.
+func (v *LoadBalancerCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 Byeonghoon Yoo.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cloudflaredoperatorv1 "github.com/isac322/cloudflared-operator/api/v1"
+	"github.com/isac322/cloudflared-operator/internal/policy"
+)
+
+// log is for logging in this package.
+var tunnelingresslog = logf.Log.WithName("tunnelingress-resource")
+
+// SetupTunnelIngressWebhookWithManager registers the webhook for TunnelIngress in the manager.
+func SetupTunnelIngressWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&cloudflaredoperatorv1.TunnelIngress{}).
+		WithValidator(&TunnelIngressCustomValidator{Reader: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-cloudflared-operator-bhyoo-com-v1-tunnelingress,mutating=false,failurePolicy=fail,sideEffects=None,groups=cloudflared-operator.bhyoo.com,resources=tunnelingresses,verbs=create;update,versions=v1,name=vtunnelingress.kb.io,admissionReviewVersions=v1
+
+// TunnelIngressCustomValidator rejects TunnelIngresses that violate TunnelPolicies.
+type TunnelIngressCustomValidator struct {
+	Reader client.Reader
+}
+
+var _ webhook.CustomValidator = &TunnelIngressCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type TunnelIngress.
+func (v *TunnelIngressCustomValidator) ValidateCreate(
+	ctx context.Context,
+	obj runtime.Object,
+) (admission.Warnings, error) {
+	ingress, ok := obj.(*cloudflaredoperatorv1.TunnelIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a TunnelIngress object but got %T", obj)
+	}
+	tunnelingresslog.Info("Validation for TunnelIngress upon creation", "name", ingress.GetName())
+
+	return deprecationWarnings(ingress), policy.CheckTunnelIngress(ctx, v.Reader, ingress)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type TunnelIngress.
+func (v *TunnelIngressCustomValidator) ValidateUpdate(
+	ctx context.Context,
+	oldObj, newObj runtime.Object,
+) (admission.Warnings, error) {
+	oldIngress, ok := oldObj.(*cloudflaredoperatorv1.TunnelIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a TunnelIngress object for the oldObj but got %T", oldObj)
+	}
+	ingress, ok := newObj.(*cloudflaredoperatorv1.TunnelIngress)
+	if !ok {
+		return nil, fmt.Errorf("expected a TunnelIngress object for the newObj but got %T", newObj)
+	}
+	tunnelingresslog.Info("Validation for TunnelIngress upon update", "name", ingress.GetName())
+
+	// metadata and status must stay writable, so that finalizers of ingresses created before a policy can be removed
+	warnings := deprecationWarnings(ingress)
+	if equality.Semantic.DeepEqual(oldIngress.Spec, ingress.Spec) {
+		return warnings, nil
+	}
+	return warnings, policy.CheckTunnelIngress(ctx, v.Reader, ingress)
+}
+
+// deprecationWarnings warns about the host:port form of service, which earlier releases exposed Services with.
+func deprecationWarnings(ingress *cloudflaredoperatorv1.TunnelIngress) admission.Warnings {
+	if !cloudflaredoperatorv1.IsLegacyService(ingress.Spec.Service) {
+		return nil
+	}
+	return admission.Warnings{fmt.Sprintf(
+		"spec.service %q is deprecated, use %q or spec.origin instead",
+		ingress.Spec.Service,
+		"http://"+ingress.Spec.Service,
+	)}
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type TunnelIngress.
+func (v *TunnelIngressCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}